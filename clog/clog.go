@@ -2,10 +2,12 @@ package clog
 
 import (
 	"context"
+	"fmt"
 	"log"
 	"os"
 	"sync"
 	"sync/atomic"
+	"time"
 
 	"github.com/ceyewan/infra-kit/clog/internal"
 	"go.uber.org/zap"
@@ -14,8 +16,14 @@ import (
 // Logger 定义统一的日志记录接口，封装 zap.Logger 提供类型安全的使用方式
 type Logger = internal.Logger
 
+// MetricLogger 以固定消息记录指标的日志器，由 Logger.Metric 创建
+type MetricLogger = internal.MetricLogger
+
+// Record 一条日志的快照，传递给 OnFatal 注册的回调和 WithEncoder 设置的自定义编码器
+type Record = internal.Record
+
 var (
-	// defaultLogger 全局默认日志器，使用 atomic.Value 保证并发安全
+	// defaultLogger 全局默认日志器，存放 *globalLogger，使用 atomic.Value 保证并发安全
 	defaultLogger atomic.Value
 
 	// defaultLoggerOnce 确保默认日志器只初始化一次
@@ -26,8 +34,21 @@ var (
 
 	// traceIDKey 类型安全的上下文键，避免字符串键冲突
 	traceIDKey struct{}
+
+	// loggerKey 嵌入 context 的日志器的键
+	// 使用独立的命名类型，避免与同为 struct{} 值的 traceIDKey 相等
+	loggerKey loggerContextKey
+
+	// contextDeadline 由 Init 的 WithContextDeadline 选项开启，WithContext 自动添加剩余时间字段
+	contextDeadline atomic.Bool
 )
 
+// loggerContextKey IntoContext 使用的上下文键类型
+type loggerContextKey struct{}
+
+// requestBufferKey BufferedContext 使用的上下文键类型
+type requestBufferKey struct{}
+
 // SetExitFunc 设置退出函数，用于测试时模拟 os.Exit 行为
 // 调用此函数后，Fatal 日志将调用指定的函数而非直接退出程序
 func SetExitFunc(fn func(int)) {
@@ -35,6 +56,25 @@ func SetExitFunc(fn func(int)) {
 	internal.SetExitFunc(fn)
 }
 
+// OnFatal 注册在 Fatal 日志写出后、进程退出前同步调用的回调
+// 作用于所有日志器（包括 New 创建的独立日志器），多个回调按注册顺序执行，注册后不可移除
+// 适用于最后的清理工作，如输出缓冲区、发送告警
+//
+// 注意：
+//   - 回调在退出路径上同步执行，必须快速返回，不要做阻塞的网络调用
+//   - 回调不应 panic；发生 panic 时会被忽略并继续执行后续回调和退出
+//   - 回调中不要再调用 Fatal
+//
+// 示例：
+//
+//	clog.OnFatal(func(r clog.Record) {
+//		alerter.Send(r.Message, r.Fields)
+//		_ = clog.Sync()
+//	})
+func OnFatal(fn func(record Record)) {
+	internal.OnFatal(fn)
+}
+
 // WithTraceID 将 trace_id 注入到 context 中，返回新的 context
 // 通常在请求入口处调用，如 HTTP 中间件或 gRPC 拦截器
 // 注入的 trace_id 会被 WithContext 自动提取并添加到日志中
@@ -42,21 +82,166 @@ func WithTraceID(ctx context.Context, traceID string) context.Context {
 	return context.WithValue(ctx, traceIDKey, traceID)
 }
 
+// IntoContext 将日志器嵌入 context，返回新的 context
+// 之后 WithContext 会优先使用该日志器而非全局日志器，便于按请求携带字段的日志器沿调用栈传递
+// logger 为 nil 时原样返回 ctx
+func IntoContext(ctx context.Context, logger Logger) context.Context {
+	if logger == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, loggerKey, logger)
+}
+
+// GoContext 返回用于后台协程的 context：保留 ctx 中通过 IntoContext 嵌入的日志器和 WithTraceID 注入的 trace_id，
+// 但不继承 ctx 的取消和截止时间，请求结束后协程中的日志仍能与请求关联
+// 只复制日志相关的值，ctx 中的其他值不会带入，需要时应显式传递；ctx 为 nil 时返回 context.Background()
+// 协程自身的退出需要另行控制，如传入独立的超时：context.WithTimeout(clog.GoContext(ctx), time.Minute)
+//
+// 示例：
+//
+//	func handle(ctx context.Context, orderID string) {
+//		bgCtx := clog.GoContext(ctx)
+//		go func() {
+//			// 请求返回后 ctx 被取消，bgCtx 不受影响，日志仍带有请求的 trace_id
+//			clog.WithContext(bgCtx).Info("异步发送通知", clog.String("order_id", orderID))
+//		}()
+//	}
+func GoContext(ctx context.Context) context.Context {
+	detached := context.Background()
+	if ctx == nil {
+		return detached
+	}
+	if logger, ok := ctx.Value(loggerKey).(Logger); ok {
+		detached = IntoContext(detached, logger)
+	}
+	if traceID := traceIDFrom(ctx); traceID != "" {
+		detached = WithTraceID(detached, traceID)
+	}
+	return detached
+}
+
+// BufferedContext 返回缓冲请求内全部日志的 context 和日志器，由 FlushContext 在请求结束时一次性输出
+// 之后通过 WithContext(ctx) 获取的日志器（包括其 With、Namespace 派生的日志器）都写入同一个缓冲，
+// 输出时按记录顺序连续写出，并发请求的日志块之间不会交错，便于按请求阅读完整的链路；
+// 未经缓冲直接写出的日志仍可能出现在两个日志块之间
+//
+// 注意：
+//   - 必须调用 FlushContext，否则缓冲的日志会丢失；单个请求超过 1000 条时提前输出已缓冲的部分
+//   - 日志在请求结束时才输出，不适合需要实时观察的场景；DPanic 及以上级别会先输出缓冲再立即输出
+//   - 字段值在输出时才编码，记录后不要修改以引用方式传入的值（如 map、切片）
+//   - ctx 已经带有缓冲时直接复用，不会嵌套缓冲
+//
+// 示例：
+//
+//	func middleware(next http.Handler) http.Handler {
+//		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+//			ctx, _ := clog.BufferedContext(r.Context())
+//			defer clog.FlushContext(ctx)
+//			next.ServeHTTP(w, r.WithContext(ctx))
+//		})
+//	}
+func BufferedContext(ctx context.Context) (context.Context, Logger) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if _, ok := ctx.Value(requestBufferKey{}).(*internal.RequestBuffer); ok {
+		return ctx, WithContext(ctx)
+	}
+
+	buffer := internal.NewRequestBuffer(internal.DefaultRequestBufferRecords)
+	logger := contextLogger(ctx).WithOptions(zap.WrapCore(buffer.WrapCore))
+	ctx = context.WithValue(IntoContext(ctx, logger), requestBufferKey{}, buffer)
+	return ctx, WithContext(ctx)
+}
+
+// FlushContext 连续输出 BufferedContext 缓冲的日志，通常在请求结束时由中间件 defer 调用
+// 之后通过该 ctx 记录的日志（如请求结束后仍在运行的协程）不再缓冲，直接输出；
+// ctx 没有缓冲时什么也不做并返回 nil
+func FlushContext(ctx context.Context) error {
+	if ctx == nil {
+		return nil
+	}
+	buffer, ok := ctx.Value(requestBufferKey{}).(*internal.RequestBuffer)
+	if !ok {
+		return nil
+	}
+	return buffer.Flush()
+}
+
 // WithContext 从 context 中获取 Logger 实例
+// 如果 ctx 中通过 IntoContext 嵌入了日志器则使用它，否则使用全局日志器
 // 如果 ctx 中包含 trace_id，返回的 Logger 会自动在每条日志中添加 "trace_id" 字段
+// 通过 Init 的 WithContextDeadline 选项开启后，ctx 带有截止时间时还会添加 "deadline_remaining" 字段
 // 这是业务代码中进行日志记录的首选方式，确保分布式链路追踪的连续性
 func WithContext(ctx context.Context) Logger {
-	logger := getDefaultLogger()
+	if ctx == nil {
+		return getDefaultLogger()
+	}
+
+	logger := contextLogger(ctx)
 
+	var fields []Field
+	if traceID := traceIDFrom(ctx); traceID != "" {
+		fields = append(fields, zap.String("trace_id", traceID))
+	}
+	if contextDeadline.Load() {
+		if _, ok := ctx.Deadline(); ok {
+			fields = append(fields, CtxDeadline(ctx))
+		}
+	}
+
+	if len(fields) == 0 {
+		return logger
+	}
+	return logger.With(fields...)
+}
+
+// traceIDFrom 返回 WithTraceID 注入的 trace_id，没有时返回空字符串
+func traceIDFrom(ctx context.Context) string {
+	traceID, _ := ctx.Value(traceIDKey).(string)
+	return traceID
+}
+
+// contextLogger 返回 ctx 中嵌入的日志器，没有时返回全局日志器
+func contextLogger(ctx context.Context) Logger {
+	if logger, ok := ctx.Value(loggerKey).(Logger); ok {
+		return logger
+	}
+	return getDefaultLogger()
+}
+
+// CtxDeadline 创建记录 context 剩余时间的字段 "deadline_remaining"
+// 剩余时间在调用时计算，已超时时为负数；ctx 没有截止时间时记录为 "none"
+// 适用于排查超时问题，如在调用下游服务前记录剩余的时间预算
+//
+// 示例：
+//
+//	clog.WithContext(ctx).Info("调用库存服务", clog.CtxDeadline(ctx))
+func CtxDeadline(ctx context.Context) Field {
 	if ctx != nil {
-		if traceID := ctx.Value(traceIDKey); traceID != nil {
-			if id, ok := traceID.(string); ok && id != "" {
-				return logger.With(zap.String("trace_id", id))
-			}
+		if deadline, ok := ctx.Deadline(); ok {
+			return zap.Duration("deadline_remaining", time.Until(deadline))
 		}
 	}
+	return zap.String("deadline_remaining", "none")
+}
 
-	return logger
+// globalLogger 全局默认日志器及其多跳过一层调用栈的派生日志器
+// 二者一起原子替换，Info 等包级函数直接使用派生日志器，不必每次调用都通过 WithOptions 创建
+type globalLogger struct {
+	logger Logger
+	caller Logger
+}
+
+// storeDefaultLogger 原子替换全局默认日志器
+func storeDefaultLogger(logger Logger) {
+	defaultLogger.Store(&globalLogger{logger: logger, caller: logger.WithOptions(zap.AddCallerSkip(1))})
+}
+
+// getCallerLogger 获取供包级日志函数使用的全局日志器，调用位置指向包级函数的调用处
+func getCallerLogger() Logger {
+	getDefaultLogger()
+	return defaultLogger.Load().(*globalLogger).caller
 }
 
 // getDefaultLogger 获取全局默认日志器
@@ -71,9 +256,9 @@ func getDefaultLogger() Logger {
 			log.Printf("clog: failed to initialize default logger: %v", err)
 			logger = internal.NewFallbackLogger()
 		}
-		defaultLogger.Store(logger)
+		storeDefaultLogger(logger)
 	})
-	return defaultLogger.Load().(Logger)
+	return defaultLogger.Load().(*globalLogger).logger
 }
 
 // New 创建独立的 Logger 实例，支持自定义配置
@@ -96,12 +281,12 @@ func New(ctx context.Context, config *Config, opts ...Option) (Logger, error) {
 
 	// 解析选项
 	options := ParseOptions(opts...)
-	logger, err := internal.NewLogger(config, options.Namespace)
+	logger, err := newLogger(config, options)
 	if err != nil {
 		// 初始化失败时返回 fallback logger 和原始错误
 		return internal.NewFallbackLogger(), err
 	}
-	return logger, nil
+	return applyOptions(logger, options), nil
 }
 
 // Init 初始化全局默认日志器
@@ -126,16 +311,75 @@ func Init(ctx context.Context, config *Config, opts ...Option) error {
 
 	// 解析选项
 	options := ParseOptions(opts...)
-	logger, err := internal.NewLogger(config, options.Namespace)
+	logger, err := newLogger(config, options)
 	if err != nil {
 		// 初始化失败时返回错误，但不替换现有 logger
 		return err
 	}
+	// 标记默认日志器已初始化，避免首次使用时的延迟初始化覆盖此处设置的 logger
+	defaultLoggerOnce.Do(func() {})
 	// 原子替换全局 logger
-	defaultLogger.Store(logger)
+	storeDefaultLogger(applyOptions(logger, options))
+	contextDeadline.Store(options.ContextDeadline)
 	return nil
 }
 
+// newLogger 根据选项创建逐行输出或攒批输出的日志器
+func newLogger(config *Config, options *Options) (Logger, error) {
+	format := config.Format
+	if options.Encoder != nil {
+		format = internal.EncoderFormat(options.Encoder)
+		if options.BufferedJSON > 0 && format == "" {
+			return nil, fmt.Errorf("buffered JSON output requires the built-in json encoder, got a custom encoder")
+		}
+	}
+	if options.BufferedJSON > 0 && format != "json" {
+		return nil, fmt.Errorf("buffered JSON output requires json format, got %q", format)
+	}
+	return internal.Build(config, options.Namespace, internal.BuildOptions{
+		BufferedJSON:  options.BufferedJSON,
+		QuietRotation: options.QuietRotation,
+		Encoder:       options.Encoder,
+	})
+}
+
+// applyOptions 将需要包装底层核心的选项应用到日志器
+func applyOptions(logger Logger, options *Options) Logger {
+	if options.DedupWindow > 0 {
+		logger = logger.WithOptions(zap.WrapCore(internal.NewDedupCore(options.DedupWindow)))
+	}
+	if options.StartupBanner {
+		logStartupBanner(logger, options)
+	}
+	return logger
+}
+
+// logStartupBanner 输出一条汇总生效配置的 info 日志，通过 AtLevel 绕过配置的级别，
+// 保证级别设置过高导致日志缺失时仍能看到这条记录；命名空间由日志器自动添加
+func logStartupBanner(logger Logger, options *Options) {
+	cfg := logger.Config()
+	fields := []Field{
+		zap.String("log_level", cfg.Level),
+		zap.String("log_format", cfg.Format),
+		zap.String("log_output", cfg.Output),
+		zap.Bool("add_source", cfg.AddSource),
+		zap.Bool("color", cfg.EnableColor),
+	}
+	if cfg.Rotation != nil {
+		fields = append(fields, zap.Any("rotation", cfg.Rotation))
+	}
+	if len(cfg.MutedNamespaces) > 0 {
+		fields = append(fields, zap.Strings("muted_namespaces", cfg.MutedNamespaces))
+	}
+	if options.DedupWindow > 0 {
+		fields = append(fields, zap.Duration("dedup_window", options.DedupWindow))
+	}
+	if options.BufferedJSON > 0 {
+		fields = append(fields, zap.Int("buffered_json", options.BufferedJSON))
+	}
+	logger.AtLevel("info").Info("日志器初始化完成", fields...)
+}
+
 // Namespace 创建带有层次化命名空间的 Logger 实例
 // 支持链式调用来构建深层命名空间路径，如 "service.module.component"
 // 这是区分不同业务模块或分层的推荐方式
@@ -149,34 +393,131 @@ func Namespace(name string) Logger {
 	return getDefaultLogger().Namespace(name)
 }
 
+// MuteNamespace 丢弃全局日志器下指定命名空间及其子命名空间的全部日志
+// 按命名空间层级做前缀匹配，静音优先于 AtLevel 的级别覆盖
+// 运行时静音在重新 Init 后失效，需要长期生效的静音请使用 Config.MutedNamespaces
+//
+// 示例：
+//
+//	clog.MuteNamespace("db")  // 静音 "db"、"db.pool" 等
+func MuteNamespace(ns string) {
+	getDefaultLogger().MuteNamespace(ns)
+}
+
+// UnmuteNamespace 取消全局日志器下命名空间的静音
+func UnmuteNamespace(ns string) {
+	getDefaultLogger().UnmuteNamespace(ns)
+}
+
+// Sync 输出全局日志器缓冲中的日志并同步底层写入器
+func Sync() error {
+	return getDefaultLogger().Sync()
+}
+
+// Close 输出全局日志器缓冲中的日志，通常在服务退出前调用
+// 使用 WithBufferedJSON 或 HTTP 输出时必须调用，否则未攒满一批的日志会丢失
+func Close() error {
+	return getDefaultLogger().Close()
+}
+
+// EffectiveConfig 返回全局日志器实际生效的配置
+// 包含默认值，并反映运行时的静音变更，可用于 /debug/logconfig 等调试接口展示当前配置；
+// HTTP 输出的请求头只返回键，值以 "***" 代替，其余配置不包含敏感信息
+//
+// 示例：
+//
+//	http.HandleFunc("/debug/logconfig", func(w http.ResponseWriter, r *http.Request) {
+//		_ = json.NewEncoder(w).Encode(clog.EffectiveConfig())
+//	})
+func EffectiveConfig() Config {
+	return configFromInternal(getDefaultLogger().Config())
+}
+
 // Debug 记录 Debug 级别的日志
 // 通常用于详细的调试信息，在生产环境中通常被禁用
 func Debug(msg string, fields ...Field) {
-	getDefaultLogger().WithOptions(zap.AddCallerSkip(1)).Debug(msg, fields...)
+	getCallerLogger().Debug(msg, fields...)
 }
 
 // Info 记录 Info 级别的日志
 // 用于记录一般的业务信息，如请求处理、状态变更等
 func Info(msg string, fields ...Field) {
-	getDefaultLogger().WithOptions(zap.AddCallerSkip(1)).Info(msg, fields...)
+	getCallerLogger().Info(msg, fields...)
 }
 
 // Warn 记录 Warn 级别的日志
 // 用于记录可能需要注意但不影响系统正常运行的情况
 func Warn(msg string, fields ...Field) {
-	getDefaultLogger().WithOptions(zap.AddCallerSkip(1)).Warn(msg, fields...)
+	getCallerLogger().Warn(msg, fields...)
 }
 
 // Error 记录 Error 级别的日志
 // 用于记录错误情况，但不影响系统继续运行
 func Error(msg string, fields ...Field) {
-	getDefaultLogger().WithOptions(zap.AddCallerSkip(1)).Error(msg, fields...)
+	getCallerLogger().Error(msg, fields...)
 }
 
 // Fatal 记录 Fatal 级别的日志并退出程序
 // 用于记录严重错误，系统无法继续运行的情况
 // 记录日志后会调用 exitFunc(1) 退出程序
 func Fatal(msg string, fields ...Field) {
-	getDefaultLogger().WithOptions(zap.AddCallerSkip(1)).Fatal(msg, fields...)
+	getCallerLogger().Fatal(msg, fields...)
 	exitFunc(1)
 }
+
+// Debugw 以交替的键值对记录 Debug 级别的日志，便于从 zap 的 SugaredLogger 迁移
+// 值的类型自动推断，无法识别的类型按 Any 编码；参数个数为奇数时忽略最后一个键并额外记录一条警告
+//
+// 示例：
+//
+//	clog.Infow("订单创建成功", "order_id", orderID, "amount", 99.5, "elapsed", time.Since(start))
+func Debugw(msg string, keysAndValues ...interface{}) {
+	getCallerLogger().Debugw(msg, keysAndValues...)
+}
+
+// Infow 以交替的键值对记录 Info 级别的日志
+func Infow(msg string, keysAndValues ...interface{}) {
+	getCallerLogger().Infow(msg, keysAndValues...)
+}
+
+// Warnw 以交替的键值对记录 Warn 级别的日志
+func Warnw(msg string, keysAndValues ...interface{}) {
+	getCallerLogger().Warnw(msg, keysAndValues...)
+}
+
+// Errorw 以交替的键值对记录 Error 级别的日志
+func Errorw(msg string, keysAndValues ...interface{}) {
+	getCallerLogger().Errorw(msg, keysAndValues...)
+}
+
+// Fatalw 以交替的键值对记录 Fatal 级别的日志并退出程序
+func Fatalw(msg string, keysAndValues ...interface{}) {
+	getCallerLogger().Fatalw(msg, keysAndValues...)
+	exitFunc(1)
+}
+
+// LogAt 使用全局日志器以指定的时间戳记录日志
+// 用于回放或补录历史事件，使日志时间与事件时间一致；fatal 按 error 记录且不退出程序
+func LogAt(t time.Time, level string, msg string, fields ...Field) {
+	getCallerLogger().LogAt(t, level, msg, fields...)
+}
+
+// LogErr 以 Error 级别记录 err 并原样返回，合并"记录错误再返回"的两行写法
+// err 为 nil 时不记录任何日志并返回 nil；logger 为 nil 时使用全局日志器
+// 调用者信息指向 LogErr 的调用处
+//
+// 示例：
+//
+//	if err := db.Query(ctx, q); err != nil {
+//		return clog.LogErr(logger, "db failed", err, clog.String("query", q))
+//	}
+func LogErr(logger Logger, msg string, err error, fields ...Field) error {
+	if err == nil {
+		return nil
+	}
+	if logger == nil {
+		logger = getDefaultLogger()
+	}
+	logger.WithOptions(zap.AddCallerSkip(1)).Error(msg, append([]Field{Err(err)}, fields...)...)
+	return err
+}
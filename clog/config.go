@@ -1,6 +1,12 @@
 package clog
 
-import "fmt"
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ceyewan/infra-kit/clog/internal"
+)
 
 // Config 定义 clog 组件的配置结构体
 // 支持通过环境变量、配置文件或直接构造进行配置
@@ -17,6 +23,7 @@ type Config struct {
 	// Output 日志输出目标
 	// stdout: 标准输出
 	// stderr: 标准错误输出
+	// http: 攒批后 POST 到 HTTP 配置的地址
 	// 文件路径: 输出到指定文件，支持日志轮转
 	Output string `json:"output" yaml:"output"`
 
@@ -28,6 +35,19 @@ type Config struct {
 	// 开发环境建议开启，提升可读性
 	EnableColor bool `json:"enableColor" yaml:"enableColor"`
 
+	// ColorMode 颜色输出模式（仅 console 格式有效），设置后覆盖 EnableColor
+	// always: 始终输出颜色
+	// never: 不输出颜色
+	// auto: 输出到终端时启用颜色，重定向到文件、管道或设置了 NO_COLOR 环境变量时关闭
+	// 为空时按 EnableColor 决定：开启时按 auto 处理，关闭时不输出颜色
+	ColorMode string `json:"colorMode,omitempty" yaml:"colorMode,omitempty"`
+
+	// LevelColors 按级别自定义颜色（仅 console 格式且启用颜色时有效）
+	// 键为日志级别（debug, info, warn, error, fatal），值为颜色名称
+	// 可选颜色：black, red, green, yellow, blue, magenta, cyan, white, bold-red, bold-yellow, none
+	// 未配置的级别使用默认颜色；设置为 none 可关闭该级别的颜色，如 {"debug": "none"}
+	LevelColors map[string]string `json:"levelColors,omitempty" yaml:"levelColors,omitempty"`
+
 	// RootPath 项目根目录路径，用于缩短显示的源码路径
 	// 设置后，日志中的调用者信息将显示相对于 RootPath 的路径
 	RootPath string `json:"rootPath,omitempty" yaml:"rootPath,omitempty"`
@@ -35,6 +55,59 @@ type Config struct {
 	// Rotation 日志文件轮转配置（仅文件输出时生效）
 	// 用于控制日志文件的大小、数量和保留时间
 	Rotation *RotationConfig `json:"rotation,omitempty" yaml:"rotation,omitempty"`
+
+	// MutedNamespaces 被静音的命名空间，匹配的日志全部丢弃
+	// 按命名空间层级做前缀匹配："db" 同时静音 "db" 和 "db.pool"，但不影响 "dbx"
+	// 静音优先于级别配置和 AtLevel，Fatal 日志不受影响
+	MutedNamespaces []string `json:"mutedNamespaces,omitempty" yaml:"mutedNamespaces,omitempty"`
+
+	// MaxFieldBytes 单个字段值的最大字节数，0 表示不限制
+	// 超出的值被截断并追加 "...(truncated)"；对象、Any 等复杂值按编码后的 JSON 长度判断，
+	// 用于防止误记录完整请求体等超大对象撑爆日志管道
+	MaxFieldBytes int `json:"maxFieldBytes,omitempty" yaml:"maxFieldBytes,omitempty"`
+
+	// MaxFields 单条日志的最大字段数（不含 namespace 字段），0 表示不限制
+	// 超出的字段被丢弃，并追加 fields_dropped 字段记录丢弃的数量
+	MaxFields int `json:"maxFields,omitempty" yaml:"maxFields,omitempty"`
+
+	// MinFreeDiskBytes 输出文件所在文件系统的最小剩余空间（字节），0 表示不检查（仅文件输出时生效）
+	// 剩余空间低于该值时暂停写文件、改写到标准错误，空间恢复后自动切回文件；
+	// 剩余空间每 10 秒检查一次，避免日志本身写满磁盘
+	MinFreeDiskBytes int64 `json:"minFreeDiskBytes,omitempty" yaml:"minFreeDiskBytes,omitempty"`
+
+	// HTTP HTTP 输出配置（仅 Output 为 http 时生效）
+	HTTP *HTTPConfig `json:"http,omitempty" yaml:"http,omitempty"`
+}
+
+// HTTPConfig 定义 HTTP 输出配置
+// 日志先进入内存队列，由后台协程攒批 POST 到 URL：JSON 格式以 JSON 数组发送，console 格式以换行分隔的文本发送；
+// 重试后仍失败的批次改写到标准错误，不阻塞业务。数值字段为 0 时使用默认值
+type HTTPConfig struct {
+	// URL 接收日志的地址，如日志收集服务的批量写入接口
+	URL string `json:"url" yaml:"url"`
+
+	// Headers 附加的请求头，如鉴权 token
+	Headers map[string]string `json:"headers,omitempty" yaml:"headers,omitempty"`
+
+	// BatchSize 每批最大记录数，默认 100
+	BatchSize int `json:"batchSize,omitempty" yaml:"batchSize,omitempty"`
+
+	// FlushInterval 未攒满一批时的最长等待时间，默认 1 秒
+	FlushInterval time.Duration `json:"flushInterval,omitempty" yaml:"flushInterval,omitempty"`
+
+	// MaxRetries 发送失败后的最大重试次数，重试间隔从 100ms 开始指数增长
+	// 0 使用默认值 3，负数（如 -1）表示失败后不重试、直接改写到标准错误
+	MaxRetries int `json:"maxRetries,omitempty" yaml:"maxRetries,omitempty"`
+
+	// Timeout 单次请求超时，默认 5 秒
+	Timeout time.Duration `json:"timeout,omitempty" yaml:"timeout,omitempty"`
+
+	// QueueSize 等待发送的最大记录数，默认 1000
+	QueueSize int `json:"queueSize,omitempty" yaml:"queueSize,omitempty"`
+
+	// BlockOnFull 队列满时是否阻塞写日志的调用方
+	// 默认不阻塞，队列满时的记录直接写到标准错误；开启后日志不会绕过 HTTP，但接收端变慢会拖慢业务
+	BlockOnFull bool `json:"blockOnFull,omitempty" yaml:"blockOnFull,omitempty"`
 }
 
 // RotationConfig 定义日志文件轮转配置
@@ -57,6 +130,26 @@ type RotationConfig struct {
 	Compress bool `json:"compress" yaml:"compress"`
 }
 
+// Encoder 将一条日志记录编码为字节，通过 WithEncoder 替换内置的 json/console 格式
+type Encoder = internal.Encoder
+
+// JSONEncoder 返回内置的 JSON 编码器，可在自定义编码器中复用内置格式
+func JSONEncoder() Encoder {
+	return internal.NewJSONEncoder()
+}
+
+// ConsoleEncoder 返回内置的 console 编码器（不输出颜色），可在自定义编码器中复用内置格式
+func ConsoleEncoder() Encoder {
+	return internal.NewConsoleEncoder()
+}
+
+// 颜色输出模式，用于 Config.ColorMode
+const (
+	ColorAlways = internal.ColorAlways // 始终输出颜色
+	ColorNever  = internal.ColorNever  // 不输出颜色
+	ColorAuto   = internal.ColorAuto   // 输出到终端时才输出颜色，重定向到文件或管道时关闭
+)
+
 // GetDefaultConfig 返回环境相关的默认配置
 // 根据不同的运行环境提供优化的配置，减少配置工作量
 //
@@ -67,9 +160,9 @@ type RotationConfig struct {
 //   - *Config: 针对指定环境优化的配置
 //
 // 环境配置说明：
-//   - development: 控制台格式，调试级别，带颜色，适合开发调试
+//   - development: 控制台格式，调试级别，输出到终端时带颜色，适合开发调试
 //   - production: JSON 格式，信息级别，无颜色，适合生产环境
-//   - 其他: 默认配置，控制台格式，信息级别，带颜色
+//   - 其他: 默认配置，控制台格式，信息级别，输出到终端时带颜色
 func GetDefaultConfig(env string) *Config {
 	switch env {
 	case "development":
@@ -79,6 +172,7 @@ func GetDefaultConfig(env string) *Config {
 			Output:      "stdout",
 			AddSource:   true,
 			EnableColor: true,
+			ColorMode:   ColorAuto,
 			RootPath:    "infra-kit",
 		}
 	case "production":
@@ -97,6 +191,7 @@ func GetDefaultConfig(env string) *Config {
 			Output:      "stdout",
 			AddSource:   true,
 			EnableColor: true,
+			ColorMode:   ColorAuto,
 			RootPath:    "infra-kit",
 		}
 	}
@@ -109,6 +204,12 @@ func GetDefaultConfig(env string) *Config {
 //   - 日志级别：必须是 debug, info, warn, error, fatal 之一
 //   - 日志格式：必须是 json 或 console
 //   - 输出目标：不能为空
+//   - 颜色模式：为空或 always, never, auto 之一
+//   - 级别颜色：级别和颜色名称必须有效
+//   - 静音命名空间：不能为空字符串
+//   - 字段限制：不能为负数
+//   - 最小剩余磁盘空间：不能为负数
+//   - HTTP 输出：必须配置 URL，除 MaxRetries 外的数值不能为负数
 //   - 轮转配置：数值不能为负数
 //
 // 返回：
@@ -133,6 +234,56 @@ func (c *Config) Validate() error {
 		return fmt.Errorf("log output cannot be empty")
 	}
 
+	// 验证颜色模式
+	switch c.ColorMode {
+	case "", ColorAlways, ColorNever, ColorAuto:
+	default:
+		return fmt.Errorf("invalid color mode: %s, must be 'always', 'never' or 'auto'", c.ColorMode)
+	}
+
+	// 验证级别颜色
+	for level, color := range c.LevelColors {
+		if !validLevels[strings.ToLower(level)] {
+			return fmt.Errorf("invalid level in levelColors: %s", level)
+		}
+		if !internal.IsValidColor(color) {
+			return fmt.Errorf("invalid color %q for level %s", color, level)
+		}
+	}
+
+	// 验证静音命名空间
+	for _, ns := range c.MutedNamespaces {
+		if ns == "" {
+			return fmt.Errorf("mutedNamespaces cannot contain empty namespace")
+		}
+	}
+
+	// 验证字段限制
+	if c.MaxFieldBytes < 0 {
+		return fmt.Errorf("maxFieldBytes cannot be negative")
+	}
+	if c.MaxFields < 0 {
+		return fmt.Errorf("maxFields cannot be negative")
+	}
+
+	// 验证磁盘空间保护
+	if c.MinFreeDiskBytes < 0 {
+		return fmt.Errorf("minFreeDiskBytes cannot be negative")
+	}
+
+	// 验证 HTTP 输出
+	if c.Output == "http" {
+		if c.HTTP == nil || c.HTTP.URL == "" {
+			return fmt.Errorf("http output requires http.url")
+		}
+		if c.HTTP.BatchSize < 0 || c.HTTP.QueueSize < 0 {
+			return fmt.Errorf("http batchSize and queueSize cannot be negative")
+		}
+		if c.HTTP.FlushInterval < 0 || c.HTTP.Timeout < 0 {
+			return fmt.Errorf("http flushInterval and timeout cannot be negative")
+		}
+	}
+
 	// 验证轮转配置
 	if c.Rotation != nil {
 		if c.Rotation.MaxSize < 0 {
@@ -148,3 +299,31 @@ func (c *Config) Validate() error {
 
 	return nil
 }
+
+// configFromInternal 将 Logger.Config 返回的生效配置快照转换为 Config
+// 快照中的 map 和切片已是副本，这里直接引用即可
+func configFromInternal(c internal.Config) Config {
+	cfg := Config{
+		Level:            c.Level,
+		Format:           c.Format,
+		Output:           c.Output,
+		AddSource:        c.AddSource,
+		EnableColor:      c.EnableColor,
+		ColorMode:        c.ColorMode,
+		LevelColors:      c.LevelColors,
+		RootPath:         c.RootPath,
+		MutedNamespaces:  c.MutedNamespaces,
+		MaxFieldBytes:    c.MaxFieldBytes,
+		MaxFields:        c.MaxFields,
+		MinFreeDiskBytes: c.MinFreeDiskBytes,
+	}
+	if c.Rotation != nil {
+		rotation := RotationConfig(*c.Rotation)
+		cfg.Rotation = &rotation
+	}
+	if c.HTTP != nil {
+		httpCfg := HTTPConfig(*c.HTTP)
+		cfg.HTTP = &httpCfg
+	}
+	return cfg
+}
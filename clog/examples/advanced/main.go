@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	"sync"
 	"time"
 
 	"github.com/ceyewan/infra-kit/clog"
@@ -11,6 +12,9 @@ import (
 	"github.com/google/uuid"
 )
 
+// notifications 跟踪后台发送的订单通知，退出前等待其完成，避免通知及其日志随进程退出丢失
+var notifications sync.WaitGroup
+
 func main() {
 	fmt.Println("=== clog 高级功能演示 ===")
 	fmt.Println("本指南演示 clog 在实际应用场景中的高级用法")
@@ -34,6 +38,9 @@ func main() {
 	fmt.Println("\n⚡ 示例4: 性能优化技巧")
 	demoPerformanceOptimization()
 
+	// 等待后台通知发送完成后再退出
+	notifications.Wait()
+
 	fmt.Println("\n✅ 高级功能演示完成！")
 	fmt.Println("💡 提示: 查看 examples/rotation/main.go 了解日志轮转功能")
 }
@@ -285,30 +292,33 @@ func processOrderWorkflow(ctx context.Context, orderID string) error {
 	// 阶段1: 库存检查
 	inventoryLogger := logger.Namespace("inventory")
 	if err := checkInventory(ctx, orderID); err != nil {
-		inventoryLogger.Error("库存检查失败", clog.Err(err))
-		return err
+		return clog.LogErr(inventoryLogger, "库存检查失败", err)
 	}
 
 	// 阶段2: 支付处理
 	paymentLogger := logger.Namespace("payment")
 	if err := processPayment(ctx, orderID, 299.99); err != nil {
-		paymentLogger.Error("支付处理失败", clog.Err(err))
-		return err
+		return clog.LogErr(paymentLogger, "支付处理失败", err)
 	}
 
 	// 阶段3: 订单创建
 	orderLogger := logger.Namespace("order")
 	if err := createOrderRecord(ctx, orderID); err != nil {
-		orderLogger.Error("订单创建失败", clog.Err(err))
-		return err
+		return clog.LogErr(orderLogger, "订单创建失败", err)
 	}
 
-	// 阶段4: 发送通知
-	notificationLogger := logger.Namespace("notification")
-	if err := sendOrderNotification(ctx, orderID); err != nil {
-		notificationLogger.Warn("通知发送失败，但订单处理成功", clog.Err(err))
-		// 不返回错误，因为订单处理已经完成
-	}
+	// 阶段4: 在后台协程中发送通知，不阻塞订单流程
+	// GoContext 保留 trace_id 但不继承取消，请求结束后通知仍会发送，日志仍能关联到本次请求
+	bgCtx := clog.GoContext(ctx)
+	notifications.Add(1)
+	go func() {
+		defer notifications.Done()
+		notificationLogger := clog.WithContext(bgCtx).Namespace("notification")
+		if err := sendOrderNotification(bgCtx, orderID); err != nil {
+			notificationLogger.Warn("通知发送失败，但订单处理成功", clog.Err(err))
+			// 不返回错误，因为订单处理已经完成
+		}
+	}()
 
 	return nil
 }
@@ -56,7 +56,7 @@ func cleanupLogs() {
 func demoEnvironmentConfigs() {
 	fmt.Println("🔧 开发环境配置:")
 	devConfig := clog.GetDefaultConfig("development")
-	fmt.Printf("   级别: %s, 格式: %s, 颜色: %t\n", devConfig.Level, devConfig.Format, devConfig.EnableColor)
+	fmt.Printf("   级别: %s, 格式: %s, 颜色: %s\n", devConfig.Level, devConfig.Format, devConfig.ColorMode)
 
 	fmt.Println("🏭 生产环境配置:")
 	prodConfig := clog.GetDefaultConfig("production")
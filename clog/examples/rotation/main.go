@@ -230,7 +230,7 @@ func checkRotationFiles(baseFile string, testName string) {
 
 	// 检查主文件
 	if info, err := os.Stat(baseFile); err == nil {
-		fmt.Printf("📄 当前日志: %s (%.2f KB)\n", baseFile, float64(info.Size())/1024)
+		fmt.Printf("📄 当前日志: %s (%s)\n", baseFile, clog.FormatBytes(info.Size()))
 	}
 
 	// 检查备份文件
@@ -240,14 +240,14 @@ func checkRotationFiles(baseFile string, testName string) {
 	for i := 1; i <= 10; i++ {
 		backupFile := fmt.Sprintf("%s.%d", baseFile, i)
 		if info, err := os.Stat(backupFile); err == nil {
-			fmt.Printf("📄 备份文件: %s (%.2f KB)\n", backupFile, float64(info.Size())/1024)
+			fmt.Printf("📄 备份文件: %s (%s)\n", backupFile, clog.FormatBytes(info.Size()))
 			totalBackups++
 		}
 
 		// 检查压缩文件
 		compressedFile := fmt.Sprintf("%s.%d.gz", baseFile, i)
 		if info, err := os.Stat(compressedFile); err == nil {
-			fmt.Printf("📦 压缩文件: %s (%.2f KB)\n", compressedFile, float64(info.Size())/1024)
+			fmt.Printf("📦 压缩文件: %s (%s)\n", compressedFile, clog.FormatBytes(info.Size()))
 			totalCompressed++
 		}
 	}
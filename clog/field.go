@@ -1,7 +1,15 @@
 package clog
 
 import (
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"reflect"
+
+	"github.com/ceyewan/infra-kit/clog/internal"
 	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
 )
 
 // Field 是 zap.Field 的别名
@@ -29,3 +37,193 @@ var (
 	Err      = zap.Error // 别名，为了兼容性
 	Stringer = zap.Stringer
 )
+
+// Bytes 创建字节数字段，适用于文件大小、内存占用等
+// JSON 格式输出原始数字（如 1572864），console 格式输出原始数字和可读格式（如 "1572864 (1.5 MiB)"）
+// 可读格式统一使用二进制单位：1 KiB = 1024 B，1 MiB = 1024 KiB
+func Bytes(key string, n int64) Field {
+	return zap.Field{Key: key, Type: zapcore.ReflectType, Interface: internal.ByteSize(n)}
+}
+
+// Lazy 创建延迟计算的字段，fn 只在日志真正输出时调用，级别未启用或被静音时不会调用
+// 适用于计算代价高的值，如序列化大对象、汇总统计；fn 的返回值按 Any 的规则编码
+// fn 为 nil 时忽略该字段；fn panic 时输出 "<key>Error" 字段记录 panic 信息
+//
+// 注意：
+//   - fn 必须快速且没有副作用：它在写日志的调用栈上同步执行，且可能被调用多次
+//     （如同时启用了 MaxFieldBytes 或 WithDedup），也可能在 BufferedContext 输出时才调用
+//   - fn 读取的数据在调用时才被访问，需自行保证并发安全
+//
+// 示例：
+//
+//	logger.Debug("请求详情", clog.Lazy("body", func() any { return dumpRequest(req) }))
+func Lazy(key string, fn func() any) Field {
+	if fn == nil {
+		return zap.Skip()
+	}
+	return zap.Field{Key: key, Type: zapcore.ReflectType, Interface: internal.LazyValue(fn)}
+}
+
+// FormatBytes 使用二进制单位将字节数格式化为可读字符串，如 1572864 -> "1.5 MiB"
+func FormatBytes(n int64) string {
+	return internal.FormatBytes(n)
+}
+
+// MaxBinaryFieldBytes Hex 和 Base64 字段最多编码的原始字节数，超出部分截断
+const MaxBinaryFieldBytes = 256
+
+// Hex 创建十六进制编码的二进制字段，适用于哈希、请求 ID 等，如 "9f86d081"
+// 超过 MaxBinaryFieldBytes 时只编码前 MaxBinaryFieldBytes 个字节，并追加 "...(truncated, N bytes)" 标明原始长度
+func Hex(key string, b []byte) Field {
+	head, suffix := truncateBinary(b)
+	return zap.String(key, hex.EncodeToString(head)+suffix)
+}
+
+// Base64 创建标准 Base64 编码的二进制字段，截断规则与 Hex 相同
+// 与 Binary 不同，编码方式不随输出格式变化，JSON 和 console 中的内容一致
+func Base64(key string, b []byte) Field {
+	head, suffix := truncateBinary(b)
+	return zap.String(key, base64.StdEncoding.EncodeToString(head)+suffix)
+}
+
+// truncateBinary 按 MaxBinaryFieldBytes 截断字节切片，返回需要编码的部分和截断标记
+func truncateBinary(b []byte) ([]byte, string) {
+	if len(b) <= MaxBinaryFieldBytes {
+		return b, ""
+	}
+	return b[:MaxBinaryFieldBytes], fmt.Sprintf("...(truncated, %d bytes)", len(b))
+}
+
+// ErrorChain 将错误链展开为结构化数组字段 "error_chain"
+// 通过 errors.Unwrap 逐层展开，每一层输出 {message, type}；
+// 对于 errors.Join 产生的多错误，会按顺序深度优先展开每个分支
+// err 为 nil 时返回空字段，不输出任何内容
+func ErrorChain(err error) Field {
+	if err == nil {
+		return zap.Skip()
+	}
+	return zap.Array("error_chain", errorChain{err: err})
+}
+
+// errorChain 实现 zapcore.ArrayMarshaler，负责展开错误链
+type errorChain struct {
+	err error
+}
+
+// MarshalLogArray 将错误链逐层写入数组编码器
+func (c errorChain) MarshalLogArray(enc zapcore.ArrayEncoder) error {
+	return appendErrorChain(enc, c.err)
+}
+
+// appendErrorChain 深度优先展开错误链，兼容单错误和多错误包装
+func appendErrorChain(enc zapcore.ArrayEncoder, err error) error {
+	for err != nil {
+		if e := enc.AppendObject(errorLink{err: err}); e != nil {
+			return e
+		}
+
+		// errors.Join 等多错误包装，逐个展开分支
+		if multi, ok := err.(interface{ Unwrap() []error }); ok {
+			for _, inner := range multi.Unwrap() {
+				if e := appendErrorChain(enc, inner); e != nil {
+					return e
+				}
+			}
+			return nil
+		}
+
+		err = errors.Unwrap(err)
+	}
+	return nil
+}
+
+// errorLink 表示错误链中的单个节点
+type errorLink struct {
+	err error
+}
+
+// MarshalLogObject 输出当前节点的错误信息和具体类型
+func (l errorLink) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	enc.AddString("message", l.err.Error())
+	enc.AddString("type", fmt.Sprintf("%T", l.err))
+	return nil
+}
+
+// GRPCStatus 将 gRPC 状态错误展开为 "grpc_code" 和 "grpc_message" 两个顶层字段，便于按状态码检索
+// 识别方式与 status.FromError 一致：沿错误链查找实现了 GRPCStatus() 方法的错误，
+// 因此 fmt.Errorf("%w") 包装过的状态错误同样可以识别；clog 不依赖 grpc，通过反射读取状态码和消息
+// 非状态错误按 gRPC 的约定输出 grpc_code=Unknown，grpc_message 为 err.Error()
+// err 为 nil 时返回空字段，不输出任何内容
+func GRPCStatus(err error) Field {
+	if err == nil {
+		return zap.Skip()
+	}
+	return zap.Inline(grpcStatusFields(err))
+}
+
+// grpcStatus 实现 zapcore.ObjectMarshaler，以内联方式输出状态码和消息
+type grpcStatus struct {
+	code    string
+	message string
+}
+
+// MarshalLogObject 输出 grpc_code 和 grpc_message
+func (s grpcStatus) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	enc.AddString("grpc_code", s.code)
+	enc.AddString("grpc_message", s.message)
+	return nil
+}
+
+// grpcStatusFields 从错误链中提取 gRPC 状态，找不到时回退为 Unknown
+func grpcStatusFields(err error) grpcStatus {
+	if s, ok := findGRPCStatus(err); ok {
+		return s
+	}
+	return grpcStatus{code: "Unknown", message: err.Error()}
+}
+
+// findGRPCStatus 深度优先遍历错误链，返回第一个可识别的 gRPC 状态
+func findGRPCStatus(err error) (grpcStatus, bool) {
+	for err != nil {
+		if s, ok := reflectGRPCStatus(err); ok {
+			return s, true
+		}
+		if multi, ok := err.(interface{ Unwrap() []error }); ok {
+			for _, inner := range multi.Unwrap() {
+				if s, ok := findGRPCStatus(inner); ok {
+					return s, true
+				}
+			}
+			return grpcStatus{}, false
+		}
+		err = errors.Unwrap(err)
+	}
+	return grpcStatus{}, false
+}
+
+// reflectGRPCStatus 调用 err.GRPCStatus()，再读取返回值的 Code() 和 Message()
+// 方法签名不符合 *status.Status 约定时视为非状态错误
+func reflectGRPCStatus(err error) (grpcStatus, bool) {
+	method := reflect.ValueOf(err).MethodByName("GRPCStatus")
+	if !method.IsValid() || method.Type().NumIn() != 0 || method.Type().NumOut() != 1 {
+		return grpcStatus{}, false
+	}
+	st := method.Call(nil)[0]
+	if st.Kind() == reflect.Ptr && st.IsNil() {
+		// 与 grpc 一致，nil 状态表示 OK
+		return grpcStatus{code: "OK"}, true
+	}
+
+	code := st.MethodByName("Code")
+	message := st.MethodByName("Message")
+	if !code.IsValid() || !message.IsValid() ||
+		code.Type().NumIn() != 0 || code.Type().NumOut() != 1 ||
+		message.Type().NumIn() != 0 || message.Type().NumOut() != 1 ||
+		message.Type().Out(0).Kind() != reflect.String {
+		return grpcStatus{}, false
+	}
+	return grpcStatus{
+		code:    fmt.Sprint(code.Call(nil)[0].Interface()),
+		message: message.Call(nil)[0].String(),
+	}, true
+}
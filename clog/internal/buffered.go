@@ -0,0 +1,86 @@
+package internal
+
+import (
+	"bytes"
+	"sync"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// bufferedJSONWriter 将逐行写入的 JSON 日志攒批后以 JSON 数组输出
+// zap 每条日志调用一次 Write，内容为一个以换行结尾的 JSON 对象；
+// 攒满 maxRecords 条或 Sync 时输出一行 "[{...},{...}]"，适合按批上传的导入接口
+type bufferedJSONWriter struct {
+	mu         sync.Mutex
+	out        zapcore.WriteSyncer
+	maxRecords int
+	records    [][]byte
+	closed     bool
+}
+
+// newBufferedJSONWriter 创建攒批写入器，maxRecords 为每个数组的最大记录数
+func newBufferedJSONWriter(out zapcore.WriteSyncer, maxRecords int) *bufferedJSONWriter {
+	return &bufferedJSONWriter{
+		out:        out,
+		maxRecords: maxRecords,
+		records:    make([][]byte, 0, maxRecords),
+	}
+}
+
+// Write 缓存一条日志记录，缓冲区满时立即输出
+// 关闭后不再缓存，每条记录单独作为一个数组输出，避免丢失
+func (w *bufferedJSONWriter) Write(p []byte) (int, error) {
+	record := bytes.TrimRight(p, "\n")
+	if len(record) == 0 {
+		return len(p), nil
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.records = append(w.records, append([]byte(nil), record...))
+	if w.closed || len(w.records) >= w.maxRecords {
+		if err := w.flushLocked(); err != nil {
+			return 0, err
+		}
+	}
+	return len(p), nil
+}
+
+// Sync 输出缓冲区中的记录并同步底层写入器
+func (w *bufferedJSONWriter) Sync() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if err := w.flushLocked(); err != nil {
+		return err
+	}
+	return w.out.Sync()
+}
+
+// Close 输出剩余记录，之后的写入不再缓存
+func (w *bufferedJSONWriter) Close() error {
+	w.mu.Lock()
+	w.closed = true
+	w.mu.Unlock()
+	return w.Sync()
+}
+
+// flushLocked 将缓存的记录编码为一个 JSON 数组写出，调用方需持有锁
+func (w *bufferedJSONWriter) flushLocked() error {
+	if len(w.records) == 0 {
+		return nil
+	}
+
+	var buf bytes.Buffer
+	buf.WriteByte('[')
+	for i, record := range w.records {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		buf.Write(record)
+	}
+	buf.WriteString("]\n")
+
+	w.records = w.records[:0]
+	_, err := w.out.Write(buf.Bytes())
+	return err
+}
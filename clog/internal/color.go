@@ -0,0 +1,47 @@
+package internal
+
+import (
+	"os"
+
+	"github.com/mattn/go-isatty"
+)
+
+// 颜色输出模式
+const (
+	ColorAlways = "always" // 始终输出颜色
+	ColorNever  = "never"  // 不输出颜色
+	ColorAuto   = "auto"   // 输出到终端时才输出颜色
+)
+
+// resolveColor 根据颜色模式和输出目标决定是否输出颜色
+// mode 为空时沿用 enableColor；parseConfig 已将开启颜色且未设置模式的配置解析为 auto
+func resolveColor(mode string, enableColor bool, output string) bool {
+	switch mode {
+	case ColorAlways:
+		return true
+	case ColorNever:
+		return false
+	case ColorAuto:
+		if os.Getenv("NO_COLOR") != "" {
+			return false
+		}
+		return isTerminal(output)
+	default:
+		return enableColor
+	}
+}
+
+// isTerminal 判断输出目标是否为终端，文件和 HTTP 输出始终返回 false
+func isTerminal(output string) bool {
+	var f *os.File
+	switch output {
+	case "stdout":
+		f = os.Stdout
+	case "stderr":
+		f = os.Stderr
+	default:
+		return false
+	}
+	fd := f.Fd()
+	return isatty.IsTerminal(fd) || isatty.IsCygwinTerminal(fd)
+}
@@ -0,0 +1,42 @@
+package internal
+
+import "time"
+
+// Config 是日志器实际生效配置的快照，由 Logger.Config 返回
+// 字段与 clog.Config 一一对应，说明见 clog.Config；clog.EffectiveConfig 将其转换为 clog.Config
+type Config struct {
+	Level            string            `json:"level" yaml:"level"`
+	Format           string            `json:"format" yaml:"format"`
+	Output           string            `json:"output" yaml:"output"`
+	AddSource        bool              `json:"addSource" yaml:"addSource"`
+	EnableColor      bool              `json:"enableColor" yaml:"enableColor"`
+	ColorMode        string            `json:"colorMode,omitempty" yaml:"colorMode,omitempty"`
+	LevelColors      map[string]string `json:"levelColors,omitempty" yaml:"levelColors,omitempty"`
+	RootPath         string            `json:"rootPath,omitempty" yaml:"rootPath,omitempty"`
+	Rotation         *RotationConfig   `json:"rotation,omitempty" yaml:"rotation,omitempty"`
+	MutedNamespaces  []string          `json:"mutedNamespaces,omitempty" yaml:"mutedNamespaces,omitempty"`
+	MaxFieldBytes    int               `json:"maxFieldBytes,omitempty" yaml:"maxFieldBytes,omitempty"`
+	MaxFields        int               `json:"maxFields,omitempty" yaml:"maxFields,omitempty"`
+	MinFreeDiskBytes int64             `json:"minFreeDiskBytes,omitempty" yaml:"minFreeDiskBytes,omitempty"`
+	HTTP             *HTTPConfig       `json:"http,omitempty" yaml:"http,omitempty"`
+}
+
+// HTTPConfig 是生效的 HTTP 输出配置，字段与 clog.HTTPConfig 一一对应
+type HTTPConfig struct {
+	URL           string            `json:"url" yaml:"url"`
+	Headers       map[string]string `json:"headers,omitempty" yaml:"headers,omitempty"`
+	BatchSize     int               `json:"batchSize,omitempty" yaml:"batchSize,omitempty"`
+	FlushInterval time.Duration     `json:"flushInterval,omitempty" yaml:"flushInterval,omitempty"`
+	MaxRetries    int               `json:"maxRetries,omitempty" yaml:"maxRetries,omitempty"`
+	Timeout       time.Duration     `json:"timeout,omitempty" yaml:"timeout,omitempty"`
+	QueueSize     int               `json:"queueSize,omitempty" yaml:"queueSize,omitempty"`
+	BlockOnFull   bool              `json:"blockOnFull,omitempty" yaml:"blockOnFull,omitempty"`
+}
+
+// RotationConfig 是生效的日志轮转配置，字段与 clog.RotationConfig 一一对应
+type RotationConfig struct {
+	MaxSize    int  `json:"maxSize" yaml:"maxSize"`
+	MaxBackups int  `json:"maxBackups" yaml:"maxBackups"`
+	MaxAge     int  `json:"maxAge" yaml:"maxAge"`
+	Compress   bool `json:"compress" yaml:"compress"`
+}
@@ -0,0 +1,150 @@
+package internal
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// dedupPending 记录窗口内等待合并的重复日志
+type dedupPending struct {
+	core   zapcore.Core    // 写入汇总行时使用的核心（携带 With 上下文）
+	key    string          // 去重键：级别 + 消息 + 字段
+	entry  zapcore.Entry   // 首次出现的日志条目
+	fields []zapcore.Field // 首次出现的日志字段
+	count  int             // 首次之后被折叠的重复次数
+	last   time.Time       // 最后一次重复出现的时间
+}
+
+// dedupState 在同一日志器派生出的所有核心之间共享的去重状态
+type dedupState struct {
+	mu      sync.Mutex
+	window  time.Duration
+	pending *dedupPending
+	timer   *time.Timer
+}
+
+// dedupCore 对连续相同的日志进行折叠
+// 首条日志立即输出，窗口内的后续重复只计数，
+// 在出现不同的日志、窗口到期或 Sync 时输出一条带 repeated=N 的汇总行
+type dedupCore struct {
+	zapcore.Core
+	state   *dedupState
+	context string // With 附加字段的编码，参与去重键计算
+}
+
+// NewDedupCore 返回包装核心的函数，用于 zap.WrapCore
+func NewDedupCore(window time.Duration) func(zapcore.Core) zapcore.Core {
+	return func(core zapcore.Core) zapcore.Core {
+		return &dedupCore{
+			Core:  core,
+			state: &dedupState{window: window},
+		}
+	}
+}
+
+// With 创建带有额外字段的子核心，共享去重状态
+func (c *dedupCore) With(fields []zapcore.Field) zapcore.Core {
+	return &dedupCore{
+		Core:    c.Core.With(fields),
+		state:   c.state,
+		context: c.context + encodeDedupFields(fields),
+	}
+}
+
+// Check 判断是否需要记录该日志
+func (c *dedupCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+// Write 写入日志，窗口内的连续重复只计数不输出
+func (c *dedupCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	// Fatal 及以上级别会终止程序，必须立即输出
+	if ent.Level >= zapcore.DPanicLevel {
+		c.state.flush()
+		return c.Core.Write(ent, fields)
+	}
+
+	key := fmt.Sprintf("%s|%s|%s%s", ent.Level, ent.Message, c.context, encodeDedupFields(fields))
+
+	s := c.state
+	s.mu.Lock()
+	if p := s.pending; p != nil && p.key == key && ent.Time.Sub(p.entry.Time) < s.window {
+		p.count++
+		p.last = ent.Time
+		s.mu.Unlock()
+		return nil
+	}
+
+	prev := s.pending
+	s.pending = &dedupPending{
+		core:   c.Core,
+		key:    key,
+		entry:  ent,
+		fields: fields,
+		last:   ent.Time,
+	}
+	if s.timer != nil {
+		s.timer.Stop()
+	}
+	s.timer = time.AfterFunc(s.window, s.flush)
+	s.mu.Unlock()
+
+	if err := prev.writeSummary(); err != nil {
+		return err
+	}
+	return c.Core.Write(ent, fields)
+}
+
+// Sync 输出待合并的重复日志后同步底层核心
+func (c *dedupCore) Sync() error {
+	c.state.flush()
+	return c.Core.Sync()
+}
+
+// flush 输出并清空待合并的重复日志
+func (s *dedupState) flush() {
+	s.mu.Lock()
+	prev := s.pending
+	s.pending = nil
+	if s.timer != nil {
+		s.timer.Stop()
+		s.timer = nil
+	}
+	s.mu.Unlock()
+
+	_ = prev.writeSummary()
+}
+
+// writeSummary 输出带 repeated=N 的汇总行，没有重复时不输出
+func (p *dedupPending) writeSummary() error {
+	if p == nil || p.count == 0 {
+		return nil
+	}
+
+	ent := p.entry
+	ent.Time = p.last
+	fields := make([]zapcore.Field, len(p.fields), len(p.fields)+1)
+	copy(fields, p.fields)
+	fields = append(fields, zap.Int("repeated", p.count))
+	return p.core.Write(ent, fields)
+}
+
+// encodeDedupFields 将字段编码为稳定的字符串，用于比较日志是否相同
+func encodeDedupFields(fields []zapcore.Field) string {
+	if len(fields) == 0 {
+		return ""
+	}
+	enc := zapcore.NewMapObjectEncoder()
+	for _, field := range fields {
+		field.AddTo(enc)
+	}
+	// fmt 按键排序输出 map，保证编码结果稳定
+	return fmt.Sprint(enc.Fields)
+}
@@ -0,0 +1,8 @@
+//go:build !linux && !darwin && !freebsd
+
+package internal
+
+// freeDiskBytes 当前平台不支持查询剩余空间，磁盘空间保护不生效
+func freeDiskBytes(dir string) (uint64, bool) {
+	return 0, false
+}
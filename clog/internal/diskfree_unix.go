@@ -0,0 +1,14 @@
+//go:build linux || darwin || freebsd
+
+package internal
+
+import "syscall"
+
+// freeDiskBytes 返回 dir 所在文件系统对非特权用户可用的剩余字节数
+func freeDiskBytes(dir string) (uint64, bool) {
+	var stat syscall.Statfs_t
+	if err := syscall.Statfs(dir, &stat); err != nil {
+		return 0, false
+	}
+	return uint64(stat.Bavail) * uint64(stat.Bsize), true
+}
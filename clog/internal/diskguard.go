@@ -0,0 +1,84 @@
+package internal
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// diskCheckInterval 两次磁盘剩余空间检查的最小间隔
+const diskCheckInterval = 10 * time.Second
+
+// diskGuardWriter 在输出文件所在文件系统剩余空间不足时暂停写文件，改写到标准错误
+// 剩余空间按 diskCheckInterval 惰性检查，而不是每行检查；空间恢复后自动切回文件
+type diskGuardWriter struct {
+	out      zapcore.WriteSyncer
+	fallback zapcore.WriteSyncer
+	dir      string
+	minFree  uint64
+
+	mu        sync.Mutex  // 保护 lastCheck，保证同一时刻只有一个写入方检查
+	lastCheck time.Time   // 上次检查时间
+	paused    atomic.Bool // 是否已暂停写文件
+}
+
+// newDiskGuardWriter 创建磁盘空间保护写入器，filename 用于定位所在的文件系统
+func newDiskGuardWriter(out zapcore.WriteSyncer, filename string, minFree int64) *diskGuardWriter {
+	return &diskGuardWriter{
+		out:      out,
+		fallback: zapcore.Lock(os.Stderr),
+		dir:      filepath.Dir(filename),
+		minFree:  uint64(minFree),
+	}
+}
+
+// Write 剩余空间充足时写文件，否则写到标准错误
+func (w *diskGuardWriter) Write(p []byte) (int, error) {
+	w.check()
+	if w.paused.Load() {
+		return w.fallback.Write(p)
+	}
+	return w.out.Write(p)
+}
+
+// Sync 同步当前生效的输出
+func (w *diskGuardWriter) Sync() error {
+	if w.paused.Load() {
+		return w.fallback.Sync()
+	}
+	return w.out.Sync()
+}
+
+// check 距上次检查超过间隔时重新读取剩余空间，状态切换时在标准错误输出提示
+// 无法获取剩余空间（如平台不支持）时保持原状态
+func (w *diskGuardWriter) check() {
+	w.mu.Lock()
+	now := time.Now()
+	if !w.lastCheck.IsZero() && now.Sub(w.lastCheck) < diskCheckInterval {
+		w.mu.Unlock()
+		return
+	}
+	w.lastCheck = now
+	w.mu.Unlock()
+
+	free, ok := freeDiskBytes(w.dir)
+	if !ok {
+		return
+	}
+	if free < w.minFree {
+		if !w.paused.Swap(true) {
+			fmt.Fprintf(os.Stderr, "clog: free disk space %s below %s, pausing file writes to %s and logging to stderr\n",
+				FormatBytes(int64(free)), FormatBytes(int64(w.minFree)), w.dir)
+		}
+		return
+	}
+	if w.paused.Swap(false) {
+		fmt.Fprintf(os.Stderr, "clog: free disk space %s recovered, resuming file writes to %s\n",
+			FormatBytes(int64(free)), w.dir)
+	}
+}
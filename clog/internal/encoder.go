@@ -1,15 +1,290 @@
 package internal
 
 import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
 	"path/filepath"
+	"reflect"
+	"strconv"
 	"strings"
 	"time"
 
+	"go.uber.org/zap"
+	"go.uber.org/zap/buffer"
 	"go.uber.org/zap/zapcore"
 )
 
+// ConsoleEncoderName 注册到 zap 的 console 编码器名称，在 zap 默认 console 编码器基础上支持 ByteSize 的可读格式
+const ConsoleEncoderName = "clog-console"
+
+func init() {
+	_ = zap.RegisterEncoder(ConsoleEncoderName, func(config zapcore.EncoderConfig) (zapcore.Encoder, error) {
+		return newConsoleEncoder(config), nil
+	})
+}
+
+// byteUnits 二进制字节单位（1 KiB = 1024 B）
+var byteUnits = []string{"B", "KiB", "MiB", "GiB", "TiB", "PiB", "EiB"}
+
+// ByteSize 表示字节数的日志字段值
+// JSON 编码为原始数字，console 编码为 "原始数字 (可读格式)"
+type ByteSize int64
+
+// String 返回二进制单位的可读格式，如 "1.5 MiB"
+func (b ByteSize) String() string {
+	return FormatBytes(int64(b))
+}
+
+// MarshalJSON 保持 JSON 输出为原始数字
+func (b ByteSize) MarshalJSON() ([]byte, error) {
+	return strconv.AppendInt(nil, int64(b), 10), nil
+}
+
+// FormatBytes 使用二进制单位格式化字节数，不足 1 KiB 时输出整数字节，否则保留一位小数
+func FormatBytes(n int64) string {
+	sign := ""
+	value := float64(n)
+	if n < 0 {
+		sign = "-"
+		value = -value
+	}
+	if value < 1024 {
+		return fmt.Sprintf("%s%d B", sign, int64(value))
+	}
+
+	unit := 0
+	for value >= 1024 && unit < len(byteUnits)-1 {
+		value /= 1024
+		unit++
+	}
+	return fmt.Sprintf("%s%.1f %s", sign, value, byteUnits[unit])
+}
+
+// consoleEncoder 包装 zap 的 console 编码器，将 ByteSize 字段输出为带可读格式的字符串
+type consoleEncoder struct {
+	zapcore.Encoder
+}
+
+// newConsoleEncoder 创建 console 编码器
+func newConsoleEncoder(config zapcore.EncoderConfig) zapcore.Encoder {
+	return &consoleEncoder{Encoder: zapcore.NewConsoleEncoder(config)}
+}
+
+// Clone 复制编码器，保留 ByteSize 处理能力（用于 With 添加的上下文字段）
+func (e *consoleEncoder) Clone() zapcore.Encoder {
+	return &consoleEncoder{Encoder: e.Encoder.Clone()}
+}
+
+// AddReflected 拦截 ByteSize 上下文字段
+func (e *consoleEncoder) AddReflected(key string, value interface{}) error {
+	if b, ok := value.(ByteSize); ok {
+		e.Encoder.AddString(key, formatConsoleBytes(b))
+		return nil
+	}
+	return e.Encoder.AddReflected(key, value)
+}
+
+// EncodeEntry 将本次日志中的 ByteSize 字段替换为字符串字段后编码
+func (e *consoleEncoder) EncodeEntry(ent zapcore.Entry, fields []zapcore.Field) (*buffer.Buffer, error) {
+	var replaced []zapcore.Field
+	for i, field := range fields {
+		b, ok := field.Interface.(ByteSize)
+		if !ok || field.Type != zapcore.ReflectType {
+			continue
+		}
+		// 首次命中时复制字段切片，避免修改调用方的数据
+		if replaced == nil {
+			replaced = make([]zapcore.Field, len(fields))
+			copy(replaced, fields)
+		}
+		replaced[i] = zap.String(field.Key, formatConsoleBytes(b))
+	}
+	if replaced != nil {
+		fields = replaced
+	}
+	return e.Encoder.EncodeEntry(ent, fields)
+}
+
+// formatConsoleBytes console 格式下同时输出原始数字和可读格式
+func formatConsoleBytes(b ByteSize) string {
+	return strconv.FormatInt(int64(b), 10) + " (" + b.String() + ")"
+}
+
+// LazyValue 延迟计算的字段值，只在日志真正被编码输出时调用
+type LazyValue func() interface{}
+
+// Resolve 调用函数计算字段值，函数 panic 时返回错误而不是中断日志记录
+func (f LazyValue) Resolve() (v interface{}, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("PANIC=%v", r)
+		}
+	}()
+	return f(), nil
+}
+
+// MarshalJSON 供直接使用 encoding/json 的路径（如字段大小限制）计算并编码字段值
+func (f LazyValue) MarshalJSON() ([]byte, error) {
+	v, err := f.Resolve()
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(v)
+}
+
+// String 供 fmt 格式化（如去重键计算）时计算字段值
+func (f LazyValue) String() string {
+	v, err := f.Resolve()
+	if err != nil {
+		return err.Error()
+	}
+	return fmt.Sprint(v)
+}
+
+// sortedReflectedEncoder 编码 Any 等反射字段，保证 map 按键排序输出，便于对比日志和编写 golden 测试
+// encoding/json 本身按键排序 map（包括嵌套的 map）；键类型无法直接编码为 JSON（如 bool、float、结构体）时，
+// 将 map 及其嵌套的 map 转换为以 fmt.Sprint(键) 为键的 map 后重新编码，而不是输出编码错误
+type sortedReflectedEncoder struct {
+	w io.Writer
+}
+
+// newSortedReflectedEncoder 创建反射字段编码器，与 zap 默认编码器一样不转义 HTML 字符
+func newSortedReflectedEncoder(w io.Writer) zapcore.ReflectedEncoder {
+	return &sortedReflectedEncoder{w: w}
+}
+
+// Encode 编码一个反射字段的值
+func (e *sortedReflectedEncoder) Encode(v interface{}) error {
+	if lazy, ok := v.(LazyValue); ok {
+		resolved, err := lazy.Resolve()
+		if err != nil {
+			return err
+		}
+		v = resolved
+	}
+
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	err := enc.Encode(v)
+	// 不同版本的 encoding/json 对不支持的键类型分别报告类型错误或值错误
+	var unsupportedType *json.UnsupportedTypeError
+	var unsupportedValue *json.UnsupportedValueError
+	if errors.As(err, &unsupportedType) || errors.As(err, &unsupportedValue) {
+		buf.Reset()
+		err = enc.Encode(stringifyMapKeys(reflect.ValueOf(v)))
+	}
+	if err != nil {
+		return err
+	}
+	_, err = e.w.Write(buf.Bytes())
+	return err
+}
+
+// stringifyMapKeys 递归地将 map 转换为字符串键的 map，穿透指针、接口和切片
+// 实现了 json.Marshaler 的值保持原样，由其自行决定编码方式
+func stringifyMapKeys(v reflect.Value) interface{} {
+	if !v.IsValid() {
+		return nil
+	}
+	if v.CanInterface() {
+		if _, ok := v.Interface().(json.Marshaler); ok {
+			return v.Interface()
+		}
+	}
+
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if v.IsNil() {
+			return nil
+		}
+		return stringifyMapKeys(v.Elem())
+	case reflect.Map:
+		if v.IsNil() {
+			return nil
+		}
+		out := make(map[string]interface{}, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			out[fmt.Sprint(iter.Key().Interface())] = stringifyMapKeys(iter.Value())
+		}
+		return out
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && v.IsNil() {
+			return nil
+		}
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			return v.Interface() // []byte 保持 base64 编码
+		}
+		out := make([]interface{}, v.Len())
+		for i := range out {
+			out[i] = stringifyMapKeys(v.Index(i))
+		}
+		return out
+	default:
+		if !v.CanInterface() {
+			return nil
+		}
+		return v.Interface()
+	}
+}
+
+// levelColorCodes 支持的颜色名称到 ANSI 转义序列的映射
+// "none" 表示该级别不着色
+var levelColorCodes = map[string]string{
+	"none":        "",
+	"black":       "\x1b[30m",
+	"red":         "\x1b[31m",
+	"green":       "\x1b[32m",
+	"yellow":      "\x1b[33m",
+	"blue":        "\x1b[34m",
+	"magenta":     "\x1b[35m",
+	"cyan":        "\x1b[36m",
+	"white":       "\x1b[37m",
+	"bold-red":    "\x1b[1;31m",
+	"bold-yellow": "\x1b[1;33m",
+}
+
+// defaultLevelColors 默认的级别颜色，与 zap 的 CapitalColorLevelEncoder 保持一致，Fatal 额外加粗
+var defaultLevelColors = map[zapcore.Level]string{
+	zapcore.DebugLevel: "magenta",
+	zapcore.InfoLevel:  "blue",
+	zapcore.WarnLevel:  "yellow",
+	zapcore.ErrorLevel: "red",
+	zapcore.FatalLevel: "bold-red",
+}
+
+// IsValidColor 检查颜色名称是否受支持
+func IsValidColor(name string) bool {
+	_, ok := levelColorCodes[strings.ToLower(name)]
+	return ok
+}
+
+// colorLevelEncoder 创建按级别着色的编码器，未配置的级别使用默认颜色
+func colorLevelEncoder(levelColors map[string]string) zapcore.LevelEncoder {
+	codes := make(map[zapcore.Level]string, len(defaultLevelColors))
+	for level, color := range defaultLevelColors {
+		codes[level] = levelColorCodes[color]
+	}
+	for name, color := range levelColors {
+		codes[parseLevel(name)] = levelColorCodes[strings.ToLower(color)]
+	}
+
+	return func(level zapcore.Level, enc zapcore.PrimitiveArrayEncoder) {
+		code, ok := codes[level]
+		if !ok || code == "" {
+			enc.AppendString(level.CapitalString())
+			return
+		}
+		enc.AppendString(code + level.CapitalString() + "\x1b[0m")
+	}
+}
+
 // buildEncoderConfig 根据格式创建编码器配置
-func buildEncoderConfig(format string, enableColor bool, rootPath string, addSource bool) zapcore.EncoderConfig {
+func buildEncoderConfig(format string, enableColor bool, rootPath string, addSource bool, levelColors map[string]string) zapcore.EncoderConfig {
 	config := zapcore.EncoderConfig{
 		TimeKey:        "time",
 		LevelKey:       "level",
@@ -21,6 +296,8 @@ func buildEncoderConfig(format string, enableColor bool, rootPath string, addSou
 		EncodeLevel:    zapcore.LowercaseLevelEncoder,
 		EncodeTime:     customTimeEncoder,
 		EncodeDuration: zapcore.SecondsDurationEncoder,
+		// Any 等反射字段按键排序输出 map，JSON 和 console 格式一致
+		NewReflectedEncoder: newSortedReflectedEncoder,
 	}
 
 	// 根据 addSource 配置决定是否包含 caller 信息
@@ -34,7 +311,7 @@ func buildEncoderConfig(format string, enableColor bool, rootPath string, addSou
 	// Console 格式特殊处理
 	if format == "console" {
 		if enableColor {
-			config.EncodeLevel = zapcore.CapitalColorLevelEncoder
+			config.EncodeLevel = colorLevelEncoder(levelColors)
 		} else {
 			config.EncodeLevel = zapcore.CapitalLevelEncoder
 		}
@@ -90,7 +367,7 @@ func createEncoder(format string, config zapcore.EncoderConfig) zapcore.Encoder
 	case "json":
 		return zapcore.NewJSONEncoder(config)
 	case "console":
-		return zapcore.NewConsoleEncoder(config)
+		return newConsoleEncoder(config)
 	default:
 		return zapcore.NewJSONEncoder(config)
 	}
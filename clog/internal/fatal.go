@@ -0,0 +1,72 @@
+package internal
+
+import (
+	"sync"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// Record 一条日志的快照，传递给 OnFatal 注册的回调和 WithEncoder 设置的自定义编码器
+type Record struct {
+	Time    time.Time              // 日志时间
+	Level   string                 // 日志级别，如 "info"、"error"；OnFatal 回调中固定为 "fatal"
+	Message string                 // 日志消息
+	Caller  string                 // 调用位置，形如 "pkg/file.go:42"，未开启 AddSource 时为空
+	Stack   string                 // 调用栈，仅 error 及以上级别的日志包含
+	Fields  map[string]interface{} // 日志字段（含 namespace）；传给编码器时包含 With 添加的字段，OnFatal 回调中只含调用 Fatal 时传入的字段
+}
+
+var (
+	fatalHooksMu sync.RWMutex
+	fatalHooks   []func(Record)
+)
+
+// OnFatal 注册 Fatal 日志写出后、进程退出前同步调用的回调，按注册顺序执行
+func OnFatal(fn func(Record)) {
+	if fn == nil {
+		return
+	}
+	fatalHooksMu.Lock()
+	defer fatalHooksMu.Unlock()
+	fatalHooks = append(fatalHooks, fn)
+}
+
+// fatalHook 替代 zap 默认的 Fatal 行为：日志写出后执行回调，不直接退出，
+// 由 zapLogger.Fatal 随后调用 ExitFunc，使 SetExitFunc 对 Fatal 生效
+type fatalHook struct{}
+
+// OnWrite 实现 zapcore.CheckWriteHook 接口
+func (fatalHook) OnWrite(ce *zapcore.CheckedEntry, fields []zapcore.Field) {
+	fatalHooksMu.RLock()
+	hooks := append([]func(Record){}, fatalHooks...)
+	fatalHooksMu.RUnlock()
+	if len(hooks) == 0 {
+		return
+	}
+
+	encoder := zapcore.NewMapObjectEncoder()
+	for _, field := range fields {
+		field.AddTo(encoder)
+	}
+	record := Record{
+		Time:    ce.Time,
+		Level:   ce.Level.String(),
+		Message: ce.Message,
+		Stack:   ce.Stack,
+		Fields:  encoder.Fields,
+	}
+	if ce.Caller.Defined {
+		record.Caller = ce.Caller.TrimmedPath()
+	}
+
+	for _, hook := range hooks {
+		runFatalHook(hook, record)
+	}
+}
+
+// runFatalHook 执行单个回调，回调 panic 时忽略，保证进程仍能按预期退出
+func runFatalHook(hook func(Record), record Record) {
+	defer func() { _ = recover() }()
+	hook(record)
+}
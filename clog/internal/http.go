@@ -0,0 +1,251 @@
+package internal
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// HTTP 输出的默认参数
+const (
+	DefaultHTTPBatchSize     = 100
+	DefaultHTTPFlushInterval = time.Second
+	DefaultHTTPMaxRetries    = 3
+	DefaultHTTPTimeout       = 5 * time.Second
+	DefaultHTTPQueueSize     = 1000
+)
+
+// httpRetryBackoff 第一次重试前的等待时间，之后每次翻倍
+var httpRetryBackoff = 100 * time.Millisecond
+
+// httpConfig HTTP 输出配置，未设置的数值已填充默认值
+type httpConfig struct {
+	URL           string            // 接收日志的地址
+	Headers       map[string]string // 附加的请求头
+	BatchSize     int               // 每批最大记录数
+	FlushInterval time.Duration     // 未攒满一批时的最长等待时间
+	MaxRetries    int               // 发送失败后的最大重试次数，负数表示不重试
+	Timeout       time.Duration     // 单次请求超时
+	QueueSize     int               // 等待发送的最大记录数
+	BlockOnFull   bool              // 队列满时阻塞写入而不是改写到标准错误
+}
+
+// httpWriter 将日志攒批后通过 HTTP POST 发送
+// 写入只把记录放入队列，由后台协程按 BatchSize 或 FlushInterval 组批发送；
+// JSON 格式以 JSON 数组发送，console 格式以换行分隔的文本发送。
+// 重试后仍失败的批次和队列满时的记录改写到标准错误，不阻塞业务（BlockOnFull 时队列满会阻塞）
+type httpWriter struct {
+	config    httpConfig
+	jsonArray bool
+	client    *http.Client
+	fallback  zapcore.WriteSyncer
+
+	mu     sync.RWMutex // 保护 closed，保证 Close 之后没有写入进入队列
+	closed bool
+
+	queue   chan []byte
+	syncs   chan chan struct{}
+	done    chan struct{} // Close 时关闭，通知后台协程发送剩余记录后退出
+	stopped chan struct{} // 后台协程退出后关闭
+}
+
+// newHTTPWriter 创建 HTTP 写入器并启动后台发送协程
+func newHTTPWriter(config httpConfig, format string) *httpWriter {
+	w := &httpWriter{
+		config:    config,
+		jsonArray: format == "json",
+		client:    &http.Client{Timeout: config.Timeout},
+		fallback:  zapcore.Lock(os.Stderr),
+		queue:     make(chan []byte, config.QueueSize),
+		syncs:     make(chan chan struct{}),
+		done:      make(chan struct{}),
+		stopped:   make(chan struct{}),
+	}
+	go w.run()
+	return w
+}
+
+// Write 将一条日志记录放入发送队列
+// 队列满时默认改写到标准错误；BlockOnFull 时等待队列空出位置
+func (w *httpWriter) Write(p []byte) (int, error) {
+	record := bytes.TrimRight(p, "\n")
+	if len(record) == 0 {
+		return len(p), nil
+	}
+	record = append([]byte(nil), record...)
+
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	if w.closed {
+		return w.fallback.Write(p)
+	}
+
+	if w.config.BlockOnFull {
+		w.queue <- record
+		return len(p), nil
+	}
+	select {
+	case w.queue <- record:
+		return len(p), nil
+	default:
+		return w.fallback.Write(p)
+	}
+}
+
+// Sync 发送队列中的全部记录，返回时这些记录已发送成功或已改写到标准错误
+func (w *httpWriter) Sync() error {
+	ack := make(chan struct{})
+	select {
+	case w.syncs <- ack:
+		<-ack
+	case <-w.stopped:
+	}
+	return nil
+}
+
+// Close 发送剩余记录并停止后台协程，之后的日志直接写到标准错误
+func (w *httpWriter) Close() error {
+	w.mu.Lock()
+	if w.closed {
+		w.mu.Unlock()
+		return nil
+	}
+	w.closed = true
+	w.mu.Unlock()
+
+	close(w.done)
+	<-w.stopped
+	return nil
+}
+
+// run 后台组批发送，直到 Close
+func (w *httpWriter) run() {
+	defer close(w.stopped)
+
+	ticker := time.NewTicker(w.config.FlushInterval)
+	defer ticker.Stop()
+
+	batch := make([][]byte, 0, w.config.BatchSize)
+	flush := func() {
+		if len(batch) > 0 {
+			w.send(batch)
+			batch = batch[:0]
+		}
+	}
+	// drain 取出队列中已有的记录，攒满一批就发送
+	drain := func() {
+		for {
+			select {
+			case record := <-w.queue:
+				batch = append(batch, record)
+				if len(batch) >= w.config.BatchSize {
+					flush()
+				}
+			default:
+				return
+			}
+		}
+	}
+
+	for {
+		select {
+		case record := <-w.queue:
+			batch = append(batch, record)
+			if len(batch) >= w.config.BatchSize {
+				flush()
+			}
+		case <-ticker.C:
+			flush()
+		case ack := <-w.syncs:
+			drain()
+			flush()
+			close(ack)
+		case <-w.done:
+			drain()
+			flush()
+			return
+		}
+	}
+}
+
+// send 发送一批记录，失败时按指数退避重试，最终失败则改写到标准错误
+func (w *httpWriter) send(batch [][]byte) {
+	body := w.encode(batch)
+
+	var err error
+	backoff := httpRetryBackoff
+	retries := max(w.config.MaxRetries, 0)
+	for attempt := 0; attempt <= retries; attempt++ {
+		if attempt > 0 {
+			time.Sleep(backoff)
+			backoff *= 2
+		}
+		if err = w.post(body); err == nil {
+			return
+		}
+	}
+
+	fmt.Fprintf(w.fallback, "clog: failed to send %d log records to %s after %d retries, writing to stderr: %v\n",
+		len(batch), w.config.URL, retries, err)
+	for _, record := range batch {
+		_, _ = w.fallback.Write(append(record, '\n'))
+	}
+}
+
+// encode 将一批记录编码为请求体
+func (w *httpWriter) encode(batch [][]byte) []byte {
+	var buf bytes.Buffer
+	if w.jsonArray {
+		buf.WriteByte('[')
+	}
+	for i, record := range batch {
+		if i > 0 {
+			if w.jsonArray {
+				buf.WriteByte(',')
+			} else {
+				buf.WriteByte('\n')
+			}
+		}
+		buf.Write(record)
+	}
+	if w.jsonArray {
+		buf.WriteByte(']')
+	} else {
+		buf.WriteByte('\n')
+	}
+	return buf.Bytes()
+}
+
+// post 发送一次请求，非 2xx 响应视为失败
+func (w *httpWriter) post(body []byte) error {
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodPost, w.config.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	if w.jsonArray {
+		req.Header.Set("Content-Type", "application/json")
+	} else {
+		req.Header.Set("Content-Type", "text/plain; charset=utf-8")
+	}
+	for k, v := range w.config.Headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body) // 读完响应体以复用连接
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,174 @@
+package internal
+
+import (
+	"encoding/json"
+	"unicode/utf8"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// TruncatedSuffix 被截断的字段值末尾追加的标记
+const TruncatedSuffix = "...(truncated)"
+
+// FieldsDroppedKey 记录被丢弃字段数量的字段名
+const FieldsDroppedKey = "fields_dropped"
+
+// limitCore 在编码之前限制字段大小和数量，避免意外记录的超大对象撑爆日志管道
+//   - maxFieldBytes：单个字段值的最大字节数，超出部分截断并追加 TruncatedSuffix
+//   - maxFields：单条日志的最大字段数（不含 namespace 字段），超出的字段被丢弃，
+//     并追加 fields_dropped=N 记录丢弃数量
+//
+// 0 表示不限制。字符串、字节串和错误直接按字节长度判断；
+// 对象、数组和 Any 记录的复杂值先编码为 JSON 再判断，超限时以截断后的 JSON 文本替代原值
+type limitCore struct {
+	zapcore.Core
+	maxFieldBytes int
+	maxFields     int
+}
+
+// NewLimitCore 返回包装核心的函数，用于 zap.WrapCore
+func NewLimitCore(maxFieldBytes, maxFields int) func(zapcore.Core) zapcore.Core {
+	return func(core zapcore.Core) zapcore.Core {
+		return &limitCore{Core: core, maxFieldBytes: maxFieldBytes, maxFields: maxFields}
+	}
+}
+
+// With 创建带有额外字段的子核心，With 附加的字段只做大小限制，不计入字段数
+func (c *limitCore) With(fields []zapcore.Field) zapcore.Core {
+	return &limitCore{
+		Core:          c.Core.With(c.truncateFields(fields)),
+		maxFieldBytes: c.maxFieldBytes,
+		maxFields:     c.maxFields,
+	}
+}
+
+// Check 判断是否需要记录该日志，写入时由 limitCore 处理字段
+func (c *limitCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+// Write 限制字段数量和大小后交给底层核心编码
+func (c *limitCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	return c.Core.Write(ent, c.truncateFields(c.dropFields(fields)))
+}
+
+// dropFields 保留前 maxFields 个字段，namespace 字段始终保留且不计数
+func (c *limitCore) dropFields(fields []zapcore.Field) []zapcore.Field {
+	if c.maxFields <= 0 || len(fields) <= c.maxFields {
+		return fields
+	}
+
+	kept := make([]zapcore.Field, 0, c.maxFields+2)
+	counted, dropped := 0, 0
+	for _, field := range fields {
+		if field.Key == "namespace" && field.Type == zapcore.StringType {
+			kept = append(kept, field)
+			continue
+		}
+		if counted < c.maxFields {
+			kept = append(kept, field)
+			counted++
+			continue
+		}
+		dropped++
+	}
+	if dropped > 0 {
+		kept = append(kept, zap.Int(FieldsDroppedKey, dropped))
+	}
+	return kept
+}
+
+// truncateFields 截断超过 maxFieldBytes 的字段值，未超限时返回原切片
+func (c *limitCore) truncateFields(fields []zapcore.Field) []zapcore.Field {
+	if c.maxFieldBytes <= 0 {
+		return fields
+	}
+
+	var result []zapcore.Field
+	for i, field := range fields {
+		truncated, ok := c.truncateField(field)
+		if !ok {
+			if result != nil {
+				result = append(result, field)
+			}
+			continue
+		}
+		if result == nil {
+			result = make([]zapcore.Field, i, len(fields))
+			copy(result, fields[:i])
+		}
+		result = append(result, truncated)
+	}
+	if result == nil {
+		return fields
+	}
+	return result
+}
+
+// truncateField 返回截断后的字段，字段未超限时返回 false
+func (c *limitCore) truncateField(field zapcore.Field) (zapcore.Field, bool) {
+	var value string
+	switch field.Type {
+	case zapcore.StringType:
+		value = field.String
+	case zapcore.ByteStringType, zapcore.BinaryType:
+		b, _ := field.Interface.([]byte)
+		if len(b) <= c.maxFieldBytes {
+			return field, false
+		}
+		value = string(b)
+	case zapcore.ErrorType:
+		err, _ := field.Interface.(error)
+		if err == nil {
+			return field, false
+		}
+		value = err.Error()
+	case zapcore.ReflectType, zapcore.ObjectMarshalerType, zapcore.ArrayMarshalerType, zapcore.StringerType:
+		encoded, ok := encodeFieldValue(field)
+		if !ok {
+			return field, false
+		}
+		value = encoded
+	default:
+		// 数值、布尔、时间等定长字段无需限制
+		return field, false
+	}
+
+	if len(value) <= c.maxFieldBytes {
+		return field, false
+	}
+	return zap.String(field.Key, truncateUTF8(value, c.maxFieldBytes)+TruncatedSuffix), true
+}
+
+// encodeFieldValue 将复杂字段的值编码为 JSON 文本，用于判断大小
+func encodeFieldValue(field zapcore.Field) (string, bool) {
+	enc := zapcore.NewMapObjectEncoder()
+	field.AddTo(enc)
+	value, ok := enc.Fields[field.Key]
+	if !ok {
+		return "", false
+	}
+	if s, ok := value.(string); ok {
+		return s, true
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return "", false
+	}
+	return string(data), true
+}
+
+// truncateUTF8 截取前 n 个字节，避免切断多字节字符
+func truncateUTF8(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return s[:n]
+}
@@ -1,14 +1,16 @@
 package internal
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
 	"reflect"
 	"strings"
+	"sync/atomic"
+	"time"
 
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
-	"gopkg.in/natefinch/lumberjack.v2"
 )
 
 // ExitFunc 退出函数类型，用于测试时模拟 os.Exit 行为
@@ -37,6 +39,24 @@ type Logger interface {
 	// Fatal 记录致命错误级别的日志并退出程序
 	Fatal(msg string, fields ...zap.Field)
 
+	// Debugw 以交替的键值对记录调试级别的日志，如 Debugw("msg", "key", val)
+	Debugw(msg string, keysAndValues ...interface{})
+
+	// Infow 以交替的键值对记录信息级别的日志
+	Infow(msg string, keysAndValues ...interface{})
+
+	// Warnw 以交替的键值对记录警告级别的日志
+	Warnw(msg string, keysAndValues ...interface{})
+
+	// Errorw 以交替的键值对记录错误级别的日志
+	Errorw(msg string, keysAndValues ...interface{})
+
+	// Fatalw 以交替的键值对记录致命错误级别的日志并退出程序
+	Fatalw(msg string, keysAndValues ...interface{})
+
+	// LogAt 以指定的时间戳记录一条日志，用于回放或补录历史事件
+	LogAt(t time.Time, level string, msg string, fields ...zap.Field)
+
 	// With 创建带有额外字段的子日志器
 	With(fields ...zap.Field) Logger
 
@@ -45,13 +65,70 @@ type Logger interface {
 
 	// Namespace 创建带有层次化命名空间的子日志器
 	Namespace(name string) Logger
+
+	// AtLevel 创建强制使用指定最低级别的子日志器，不受全局级别影响
+	AtLevel(level string) Logger
+
+	// Metric 创建以 name 为固定消息的指标日志器，通过 Emit 只提供字段，以 info 级别记录
+	Metric(name string) *MetricLogger
+
+	// MuteNamespace 丢弃指定命名空间及其子命名空间的全部日志
+	MuteNamespace(ns string)
+
+	// UnmuteNamespace 取消命名空间的静音
+	UnmuteNamespace(ns string)
+
+	// Clone 返回与当前日志器具有相同命名空间和字段的独立副本
+	Clone() Logger
+
+	// Sync 输出缓冲中的日志并同步底层写入器
+	Sync() error
+
+	// Close 输出缓冲中的日志；启用 BufferedJSON 时之后的日志不再攒批，逐条输出；HTTP 输出时之后的日志写到标准错误
+	Close() error
+
+	// Config 返回日志器实际生效的配置快照，包含默认值和运行时的级别、静音变更；字段与 clog.Config 一致
+	Config() Config
 }
 
 // zapLogger 封装 zap.Logger 的具体实现
 // 添加命名空间支持和优化的字段管理
 type zapLogger struct {
-	*zap.Logger        // 底层的 zap.Logger 实例
-	namespace   string // 层次化命名空间路径，如 "service.module.component"
+	*zap.Logger          // 底层的 zap.Logger 实例
+	namespace   string   // 层次化命名空间路径，如 "service.module.component"
+	mutes       *muteSet // 被静音的命名空间，与派生的子日志器共享
+	config      *config  // 创建时解析出的配置，与派生的子日志器共享，只读
+
+	buffer  closableWriter  // 攒批写入器（BufferedJSON 或 HTTP 输出），未启用时为 nil，与派生的子日志器共享
+	rotator *rotatingWriter // 记录轮转日志的轮转写入器，未启用时为 nil，与派生的子日志器共享
+
+	// caller 跳过 Debug 等方法和 write 两层调用栈的底层日志器，首次记录日志时创建并缓存，
+	// 避免每次调用都通过 WithOptions 复制 zap.Logger
+	caller atomic.Pointer[zap.Logger]
+}
+
+// callerLogger 返回缓存的底层日志器，并发首次调用时可能各自创建一次，结果相同
+func (l *zapLogger) callerLogger() *zap.Logger {
+	if logger := l.caller.Load(); logger != nil {
+		return logger
+	}
+	logger := l.Logger.WithOptions(zap.AddCallerSkip(2))
+	l.caller.Store(logger)
+	return logger
+}
+
+// write 先检查级别再组装字段，级别未启用时不产生任何分配
+// 只能由 Debug 等方法直接调用，callerLogger 按这一层调用深度跳过调用栈
+func (l *zapLogger) write(level zapcore.Level, msg string, fields []zap.Field) {
+	if ce := l.callerLogger().Check(level, msg); ce != nil {
+		ce.Write(l.addNamespaceToFields(fields)...)
+	}
+}
+
+// closableWriter 需要在 Close 时输出剩余日志的写入器
+type closableWriter interface {
+	zapcore.WriteSyncer
+	Close() error
 }
 
 // addNamespaceToFields 动态添加命名空间字段到日志字段中
@@ -87,13 +164,23 @@ type rotationConfig struct {
 // config 内部配置结构，避免循环依赖
 // 通过反射从外部 Config 结构体解析而来
 type config struct {
-	Level       string          // 日志级别
-	Format      string          // 输出格式
-	Output      string          // 输出目标
-	AddSource   bool            // 是否包含源码信息
-	EnableColor bool            // 是否启用颜色
-	RootPath    string          // 项目根路径
-	Rotation    *rotationConfig // 日志轮转配置
+	Level         string            // 日志级别
+	Format        string            // 输出格式
+	Output        string            // 输出目标
+	AddSource     bool              // 是否包含源码信息
+	EnableColor   bool              // 是否启用颜色，已按 ColorMode 和输出目标解析
+	ColorMode     string            // 颜色输出模式
+	RootPath      string            // 项目根路径
+	Rotation      *rotationConfig   // 日志轮转配置
+	LevelColors   map[string]string // 各级别的颜色
+	MutedNS       []string          // 被静音的命名空间
+	MaxFieldBytes int               // 单个字段值的最大字节数，0 表示不限制
+	MaxFields     int               // 单条日志的最大字段数，0 表示不限制
+	MinFreeDisk   int64             // 输出文件所在文件系统的最小剩余字节数，0 表示不检查
+	HTTP          *httpConfig       // HTTP 输出配置，仅 Output 为 http 时非空
+	BufferedJSON  int               // 攒批输出的每批最大记录数，0 表示逐行输出；由选项设置，不从 Config 解析
+	QuietRotation bool              // 是否不记录轮转日志；由选项设置，不从 Config 解析
+	Encoder       Encoder           // 自定义编码器，nil 表示按 Format 使用内置编码器；由选项设置，不从 Config 解析
 }
 
 // NewLogger 创建新的日志器实例
@@ -112,39 +199,100 @@ type config struct {
 //   - 自动创建输出目录
 //   - 优化性能的配置设置
 func NewLogger(cfg interface{}, namespace string) (Logger, error) {
-	// 类型断言获取配置
+	return newLogger(parseConfig(cfg), namespace)
+}
+
+// NewBufferedJSONLogger 创建以 JSON 数组攒批输出的日志器
+// 每攒满 maxRecords 条记录或调用 Sync/Close 时输出一个数组，要求 JSON 格式
+func NewBufferedJSONLogger(cfg interface{}, namespace string, maxRecords int) (Logger, error) {
+	return Build(cfg, namespace, BuildOptions{BufferedJSON: maxRecords})
+}
+
+// BuildOptions 不属于 Config、由 clog 的函数式选项决定的构建参数
+type BuildOptions struct {
+	BufferedJSON  int     // 攒批输出的每批最大记录数，0 表示逐行输出
+	QuietRotation bool    // 不记录轮转日志
+	Encoder       Encoder // 自定义编码器，内置编码器按其格式覆盖 Format
+}
+
+// Build 按配置和构建参数创建日志器
+func Build(cfg interface{}, namespace string, opts BuildOptions) (Logger, error) {
 	config := parseConfig(cfg)
+	config.BufferedJSON = opts.BufferedJSON
+	config.QuietRotation = opts.QuietRotation
+	if opts.Encoder != nil {
+		if format := EncoderFormat(opts.Encoder); format != "" {
+			config.Format = format
+		} else {
+			config.Encoder = opts.Encoder
+		}
+	}
+	return newLogger(config, namespace)
+}
+
+// newLogger 根据解析后的配置创建日志器
+func newLogger(config *config, namespace string) (Logger, error) {
+	if config.BufferedJSON > 0 {
+		return buildBufferedJSONLogger(config, namespace)
+	}
+	if config.Output == "http" {
+		return buildHTTPLogger(config, namespace)
+	}
+
+	// console 格式使用 clog 注册的编码器，支持 ByteSize 等字段的可读格式
+	encoding := config.Format
+	if encoding == "console" {
+		encoding = ConsoleEncoderName
+	}
 
 	// 创建 zap 配置
 	zapConfig := zap.Config{
 		Level:            zap.NewAtomicLevelAt(parseLevel(config.Level)),
-		Encoding:         config.Format,
+		Encoding:         encoding,
 		OutputPaths:      []string{config.Output},
 		ErrorOutputPaths: []string{"stderr"},
-		EncoderConfig:    buildEncoderConfig(config.Format, config.EnableColor, config.RootPath, config.AddSource),
+		EncoderConfig:    buildEncoderConfig(config.Format, config.EnableColor, config.RootPath, config.AddSource, config.LevelColors),
 	}
 
 	// 处理文件输出
-	if config.Output != "stdout" && config.Output != "stderr" {
+	if isFileOutput(config.Output) {
 		if err := ensureDir(config.Output); err != nil {
 			return nil, err
 		}
 
-		// 如果需要轮转，使用自定义的文件写入器
-		if config.Rotation != nil {
-			// 对于轮转文件，我们需要使用自定义的核心
-			return buildLoggerWithRotation(config, namespace)
+		// 如果需要轮转或磁盘空间保护，使用自定义的文件写入器
+		if config.Rotation != nil || config.MinFreeDisk > 0 {
+			writer, rotator, err := newFileWriter(config)
+			if err != nil {
+				return nil, err
+			}
+			logger := buildLoggerWithWriter(config, namespace, writer)
+			watchRotations(config, logger, rotator)
+			return logger, nil
 		}
 	}
 
+	// 自定义编码器无法注册到 zap.Config，直接打开输出目标并使用自定义写入器的构建方式
+	if config.Encoder != nil {
+		out, _, err := zap.Open(config.Output)
+		if err != nil {
+			return nil, err
+		}
+		return buildLoggerWithWriter(config, namespace, out), nil
+	}
+
 	// 构建 logger
 	buildOptions := []zap.Option{
 		zap.AddStacktrace(zapcore.ErrorLevel),
+		zap.WithFatalHook(fatalHook{}),
 	}
 	if config.AddSource {
 		// 只添加 AddCaller，不设置固定的 CallerSkip
 		buildOptions = append(buildOptions, zap.AddCaller())
 	}
+	if config.MaxFieldBytes > 0 || config.MaxFields > 0 {
+		buildOptions = append(buildOptions, zap.WrapCore(NewLimitCore(config.MaxFieldBytes, config.MaxFields)))
+	}
 
 	baseLogger, err := zapConfig.Build(buildOptions...)
 	if err != nil {
@@ -155,6 +303,8 @@ func NewLogger(cfg interface{}, namespace string) (Logger, error) {
 	return &zapLogger{
 		Logger:    baseLogger,
 		namespace: namespace,
+		mutes:     newMuteSet(config.MutedNS),
+		config:    config,
 	}, nil
 }
 
@@ -162,8 +312,10 @@ func NewLogger(cfg interface{}, namespace string) (Logger, error) {
 // 在初始化失败时提供基本的日志功能，确保系统可用性
 // 使用 zap.NewProduction 创建生产环境配置的日志器
 func NewFallbackLogger() Logger {
-	logger, _ := zap.NewProduction()
-	return &zapLogger{Logger: logger}
+	logger, _ := zap.NewProduction(zap.WithFatalHook(fatalHook{}))
+	fallback := getDefaultConfig()
+	fallback.Output = "stderr"
+	return &zapLogger{Logger: logger, mutes: newMuteSet(nil), config: fallback}
 }
 
 // With 添加字段
@@ -181,6 +333,10 @@ func (l *zapLogger) With(fields ...zap.Field) Logger {
 	return &zapLogger{
 		Logger:    l.Logger.With(filteredFields...),
 		namespace: l.namespace,
+		mutes:     l.mutes,
+		config:    l.config,
+		buffer:    l.buffer,
+		rotator:   l.rotator,
 	}
 }
 
@@ -193,76 +349,85 @@ func (l *zapLogger) WithOptions(opts ...zap.Option) Logger {
 	return &zapLogger{
 		Logger:    newLogger,
 		namespace: l.namespace,
+		mutes:     l.mutes,
+		config:    l.config,
+		buffer:    l.buffer,
+		rotator:   l.rotator,
 	}
 }
 
 // Debug 记录 Debug 级别的日志
-// 自动添加命名空间字段并调整调用栈信息
+// 自动添加命名空间字段并调整调用栈信息，级别未启用时不组装字段
 func (l *zapLogger) Debug(msg string, fields ...zap.Field) {
-	logger := l.Logger.WithOptions(zap.AddCallerSkip(1))
-	if l.namespace != "" {
-		allFields := make([]zap.Field, len(fields)+1)
-		allFields[0] = WithNamespaceField(l.namespace)
-		copy(allFields[1:], fields)
-		logger.Debug(msg, allFields...)
-	} else {
-		logger.Debug(msg, fields...)
+	if l.mutes.muted(l.namespace) {
+		return
 	}
+	l.write(zapcore.DebugLevel, msg, fields)
 }
 
 // Info 记录 Info 级别的日志
 func (l *zapLogger) Info(msg string, fields ...zap.Field) {
-	logger := l.Logger.WithOptions(zap.AddCallerSkip(1))
-	if l.namespace != "" {
-		allFields := make([]zap.Field, len(fields)+1)
-		allFields[0] = WithNamespaceField(l.namespace)
-		copy(allFields[1:], fields)
-		logger.Info(msg, allFields...)
-	} else {
-		logger.Info(msg, fields...)
+	if l.mutes.muted(l.namespace) {
+		return
 	}
+	l.write(zapcore.InfoLevel, msg, fields)
 }
 
 // Warn 记录 Warn 级别的日志
 func (l *zapLogger) Warn(msg string, fields ...zap.Field) {
-	logger := l.Logger.WithOptions(zap.AddCallerSkip(1))
-	if l.namespace != "" {
-		allFields := make([]zap.Field, len(fields)+1)
-		allFields[0] = WithNamespaceField(l.namespace)
-		copy(allFields[1:], fields)
-		logger.Warn(msg, allFields...)
-	} else {
-		logger.Warn(msg, fields...)
+	if l.mutes.muted(l.namespace) {
+		return
 	}
+	l.write(zapcore.WarnLevel, msg, fields)
 }
 
 // Error 记录 Error 级别的日志
 func (l *zapLogger) Error(msg string, fields ...zap.Field) {
-	logger := l.Logger.WithOptions(zap.AddCallerSkip(1))
-	if l.namespace != "" {
-		allFields := make([]zap.Field, len(fields)+1)
-		allFields[0] = WithNamespaceField(l.namespace)
-		copy(allFields[1:], fields)
-		logger.Error(msg, allFields...)
-	} else {
-		logger.Error(msg, fields...)
+	if l.mutes.muted(l.namespace) {
+		return
 	}
+	l.write(zapcore.ErrorLevel, msg, fields)
 }
 
 // Fatal 记录 Fatal 级别的日志并退出程序
+// 日志写出后先执行 OnFatal 注册的回调，再调用 ExitFunc 退出
 func (l *zapLogger) Fatal(msg string, fields ...zap.Field) {
-	logger := l.Logger.WithOptions(zap.AddCallerSkip(1))
-	if l.namespace != "" {
-		allFields := make([]zap.Field, len(fields)+1)
-		allFields[0] = WithNamespaceField(l.namespace)
-		copy(allFields[1:], fields)
-		logger.Fatal(msg, allFields...)
-	} else {
-		logger.Fatal(msg, fields...)
-	}
+	l.write(zapcore.FatalLevel, msg, fields)
 	ExitFunc(1)
 }
 
+// LogAt 以 t 作为日志时间记录一条日志，其他日志方法仍使用当前时间
+// level 取值与 AtLevel 相同，无效的级别按 info 处理；回放历史数据不应终止进程，
+// 因此 fatal 按 error 级别记录且不退出程序
+func (l *zapLogger) LogAt(t time.Time, level string, msg string, fields ...zap.Field) {
+	if l.mutes.muted(l.namespace) {
+		return
+	}
+	lvl := parseLevel(level)
+	if lvl > zapcore.ErrorLevel {
+		lvl = zapcore.ErrorLevel
+	}
+	logger := l.Logger.WithOptions(zap.AddCallerSkip(1), zap.WithClock(fixedClock{t: t}))
+	if ce := logger.Check(lvl, msg); ce != nil {
+		ce.Write(l.addNamespaceToFields(fields)...)
+	}
+}
+
+// fixedClock 总是返回固定时间的时钟，用于 LogAt 覆盖日志时间
+type fixedClock struct {
+	t time.Time
+}
+
+// Now 返回固定的时间
+func (c fixedClock) Now() time.Time {
+	return c.t
+}
+
+// NewTicker 返回标准的定时器，日志器不依赖它
+func (c fixedClock) NewTicker(d time.Duration) *time.Ticker {
+	return time.NewTicker(d)
+}
+
 // Namespace 创建子命名空间的 Logger 实例，支持链式调用
 // 子命名空间会与父命名空间组合形成完整的层次化路径
 func (l *zapLogger) Namespace(name string) Logger {
@@ -278,9 +443,162 @@ func (l *zapLogger) Namespace(name string) Logger {
 	return &zapLogger{
 		Logger:    l.Logger,
 		namespace: fullNamespace,
+		mutes:     l.mutes,
+		config:    l.config,
+		buffer:    l.buffer,
+		rotator:   l.rotator,
 	}
 }
 
+// AtLevel 创建强制使用指定最低级别的子日志器
+// 级别覆盖作用于整个核心链的级别判断，只影响返回的日志器及其派生的子日志器，
+// 父日志器和其他日志器不受影响；无效的级别按 info 处理
+//
+// 与其他功能的交互：
+//   - 采样：zap 的采样在 Check 阶段完成，在 AtLevel 之前通过 WithOptions 注入的采样核心会被绕过，
+//     之后注入的采样核心仍然生效
+//   - 去重：WithDedup 在 Write 阶段完成，AtLevel 之后仍然生效
+//   - 命名空间：级别与命名空间无关，Namespace 派生的子日志器继承覆盖后的级别
+func (l *zapLogger) AtLevel(level string) Logger {
+	minLevel := parseLevel(level)
+	return &zapLogger{
+		Logger: l.Logger.WithOptions(zap.WrapCore(func(core zapcore.Core) zapcore.Core {
+			return &levelOverrideCore{Core: core, level: minLevel}
+		})),
+		namespace: l.namespace,
+		mutes:     l.mutes,
+		config:    l.config,
+		buffer:    l.buffer,
+		rotator:   l.rotator,
+	}
+}
+
+// levelOverrideCore 以固定的最低级别替代底层核心的级别判断
+type levelOverrideCore struct {
+	zapcore.Core
+	level zapcore.Level
+}
+
+// Enabled 判断级别是否满足覆盖后的最低级别
+func (c *levelOverrideCore) Enabled(level zapcore.Level) bool {
+	return level >= c.level
+}
+
+// With 创建带有额外字段的子核心，保留级别覆盖
+func (c *levelOverrideCore) With(fields []zapcore.Field) zapcore.Core {
+	return &levelOverrideCore{Core: c.Core.With(fields), level: c.level}
+}
+
+// Check 按覆盖后的级别决定是否记录，写入时直接交给底层核心
+func (c *levelOverrideCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+// Clone 返回与当前日志器具有相同命名空间和字段的独立副本
+// 日志器本身不可变，With、Namespace 等方法总是返回新实例，因此原日志器和副本
+// 各自派生的子日志器互不影响；Clone 用于在长生命周期对象中明确字段快照的归属。
+// 静音状态属于整个日志器树，副本与原日志器共享
+func (l *zapLogger) Clone() Logger {
+	return &zapLogger{
+		Logger:    l.Logger.WithOptions(),
+		namespace: l.namespace,
+		mutes:     l.mutes,
+		config:    l.config,
+		buffer:    l.buffer,
+		rotator:   l.rotator,
+	}
+}
+
+// MuteNamespace 丢弃指定命名空间及其子命名空间的全部日志
+// 按命名空间层级做前缀匹配："db" 同时静音 "db" 和 "db.pool"，但不影响 "dbx"
+//
+// 静音状态由同一根日志器派生出的所有日志器共享，在任意一个上调用都会生效。
+// 静音优先于级别：即使通过 AtLevel 放开了级别，被静音的命名空间仍不输出；
+// Fatal 日志不受静音影响，以免进程无声退出
+func (l *zapLogger) MuteNamespace(ns string) {
+	l.mutes.mute(ns)
+}
+
+// UnmuteNamespace 取消命名空间的静音
+// 只移除完全相同的静音条目，不影响为父命名空间设置的静音
+func (l *zapLogger) UnmuteNamespace(ns string) {
+	l.mutes.unmute(ns)
+}
+
+// Close 输出缓冲中的日志；启用 BufferedJSON 时关闭攒批，之后的日志逐条输出；
+// HTTP 输出时发送剩余日志并停止后台发送，之后的日志写到标准错误
+func (l *zapLogger) Close() error {
+	l.rotator.wait()
+	if l.buffer != nil {
+		return l.buffer.Close()
+	}
+	return l.Logger.Sync()
+}
+
+// Sync 等待已触发的轮转日志写入后同步底层写入器
+func (l *zapLogger) Sync() error {
+	l.rotator.wait()
+	return l.Logger.Sync()
+}
+
+// Config 返回日志器实际生效的配置
+// 未设置的字段已填充默认值；EnableColor 为按 ColorMode 和输出目标解析后是否输出颜色；Level 为当前日志器的有效级别，反映 AtLevel 的覆盖；
+// MutedNamespaces 为当前的静音列表，反映运行时的 MuteNamespace/UnmuteNamespace；
+// Rotation 仅在文件输出时返回，HTTP 仅在 HTTP 输出时返回且请求头的值已脱敏
+func (l *zapLogger) Config() Config {
+	c := l.config
+	effective := Config{
+		Level:           l.Logger.Level().String(),
+		Format:          c.Format,
+		Output:          c.Output,
+		AddSource:       c.AddSource,
+		EnableColor:     c.EnableColor,
+		ColorMode:       c.ColorMode,
+		RootPath:        c.RootPath,
+		MutedNamespaces: l.mutes.list(),
+		MaxFieldBytes:   c.MaxFieldBytes,
+		MaxFields:       c.MaxFields,
+	}
+	if len(c.LevelColors) > 0 {
+		effective.LevelColors = make(map[string]string, len(c.LevelColors))
+		for level, color := range c.LevelColors {
+			effective.LevelColors[level] = color
+		}
+	}
+	if isFileOutput(c.Output) {
+		effective.MinFreeDiskBytes = c.MinFreeDisk
+	}
+	if c.HTTP != nil {
+		effective.HTTP = &HTTPConfig{
+			URL:           c.HTTP.URL,
+			BatchSize:     c.HTTP.BatchSize,
+			FlushInterval: c.HTTP.FlushInterval,
+			MaxRetries:    c.HTTP.MaxRetries,
+			Timeout:       c.HTTP.Timeout,
+			QueueSize:     c.HTTP.QueueSize,
+			BlockOnFull:   c.HTTP.BlockOnFull,
+		}
+		if len(c.HTTP.Headers) > 0 {
+			effective.HTTP.Headers = make(map[string]string, len(c.HTTP.Headers))
+			for k := range c.HTTP.Headers {
+				effective.HTTP.Headers[k] = "***" // 请求头通常包含鉴权信息，只返回键
+			}
+		}
+	}
+	if c.Rotation != nil && isFileOutput(c.Output) {
+		effective.Rotation = &RotationConfig{
+			MaxSize:    c.Rotation.MaxSize,
+			MaxBackups: c.Rotation.MaxBackups,
+			MaxAge:     c.Rotation.MaxAge,
+			Compress:   c.Rotation.Compress,
+		}
+	}
+	return effective
+}
+
 // parseConfig 解析配置
 func parseConfig(cfg interface{}) *config {
 	// 使用反射来解析配置，避免循环依赖
@@ -290,13 +608,24 @@ func parseConfig(cfg interface{}) *config {
 
 	// 尝试使用反射获取字段值
 	config := &config{
-		Level:       getStringField(cfg, "Level", "info"),
-		Format:      getStringField(cfg, "Format", "json"),
-		Output:      getStringField(cfg, "Output", "stdout"),
-		AddSource:   getBoolField(cfg, "AddSource", true),
-		EnableColor: getBoolField(cfg, "EnableColor", false),
-		RootPath:    getStringField(cfg, "RootPath", ""),
-	}
+		Level:         getStringField(cfg, "Level", "info"),
+		Format:        getStringField(cfg, "Format", "json"),
+		Output:        getStringField(cfg, "Output", "stdout"),
+		AddSource:     getBoolField(cfg, "AddSource", true),
+		EnableColor:   getBoolField(cfg, "EnableColor", false),
+		ColorMode:     getStringField(cfg, "ColorMode", ""),
+		RootPath:      getStringField(cfg, "RootPath", ""),
+		LevelColors:   getStringMapField(cfg, "LevelColors"),
+		MutedNS:       getStringSliceField(cfg, "MutedNamespaces"),
+		MaxFieldBytes: getIntField(cfg, "MaxFieldBytes", 0),
+		MaxFields:     getIntField(cfg, "MaxFields", 0),
+		MinFreeDisk:   getInt64Field(cfg, "MinFreeDiskBytes", 0),
+	}
+	if config.ColorMode == "" && config.EnableColor {
+		// 未设置 ColorMode 时开启颜色按 auto 处理，避免重定向到文件或管道时写入 ANSI 转义码
+		config.ColorMode = ColorAuto
+	}
+	config.EnableColor = resolveColor(config.ColorMode, config.EnableColor, config.Output)
 
 	// 处理轮转配置
 	if rotationField := getField(cfg, "Rotation"); rotationField != nil {
@@ -308,9 +637,52 @@ func parseConfig(cfg interface{}) *config {
 		}
 	}
 
+	// 处理 HTTP 输出配置
+	if config.Output == "http" {
+		config.HTTP = parseHTTPConfig(getField(cfg, "HTTP"))
+	}
+
 	return config
 }
 
+// parseHTTPConfig 解析 HTTP 输出配置，未设置的数值使用默认值
+func parseHTTPConfig(field interface{}) *httpConfig {
+	if v := reflect.ValueOf(field); v.Kind() == reflect.Ptr && v.IsNil() {
+		field = nil
+	}
+	httpCfg := &httpConfig{
+		URL:           getStringField(field, "URL", ""),
+		Headers:       getStringMapField(field, "Headers"),
+		BatchSize:     getIntField(field, "BatchSize", 0),
+		FlushInterval: getDurationField(field, "FlushInterval", 0),
+		MaxRetries:    getIntField(field, "MaxRetries", 0),
+		Timeout:       getDurationField(field, "Timeout", 0),
+		QueueSize:     getIntField(field, "QueueSize", 0),
+		BlockOnFull:   getBoolField(field, "BlockOnFull", false),
+	}
+	if httpCfg.BatchSize <= 0 {
+		httpCfg.BatchSize = DefaultHTTPBatchSize
+	}
+	if httpCfg.FlushInterval <= 0 {
+		httpCfg.FlushInterval = DefaultHTTPFlushInterval
+	}
+	if httpCfg.MaxRetries == 0 {
+		httpCfg.MaxRetries = DefaultHTTPMaxRetries
+	}
+	if httpCfg.Timeout <= 0 {
+		httpCfg.Timeout = DefaultHTTPTimeout
+	}
+	if httpCfg.QueueSize <= 0 {
+		httpCfg.QueueSize = DefaultHTTPQueueSize
+	}
+	return httpCfg
+}
+
+// isFileOutput 判断输出目标是否为文件路径
+func isFileOutput(output string) bool {
+	return output != "stdout" && output != "stderr" && output != "http"
+}
+
 // getDefaultConfig 返回默认配置
 func getDefaultConfig() *config {
 	return &config{
@@ -334,43 +706,115 @@ func parseLevel(level string) zapcore.Level {
 		return zapcore.WarnLevel
 	case "error":
 		return zapcore.ErrorLevel
+	case "fatal":
+		return zapcore.FatalLevel
 	default:
 		return zapcore.InfoLevel
 	}
 }
 
-// buildLoggerWithRotation 构建带轮转的日志器
-func buildLoggerWithRotation(config *config, namespace string) (Logger, error) {
+// newFileWriter 创建文件输出的写入器：配置了轮转时使用轮转写入器，否则直接追加写文件；
+// 配置了最小剩余磁盘空间时再包装一层磁盘空间保护。未配置轮转时返回的 rotator 为 nil
+func newFileWriter(config *config) (out zapcore.WriteSyncer, rotator *rotatingWriter, err error) {
+	if config.Rotation != nil {
+		rotator = newRotatingWriter(config)
+		out = rotator
+	} else {
+		file, err := os.OpenFile(config.Output, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+		if err != nil {
+			return nil, nil, err
+		}
+		out = file
+	}
+	if config.MinFreeDisk > 0 {
+		out = newDiskGuardWriter(out, config.Output, config.MinFreeDisk)
+	}
+	return out, rotator, nil
+}
+
+// watchRotations 未关闭轮转日志时，为每次轮转通过 logger 记录一条日志
+func watchRotations(config *config, logger *zapLogger, rotator *rotatingWriter) {
+	if rotator != nil && !config.QuietRotation {
+		logRotations(logger, rotator)
+		logger.rotator = rotator
+	}
+}
+
+// buildHTTPLogger 构建攒批 POST 到 HTTP 地址的日志器，Close 时发送剩余日志
+func buildHTTPLogger(config *config, namespace string) (Logger, error) {
+	if config.HTTP == nil || config.HTTP.URL == "" {
+		return nil, fmt.Errorf("http output requires http.url")
+	}
+	// 自定义编码器的输出不一定是 JSON，不能拼接为 JSON 数组
+	format := config.Format
+	if config.Encoder != nil {
+		format = ""
+	}
+	writer := newHTTPWriter(*config.HTTP, format)
+	logger := buildLoggerWithWriter(config, namespace, writer)
+	logger.buffer = writer
+	return logger, nil
+}
+
+// buildBufferedJSONLogger 构建以 JSON 数组攒批输出的日志器
+// 攒批包装在输出目标（标准输出、普通文件或轮转文件）之上
+func buildBufferedJSONLogger(config *config, namespace string) (Logger, error) {
+	var out zapcore.WriteSyncer
+	var rotator *rotatingWriter
+	switch config.Output {
+	case "stdout":
+		out = zapcore.Lock(os.Stdout)
+	case "stderr":
+		out = zapcore.Lock(os.Stderr)
+	case "http":
+		return nil, fmt.Errorf("buffered JSON output does not support http output, which already sends batches")
+	default:
+		if err := ensureDir(config.Output); err != nil {
+			return nil, err
+		}
+		writer, fileRotator, err := newFileWriter(config)
+		if err != nil {
+			return nil, err
+		}
+		out, rotator = writer, fileRotator
+	}
+
+	buffer := newBufferedJSONWriter(out, config.BufferedJSON)
+	logger := buildLoggerWithWriter(config, namespace, buffer)
+	logger.buffer = buffer
+	watchRotations(config, logger, rotator)
+	return logger, nil
+}
+
+// buildLoggerWithWriter 使用指定的写入器构建日志器，用于轮转文件和攒批输出等自定义写入场景
+func buildLoggerWithWriter(config *config, namespace string, writer zapcore.WriteSyncer) *zapLogger {
 	// 创建编码器
-	encoderConfig := buildEncoderConfig(config.Format, config.EnableColor, config.RootPath, config.AddSource)
+	encoderConfig := buildEncoderConfig(config.Format, config.EnableColor, config.RootPath, config.AddSource, config.LevelColors)
 	encoder := createEncoder(config.Format, encoderConfig)
-
-	// 创建轮转写入器
-	rotatingWriter := &lumberjack.Logger{
-		Filename:   config.Output,
-		MaxSize:    config.Rotation.MaxSize,
-		MaxBackups: config.Rotation.MaxBackups,
-		MaxAge:     config.Rotation.MaxAge,
-		Compress:   config.Rotation.Compress,
-		LocalTime:  true,
+	if config.Encoder != nil {
+		encoder = newRecordEncoder(config.Encoder)
 	}
 
 	// 创建核心
 	core := zapcore.NewCore(
 		encoder,
-		zapcore.AddSync(rotatingWriter),
+		writer,
 		parseLevel(config.Level),
 	)
 
 	// 构建选项
 	opts := []zap.Option{
 		zap.AddStacktrace(zapcore.ErrorLevel),
+		zap.WithFatalHook(fatalHook{}),
 	}
 
 	if config.AddSource {
 		// 只添加 AddCaller，不设置固定的 CallerSkip
 		opts = append(opts, zap.AddCaller())
 	}
+	if config.MaxFieldBytes > 0 || config.MaxFields > 0 {
+		opts = append(opts, zap.WrapCore(NewLimitCore(config.MaxFieldBytes, config.MaxFields)))
+	}
 
 	// 创建 logger
 	logger := zap.New(core, opts...)
@@ -379,7 +823,9 @@ func buildLoggerWithRotation(config *config, namespace string) (Logger, error) {
 	return &zapLogger{
 		Logger:    logger,
 		namespace: namespace,
-	}, nil
+		mutes:     newMuteSet(config.MutedNS),
+		config:    config,
+	}
 }
 
 func ensureDir(filename string) error {
@@ -436,6 +882,45 @@ func getBoolField(obj interface{}, fieldName string, defaultValue bool) bool {
 	return defaultValue
 }
 
+func getStringMapField(obj interface{}, fieldName string) map[string]string {
+	field := getField(obj, fieldName)
+	if field == nil {
+		return nil
+	}
+
+	if m, ok := field.(map[string]string); ok {
+		return m
+	}
+
+	return nil
+}
+
+func getStringSliceField(obj interface{}, fieldName string) []string {
+	field := getField(obj, fieldName)
+	if field == nil {
+		return nil
+	}
+
+	if s, ok := field.([]string); ok {
+		return s
+	}
+
+	return nil
+}
+
+func getInt64Field(obj interface{}, fieldName string, defaultValue int64) int64 {
+	field := getField(obj, fieldName)
+	if field == nil {
+		return defaultValue
+	}
+
+	if i, ok := field.(int64); ok {
+		return i
+	}
+
+	return defaultValue
+}
+
 func getIntField(obj interface{}, fieldName string, defaultValue int) int {
 	field := getField(obj, fieldName)
 	if field == nil {
@@ -448,3 +933,16 @@ func getIntField(obj interface{}, fieldName string, defaultValue int) int {
 
 	return defaultValue
 }
+
+func getDurationField(obj interface{}, fieldName string, defaultValue time.Duration) time.Duration {
+	field := getField(obj, fieldName)
+	if field == nil {
+		return defaultValue
+	}
+
+	if d, ok := field.(time.Duration); ok {
+		return d
+	}
+
+	return defaultValue
+}
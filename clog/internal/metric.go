@@ -0,0 +1,25 @@
+package internal
+
+import "go.uber.org/zap"
+
+// MetricLogger 以固定消息记录指标的日志器，由 Logger.Metric 创建，调用时只需提供字段
+type MetricLogger struct {
+	logger Logger
+	name   string
+}
+
+// Metric 创建以 name 为消息、沿用当前命名空间和字段的指标日志器
+func (l *zapLogger) Metric(name string) *MetricLogger {
+	// 跳过 Emit 这一层，调用位置指向 Emit 的调用处
+	return &MetricLogger{logger: l.WithOptions(zap.AddCallerSkip(1)), name: name}
+}
+
+// Emit 以 info 级别记录一条指标日志，消息为创建时指定的指标名
+func (m *MetricLogger) Emit(fields ...zap.Field) {
+	m.logger.Info(m.name, fields...)
+}
+
+// Name 返回指标名，即每条日志的消息
+func (m *MetricLogger) Name() string {
+	return m.name
+}
@@ -0,0 +1,97 @@
+package internal
+
+import (
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// muteSet 记录被静音的命名空间前缀，在同一根日志器派生出的所有日志器之间共享
+// 读多写少，写入时复制切片，读取无锁
+type muteSet struct {
+	mu       sync.Mutex
+	prefixes atomic.Pointer[[]string]
+}
+
+// newMuteSet 创建静音集合，忽略空命名空间
+func newMuteSet(namespaces []string) *muteSet {
+	s := &muteSet{}
+	for _, ns := range namespaces {
+		s.mute(ns)
+	}
+	return s
+}
+
+// mute 静音命名空间及其所有子命名空间
+func (s *muteSet) mute(ns string) {
+	if s == nil || ns == "" {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var current []string
+	if p := s.prefixes.Load(); p != nil {
+		current = *p
+	}
+	for _, existing := range current {
+		if existing == ns {
+			return
+		}
+	}
+	next := make([]string, len(current), len(current)+1)
+	copy(next, current)
+	next = append(next, ns)
+	s.prefixes.Store(&next)
+}
+
+// unmute 取消命名空间的静音，只移除完全相同的条目
+func (s *muteSet) unmute(ns string) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	p := s.prefixes.Load()
+	if p == nil {
+		return
+	}
+	next := make([]string, 0, len(*p))
+	for _, existing := range *p {
+		if existing != ns {
+			next = append(next, existing)
+		}
+	}
+	s.prefixes.Store(&next)
+}
+
+// list 返回当前静音的命名空间副本，没有静音时返回 nil
+func (s *muteSet) list() []string {
+	if s == nil {
+		return nil
+	}
+	p := s.prefixes.Load()
+	if p == nil || len(*p) == 0 {
+		return nil
+	}
+	return append([]string(nil), *p...)
+}
+
+// muted 判断命名空间是否被静音
+// 按命名空间层级做前缀匹配："db" 匹配 "db" 和 "db.pool"，不匹配 "dbx"
+func (s *muteSet) muted(namespace string) bool {
+	if s == nil || namespace == "" {
+		return false
+	}
+	p := s.prefixes.Load()
+	if p == nil {
+		return false
+	}
+	for _, prefix := range *p {
+		if namespace == prefix || strings.HasPrefix(namespace, prefix+".") {
+			return true
+		}
+	}
+	return false
+}
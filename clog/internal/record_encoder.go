@@ -0,0 +1,173 @@
+package internal
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/buffer"
+	"go.uber.org/zap/zapcore"
+)
+
+// Encoder 将一条日志记录编码为输出的字节，用于替换内置的 json/console 格式
+// 返回的字节原样写出，换行等记录分隔符由编码器自行添加；返回错误时该条日志被丢弃，错误写到标准错误
+type Encoder interface {
+	EncodeRecord(Record) ([]byte, error)
+}
+
+// encodeBufferPool 自定义编码器输出的缓冲池
+var encodeBufferPool = buffer.NewPool()
+
+// builtinEncoder 内置的 json/console 编码器
+// 作为 WithEncoder 的参数时等同于设置对应的 Format，直接使用 zap 编码器；
+// 单独调用 EncodeRecord 时可用于在自定义编码器中复用内置格式
+type builtinEncoder struct {
+	format string
+}
+
+// NewJSONEncoder 返回内置的 JSON 编码器
+func NewJSONEncoder() Encoder {
+	return &builtinEncoder{format: "json"}
+}
+
+// NewConsoleEncoder 返回内置的 console 编码器，不输出颜色
+func NewConsoleEncoder() Encoder {
+	return &builtinEncoder{format: "console"}
+}
+
+// EncoderFormat 返回内置编码器对应的格式，自定义编码器返回空字符串
+func EncoderFormat(encoder Encoder) string {
+	if builtin, ok := encoder.(*builtinEncoder); ok {
+		return builtin.format
+	}
+	return ""
+}
+
+// EncodeRecord 按内置格式编码记录，namespace 字段在前，其余字段按键排序
+// Caller 按原样输出，不再按 RootPath 截取
+func (e *builtinEncoder) EncodeRecord(record Record) ([]byte, error) {
+	config := buildEncoderConfig(e.format, false, "", record.Caller != "", nil)
+	config.EncodeCaller = zapcore.FullCallerEncoder
+	encoder := createEncoder(e.format, config)
+
+	level, err := zapcore.ParseLevel(record.Level)
+	if err != nil {
+		level = zapcore.InfoLevel
+	}
+	entry := zapcore.Entry{
+		Level:   level,
+		Time:    record.Time,
+		Message: record.Message,
+		Caller:  parseCaller(record.Caller),
+		Stack:   record.Stack,
+	}
+
+	keys := make([]string, 0, len(record.Fields))
+	for key := range record.Fields {
+		if key != "namespace" {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	fields := make([]zapcore.Field, 0, len(record.Fields))
+	if ns, ok := record.Fields["namespace"]; ok {
+		fields = append(fields, zap.Any("namespace", ns))
+	}
+	for _, key := range keys {
+		fields = append(fields, zap.Any(key, record.Fields[key]))
+	}
+
+	buf, err := encoder.EncodeEntry(entry, fields)
+	if err != nil {
+		return nil, err
+	}
+	defer buf.Free()
+	return append([]byte(nil), buf.Bytes()...), nil
+}
+
+// parseCaller 将 "file:line" 形式的调用位置还原为 EntryCaller，为空时返回未定义的调用位置
+func parseCaller(caller string) zapcore.EntryCaller {
+	if caller == "" {
+		return zapcore.EntryCaller{}
+	}
+	file, line := caller, 0
+	if idx := strings.LastIndex(caller, ":"); idx != -1 {
+		if n, err := strconv.Atoi(caller[idx+1:]); err == nil {
+			file, line = caller[:idx], n
+		}
+	}
+	return zapcore.EntryCaller{Defined: true, File: file, Line: line}
+}
+
+// recordEncoder 将 zap 的编码调用转换为 Record，交给自定义的 Encoder 编码
+// With 添加的字段保存在 MapObjectEncoder 中，编码时与本条日志的字段合并
+type recordEncoder struct {
+	*zapcore.MapObjectEncoder
+	encoder    Encoder
+	namespaces []string // With 中通过 zap.Namespace 打开的命名空间，复制时据此恢复当前层级
+}
+
+// newRecordEncoder 创建使用自定义 Encoder 的 zap 编码器
+func newRecordEncoder(encoder Encoder) zapcore.Encoder {
+	return &recordEncoder{MapObjectEncoder: zapcore.NewMapObjectEncoder(), encoder: encoder}
+}
+
+// OpenNamespace 记录打开的命名空间，之后添加的字段位于该命名空间下
+func (e *recordEncoder) OpenNamespace(key string) {
+	e.namespaces = append(e.namespaces, key)
+	e.MapObjectEncoder.OpenNamespace(key)
+}
+
+// Clone 复制已添加的字段，并恢复到相同的命名空间层级
+func (e *recordEncoder) Clone() zapcore.Encoder {
+	clone := &recordEncoder{
+		MapObjectEncoder: zapcore.NewMapObjectEncoder(),
+		encoder:          e.encoder,
+		namespaces:       append([]string(nil), e.namespaces...),
+	}
+	src, dst := e.Fields, clone.Fields
+	for _, ns := range e.namespaces {
+		copyFieldsExcept(dst, src, ns)
+		clone.MapObjectEncoder.OpenNamespace(ns)
+		src, _ = src[ns].(map[string]interface{})
+		dst = dst[ns].(map[string]interface{})
+	}
+	copyFieldsExcept(dst, src, "")
+	return clone
+}
+
+// copyFieldsExcept 复制 src 中除 skip 以外的字段
+func copyFieldsExcept(dst, src map[string]interface{}, skip string) {
+	for key, value := range src {
+		if key != skip || skip == "" {
+			dst[key] = value
+		}
+	}
+}
+
+// EncodeEntry 合并字段构造 Record，交给自定义编码器编码
+func (e *recordEncoder) EncodeEntry(ent zapcore.Entry, fields []zapcore.Field) (*buffer.Buffer, error) {
+	final := e.Clone().(*recordEncoder)
+	for _, field := range fields {
+		field.AddTo(final)
+	}
+	record := Record{
+		Time:    ent.Time,
+		Level:   ent.Level.String(),
+		Message: ent.Message,
+		Stack:   ent.Stack,
+		Fields:  final.Fields,
+	}
+	if ent.Caller.Defined {
+		record.Caller = ent.Caller.TrimmedPath()
+	}
+
+	data, err := e.encoder.EncodeRecord(record)
+	if err != nil {
+		return nil, err
+	}
+	buf := encodeBufferPool.Get()
+	_, _ = buf.Write(data)
+	return buf, nil
+}
@@ -0,0 +1,133 @@
+package internal
+
+import (
+	"sync"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// DefaultRequestBufferRecords 单个请求缓冲的最大记录数，超出时提前输出已缓冲的记录，避免长请求占用过多内存
+const DefaultRequestBufferRecords = 1000
+
+// requestFlushMu 串行化所有请求缓冲的输出，保证不同请求的日志块之间不交错
+var requestFlushMu sync.Mutex
+
+// requestRecord 缓冲的一条日志，保留写入时的核心以携带 With 附加的字段
+type requestRecord struct {
+	core   zapcore.Core
+	entry  zapcore.Entry
+	fields []zapcore.Field
+}
+
+// RequestBuffer 缓冲一个请求内的全部日志，在请求结束时作为连续的一段输出
+// 同一请求派生的所有核心共享同一个缓冲；日志的时间、调用者等信息在写入时确定，
+// 字段值在输出时才编码，因此不要在记录日志后修改以引用方式传入的字段值
+type RequestBuffer struct {
+	mu         sync.Mutex
+	maxRecords int
+	records    []requestRecord
+	flushed    bool
+}
+
+// NewRequestBuffer 创建请求缓冲，maxRecords 为提前输出前最多缓冲的记录数
+func NewRequestBuffer(maxRecords int) *RequestBuffer {
+	return &RequestBuffer{maxRecords: maxRecords}
+}
+
+// WrapCore 返回将日志写入缓冲的核心，用于 zap.WrapCore
+func (b *RequestBuffer) WrapCore(core zapcore.Core) zapcore.Core {
+	return &requestCore{Core: core, buffer: b}
+}
+
+// Flush 按记录顺序连续输出缓冲的日志，之后的日志不再缓冲，直接输出
+func (b *RequestBuffer) Flush() error {
+	b.mu.Lock()
+	b.flushed = true
+	records := b.records
+	b.records = nil
+	b.mu.Unlock()
+	return writeRequestRecords(records)
+}
+
+// drain 取出并清空已缓冲的记录
+func (b *RequestBuffer) drain() []requestRecord {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	records := b.records
+	b.records = nil
+	return records
+}
+
+// add 缓冲一条日志，已输出过或缓冲区满时返回需要立即输出的记录
+func (b *RequestBuffer) add(record requestRecord) []requestRecord {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.flushed {
+		return []requestRecord{record}
+	}
+	b.records = append(b.records, record)
+	if len(b.records) < b.maxRecords {
+		return nil
+	}
+	records := b.records
+	b.records = nil
+	return records
+}
+
+// writeRequestRecords 持有全局输出锁依次写出记录，返回第一个写入错误
+func writeRequestRecords(records []requestRecord) error {
+	if len(records) == 0 {
+		return nil
+	}
+
+	requestFlushMu.Lock()
+	defer requestFlushMu.Unlock()
+	var firstErr error
+	for _, r := range records {
+		if err := r.core.Write(r.entry, r.fields); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// requestCore 将日志写入请求缓冲而不是立即输出
+type requestCore struct {
+	zapcore.Core
+	buffer *RequestBuffer
+}
+
+// With 创建带有额外字段的子核心，共享请求缓冲
+func (c *requestCore) With(fields []zapcore.Field) zapcore.Core {
+	return &requestCore{Core: c.Core.With(fields), buffer: c.buffer}
+}
+
+// Check 判断是否需要记录该日志
+func (c *requestCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+// Write 缓冲日志；DPanic 及以上级别可能终止程序，先输出已缓冲的日志再立即输出
+func (c *requestCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	if ent.Level >= zapcore.DPanicLevel {
+		if err := writeRequestRecords(c.buffer.drain()); err != nil {
+			return err
+		}
+		return c.Core.Write(ent, fields)
+	}
+
+	// 字段切片属于调用方，缓冲前复制一份
+	record := requestRecord{core: c.Core, entry: ent, fields: append([]zapcore.Field(nil), fields...)}
+	return writeRequestRecords(c.buffer.add(record))
+}
+
+// Sync 输出已缓冲的日志（不结束缓冲）后同步底层核心
+func (c *requestCore) Sync() error {
+	if err := writeRequestRecords(c.buffer.drain()); err != nil {
+		return err
+	}
+	return c.Core.Sync()
+}
@@ -0,0 +1,171 @@
+package internal
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"gopkg.in/natefinch/lumberjack.v2"
+)
+
+// rotationMessage 每次轮转后记录的 info 日志
+const rotationMessage = "日志文件已轮转"
+
+// 与 lumberjack 保持一致的备份文件命名和默认大小
+const (
+	backupTimeFormat    = "2006-01-02T15-04-05.000"
+	defaultRotationSize = 100 // MB
+	megabyte            = 1024 * 1024
+)
+
+// rotationEvent 一次轮转的信息
+type rotationEvent struct {
+	oldFile    string // 轮转出的备份文件
+	newFile    string // 轮转后继续写入的文件
+	compressed bool   // 备份文件是否会被压缩为 oldFile + ".gz"
+	size       int64  // 备份文件的大小
+}
+
+// rotatingWriter 包装 lumberjack，自行按大小决定何时轮转，以便在轮转后得到通知（lumberjack 没有轮转回调）
+// lumberjack 在写入前判断 当前大小+本次写入 是否超过 MaxSize，这里按同样的规则提前调用 Rotate，
+// lumberjack 自身的判断因此不会再触发
+type rotatingWriter struct {
+	out      *lumberjack.Logger
+	max      int64
+	compress bool
+
+	mu       sync.Mutex
+	size     int64 // 当前文件已写入的字节数
+	opened   bool  // 是否已写入过，lumberjack 打开已有文件时按 >= MaxSize 判断
+	onRotate func(rotationEvent)
+	pending  sync.WaitGroup // 尚未完成的轮转通知
+}
+
+// newRotatingWriter 创建轮转文件写入器，当前大小从已有的输出文件读取
+func newRotatingWriter(config *config) *rotatingWriter {
+	maxSize := config.Rotation.MaxSize
+	if maxSize <= 0 {
+		maxSize = defaultRotationSize
+	}
+	w := &rotatingWriter{
+		out: &lumberjack.Logger{
+			Filename:   config.Output,
+			MaxSize:    config.Rotation.MaxSize,
+			MaxBackups: config.Rotation.MaxBackups,
+			MaxAge:     config.Rotation.MaxAge,
+			Compress:   config.Rotation.Compress,
+			LocalTime:  true,
+		},
+		max:      int64(maxSize) * megabyte,
+		compress: config.Rotation.Compress,
+	}
+	if info, err := os.Stat(config.Output); err == nil {
+		w.size = info.Size()
+	}
+	return w
+}
+
+// Write 写入前按需轮转，轮转成功时在独立的 goroutine 中通知 onRotate
+// 通知不能同步执行：记录轮转日志会再次调用 Write，而上层的攒批等写入器在调用 Write 时持有锁；
+// 日志器的 Sync 和 Close 先通过 wait 等待通知写完，因此轮转日志不会在它们之后写入
+func (w *rotatingWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	var event *rotationEvent
+	writeLen := int64(len(p))
+	full := w.size+writeLen > w.max || (!w.opened && w.size > 0 && w.size+writeLen >= w.max)
+	if full && w.size > 0 && writeLen <= w.max {
+		if err := w.out.Rotate(); err == nil {
+			event = &rotationEvent{
+				oldFile:    latestBackup(w.out.Filename),
+				newFile:    w.out.Filename,
+				compressed: w.compress,
+				size:       w.size,
+			}
+			w.size = 0
+		}
+	}
+	n, err := w.out.Write(p)
+	w.size += int64(n)
+	w.opened = true
+	onRotate := w.onRotate
+	w.mu.Unlock()
+
+	if event != nil && onRotate != nil {
+		w.pending.Add(1)
+		go func() {
+			defer w.pending.Done()
+			onRotate(*event)
+		}()
+	}
+	return n, err
+}
+
+// Sync lumberjack 直接写文件，没有需要同步的缓冲
+func (w *rotatingWriter) Sync() error {
+	return nil
+}
+
+// wait 等待已触发的轮转通知完成，w 为 nil 时直接返回
+// 不能在 Sync 中等待：上层的攒批写入器在调用 Sync 时持有锁，通知写日志需要同一把锁
+func (w *rotatingWriter) wait() {
+	if w != nil {
+		w.pending.Wait()
+	}
+}
+
+// setOnRotate 设置轮转通知
+func (w *rotatingWriter) setOnRotate(fn func(rotationEvent)) {
+	w.mu.Lock()
+	w.onRotate = fn
+	w.mu.Unlock()
+}
+
+// latestBackup 返回 filename 最新的备份文件，按 lumberjack 的命名规则 "<name>-<时间><ext>" 查找
+// 压缩可能已经开始，返回时去掉 ".gz" 后缀；找不到时返回空字符串
+func latestBackup(filename string) string {
+	dir := filepath.Dir(filename)
+	base := filepath.Base(filename)
+	ext := filepath.Ext(base)
+	prefix := strings.TrimSuffix(base, ext) + "-"
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return ""
+	}
+	var latest string
+	var latestTime time.Time
+	for _, entry := range entries {
+		name := strings.TrimSuffix(entry.Name(), ".gz")
+		if entry.IsDir() || !strings.HasPrefix(name, prefix) || !strings.HasSuffix(name, ext) {
+			continue
+		}
+		ts, err := time.ParseInLocation(backupTimeFormat, name[len(prefix):len(name)-len(ext)], time.Local)
+		if err != nil {
+			continue
+		}
+		if latest == "" || ts.After(latestTime) {
+			latest, latestTime = name, ts
+		}
+	}
+	if latest == "" {
+		return ""
+	}
+	return filepath.Join(dir, latest)
+}
+
+// logRotations 通过 logger 为每次轮转记录一条 info 日志，便于将日志中的时间间隙与轮转对应起来
+func logRotations(logger *zapLogger, writer *rotatingWriter) {
+	// 调用位置固定在轮转通知处，对排查没有帮助
+	rotationLogger := logger.WithOptions(zap.WithCaller(false))
+	writer.setOnRotate(func(event rotationEvent) {
+		rotationLogger.Info(rotationMessage,
+			zap.String("old_file", event.oldFile),
+			zap.String("new_file", event.newFile),
+			zap.Bool("compressed", event.compressed),
+			zap.Field{Key: "size", Type: zapcore.ReflectType, Interface: ByteSize(event.size)})
+	})
+}
@@ -0,0 +1,81 @@
+package internal
+
+import (
+	"fmt"
+
+	"go.uber.org/zap"
+)
+
+// danglingKeyMessage 键值对参数个数为奇数时记录的警告
+const danglingKeyMessage = "日志键值对缺少值，已忽略最后一个键"
+
+// sweetenFields 将交替的键值对转换为字段，用于 Infow 等键值对风格的方法
+// 值的类型由 zap.Any 推断，如 string、int、error、time.Duration 分别编码为对应类型，其他类型按反射编码；
+// 参数中直接传入的 zap.Field 原样使用，不占用键的位置；键不是字符串时用 fmt.Sprint 转换
+// 最后一个键缺少值时不生成字段，通过 dangling 返回该键
+func sweetenFields(keysAndValues []interface{}) (fields []zap.Field, dangling string, ok bool) {
+	if len(keysAndValues) == 0 {
+		return nil, "", false
+	}
+
+	fields = make([]zap.Field, 0, (len(keysAndValues)+1)/2)
+	for i := 0; i < len(keysAndValues); {
+		if field, isField := keysAndValues[i].(zap.Field); isField {
+			fields = append(fields, field)
+			i++
+			continue
+		}
+
+		key, isString := keysAndValues[i].(string)
+		if !isString {
+			key = fmt.Sprint(keysAndValues[i])
+		}
+		if i == len(keysAndValues)-1 {
+			return fields, key, true
+		}
+		fields = append(fields, zap.Any(key, keysAndValues[i+1]))
+		i += 2
+	}
+	return fields, "", false
+}
+
+// sugared 返回多跳过一层调用栈的日志器，并在键值对缺少值时记录一条警告
+// 警告与日志本身的调用位置都指向 Infow 等方法的调用处
+func (l *zapLogger) sugared(keysAndValues []interface{}) (Logger, []zap.Field) {
+	logger := l.WithOptions(zap.AddCallerSkip(1))
+	fields, dangling, ok := sweetenFields(keysAndValues)
+	if ok {
+		logger.WithOptions(zap.AddCallerSkip(1)).Warn(danglingKeyMessage, zap.String("ignored_key", dangling))
+	}
+	return logger, fields
+}
+
+// Debugw 以交替的键值对记录 Debug 级别的日志
+func (l *zapLogger) Debugw(msg string, keysAndValues ...interface{}) {
+	logger, fields := l.sugared(keysAndValues)
+	logger.Debug(msg, fields...)
+}
+
+// Infow 以交替的键值对记录 Info 级别的日志
+func (l *zapLogger) Infow(msg string, keysAndValues ...interface{}) {
+	logger, fields := l.sugared(keysAndValues)
+	logger.Info(msg, fields...)
+}
+
+// Warnw 以交替的键值对记录 Warn 级别的日志
+func (l *zapLogger) Warnw(msg string, keysAndValues ...interface{}) {
+	logger, fields := l.sugared(keysAndValues)
+	logger.Warn(msg, fields...)
+}
+
+// Errorw 以交替的键值对记录 Error 级别的日志
+func (l *zapLogger) Errorw(msg string, keysAndValues ...interface{}) {
+	logger, fields := l.sugared(keysAndValues)
+	logger.Error(msg, fields...)
+}
+
+// Fatalw 以交替的键值对记录 Fatal 级别的日志并退出程序
+func (l *zapLogger) Fatalw(msg string, keysAndValues ...interface{}) {
+	logger, fields := l.sugared(keysAndValues)
+	logger.Fatal(msg, fields...)
+}
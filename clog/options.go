@@ -1,11 +1,34 @@
 package clog
 
+import "time"
+
 // Options 定义 clog 日志器实例的配置选项
 // 使用函数式选项模式，支持灵活的配置方式
 type Options struct {
 	// Namespace 日志器的根命名空间，通常为服务名称
 	// 该命名空间会出现在此日志器实例产生的所有日志中
 	Namespace string
+
+	// DedupWindow 重复日志折叠窗口，0 表示不启用
+	// 窗口内连续相同（级别、消息、字段均一致）的日志会被合并为一条带 repeated=N 的汇总行
+	DedupWindow time.Duration
+
+	// ContextDeadline 是否在 WithContext 中自动添加 context 剩余时间字段
+	// 仅对 Init 初始化的全局日志器生效
+	ContextDeadline bool
+
+	// BufferedJSON 攒批输出的每批最大记录数，0 表示逐行输出
+	// 启用后日志以 JSON 数组批量写出，要求 Format 为 json
+	BufferedJSON int
+
+	// StartupBanner 是否在创建日志器后输出一条汇总生效配置的 info 日志
+	StartupBanner bool
+
+	// QuietRotation 是否不记录文件轮转日志，默认每次轮转后记录一条 info 日志
+	QuietRotation bool
+
+	// Encoder 自定义编码器，nil 表示按 Config.Format 使用内置编码器
+	Encoder Encoder
 }
 
 // Option 定义配置 clog 选项的函数类型
@@ -33,6 +56,112 @@ func WithNamespace(namespace string) Option {
 	}
 }
 
+// WithDedup 启用重复日志折叠
+// 与采样不同，仅针对完全相同的连续日志：首条立即输出，窗口内的后续重复只计数，
+// 在出现不同的日志或窗口到期时输出一条带 repeated=N 字段的汇总行
+//
+// 参数：
+//   - window: 折叠窗口，从首条日志开始计时，小于等于 0 时不启用
+//
+// 返回：
+//   - Option: 配置选项函数
+//
+// 示例：
+//
+//	// 紧密的错误循环中，1 秒内的相同错误只输出首条和一条汇总
+//	logger, err := clog.New(ctx, config, clog.WithDedup(time.Second))
+func WithDedup(window time.Duration) Option {
+	return func(opts *Options) {
+		opts.DedupWindow = window
+	}
+}
+
+// WithContextDeadline 让 WithContext 自动添加 "deadline_remaining" 字段
+// 仅在 ctx 带有截止时间时添加，剩余时间在调用 WithContext 时计算；
+// 该选项作用于 WithContext，只在 Init 中使用时生效
+//
+// 返回：
+//   - Option: 配置选项函数
+//
+// 示例：
+//
+//	err := clog.Init(ctx, config, clog.WithContextDeadline())
+//	clog.WithContext(reqCtx).Warn("查询超时") // 附带 deadline_remaining=-15ms
+func WithContextDeadline() Option {
+	return func(opts *Options) {
+		opts.ContextDeadline = true
+	}
+}
+
+// WithBufferedJSON 将日志攒批后以 JSON 数组输出，而不是默认的逐行 JSON
+// 每攒满 maxRecords 条记录输出一行 "[{...},{...}]"，Sync 或 Close 时输出剩余记录；
+// 适用于按批上传的导入接口，要求 Format 为 json，退出前必须调用 Sync 或 Close，否则缓冲中的日志会丢失
+//
+// 参数：
+//   - maxRecords: 每个数组的最大记录数，小于等于 0 时不启用
+//
+// 返回：
+//   - Option: 配置选项函数
+//
+// 示例：
+//
+//	logger, err := clog.New(ctx, config, clog.WithBufferedJSON(500))
+//	defer logger.Close()
+func WithBufferedJSON(maxRecords int) Option {
+	return func(opts *Options) {
+		opts.BufferedJSON = maxRecords
+	}
+}
+
+// WithStartupBanner 在 New/Init 创建日志器后输出一条汇总生效配置的 info 日志
+// 包含级别、格式、输出目标、轮转、静音命名空间等，日志本身说明了日志是如何配置的，
+// 便于排查日志缺失或格式异常；该日志不受配置的级别限制，默认不输出
+//
+// 返回：
+//   - Option: 配置选项函数
+//
+// 示例：
+//
+//	err := clog.Init(ctx, config, clog.WithNamespace("order-service"), clog.WithStartupBanner())
+//	// {"level":"info","namespace":"order-service","msg":"日志器初始化完成","log_level":"warn","log_format":"json",...}
+func WithStartupBanner() Option {
+	return func(opts *Options) {
+		opts.StartupBanner = true
+	}
+}
+
+// WithQuietRotation 关闭文件轮转日志
+// 默认配置了 Rotation 的文件输出每次轮转后记录一条 info 日志 "日志文件已轮转"，包含 old_file（备份文件）、
+// new_file（继续写入的文件）、compressed（备份是否压缩为 old_file.gz）和 size（备份文件大小），
+// 便于将日志中的时间间隙与轮转对应起来；该日志异步写入新文件，受配置的级别和命名空间静音影响
+//
+// 返回：
+//   - Option: 配置选项函数
+//
+// 示例：
+//
+//	logger, err := clog.New(ctx, config, clog.WithQuietRotation())
+func WithQuietRotation() Option {
+	return func(opts *Options) {
+		opts.QuietRotation = true
+	}
+}
+
+// WithEncoder 使用自定义编码器替换内置的 json/console 格式，如 logfmt 或私有的二进制格式
+// 每条日志以 Record 的形式交给 enc.EncodeRecord，返回的字节原样写到输出目标；
+// 传入 JSONEncoder() 或 ConsoleEncoder() 等同于设置对应的 Config.Format
+//
+// 自定义编码器不支持 WithBufferedJSON；HTTP 输出时每批请求体为各条记录直接拼接，而不是 JSON 数组
+//
+// 示例：
+//
+//	logger, err := clog.New(ctx, config, clog.WithEncoder(logfmtEncoder{}))
+func WithEncoder(enc Encoder) Option {
+	return func(opts *Options) {
+		opts.Encoder = enc
+	}
+}
+
 // DefaultOptions 返回 clog 的默认选项
 // 返回空命名空间的默认配置，作为选项解析的基础
 //
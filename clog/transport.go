@@ -0,0 +1,105 @@
+package clog
+
+import (
+	"net/http"
+	"time"
+)
+
+// DefaultTraceHeader NewRoundTripper 传递 trace_id 使用的默认请求头
+const DefaultTraceHeader = "X-Trace-ID"
+
+// RoundTripFields 根据一次出站请求的结果生成日志字段，请求失败时 resp 为 nil
+type RoundTripFields func(req *http.Request, resp *http.Response, latency time.Duration) []Field
+
+// RoundTripperOption 配置 NewRoundTripper 的函数式选项
+type RoundTripperOption func(*roundTripper)
+
+// WithTraceHeader 设置传递 trace_id 的请求头名称，默认为 DefaultTraceHeader，为空时不传递
+func WithTraceHeader(name string) RoundTripperOption {
+	return func(rt *roundTripper) {
+		rt.traceHeader = name
+	}
+}
+
+// WithRoundTripFields 用 fn 生成的字段替换默认的 method、url、status 和 latency 字段
+// 需要在默认字段基础上追加时，可在 fn 中调用 DefaultRoundTripFields
+func WithRoundTripFields(fn RoundTripFields) RoundTripperOption {
+	return func(rt *roundTripper) {
+		if fn != nil {
+			rt.fields = fn
+		}
+	}
+}
+
+// DefaultRoundTripFields NewRoundTripper 默认记录的字段：method、url、status（请求失败时不记录）和 latency
+// url 中的密码会被隐藏，但查询参数原样记录，敏感参数需通过 WithRoundTripFields 自行处理
+func DefaultRoundTripFields(req *http.Request, resp *http.Response, latency time.Duration) []Field {
+	fields := []Field{
+		String("method", req.Method),
+		String("url", req.URL.Redacted()),
+	}
+	if resp != nil {
+		fields = append(fields, Int("status", resp.StatusCode))
+	}
+	return append(fields, Duration("latency", latency))
+}
+
+// NewRoundTripper 返回记录每次出站 HTTP 请求的 http.RoundTripper，next 为 nil 时使用 http.DefaultTransport
+// 请求完成时以 info 级别记录，响应状态码为 5xx 时以 warn 级别记录，请求失败时以 error 级别记录并附带错误；
+// 日志带有请求 context 中的 trace_id，同时写入 WithTraceHeader 指定的请求头传递给下游，请求已设置该头时保持不变。
+// logger 为 nil 时使用 WithContext(req.Context()) 得到的日志器
+//
+// 示例：
+//
+//	client := &http.Client{Transport: clog.NewRoundTripper(nil, clog.Namespace("payment-api"))}
+//	req, _ := http.NewRequestWithContext(ctx, http.MethodPost, url, body)
+//	resp, err := client.Do(req)
+func NewRoundTripper(next http.RoundTripper, logger Logger, opts ...RoundTripperOption) http.RoundTripper {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	rt := &roundTripper{
+		next:        next,
+		logger:      logger,
+		traceHeader: DefaultTraceHeader,
+		fields:      DefaultRoundTripFields,
+	}
+	for _, opt := range opts {
+		opt(rt)
+	}
+	return rt
+}
+
+// roundTripper NewRoundTripper 返回的 http.RoundTripper 实现
+type roundTripper struct {
+	next        http.RoundTripper
+	logger      Logger
+	traceHeader string
+	fields      RoundTripFields
+}
+
+// RoundTrip 传递 trace_id 后执行请求并记录结果
+// http.RoundTripper 不允许修改传入的请求，需要写入请求头时先克隆
+func (rt *roundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	if traceID := traceIDFrom(ctx); traceID != "" && rt.traceHeader != "" && req.Header.Get(rt.traceHeader) == "" {
+		req = req.Clone(ctx)
+		req.Header.Set(rt.traceHeader, traceID)
+	}
+
+	start := time.Now()
+	resp, err := rt.next.RoundTrip(req)
+	latency := time.Since(start)
+
+	logger := WithContext(IntoContext(ctx, rt.logger))
+	fields := rt.fields(req, resp, latency)
+	switch {
+	case err != nil:
+		logger.Error("HTTP 请求失败", append(fields, Err(err))...)
+	case resp.StatusCode >= http.StatusInternalServerError:
+		logger.Warn("HTTP 请求完成", fields...)
+	default:
+		logger.Info("HTTP 请求完成", fields...)
+	}
+	return resp, err
+}
@@ -1,19 +1,39 @@
 package allocator
 
-import "context"
+import (
+    "context"
+    "errors"
+)
+
+// ErrExhausted 可分配范围内的 ID 已全部被占用，AcquireID 返回包装该错误的错误（可用 errors.Is 判断）
+var ErrExhausted = errors.New("no available ID found")
 
 // InstanceIDAllocator 为一类服务的实例分配唯一的、可自动回收的ID
 type InstanceIDAllocator interface {
-    // AcquireID 尝试获取一个未被使用的 ID
+    // AcquireID 尝试获取一个未被使用的 ID，范围内的 ID 全部被占用时返回包装 ErrExhausted 的错误
     // ctx 用于控制本次获取操作的超时
     // 返回的 AllocatedID 对象代表一个被成功占用的、会自动续租的 ID
     AcquireID(ctx context.Context) (AllocatedID, error)
+    // WaitAcquireID 获取一个 ID，ID 耗尽时排队等待直到有 ID 被释放或 ctx 被取消
+    // 等待者按排队先后顺序获得释放的 ID（尽力而为的 FIFO）：
+    // 同时调用 AcquireID 的非排队调用方仍可能抢先拿到刚释放的 ID
+    WaitAcquireID(ctx context.Context) (AllocatedID, error)
 }
 
 // AllocatedID 代表一个被当前服务实例持有的、会自动续租的 ID
 type AllocatedID interface {
     // ID 返回被分配的整数 ID
     ID() int
+    // IsReused 报告该 ID 此前是否被同一服务的实例持有过（已主动释放或因租约过期被回收）
+    // 首次分配的 ID 返回 false，可据此决定复用与 ID 关联的状态（如磁盘数据）还是重新初始化
+    // 持有者崩溃时 ID 要等租约过期才会被回收，再次分配时返回 true，但上一个持有者留下的状态可能不完整
+    // 分配历史持久保存在 etcd 中，不随租约过期而删除
+    IsReused() bool
+    // OnExpiring 注册回调，在续约失败导致租约剩余时间进入 WithExpiryMargin 设置的余量（默认 TTL/3）时调用
+    // 用于在 ID 被其他实例取得之前告警或主动补救（如停止写入依赖该 ID 的数据、重新获取 ID）
+    // 剩余时间按定期查询的租约 TTL 判断，查询失败时按上次查询结果推算；续约恢复后再次进入余量会重新调用
+    // 回调在独立的 goroutine 中执行，可多次调用以注册多个回调；ID 释放后不再调用
+    OnExpiring(fn func())
     // Close 主动释放当前持有的 ID。这是一个幂等操作
     // 如果不调用此方法，ID 将在服务实例关闭时通过 etcd 的租约机制自动释放
     // ctx 用于控制本次释放操作的超时
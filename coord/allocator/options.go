@@ -0,0 +1,71 @@
+package allocator
+
+import "time"
+
+const (
+	// DefaultLeaseTTL 默认的租约 TTL
+	DefaultLeaseTTL = 30 * time.Second
+	// MinLeaseTTL 允许的最小租约 TTL
+	// etcd 按整秒授予租约，且默认心跳/选举参数下服务端会把过短的 TTL 提升到约 2 秒
+	MinLeaseTTL = 2 * time.Second
+)
+
+// Options 定义 ID 分配器的配置选项
+type Options struct {
+	// LeaseTTL 持有 ID 的会话租约有效期，按整秒截断
+	LeaseTTL time.Duration
+	// ExpiryMargin 租约剩余时间不超过该值时触发 AllocatedID.OnExpiring 的回调，0 表示使用 LeaseTTL/3
+	ExpiryMargin time.Duration
+	// Partition 分区名，如租户 ID；非空时分配器在服务下独立的 ID 空间中分配，不能包含 "/"
+	Partition string
+}
+
+// Option 配置 ID 分配器的函数式选项
+type Option func(*Options)
+
+// WithLeaseTTL 设置持有 ID 的租约 TTL，不能小于 MinLeaseTTL
+// TTL 越长，续约流量越少，但实例崩溃后 ID 需要更久才能被回收，故障发现也更慢；
+// 长期运行的服务适合较长的 TTL，短生命周期的任务适合较短的 TTL 以便尽快归还 ID
+// 会话由客户端每 TTL/3 自动续约一次
+func WithLeaseTTL(ttl time.Duration) Option {
+	return func(o *Options) {
+		o.LeaseTTL = ttl
+	}
+}
+
+// WithExpiryMargin 设置触发 AllocatedID.OnExpiring 回调的剩余时间，默认为 LeaseTTL/3，不能超过 LeaseTTL/2
+// 续约正常时租约剩余时间保持在 TTL 的 2/3 以上，剩余时间降到 margin 以内说明续约已连续失败；
+// margin 越大，回调越早触发、补救时间越充裕，但网络短暂抖动时也更容易误报
+func WithExpiryMargin(margin time.Duration) Option {
+	return func(o *Options) {
+		o.ExpiryMargin = margin
+	}
+}
+
+// WithPartition 在服务下按分区（如租户 ID）划分独立的 ID 空间
+// 不同分区之间同一个 ID 可以同时被持有，同一分区内的 ID 仍保证唯一；排队等待和分配历史（IsReused）也按分区独立
+// 分区名不能包含 "/"；不设置分区的分配器与所有分区互不影响
+func WithPartition(partition string) Option {
+	return func(o *Options) {
+		o.Partition = partition
+	}
+}
+
+// ParseOptions 应用选项并返回最终的分配器配置
+func ParseOptions(opts ...Option) *Options {
+	result := &Options{
+		LeaseTTL: DefaultLeaseTTL,
+	}
+	for _, opt := range opts {
+		opt(result)
+	}
+	return result
+}
+
+// Margin 返回触发 OnExpiring 回调的剩余时间，未设置时为 LeaseTTL/3
+func (o *Options) Margin() time.Duration {
+	if o.ExpiryMargin > 0 {
+		return o.ExpiryMargin
+	}
+	return o.LeaseTTL / 3
+}
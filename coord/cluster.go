@@ -0,0 +1,143 @@
+package coord
+
+import (
+	"context"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/ceyewan/infra-kit/clog"
+	"github.com/ceyewan/infra-kit/coord/internal/client"
+)
+
+// DefaultClusterWatchInterval WatchCluster 默认的成员列表轮询间隔
+const DefaultClusterWatchInterval = 10 * time.Second
+
+// ClusterEventType 集群成员变化的类型
+type ClusterEventType string
+
+const (
+	// ClusterMemberAdded 新成员加入集群，包括以 learner 身份加入
+	ClusterMemberAdded ClusterEventType = "ADDED"
+	// ClusterMemberRemoved 成员被移出集群
+	ClusterMemberRemoved ClusterEventType = "REMOVED"
+	// ClusterMemberUpdated 成员的名称、地址或 learner 状态变化，如新成员启动后上报名称和客户端地址、learner 被提升为投票成员
+	ClusterMemberUpdated ClusterEventType = "UPDATED"
+)
+
+// ClusterMember etcd 集群成员
+type ClusterMember struct {
+	ID         uint64
+	Name       string // 尚未启动的新成员名称为空
+	PeerURLs   []string
+	ClientURLs []string // 可用于重新解析客户端连接的 endpoints
+	IsLearner  bool
+}
+
+// ClusterEvent 集群成员变化事件
+type ClusterEvent struct {
+	Type   ClusterEventType
+	Member ClusterMember // 变化后的成员信息，移除时为移除前的信息
+}
+
+// WatchCluster 实现 Provider 接口 - 监听 etcd 集群成员变化
+func (c *coordinator) WatchCluster(ctx context.Context) (<-chan ClusterEvent, error) {
+	c.mu.RLock()
+	closed := c.closed
+	c.mu.RUnlock()
+	if closed {
+		return nil, client.NewError(client.ErrCodeUnavailable, "coordinator is closed", nil)
+	}
+
+	// 首次获取的成员列表作为基准，不产生事件；失败时直接返回错误
+	members, err := c.memberList(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	eventCh := make(chan ClusterEvent, 10)
+	go func() {
+		defer close(eventCh)
+
+		ticker := time.NewTicker(c.clusterWatchInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-c.done:
+				return
+			case <-ticker.C:
+			}
+
+			current, err := c.memberList(ctx)
+			if err != nil {
+				// 成员变更期间请求可能短暂失败，下一轮继续轮询
+				if ctx.Err() == nil {
+					c.logger.Warn("failed to list etcd cluster members", clog.Err(err))
+				}
+				continue
+			}
+			for _, event := range diffMembers(members, current) {
+				c.logger.Info("etcd cluster membership changed",
+					clog.String("type", string(event.Type)),
+					clog.Uint64("member_id", event.Member.ID),
+					clog.String("member_name", event.Member.Name),
+					clog.Strings("client_urls", event.Member.ClientURLs))
+				select {
+				case eventCh <- event:
+				case <-ctx.Done():
+					return
+				case <-c.done:
+					return
+				}
+			}
+			members = current
+		}
+	}()
+
+	return eventCh, nil
+}
+
+// memberList 获取当前的集群成员，按成员 ID 索引
+func (c *coordinator) memberList(ctx context.Context) (map[uint64]ClusterMember, error) {
+	resp, err := c.client.MemberList(ctx)
+	if err != nil {
+		return nil, err
+	}
+	members := make(map[uint64]ClusterMember, len(resp.Members))
+	for _, m := range resp.Members {
+		members[m.ID] = ClusterMember{
+			ID:         m.ID,
+			Name:       m.Name,
+			PeerURLs:   m.PeerURLs,
+			ClientURLs: m.ClientURLs,
+			IsLearner:  m.IsLearner,
+		}
+	}
+	return members, nil
+}
+
+// diffMembers 比较两次获取的成员列表，返回按成员 ID 排序的变化事件
+func diffMembers(previous, current map[uint64]ClusterMember) []ClusterEvent {
+	var events []ClusterEvent
+	for id, member := range current {
+		old, exists := previous[id]
+		switch {
+		case !exists:
+			events = append(events, ClusterEvent{Type: ClusterMemberAdded, Member: member})
+		case old.Name != member.Name || old.IsLearner != member.IsLearner ||
+			!slices.Equal(old.PeerURLs, member.PeerURLs) || !slices.Equal(old.ClientURLs, member.ClientURLs):
+			events = append(events, ClusterEvent{Type: ClusterMemberUpdated, Member: member})
+		}
+	}
+	for id, member := range previous {
+		if _, exists := current[id]; !exists {
+			events = append(events, ClusterEvent{Type: ClusterMemberRemoved, Member: member})
+		}
+	}
+	sort.Slice(events, func(i, j int) bool {
+		return events[i].Member.ID < events[j].Member.ID
+	})
+	return events
+}
@@ -2,35 +2,51 @@ package coord
 
 import "time"
 
+// DefaultOperationTimeout 未配置 OperationTimeout 时单次 etcd 请求的默认超时时间
+const DefaultOperationTimeout = 10 * time.Second
+
 // Config 是 coord 组件的配置结构体
 type Config struct {
 	// Endpoints 是 etcd 集群的地址列表
 	Endpoints []string `json:"endpoints"`
-	
+
 	// DialTimeout 是连接 etcd 的超时时间
 	DialTimeout time.Duration `json:"dialTimeout"`
-	
+
 	// KeepAliveTime 是 keepalive 心跳间隔
 	KeepAliveTime time.Duration `json:"keepAliveTime"`
-	
+
 	// KeepAliveTimeout 是 keepalive 超时时间
 	KeepAliveTimeout time.Duration `json:"keepAliveTimeout"`
-	
+
+	// OperationTimeout 是单次 etcd 请求的默认超时时间，仅在调用方的 context 没有截止时间时生效，
+	// 调用方设置的截止时间优先；为 0 时使用 DefaultOperationTimeout，为负数时不设默认超时
+	// 监听、租约续约和阻塞等待锁、选举等长连接操作不受影响
+	OperationTimeout time.Duration `json:"operationTimeout"`
+
 	// Username 是认证用户名，可选
 	Username string `json:"username,omitempty"`
-	
+
 	// Password 是认证密码，可选
 	Password string `json:"password,omitempty"`
-	
+
 	// TLS 相关配置，可选
 	TLS *TLSConfig `json:"tls,omitempty"`
 }
 
 // TLSConfig 定义了 TLS 连接配置
+// 仅设置 CAFile 时为单向 TLS（客户端校验服务端证书）；
+// 同时设置 CertFile 和 KeyFile 时为双向 TLS（mTLS），客户端向 etcd 出示自身证书
 type TLSConfig struct {
+	// CertFile 客户端证书路径，mTLS 时必填，需与 KeyFile 同时设置
 	CertFile string `json:"certFile,omitempty"`
-	KeyFile  string `json:"keyFile,omitempty"`
-	CAFile   string `json:"caFile,omitempty"`
+	// KeyFile 客户端私钥路径，mTLS 时必填，需与 CertFile 同时设置
+	KeyFile string `json:"keyFile,omitempty"`
+	// CAFile 用于校验 etcd 服务端证书的 CA 证书路径，为空时使用系统根证书
+	CAFile string `json:"caFile,omitempty"`
+	// ServerName 覆盖证书校验时使用的服务端名称
+	// 当 endpoint 使用 IP 或与证书 SAN 不一致时设置
+	ServerName string `json:"serverName,omitempty"`
 }
 
 // GetDefaultConfig 返回默认的 coord 配置
@@ -38,24 +54,27 @@ func GetDefaultConfig(env string) *Config {
 	switch env {
 	case "development":
 		return &Config{
-			Endpoints:       []string{"localhost:2379"},
-			DialTimeout:     5 * time.Second,
-			KeepAliveTime:   30 * time.Second,
+			Endpoints:        []string{"localhost:2379"},
+			DialTimeout:      5 * time.Second,
+			KeepAliveTime:    30 * time.Second,
 			KeepAliveTimeout: 10 * time.Second,
+			OperationTimeout: DefaultOperationTimeout,
 		}
 	case "production":
 		return &Config{
-			Endpoints:       []string{"etcd1:2379", "etcd2:2379", "etcd3:2379"},
-			DialTimeout:     10 * time.Second,
-			KeepAliveTime:   30 * time.Second,
+			Endpoints:        []string{"etcd1:2379", "etcd2:2379", "etcd3:2379"},
+			DialTimeout:      10 * time.Second,
+			KeepAliveTime:    30 * time.Second,
 			KeepAliveTimeout: 10 * time.Second,
+			OperationTimeout: DefaultOperationTimeout,
 		}
 	default:
 		return &Config{
-			Endpoints:       []string{"localhost:2379"},
-			DialTimeout:     5 * time.Second,
-			KeepAliveTime:   30 * time.Second,
+			Endpoints:        []string{"localhost:2379"},
+			DialTimeout:      5 * time.Second,
+			KeepAliveTime:    30 * time.Second,
 			KeepAliveTimeout: 10 * time.Second,
+			OperationTimeout: DefaultOperationTimeout,
 		}
 	}
 }
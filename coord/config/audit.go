@@ -0,0 +1,45 @@
+package config
+
+import (
+	"context"
+	"time"
+)
+
+// AuditOperation 审计记录中的写操作类型
+type AuditOperation string
+
+const (
+	AuditOpSet              AuditOperation = "SET"
+	AuditOpSetIfAbsent      AuditOperation = "SET_IF_ABSENT"
+	AuditOpDelete           AuditOperation = "DELETE"
+	AuditOpDeletePrefix     AuditOperation = "DELETE_PREFIX"
+	AuditOpCompareAndSet    AuditOperation = "COMPARE_AND_SET"
+	AuditOpCompareAndDelete AuditOperation = "COMPARE_AND_DELETE"
+	AuditOpRollback         AuditOperation = "ROLLBACK"
+	AuditOpMove             AuditOperation = "MOVE" // 目标键的写入与源键的删除各记一条
+)
+
+// AuditEntry 一次配置写入的审计记录
+type AuditEntry struct {
+	Timestamp time.Time      `json:"timestamp"`          // 写入时间
+	Key       string         `json:"key"`                // 配置键（相对于配置前缀）
+	Operation AuditOperation `json:"operation"`          // 操作类型
+	Actor     string         `json:"actor,omitempty"`    // 操作者，来自 WithActor，未设置时为空
+	OldValue  string         `json:"oldValue,omitempty"` // 写入前的编码值，键原本不存在时为空
+	NewValue  string         `json:"newValue,omitempty"` // 写入后的编码值，删除时为空
+	Version   int64          `json:"version"`            // 本次写入产生的版本号
+}
+
+// actorContextKey 操作者在 context 中的键类型
+type actorContextKey struct{}
+
+// WithActor 返回携带操作者身份的 context，配置中心写入审计记录时从中读取 Actor
+func WithActor(ctx context.Context, actor string) context.Context {
+	return context.WithValue(ctx, actorContextKey{}, actor)
+}
+
+// ActorFromContext 返回 context 中的操作者身份，未设置时返回空字符串
+func ActorFromContext(ctx context.Context) string {
+	actor, _ := ctx.Value(actorContextKey{}).(string)
+	return actor
+}
@@ -0,0 +1,41 @@
+package config
+
+import (
+	"encoding/json"
+
+	"github.com/pelletier/go-toml/v2"
+	"gopkg.in/yaml.v3"
+)
+
+// Codec 定义配置值的编解码方式。
+// string 和 []byte 类型的值始终按原样存储，不经过 Codec。
+type Codec interface {
+	// Marshal 将值编码为字节
+	Marshal(v interface{}) ([]byte, error)
+	// Unmarshal 将字节解码到 v 指向的值
+	Unmarshal(data []byte, v interface{}) error
+}
+
+var (
+	// JSONCodec JSON 编码，默认使用
+	JSONCodec Codec = jsonCodec{}
+	// YAMLCodec YAML 编码，适合人工编写的配置
+	YAMLCodec Codec = yamlCodec{}
+	// TOMLCodec TOML 编码，顶层值必须是结构体或 map
+	TOMLCodec Codec = tomlCodec{}
+)
+
+type jsonCodec struct{}
+
+func (jsonCodec) Marshal(v interface{}) ([]byte, error)      { return json.Marshal(v) }
+func (jsonCodec) Unmarshal(data []byte, v interface{}) error { return json.Unmarshal(data, v) }
+
+type yamlCodec struct{}
+
+func (yamlCodec) Marshal(v interface{}) ([]byte, error)      { return yaml.Marshal(v) }
+func (yamlCodec) Unmarshal(data []byte, v interface{}) error { return yaml.Unmarshal(data, v) }
+
+type tomlCodec struct{}
+
+func (tomlCodec) Marshal(v interface{}) ([]byte, error)      { return toml.Marshal(v) }
+func (tomlCodec) Unmarshal(data []byte, v interface{}) error { return toml.Unmarshal(data, v) }
@@ -1,6 +1,32 @@
 package config
 
-import "context"
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+var (
+	// ErrExists 目标配置键已存在
+	ErrExists = errors.New("config key already exists")
+	// ErrVersionMismatch CompareAndSet/CompareAndDelete 时配置的版本号与期望版本号不一致
+	ErrVersionMismatch = errors.New("config version mismatch")
+	// ErrValueMismatch CompareValueAndSet 时当前配置值与期望值不相等
+	ErrValueMismatch = errors.New("config value does not match expected value")
+	// ErrReadOnly 通过只读视图（ReadOnly、Provider.ConfigReadOnly）执行写操作
+	ErrReadOnly = errors.New("config center is read-only")
+	// ErrAuditDisabled 未通过 WithAuditPrefix 启用审计
+	ErrAuditDisabled = errors.New("config audit is not enabled")
+	// ErrCompacted 指定的修订号已被 etcd 压缩：WithStartRevision 无法从该位置续传，Rollback 无法读取目标版本
+	ErrCompacted = errors.New("config watch start revision has been compacted")
+	// ErrWatchInterrupted 底层 etcd 监听中断，监听器正在自动重连
+	ErrWatchInterrupted = errors.New("config watch interrupted")
+	// ErrWatchResumed 中断的监听已重新建立，属于通知而非失败
+	ErrWatchResumed = errors.New("config watch resumed")
+)
+
+// KeySeparator 配置键的层级分隔符，ListChildren 按它划分子节点。
+const KeySeparator = "/"
 
 // EventType 表示事件类型。
 type EventType string
@@ -12,15 +38,37 @@ const (
 
 // ConfigEvent 表示配置变更事件，泛型以支持类型化的值。
 type ConfigEvent[T any] struct {
-	Type  EventType // 事件类型
-	Key   string    // 配置键
-	Value T         // 配置值
+	Type    EventType // 事件类型
+	Key     string    // 配置键
+	Value   T         // 配置值
+	Version int64     // 配置版本（etcd ModRevision），可用于 CompareAndSet
+	// Revision 事件发生时的 etcd 修订号，持久化后可通过 WithStartRevision(Revision+1) 续传
+	// 同一事务内的多个事件共享一个修订号
+	Revision int64
+	// Err 值解码错误：PUT 事件的值无法解码为监听的目标类型时非 nil，此时 Value 为原始字符串，便于排查
+	// 目标类型为 interface{} 时普通字符串是合法值，只有形如 JSON 对象或数组却无法解码的值才报告错误
+	Err error
+}
+
+// VersionedValue 配置键的一个历史版本，由 History 返回。
+type VersionedValue struct {
+	Version int64 // 该版本的 etcd ModRevision，可传给 Rollback
+	// Value 按配置的编码解码后的值，规则与以 interface{} 监听时相同，无法解码时为原始字符串
+	Value interface{}
+	// Timestamp 写入时间。etcd 不记录修订号的时间，只有启用审计（WithAuditPrefix）时才能从审计记录得到，否则为零值
+	Timestamp time.Time
+	// Err 值形如 JSON 对象或数组却无法解码时非 nil，此时 Value 为原始字符串
+	Err error
 }
 
 // Watcher 是用于监听配置变更的泛型接口。
 type Watcher[T any] interface {
 	// Chan 返回一个接收配置变更事件的通道。
 	Chan() <-chan ConfigEvent[T]
+	// Errors 返回监听的健康状态通道：底层监听中断时发送包装 ErrWatchInterrupted 的错误并自动重连，
+	// 重连成功后发送包装 ErrWatchResumed 的通知；续传位置已被压缩、期间的事件可能丢失时发送包装 ErrCompacted 的错误，
+	// 此时应重新读取配置。通道有缓冲，未及时读取时丢弃新的错误而不阻塞事件投递；监听结束时关闭。
+	Errors() <-chan error
 	// Close 停止监听器。
 	Close()
 }
@@ -33,12 +81,21 @@ type ConfigCenter interface {
 	Set(ctx context.Context, key string, value interface{}) error
 	// Delete 删除配置键。
 	Delete(ctx context.Context, key string) error
+	// DeletePrefix 原子地删除前缀下的所有键，返回删除的键数量，前缀下没有键时返回 0 而不报错。
+	// 前缀按 KeySeparator 分级匹配，"app" 匹配 "app/port" 而不匹配 "application"，也不删除键 "app" 本身；
+	// 前缀为空或只含分隔符（即整个配置中心）时返回校验错误，防止误删全部配置。
+	DeletePrefix(ctx context.Context, prefix string) (int, error)
 	// Watch 监听单个键的变更，并尝试反序列化为给定类型。
-	Watch(ctx context.Context, key string, v interface{}) (Watcher[any], error)
+	// 可通过 WithDebounce 合并短时间内的连续变更。
+	Watch(ctx context.Context, key string, v interface{}, opts ...WatchOption) (Watcher[any], error)
 	// WatchPrefix 监听指定前缀下所有键的变更。
-	WatchPrefix(ctx context.Context, prefix string, v interface{}) (Watcher[any], error)
+	WatchPrefix(ctx context.Context, prefix string, v interface{}, opts ...WatchOption) (Watcher[any], error)
 	// List 列出指定前缀下的所有键。
 	List(ctx context.Context, prefix string) ([]string, error)
+	// ListChildren 列出前缀下一级的子节点名称（类似 ls 与 ls -R 的区别），按字典序排列且不重复。
+	// 键按 KeySeparator 分级，如 "app/db/host" 和 "app/port" 对前缀 "app" 返回 ["db", "port"]；
+	// 只返回名称，不区分子节点是配置键还是还有下级的目录，前缀为空时列出顶层节点。
+	ListChildren(ctx context.Context, prefix string) ([]string, error)
 
 	// ===== CAS (Compare-And-Swap) 操作支持 =====
 
@@ -47,7 +104,43 @@ type ConfigCenter interface {
 	GetWithVersion(ctx context.Context, key string, v interface{}) (version int64, err error)
 
 	// CompareAndSet 原子地比较并设置配置值
-	// 只有当远程配置的版本号与期望版本号匹配时，才会更新配置
+	// 只有当远程配置的版本号与期望版本号匹配时，才会更新配置，否则返回 ErrVersionMismatch（可用 errors.Is 判断）
 	// 这确保了配置更新的原子性，避免并发修改导致的数据丢失
 	CompareAndSet(ctx context.Context, key string, value interface{}, expectedVersion int64) error
+
+	// CompareValueAndSet 仅当当前配置值等于 expected 时才写入 value，适合不跟踪版本号的幂等更新
+	// 当前值按 expected 的类型解码后用 reflect.DeepEqual 比较（string 和 []byte 直接比较原始内容），
+	// 写入以读取时的版本号为条件，期间被他人修改则重新读取比较
+	// 值不相等或无法解码为 expected 的类型时返回 ErrValueMismatch（可用 errors.Is 判断），键不存在时返回未找到错误
+	CompareValueAndSet(ctx context.Context, key string, expected, value interface{}) error
+
+	// SetIfAbsent 仅当键不存在时才创建配置值（创建语义）
+	// 返回 created 表示本次调用是否实际完成了创建，键已存在时返回 false 且不报错
+	// 适用于集群范围内只初始化一次的场景，如默认配置播种、引导数据写入
+	SetIfAbsent(ctx context.Context, key string, value interface{}) (created bool, err error)
+
+	// CompareAndDelete 仅当配置的版本号与 expectedVersion 匹配时才删除
+	// 防止读取与删除之间键被他人修改或删除后重新创建时误删新值
+	// 版本不匹配时返回与 CompareAndSet 相同的版本冲突错误，键不存在时返回未找到错误
+	CompareAndDelete(ctx context.Context, key string, expectedVersion int64) error
+
+	// Move 在单个事务中将配置从 src 移动到 dst（写入 dst 并删除 src）
+	// 监听者会在同一版本号下观察到 src 的 DELETE 事件和 dst 的 PUT 事件
+	// dst 已存在且 overwrite 为 false 时返回 ErrExists（可用 errors.Is 判断）
+	Move(ctx context.Context, src, dst string, overwrite bool) error
+
+	// AuditHistory 返回指定键的审计记录，按时间从新到旧排列，limit <= 0 表示不限制条数
+	// 未通过 WithAuditPrefix 启用审计时返回 ErrAuditDisabled（可用 errors.Is 判断）
+	AuditHistory(ctx context.Context, key string, limit int) ([]AuditEntry, error)
+
+	// ===== 历史版本 =====
+
+	// History 返回指定键的历史版本，按版本从新到旧排列，第一个元素为当前值，limit <= 0 表示不限制条数
+	// 历史来自 etcd 保留的修订号，只包含键最近一次创建以来的版本；早于压缩点的版本已被 etcd 丢弃，
+	// 遇到时历史在此截断而不报错。键不存在时返回未找到错误
+	History(ctx context.Context, key string, limit int) ([]VersionedValue, error)
+
+	// Rollback 将键的值恢复为 toVersion（History 返回的 Version）时的值，恢复本身是一次新的写入，产生新的版本
+	// toVersion 已被压缩时返回 ErrCompacted（可用 errors.Is 判断），不是该键的版本时返回校验错误
+	Rollback(ctx context.Context, key string, toVersion int64) error
 }
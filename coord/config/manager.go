@@ -3,9 +3,13 @@ package config
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"os"
+	"os/signal"
 	"sync"
 	"sync/atomic"
+	"syscall"
 	"time"
 
 	"github.com/ceyewan/infra-kit/clog"
@@ -39,32 +43,55 @@ type Manager[T any] struct {
 	service   string
 	component string
 
-	// 当前配置（原子操作）
-	currentConfig atomic.Value // *T
+	// 当前配置及其版本（原子操作）
+	currentConfig atomic.Value // *configSnapshot[T]
 
 	// 默认配置
 	defaultConfig T
 
 	// 可选组件
-	validator Validator[T]
-	updater   ConfigUpdater[T]
-	logger    clog.Logger
+	transformers []func(T) T // 按注册顺序在验证前依次应用
+	validator    Validator[T]
+	updater      ConfigUpdater[T]
+	logger       clog.Logger
 
 	// 配置监听器
-	watcher Watcher[any]
+	watcher   Watcher[any]
+	watchOpts []WatchOption // 传递给 ConfigCenter.Watch 的监听选项
 
 	// 控制
-	mu       sync.RWMutex
+	mu       sync.RWMutex // 保护生命周期状态
+	updateMu sync.Mutex   // 串行化配置的验证和更新，与生命周期锁分离，避免 Start 加载配置时重入死锁
 	stopCh   chan struct{}
 	watching bool
 
+	// 信号触发的重新加载
+	signalCh   chan os.Signal
+	signalDone chan struct{}
+
 	// 生命周期控制
 	started bool
 }
 
+// configSnapshot 配置快照，保证配置与版本号作为整体原子替换
+type configSnapshot[T any] struct {
+	config  *T
+	version int64
+}
+
 // ManagerOption 配置管理器选项
 type ManagerOption[T any] func(*Manager[T])
 
+// WithTransformer 添加配置转换函数，在解码之后、验证之前应用
+// 处理顺序为：解码 → 转换 → 验证 → 更新器，转换后的结果即为验证和最终生效的配置；
+// 适用于规范化取值或展开环境变量占位符，例如 os.ExpandEnv 展开 "${SECRET}"
+// 多次调用时按注册顺序依次应用；默认配置不经过转换
+func WithTransformer[T any](transform func(T) T) ManagerOption[T] {
+	return func(m *Manager[T]) {
+		m.transformers = append(m.transformers, transform)
+	}
+}
+
 // WithValidator 设置配置验证器
 func WithValidator[T any](validator Validator[T]) ManagerOption[T] {
 	return func(m *Manager[T]) {
@@ -86,6 +113,14 @@ func WithLogger[T any](logger clog.Logger) ManagerOption[T] {
 	}
 }
 
+// WithWatchOptions 设置配置监听选项
+// 例如 WithWatchOptions[T](WithDebounce(time.Second)) 合并短时间内的连续更新，避免重复触发验证和更新器
+func WithWatchOptions[T any](opts ...WatchOption) ManagerOption[T] {
+	return func(m *Manager[T]) {
+		m.watchOpts = append(m.watchOpts, opts...)
+	}
+}
+
 // NewManager 创建配置管理器
 // 注意：创建后需要调用 Start() 方法来启动配置监听
 func NewManager[T any](
@@ -108,8 +143,8 @@ func NewManager[T any](
 		opt(m)
 	}
 
-	// 设置默认配置
-	m.currentConfig.Store(&defaultConfig)
+	// 设置默认配置，版本为 0 表示尚未从配置中心加载
+	m.currentConfig.Store(&configSnapshot[T]{config: &defaultConfig})
 
 	// 不再自动启动，需要显式调用 Start() 方法
 	return m
@@ -117,14 +152,29 @@ func NewManager[T any](
 
 // GetCurrentConfig 获取当前配置
 func (m *Manager[T]) GetCurrentConfig() *T {
-	if config := m.currentConfig.Load(); config != nil {
-		return config.(*T)
+	if snapshot := m.currentConfig.Load(); snapshot != nil {
+		return snapshot.(*configSnapshot[T]).config
 	}
 	// 返回默认配置的副本
 	defaultCopy := m.defaultConfig
 	return &defaultCopy
 }
 
+// CurrentVersion 返回当前生效配置对应的版本号（etcd ModRevision）
+// 返回 0 表示当前使用的是默认配置，尚未从配置中心成功加载
+func (m *Manager[T]) CurrentVersion() int64 {
+	if snapshot := m.currentConfig.Load(); snapshot != nil {
+		return snapshot.(*configSnapshot[T]).version
+	}
+	return 0
+}
+
+// SourceKey 返回配置在配置中心中的来源键
+// 可与 CurrentVersion 一起用于调试端点，展示当前加载的配置及其来源
+func (m *Manager[T]) SourceKey() string {
+	return m.buildConfigKey()
+}
+
 // Start 启动配置管理器和监听器
 // 这个方法是幂等的，可以安全地多次调用
 func (m *Manager[T]) Start() {
@@ -144,12 +194,44 @@ func (m *Manager[T]) Start() {
 	m.started = true
 }
 
+// StartAndWait 同步地从配置中心加载一次配置后再启动监听器
+// 加载的配置与监听更新一样经过转换器、验证器和更新器，返回 nil 时 GetCurrentConfig 已反映配置中心中的配置，
+// 无需在 Start 后手动调用 ReloadConfig；ctx 控制加载的超时
+//
+// 配置不存在、读取失败、验证或更新失败以及 ctx 超时都会返回错误，此时管理器不会启动，
+// 仍使用默认配置，调用方可以重试，或改用 Start 以默认配置降级运行；已经启动时直接返回 nil
+func (m *Manager[T]) StartAndWait(ctx context.Context) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.started {
+		return nil
+	}
+	if m.configCenter == nil {
+		return fmt.Errorf("config center is not configured")
+	}
+
+	// 内存配置中心的读取不检查 ctx，先行检查使各实现的行为一致
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("initial config load failed: %w", err)
+	}
+	if err := m.loadConfig(ctx); err != nil {
+		return fmt.Errorf("initial config load failed: %w", err)
+	}
+	m.startWatching()
+
+	m.started = true
+	return nil
+}
+
 // Stop 停止配置管理器和监听器
 // 这个方法是幂等的，可以安全地多次调用
 func (m *Manager[T]) Stop() {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
+	m.stopSignalReload()
+
 	if !m.started {
 		return
 	}
@@ -165,6 +247,68 @@ func (m *Manager[T]) ReloadConfig() {
 	}
 }
 
+// ReloadOnSignal 收到指定信号时从配置中心重新加载配置，未指定信号时默认监听 SIGHUP
+// 重新加载与监听更新走同一条路径：先经过验证器，再调用更新器，任一失败则保留当前配置。
+// 适用于 etcd 监听暂时不可用、需要运维手动强制刷新的场景。
+//
+// 与自动监听的关系：
+//   - 两者互不替代，可同时启用；验证和更新通过同一把锁串行执行，不会并发应用配置
+//   - 信号触发时总是读取配置中心的最新值，即使版本未变化也会再次调用更新器
+//   - 重复调用会替换之前注册的信号；Stop/Close 时取消信号监听
+func (m *Manager[T]) ReloadOnSignal(sig ...os.Signal) {
+	if len(sig) == 0 {
+		sig = []os.Signal{syscall.SIGHUP}
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.stopSignalReload()
+
+	ch := make(chan os.Signal, 1)
+	done := make(chan struct{})
+	signal.Notify(ch, sig...)
+	m.signalCh = ch
+	m.signalDone = done
+
+	go m.signalLoop(ch, done)
+
+	if m.logger != nil {
+		m.logger.Info("config reload on signal enabled",
+			clog.String("key", m.buildConfigKey()),
+			clog.Any("signals", sig))
+	}
+}
+
+// stopSignalReload 取消信号监听
+// 注意：此方法应该在 m.mu.Lock() 保护下调用
+func (m *Manager[T]) stopSignalReload() {
+	if m.signalCh == nil {
+		return
+	}
+	signal.Stop(m.signalCh)
+	close(m.signalDone)
+	m.signalCh = nil
+	m.signalDone = nil
+}
+
+// signalLoop 信号触发的重新加载循环
+func (m *Manager[T]) signalLoop(ch <-chan os.Signal, done <-chan struct{}) {
+	for {
+		select {
+		case s := <-ch:
+			if m.logger != nil {
+				m.logger.Info("reloading config on signal",
+					clog.String("key", m.buildConfigKey()),
+					clog.String("signal", s.String()))
+			}
+			m.ReloadConfig()
+		case <-done:
+			return
+		}
+	}
+}
+
 // Close 关闭配置管理器（保持向后兼容）
 // 推荐使用 Stop() 方法
 func (m *Manager[T]) Close() {
@@ -179,10 +323,14 @@ func (m *Manager[T]) loadConfigFromCenter() {
 
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
+	_ = m.loadConfig(ctx)
+}
 
+// loadConfig 在 ctx 内从配置中心读取并应用配置，失败时记录日志、保留当前配置并返回错误
+func (m *Manager[T]) loadConfig(ctx context.Context) error {
 	key := m.buildConfigKey()
 	var config T
-	err := m.configCenter.Get(ctx, key, &config)
+	version, err := m.configCenter.GetWithVersion(ctx, key, &config)
 	if err != nil {
 		// 记录错误但不阻断，继续使用当前配置
 		if m.logger != nil {
@@ -193,17 +341,17 @@ func (m *Manager[T]) loadConfigFromCenter() {
 				clog.String("service", m.service),
 				clog.String("component", m.component))
 		}
-		return
+		return err
 	}
 
 	// 使用原子的验证和更新方法
-	if err := m.safeUpdateAndApply(&config); err != nil {
+	if err := m.safeUpdateAndApply(&config, version); err != nil {
 		if m.logger != nil {
 			m.logger.Error("failed to apply config from center",
 				clog.Err(err),
 				clog.String("key", key))
 		}
-		return
+		return err
 	}
 
 	if m.logger != nil {
@@ -213,13 +361,23 @@ func (m *Manager[T]) loadConfigFromCenter() {
 			clog.String("service", m.service),
 			clog.String("component", m.component))
 	}
+	return nil
 }
 
-// safeUpdateAndApply 原子地验证、更新和应用配置
+// safeUpdateAndApply 原子地转换、验证、更新和应用配置
 // 这个方法确保验证和更新是原子操作，避免系统状态不一致
-func (m *Manager[T]) safeUpdateAndApply(newConfig *T) error {
-	m.mu.Lock()
-	defer m.mu.Unlock()
+//
+// 串行化使用独立的 updateMu 而不是生命周期锁 mu：Start 和 StartAndWait 持有 mu 加载初始配置，
+// 会经由 loadConfig 调用本方法，sync.RWMutex 不可重入，在这里获取 mu 会使存在配置时的启动永久阻塞
+func (m *Manager[T]) safeUpdateAndApply(newConfig *T, version int64) error {
+	m.updateMu.Lock()
+	defer m.updateMu.Unlock()
+	// 0. 转换配置
+	for _, transform := range m.transformers {
+		transformed := transform(*newConfig)
+		newConfig = &transformed
+	}
+
 	// 1. 验证配置
 	if m.validator != nil {
 		if err := m.validator.Validate(newConfig); err != nil {
@@ -231,7 +389,7 @@ func (m *Manager[T]) safeUpdateAndApply(newConfig *T) error {
 	}
 
 	// 2. 调用更新器（两阶段提交）
-	oldConfig := m.currentConfig.Load().(*T)
+	oldConfig := m.currentConfig.Load().(*configSnapshot[T]).config
 	if m.updater != nil {
 		if err := m.updater.OnConfigUpdate(oldConfig, newConfig); err != nil {
 			if m.logger != nil {
@@ -241,11 +399,13 @@ func (m *Manager[T]) safeUpdateAndApply(newConfig *T) error {
 		}
 	}
 
-	// 3. 原子地更新配置指针
-	m.currentConfig.Store(newConfig)
+	// 3. 原子地更新配置指针和版本
+	m.currentConfig.Store(&configSnapshot[T]{config: newConfig, version: version})
 
 	if m.logger != nil {
-		m.logger.Info("config updated and applied successfully", clog.String("key", m.buildConfigKey()))
+		m.logger.Info("config updated and applied successfully",
+			clog.String("key", m.buildConfigKey()),
+			clog.Int64("version", version))
 	}
 	return nil
 }
@@ -253,7 +413,7 @@ func (m *Manager[T]) safeUpdateAndApply(newConfig *T) error {
 // safeUpdateConfig 安全地更新配置（保持向后兼容）
 // 推荐使用 safeUpdateAndApply 方法
 func (m *Manager[T]) safeUpdateConfig(newConfig *T) error {
-	return m.safeUpdateAndApply(newConfig)
+	return m.safeUpdateAndApply(newConfig, m.CurrentVersion())
 }
 
 // buildConfigKey 构建配置键
@@ -270,7 +430,7 @@ func (m *Manager[T]) startWatching() {
 
 	ctx := context.Background()
 	var config T
-	watcher, err := m.configCenter.Watch(ctx, m.buildConfigKey(), &config)
+	watcher, err := m.configCenter.Watch(ctx, m.buildConfigKey(), &config, m.watchOpts...)
 	if err != nil {
 		if m.logger != nil {
 			m.logger.Warn("failed to start config watcher",
@@ -283,8 +443,8 @@ func (m *Manager[T]) startWatching() {
 	m.watcher = watcher
 	m.watching = true
 
-	// 启动监听协程
-	go m.watchLoop()
+	// 启动监听协程，watcher 和 stopCh 以参数传入：stopWatching 会在 m.mu 下替换这两个字段
+	go m.watchLoop(watcher, m.stopCh)
 
 	if m.logger != nil {
 		m.logger.Info("config watcher started",
@@ -319,8 +479,8 @@ func (m *Manager[T]) stopWatching() {
 	m.stopCh = make(chan struct{})
 }
 
-// watchLoop 配置监听循环
-func (m *Manager[T]) watchLoop() {
+// watchLoop 配置监听循环，直到 watcher 的事件通道关闭或 stopCh 关闭
+func (m *Manager[T]) watchLoop(watcher Watcher[any], stopCh <-chan struct{}) {
 	defer func() {
 		if r := recover(); r != nil {
 			if m.logger != nil {
@@ -331,9 +491,24 @@ func (m *Manager[T]) watchLoop() {
 		}
 	}()
 
+	errs := watcher.Errors()
 	for {
 		select {
-		case event, ok := <-m.watcher.Chan():
+		case err, ok := <-errs:
+			if !ok {
+				errs = nil
+				continue
+			}
+			// 重连时续传位置已被压缩，期间的变更可能丢失，重新加载一次
+			if errors.Is(err, ErrCompacted) {
+				if m.logger != nil {
+					m.logger.Warn("config watch missed changes, reloading",
+						clog.Err(err),
+						clog.String("key", m.buildConfigKey()))
+				}
+				m.loadConfigFromCenter()
+			}
+		case event, ok := <-watcher.Chan():
 			if !ok {
 				if m.logger != nil {
 					m.logger.Debug("config watcher channel closed",
@@ -342,11 +517,20 @@ func (m *Manager[T]) watchLoop() {
 				return
 			}
 
+			if event.Err != nil {
+				if m.logger != nil {
+					m.logger.Error("config value from watcher cannot be decoded",
+						clog.Err(event.Err),
+						clog.String("key", m.buildConfigKey()))
+				}
+				continue
+			}
+
 			if event.Type == EventTypePut {
 				// 解析配置
 				if config, err := m.parseConfig(event.Value); err == nil {
 					// 使用原子的验证和更新方法
-					if err := m.safeUpdateAndApply(config); err != nil {
+					if err := m.safeUpdateAndApply(config, event.Version); err != nil {
 						if m.logger != nil {
 							m.logger.Error("failed to apply config from watcher",
 								clog.Err(err),
@@ -368,7 +552,7 @@ func (m *Manager[T]) watchLoop() {
 					}
 				}
 			}
-		case <-m.stopCh:
+		case <-stopCh:
 			return
 		}
 	}
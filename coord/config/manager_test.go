@@ -0,0 +1,447 @@
+package config_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ceyewan/infra-kit/coord/config"
+	"github.com/ceyewan/infra-kit/coord/internal/client"
+	"github.com/ceyewan/infra-kit/coord/internal/configimpl"
+	"github.com/ceyewan/infra-kit/coord/internal/memstore"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testAppConfig 测试用配置类型
+type testAppConfig struct {
+	Port  int  `json:"port"`
+	Debug bool `json:"debug"`
+}
+
+// newTestCenter 创建基于进程内存储的配置中心，存储随测试结束关闭
+func newTestCenter(t *testing.T) config.ConfigCenter {
+	store := memstore.New()
+	t.Cleanup(store.Close)
+	return configimpl.NewMemoryConfigCenter(store, "", nil)
+}
+
+// faultyCenter 包装配置中心，记录最近一次的监听选项，并允许测试暂停事件投递或向监听注入错误，
+// 用于模拟监听断线期间错过的变更
+type faultyCenter struct {
+	config.ConfigCenter
+
+	mu               sync.Mutex
+	lastWatchOptions *config.WatchOptions
+	watchers         []*faultyWatcher
+}
+
+func newFaultyCenter(t *testing.T) *faultyCenter {
+	return &faultyCenter{ConfigCenter: newTestCenter(t)}
+}
+
+func (c *faultyCenter) Watch(ctx context.Context, key string, v interface{}, opts ...config.WatchOption) (config.Watcher[any], error) {
+	watcher, err := c.ConfigCenter.Watch(ctx, key, v, opts...)
+	if err != nil {
+		return nil, err
+	}
+	w := &faultyWatcher{Watcher: watcher, ch: make(chan config.ConfigEvent[any], 10), errs: make(chan error, 1)}
+	go w.forward()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.lastWatchOptions = config.ParseWatchOptions(opts...)
+	c.watchers = append(c.watchers, w)
+	return w, nil
+}
+
+// watchOptions 返回最近一次监听使用的选项
+func (c *faultyCenter) watchOptions() *config.WatchOptions {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.lastWatchOptions
+}
+
+// pause 暂停或恢复所有监听的事件投递，暂停期间的事件被丢弃
+func (c *faultyCenter) pause(paused bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, w := range c.watchers {
+		w.paused.Store(paused)
+	}
+}
+
+// injectError 向所有监听的错误通道投递 err
+func (c *faultyCenter) injectError(err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, w := range c.watchers {
+		w.errs <- err
+	}
+}
+
+// faultyWatcher 转发底层监听的事件，暂停时丢弃
+type faultyWatcher struct {
+	config.Watcher[any]
+	ch     chan config.ConfigEvent[any]
+	errs   chan error
+	paused atomic.Bool
+}
+
+func (w *faultyWatcher) forward() {
+	defer close(w.ch)
+	for event := range w.Watcher.Chan() {
+		if !w.paused.Load() {
+			w.ch <- event
+		}
+	}
+}
+
+func (w *faultyWatcher) Chan() <-chan config.ConfigEvent[any] { return w.ch }
+func (w *faultyWatcher) Errors() <-chan error                 { return w.errs }
+
+// TestManager_VersionAndSourceKey 测试配置版本和来源键
+func TestManager_VersionAndSourceKey(t *testing.T) {
+	center := newTestCenter(t)
+	ctx := context.Background()
+	key := "/config/dev/user-service/app"
+
+	require.NoError(t, center.Set(ctx, key, testAppConfig{Port: 8080}))
+
+	manager := config.NewManager(center, "dev", "user-service", "app", testAppConfig{Port: 80})
+	assert.Equal(t, key, manager.SourceKey())
+	assert.Equal(t, int64(0), manager.CurrentVersion())
+
+	manager.Start()
+	defer manager.Stop()
+
+	assert.Equal(t, 8080, manager.GetCurrentConfig().Port)
+	assert.Equal(t, int64(1), manager.CurrentVersion())
+
+	// 监听到的更新应同时替换配置和版本
+	require.NoError(t, center.Set(ctx, key, testAppConfig{Port: 9090}))
+	assert.Eventually(t, func() bool {
+		return manager.GetCurrentConfig().Port == 9090 && manager.CurrentVersion() == 2
+	}, time.Second, 10*time.Millisecond)
+}
+
+// TestManager_StartAndWait 测试同步加载后启动
+func TestManager_StartAndWait(t *testing.T) {
+	center := newTestCenter(t)
+	ctx := context.Background()
+	key := "/config/dev/user-service/app"
+	defaultConfig := testAppConfig{Port: 80}
+
+	// 配置不存在时返回错误且不启动，仍使用默认配置
+	manager := config.NewManager(center, "dev", "user-service", "app", defaultConfig)
+	assert.Error(t, manager.StartAndWait(ctx))
+	assert.Equal(t, 80, manager.GetCurrentConfig().Port)
+
+	// 验证失败同样返回错误
+	require.NoError(t, center.Set(ctx, key, testAppConfig{Port: -1}))
+	validated := config.NewManager(center, "dev", "user-service", "app", defaultConfig,
+		config.WithValidator[testAppConfig](&portValidator{}))
+	assert.Error(t, validated.StartAndWait(ctx))
+	assert.Equal(t, int64(0), validated.CurrentVersion())
+
+	// ctx 已超时
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	assert.ErrorIs(t, manager.StartAndWait(cancelled), context.Canceled)
+
+	// 成功后第一次读取即为配置中心中的配置，之后照常接收监听更新
+	require.NoError(t, center.Set(ctx, key, testAppConfig{Port: 8080}))
+	require.NoError(t, manager.StartAndWait(ctx))
+	defer manager.Stop()
+	assert.Equal(t, 8080, manager.GetCurrentConfig().Port)
+	assert.NotZero(t, manager.CurrentVersion())
+	assert.NoError(t, manager.StartAndWait(ctx), "already started")
+
+	require.NoError(t, center.Set(ctx, key, testAppConfig{Port: 9090}))
+	assert.Eventually(t, func() bool {
+		return manager.GetCurrentConfig().Port == 9090
+	}, time.Second, 10*time.Millisecond)
+}
+
+// TestManager_ReloadOnCompacted 测试监听报告变更丢失时重新加载配置
+func TestManager_ReloadOnCompacted(t *testing.T) {
+	center := newFaultyCenter(t)
+	ctx := context.Background()
+	key := "/config/dev/user-service/app"
+
+	require.NoError(t, center.Set(ctx, key, testAppConfig{Port: 8080}))
+	manager := config.NewManager(center, "dev", "user-service", "app", testAppConfig{Port: 80})
+	manager.Start()
+	defer manager.Stop()
+	require.Equal(t, 8080, manager.GetCurrentConfig().Port)
+
+	// 暂停事件投递后修改数据，模拟断线期间错过的变更
+	center.pause(true)
+	require.NoError(t, center.Set(ctx, key, testAppConfig{Port: 9090}))
+
+	// 中断通知不触发重新加载
+	center.injectError(fmt.Errorf("%w: connection lost", config.ErrWatchInterrupted))
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(t, 8080, manager.GetCurrentConfig().Port)
+
+	center.injectError(fmt.Errorf("resume: %w", config.ErrCompacted))
+	assert.Eventually(t, func() bool {
+		return manager.GetCurrentConfig().Port == 9090 && manager.CurrentVersion() == 2
+	}, time.Second, 10*time.Millisecond)
+}
+
+// TestManager_WatchOptions 测试监听选项透传给配置中心
+func TestManager_WatchOptions(t *testing.T) {
+	center := newFaultyCenter(t)
+
+	manager := config.NewManager(center, "dev", "user-service", "app", testAppConfig{Port: 80},
+		config.WithWatchOptions[testAppConfig](config.WithDebounce(200*time.Millisecond)))
+	manager.Start()
+	defer manager.Stop()
+
+	require.NotNil(t, center.watchOptions())
+	assert.Equal(t, 200*time.Millisecond, center.watchOptions().Debounce)
+}
+
+// TestManager_Transformer 测试转换函数在验证之前按注册顺序应用
+func TestManager_Transformer(t *testing.T) {
+	center := newTestCenter(t)
+	ctx := context.Background()
+	key := "/config/dev/user-service/app"
+	require.NoError(t, center.Set(ctx, key, testAppConfig{Port: -8080}))
+
+	abs := func(cfg testAppConfig) testAppConfig {
+		if cfg.Port < 0 {
+			cfg.Port = -cfg.Port
+		}
+		return cfg
+	}
+	enableDebug := func(cfg testAppConfig) testAppConfig {
+		cfg.Debug = cfg.Port == 8080
+		return cfg
+	}
+
+	manager := config.NewManager(center, "dev", "user-service", "app", testAppConfig{Port: 80},
+		config.WithTransformer[testAppConfig](abs),
+		config.WithTransformer[testAppConfig](enableDebug),
+		config.WithValidator[testAppConfig](&portValidator{}))
+	manager.Start()
+	defer manager.Stop()
+
+	// 未转换时负端口会被验证器拒绝
+	assert.Equal(t, testAppConfig{Port: 8080, Debug: true}, *manager.GetCurrentConfig())
+
+	require.NoError(t, center.Set(ctx, key, testAppConfig{Port: -9090}))
+	assert.Eventually(t, func() bool {
+		return *manager.GetCurrentConfig() == testAppConfig{Port: 9090}
+	}, time.Second, 10*time.Millisecond)
+}
+
+// portValidator 拒绝非正端口
+type portValidator struct{}
+
+func (portValidator) Validate(cfg *testAppConfig) error {
+	if cfg.Port <= 0 {
+		return errors.New("port must be positive")
+	}
+	return nil
+}
+
+// TestWatchTyped 测试类型化监听的解码与逐事件错误返回
+func TestWatchTyped(t *testing.T) {
+	center := newFaultyCenter(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	events, err := config.WatchTyped[testAppConfig](ctx, center, "app", config.WithDebounce(10*time.Millisecond))
+	require.NoError(t, err)
+	assert.Equal(t, 10*time.Millisecond, center.watchOptions().Debounce)
+
+	require.NoError(t, center.Set(ctx, "app", testAppConfig{Port: 8080, Debug: true}))
+	event := <-events
+	require.NoError(t, event.Err)
+	assert.Equal(t, config.EventTypePut, event.Type)
+	assert.Equal(t, "app", event.Key)
+	assert.Equal(t, testAppConfig{Port: 8080, Debug: true}, event.Value)
+	assert.Equal(t, int64(1), event.Version)
+
+	// 配置中心报告的解码错误按事件透传，值为零值，不中断监听
+	require.NoError(t, center.Set(ctx, "app", "not-a-struct"))
+	event = <-events
+	assert.Error(t, event.Err)
+	assert.Equal(t, testAppConfig{}, event.Value)
+
+	require.NoError(t, center.Set(ctx, "app", testAppConfig{Port: 9090}))
+	event = <-events
+	require.NoError(t, event.Err)
+	assert.Equal(t, 9090, event.Value.Port)
+
+	cancel()
+	for range events {
+	}
+}
+
+// conflictingCenter 每次读取后由"其他写入者"修改配置，模拟前 conflicts 次更新发生版本冲突
+type conflictingCenter struct {
+	config.ConfigCenter
+	conflicts int
+}
+
+func (c *conflictingCenter) GetWithVersion(ctx context.Context, key string, v interface{}) (int64, error) {
+	version, err := c.ConfigCenter.GetWithVersion(ctx, key, v)
+	if err == nil && c.conflicts > 0 {
+		c.conflicts--
+		var current testAppConfig
+		_, _ = c.ConfigCenter.GetWithVersion(ctx, key, &current)
+		current.Debug = !current.Debug
+		_ = c.ConfigCenter.Set(ctx, key, current)
+	}
+	return version, err
+}
+
+// TestUpdate 测试读取-修改-写回辅助函数的冲突重试
+func TestUpdate(t *testing.T) {
+	ctx := context.Background()
+	incrementPort := func(calls *int) func(testAppConfig) (testAppConfig, error) {
+		return func(old testAppConfig) (testAppConfig, error) {
+			*calls++
+			old.Port++
+			return old, nil
+		}
+	}
+
+	t.Run("retries on conflict", func(t *testing.T) {
+		cc := &conflictingCenter{ConfigCenter: newTestCenter(t), conflicts: 2}
+		require.NoError(t, cc.Set(ctx, "app", testAppConfig{Port: 8080}))
+
+		calls := 0
+		require.NoError(t, config.Update(ctx, cc, "app", incrementPort(&calls)))
+		assert.Equal(t, 3, calls)
+
+		var got testAppConfig
+		require.NoError(t, cc.Get(ctx, "app", &got))
+		assert.Equal(t, testAppConfig{Port: 8081, Debug: false}, got, "the concurrent writes are preserved")
+	})
+
+	t.Run("gives up after max retries", func(t *testing.T) {
+		cc := &conflictingCenter{ConfigCenter: newTestCenter(t), conflicts: 5}
+		require.NoError(t, cc.Set(ctx, "app", testAppConfig{Port: 8080}))
+
+		calls := 0
+		err := config.Update(ctx, cc, "app", incrementPort(&calls), config.WithMaxRetries(2))
+		assert.ErrorIs(t, err, config.ErrVersionMismatch)
+		assert.Equal(t, 3, calls)
+
+		var got testAppConfig
+		require.NoError(t, cc.Get(ctx, "app", &got))
+		assert.Equal(t, 8080, got.Port)
+	})
+
+	t.Run("fn error aborts", func(t *testing.T) {
+		cc := newTestCenter(t)
+		require.NoError(t, cc.Set(ctx, "app", testAppConfig{Port: 8080}))
+
+		errInvalid := errors.New("invalid port")
+		err := config.Update(ctx, cc, "app", func(old testAppConfig) (testAppConfig, error) {
+			return old, errInvalid
+		})
+		assert.ErrorIs(t, err, errInvalid)
+	})
+
+	t.Run("missing key", func(t *testing.T) {
+		calls := 0
+		err := config.Update(ctx, newTestCenter(t), "missing", incrementPort(&calls))
+		assert.ErrorIs(t, err, client.ErrNotFound)
+		assert.Zero(t, calls)
+	})
+
+	t.Run("read-only", func(t *testing.T) {
+		cc := newTestCenter(t)
+		require.NoError(t, cc.Set(ctx, "app", testAppConfig{Port: 8080}))
+		calls := 0
+		assert.ErrorIs(t, config.Update(ctx, config.ReadOnly(cc), "app", incrementPort(&calls)), config.ErrReadOnly)
+	})
+
+	t.Run("options", func(t *testing.T) {
+		assert.Equal(t, config.DefaultUpdateRetries, config.ParseUpdateOptions().MaxRetries)
+		assert.Equal(t, 0, config.ParseUpdateOptions(config.WithMaxRetries(-1)).MaxRetries)
+	})
+}
+
+// TestReadOnly 测试只读视图拒绝写操作，读取和监听正常工作
+func TestReadOnly(t *testing.T) {
+	center := newTestCenter(t)
+	ctx := context.Background()
+	require.NoError(t, center.Set(ctx, "app", testAppConfig{Port: 8080}))
+
+	ro := config.ReadOnly(center)
+	assert.Same(t, ro, config.ReadOnly(ro))
+
+	var cfg testAppConfig
+	version, err := ro.GetWithVersion(ctx, "app", &cfg)
+	require.NoError(t, err)
+	assert.Equal(t, 8080, cfg.Port)
+
+	watcher, err := ro.Watch(ctx, "app", &cfg)
+	require.NoError(t, err)
+	defer watcher.Close()
+
+	writes := map[string]error{
+		"Set":                ro.Set(ctx, "app", testAppConfig{Port: 1}),
+		"Delete":             ro.Delete(ctx, "app"),
+		"CompareAndSet":      ro.CompareAndSet(ctx, "app", testAppConfig{Port: 1}, version),
+		"CompareValueAndSet": ro.CompareValueAndSet(ctx, "app", cfg, testAppConfig{Port: 1}),
+		"CompareAndDelete":   ro.CompareAndDelete(ctx, "app", version),
+		"Move":               ro.Move(ctx, "app", "app-new", true),
+	}
+	_, writes["SetIfAbsent"] = ro.SetIfAbsent(ctx, "other", testAppConfig{Port: 1})
+	for op, err := range writes {
+		assert.ErrorIs(t, err, config.ErrReadOnly, op)
+	}
+
+	// 写操作未到达底层配置中心
+	require.NoError(t, center.Get(ctx, "app", &cfg))
+	assert.Equal(t, 8080, cfg.Port)
+
+	// 通过底层配置中心的写入仍能被只读视图监听到
+	require.NoError(t, center.Set(ctx, "app", testAppConfig{Port: 9090}))
+	select {
+	case event := <-watcher.Chan():
+		assert.Equal(t, "app", event.Key)
+	case <-time.After(time.Second):
+		t.Fatal("expected watch event")
+	}
+}
+
+// TestCodecs 测试内置编码的往返与默认选项
+func TestCodecs(t *testing.T) {
+	assert.Equal(t, config.JSONCodec, config.ParseOptions().Codec)
+	assert.Equal(t, config.YAMLCodec, config.ParseOptions(config.WithCodec(config.YAMLCodec)).Codec)
+	assert.Equal(t, config.JSONCodec, config.ParseOptions(config.WithCodec(nil)).Codec)
+
+	type appConfig struct {
+		Port  int               `json:"port" yaml:"port" toml:"port"`
+		Debug bool              `json:"debug" yaml:"debug" toml:"debug"`
+		Tags  map[string]string `json:"tags" yaml:"tags" toml:"tags"`
+	}
+	want := appConfig{Port: 8080, Debug: true, Tags: map[string]string{"env": "dev"}}
+
+	for name, codec := range map[string]config.Codec{"json": config.JSONCodec, "yaml": config.YAMLCodec, "toml": config.TOMLCodec} {
+		t.Run(name, func(t *testing.T) {
+			data, err := codec.Marshal(want)
+			require.NoError(t, err)
+
+			var got appConfig
+			require.NoError(t, codec.Unmarshal(data, &got))
+			assert.Equal(t, want, got)
+		})
+	}
+
+	data, err := config.YAMLCodec.Marshal(want)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "port: 8080")
+}
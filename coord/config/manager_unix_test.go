@@ -0,0 +1,46 @@
+//go:build unix
+
+package config_test
+
+import (
+	"context"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/ceyewan/infra-kit/coord/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestManager_ReloadOnSignal 测试收到信号时从配置中心重新加载
+func TestManager_ReloadOnSignal(t *testing.T) {
+	center := newFaultyCenter(t)
+	ctx := context.Background()
+	key := "/config/dev/user-service/app"
+	require.NoError(t, center.Set(ctx, key, testAppConfig{Port: 8080}))
+
+	manager := config.NewManager(center, "dev", "user-service", "app", testAppConfig{Port: 80},
+		config.WithValidator[testAppConfig](&portValidator{}))
+	manager.Start()
+	defer manager.Stop()
+	manager.ReloadOnSignal(syscall.SIGUSR1)
+
+	// 暂停事件投递，模拟监听不可用
+	center.pause(true)
+
+	require.NoError(t, center.Set(ctx, key, testAppConfig{Port: 9090}))
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(t, 8080, manager.GetCurrentConfig().Port)
+
+	require.NoError(t, syscall.Kill(syscall.Getpid(), syscall.SIGUSR1))
+	assert.Eventually(t, func() bool {
+		return manager.GetCurrentConfig().Port == 9090 && manager.CurrentVersion() == 2
+	}, time.Second, 10*time.Millisecond)
+
+	// 未通过验证的配置不会被应用
+	require.NoError(t, center.Set(ctx, key, testAppConfig{Port: -1}))
+	require.NoError(t, syscall.Kill(syscall.Getpid(), syscall.SIGUSR1))
+	time.Sleep(100 * time.Millisecond)
+	assert.Equal(t, 9090, manager.GetCurrentConfig().Port)
+}
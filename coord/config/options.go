@@ -0,0 +1,125 @@
+package config
+
+import "time"
+
+// Options 定义配置中心的选项
+type Options struct {
+	// Codec 配置值的编解码方式，默认 JSONCodec
+	Codec Codec
+	// AuditPrefix 审计记录的存储前缀，为空表示不记录审计
+	AuditPrefix string
+	// ReadCacheTTL Get 本地读缓存的有效期，0 表示不启用
+	ReadCacheTTL time.Duration
+}
+
+// Option 配置配置中心的函数式选项
+type Option func(*Options)
+
+// WithCodec 设置 Set/Get/Watch 等操作使用的编解码方式，如 YAMLCodec、TOMLCodec
+// 编码作用于整个配置中心，同一个键混用不同编码写入和读取是不受支持的：
+// 切换编码前需要迁移已有的值
+func WithCodec(codec Codec) Option {
+	return func(o *Options) {
+		if codec != nil {
+			o.Codec = codec
+		}
+	}
+}
+
+// WithAuditPrefix 为 Set/Delete/CompareAndSet/CompareAndDelete 写入审计记录，记录存放在 prefix 下，
+// 与配置前缀相互独立，不会出现在 List/WatchPrefix 的结果中
+// 审计记录在配置写入成功后追加，写入失败只记录日志，不影响配置写入的结果
+func WithAuditPrefix(prefix string) Option {
+	return func(o *Options) {
+		o.AuditPrefix = prefix
+	}
+}
+
+// WithReadCache 为 Get 启用本地读缓存，TTL 内重复读取同一个键直接命中内存，降低 etcd 的读压力
+// 缓存由监听配置前缀的后台 watch 主动失效，通过本配置中心的写入也会立即失效，
+// 因此通常能在变更后很快读到新值；监听中断或事件丢失时，读到旧值的时间最长为 ttl
+// GetWithVersion 始终读取 etcd，保证 CompareAndSet 使用最新的版本；内存实现忽略该选项
+func WithReadCache(ttl time.Duration) Option {
+	return func(o *Options) {
+		o.ReadCacheTTL = ttl
+	}
+}
+
+// ParseOptions 应用选项并返回最终的配置中心选项
+func ParseOptions(opts ...Option) *Options {
+	result := &Options{Codec: JSONCodec}
+	for _, opt := range opts {
+		opt(result)
+	}
+	return result
+}
+
+// WatchOptions 定义 Watch/WatchPrefix 的监听选项
+type WatchOptions struct {
+	// Debounce 防抖窗口，0 表示不启用
+	// 窗口内同一个键的多次变更只投递最后一次，窗口从一批变更的首个事件开始计时
+	Debounce time.Duration
+	// StartRevision 从该修订号（包含）开始投递事件，0 表示只投递监听建立之后的变更
+	StartRevision int64
+}
+
+// WatchOption 配置 Watch/WatchPrefix 的函数式选项
+type WatchOption func(*WatchOptions)
+
+// WithDebounce 合并短时间内的连续变更，避免每次变更都触发昂贵的重新加载
+// 窗口内同一个键只投递最后一次变更（最终状态一定会被投递），不同键的变更各自保留；
+// 监听关闭前会先投递窗口内尚未发出的变更
+func WithDebounce(d time.Duration) WatchOption {
+	return func(o *WatchOptions) {
+		o.Debounce = d
+	}
+}
+
+// WithStartRevision 从指定的修订号（包含）开始监听，用于进程重启后续传，不漏掉停机期间的变更
+// 通常传入最后处理的事件的 Revision+1；同一事务可能产生多个相同 Revision 的事件，
+// 只有整个修订号的事件都处理完才应持久化该修订号
+// etcd 压缩历史后，早于压缩点的修订号无法续传，Watch/WatchPrefix 返回 ErrCompacted（可用 errors.Is 判断），
+// 此时需要重新全量读取配置后再从最新位置监听；内存实现不回放历史事件，任何已发生的修订号都视为已压缩
+func WithStartRevision(rev int64) WatchOption {
+	return func(o *WatchOptions) {
+		o.StartRevision = rev
+	}
+}
+
+// ParseWatchOptions 应用选项并返回最终的监听配置
+func ParseWatchOptions(opts ...WatchOption) *WatchOptions {
+	result := &WatchOptions{}
+	for _, opt := range opts {
+		opt(result)
+	}
+	return result
+}
+
+// DefaultUpdateRetries Update 遇到版本冲突时默认的最大重试次数
+const DefaultUpdateRetries = 10
+
+// UpdateOptions 定义 Update 的选项
+type UpdateOptions struct {
+	// MaxRetries 版本冲突后的最大重试次数，不含第一次尝试，0 表示不重试
+	MaxRetries int
+}
+
+// UpdateOption 配置 Update 的函数式选项
+type UpdateOption func(*UpdateOptions)
+
+// WithMaxRetries 设置 Update 遇到版本冲突时的最大重试次数，默认为 DefaultUpdateRetries，负数按 0 处理
+// 同一个键写入越频繁越容易冲突，重试次数用尽后 Update 返回包装 ErrVersionMismatch 的错误
+func WithMaxRetries(n int) UpdateOption {
+	return func(o *UpdateOptions) {
+		o.MaxRetries = max(n, 0)
+	}
+}
+
+// ParseUpdateOptions 应用选项并返回最终的 Update 配置
+func ParseUpdateOptions(opts ...UpdateOption) *UpdateOptions {
+	result := &UpdateOptions{MaxRetries: DefaultUpdateRetries}
+	for _, opt := range opts {
+		opt(result)
+	}
+	return result
+}
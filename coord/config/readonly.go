@@ -0,0 +1,111 @@
+package config
+
+import (
+	"context"
+	"fmt"
+)
+
+// ReadOnly 返回 cc 的只读视图：读取和监听委托给 cc，所有写操作直接返回包装 ErrReadOnly 的错误，不访问存储
+// 用于只消费配置的服务，在 API 层面防止误写配置；这是纵深防御措施，不能代替 etcd 的访问控制
+func ReadOnly(cc ConfigCenter) ConfigCenter {
+	if ro, ok := cc.(*readOnlyCenter); ok {
+		return ro
+	}
+	return &readOnlyCenter{cc: cc}
+}
+
+// readOnlyCenter 拒绝写操作的 ConfigCenter
+// 逐个实现接口方法而不是嵌入 ConfigCenter，接口新增写操作时必须在这里显式处理
+type readOnlyCenter struct {
+	cc ConfigCenter
+}
+
+// readOnlyError 返回写操作被拒绝的错误
+func readOnlyError(op, key string) error {
+	return fmt.Errorf("config %s %q rejected: %w", op, key, ErrReadOnly)
+}
+
+// Get 获取配置值
+func (r *readOnlyCenter) Get(ctx context.Context, key string, v interface{}) error {
+	return r.cc.Get(ctx, key, v)
+}
+
+// Set 拒绝写入
+func (r *readOnlyCenter) Set(ctx context.Context, key string, value interface{}) error {
+	return readOnlyError("set", key)
+}
+
+// Delete 拒绝删除
+func (r *readOnlyCenter) Delete(ctx context.Context, key string) error {
+	return readOnlyError("delete", key)
+}
+
+// DeletePrefix 拒绝删除
+func (r *readOnlyCenter) DeletePrefix(ctx context.Context, prefix string) (int, error) {
+	return 0, readOnlyError("delete prefix", prefix)
+}
+
+// Watch 监听单个键的变更
+func (r *readOnlyCenter) Watch(ctx context.Context, key string, v interface{}, opts ...WatchOption) (Watcher[any], error) {
+	return r.cc.Watch(ctx, key, v, opts...)
+}
+
+// WatchPrefix 监听前缀下所有键的变更
+func (r *readOnlyCenter) WatchPrefix(ctx context.Context, prefix string, v interface{}, opts ...WatchOption) (Watcher[any], error) {
+	return r.cc.WatchPrefix(ctx, prefix, v, opts...)
+}
+
+// List 列出前缀下的所有键
+func (r *readOnlyCenter) List(ctx context.Context, prefix string) ([]string, error) {
+	return r.cc.List(ctx, prefix)
+}
+
+// ListChildren 列出前缀下一级的子节点名称
+func (r *readOnlyCenter) ListChildren(ctx context.Context, prefix string) ([]string, error) {
+	return r.cc.ListChildren(ctx, prefix)
+}
+
+// GetWithVersion 获取配置值和版本号
+func (r *readOnlyCenter) GetWithVersion(ctx context.Context, key string, v interface{}) (int64, error) {
+	return r.cc.GetWithVersion(ctx, key, v)
+}
+
+// CompareAndSet 拒绝写入
+func (r *readOnlyCenter) CompareAndSet(ctx context.Context, key string, value interface{}, expectedVersion int64) error {
+	return readOnlyError("compare and set", key)
+}
+
+// CompareValueAndSet 拒绝写入
+func (r *readOnlyCenter) CompareValueAndSet(ctx context.Context, key string, expected, value interface{}) error {
+	return readOnlyError("compare value and set", key)
+}
+
+// SetIfAbsent 拒绝写入
+func (r *readOnlyCenter) SetIfAbsent(ctx context.Context, key string, value interface{}) (bool, error) {
+	return false, readOnlyError("set if absent", key)
+}
+
+// CompareAndDelete 拒绝删除
+func (r *readOnlyCenter) CompareAndDelete(ctx context.Context, key string, expectedVersion int64) error {
+	return readOnlyError("compare and delete", key)
+}
+
+// Move 拒绝移动
+func (r *readOnlyCenter) Move(ctx context.Context, src, dst string, overwrite bool) error {
+	return readOnlyError("move", src)
+}
+
+// AuditHistory 返回审计记录
+func (r *readOnlyCenter) AuditHistory(ctx context.Context, key string, limit int) ([]AuditEntry, error) {
+	return r.cc.AuditHistory(ctx, key, limit)
+}
+
+// History 返回历史版本
+func (r *readOnlyCenter) History(ctx context.Context, key string, limit int) ([]VersionedValue, error) {
+	return r.cc.History(ctx, key, limit)
+}
+
+// Rollback 拒绝回滚
+func (r *readOnlyCenter) Rollback(ctx context.Context, key string, toVersion int64) error {
+	return readOnlyError("rollback", key)
+}
@@ -0,0 +1,95 @@
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+// TypedEvent 表示解码为具体类型的配置变更事件。
+// 解码失败时 Err（来自 ConfigEvent）非 nil，Value 为零值；DELETE 事件的 Value 同样为零值。
+type TypedEvent[T any] struct {
+	ConfigEvent[T]
+}
+
+// WatchTyped 监听单个键的变更，并将值解码为 T 后投递。
+// 解码使用与 Get/Set 相同的 JSON 编码，失败时通过事件的 Err 字段返回，
+// 调用方无需再对 event.Value 做类型断言。
+// ctx 取消或底层监听结束时关闭返回的通道。
+//
+// 示例：
+//
+//	events, err := config.WatchTyped[AppConfig](ctx, cc, "app")
+//	for event := range events {
+//	    if event.Err != nil {
+//	        log.Warn("配置解码失败", clog.Err(event.Err))
+//	        continue
+//	    }
+//	    apply(event.Value)
+//	}
+func WatchTyped[T any](ctx context.Context, cc ConfigCenter, key string, opts ...WatchOption) (<-chan TypedEvent[T], error) {
+	var target T
+	watcher, err := cc.Watch(ctx, key, &target, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make(chan TypedEvent[T], 10)
+	go func() {
+		defer close(out)
+		defer watcher.Close()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case event, ok := <-watcher.Chan():
+				if !ok {
+					return
+				}
+				select {
+				case out <- decodeTypedEvent[T](event):
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+
+	return out, nil
+}
+
+// decodeTypedEvent 将事件值转换为 T，已是目标类型时直接使用，否则通过 JSON 转换
+func decodeTypedEvent[T any](event ConfigEvent[any]) TypedEvent[T] {
+	typed := TypedEvent[T]{
+		ConfigEvent: ConfigEvent[T]{
+			Type:     event.Type,
+			Key:      event.Key,
+			Version:  event.Version,
+			Revision: event.Revision,
+		},
+	}
+	if event.Type != EventTypePut {
+		return typed
+	}
+	if event.Err != nil {
+		typed.Err = event.Err
+		return typed
+	}
+
+	if value, ok := event.Value.(T); ok {
+		typed.Value = value
+		return typed
+	}
+
+	data, err := json.Marshal(event.Value)
+	if err == nil {
+		err = json.Unmarshal(data, &typed.Value)
+	}
+	if err != nil {
+		var zero T
+		typed.Value = zero
+		typed.Err = fmt.Errorf("failed to decode config value for key %s as %T: %w", event.Key, zero, err)
+	}
+	return typed
+}
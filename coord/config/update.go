@@ -0,0 +1,50 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// Update 以乐观并发的方式读取、修改并写回配置：读取当前值和版本号，调用 fn 计算新值，
+// 再以读取时的版本号 CompareAndSet 写入；期间被他人修改导致版本冲突时重新读取并再次调用 fn，
+// 最多重试 WithMaxRetries 设置的次数（默认 DefaultUpdateRetries）
+//
+// fn 可能被调用多次，必须只依赖传入的旧值且没有副作用；fn 返回错误时放弃更新并原样返回该错误
+// 键不存在时返回 GetWithVersion 的未找到错误，需要先用 SetIfAbsent 初始化；
+// 重试次数用尽时返回包装 ErrVersionMismatch 的错误，ctx 取消时返回 ctx 的错误
+//
+// 示例：
+//
+//	err := config.Update(ctx, cc, "app/limits", func(old Limits) (Limits, error) {
+//		old.MaxConnections += 100
+//		return old, nil
+//	})
+func Update[T any](ctx context.Context, cc ConfigCenter, key string, fn func(old T) (T, error), opts ...UpdateOption) error {
+	options := ParseUpdateOptions(opts...)
+
+	var err error
+	for attempt := 0; attempt <= options.MaxRetries; attempt++ {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
+
+		// 每次尝试使用新的零值解码，避免 map、切片等字段残留上一次读取的内容
+		var old T
+		version, getErr := cc.GetWithVersion(ctx, key, &old)
+		if getErr != nil {
+			return getErr
+		}
+
+		value, fnErr := fn(old)
+		if fnErr != nil {
+			return fnErr
+		}
+
+		err = cc.CompareAndSet(ctx, key, value, version)
+		if !errors.Is(err, ErrVersionMismatch) {
+			return err
+		}
+	}
+	return fmt.Errorf("config update %q gave up after %d attempts: %w", key, options.MaxRetries+1, err)
+}
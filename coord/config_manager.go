@@ -0,0 +1,55 @@
+package coord
+
+import (
+	"github.com/ceyewan/infra-kit/coord/config"
+	"github.com/ceyewan/infra-kit/coord/internal/client"
+)
+
+// ConfigManagerFactory 按协调器统一的 env/service 作用域创建配置管理器，通过 Provider.ConfigManagers 获取
+// 作用域由 WithConfigScope 在创建协调器时设置，进程内所有管理器共用，避免各处分别传入 env/service 时不一致
+type ConfigManagerFactory struct {
+	center  config.ConfigCenter
+	env     string
+	service string
+}
+
+// newConfigManagerFactory 从协调器选项创建配置管理器工厂
+func newConfigManagerFactory(center config.ConfigCenter, options *Options) *ConfigManagerFactory {
+	return &ConfigManagerFactory{
+		center:  center,
+		env:     options.ConfigEnv,
+		service: options.ConfigService,
+	}
+}
+
+// validateConfigScope 校验 WithConfigScope 设置的作用域，env 和 service 需同时设置
+func validateConfigScope(options *Options) error {
+	if (options.ConfigEnv == "") != (options.ConfigService == "") {
+		return client.NewError(client.ErrCodeValidation, "config scope requires both env and service", nil)
+	}
+	return nil
+}
+
+// Env 返回配置管理器使用的环境
+func (f *ConfigManagerFactory) Env() string {
+	return f.env
+}
+
+// Service 返回配置管理器使用的服务名
+func (f *ConfigManagerFactory) Service() string {
+	return f.service
+}
+
+// NewConfigManager 在工厂的 env/service 作用域下为 component 创建配置管理器，
+// 等价于 config.NewManager(provider.Config(), env, service, component, defaultConfig, opts...)
+// 协调器未通过 WithConfigScope 设置作用域或 component 为空时返回 ErrValidation
+// 方法不能带类型参数，因此以函数形式提供；与 config.NewManager 相同，创建后需要调用 Start()
+func NewConfigManager[T any](f *ConfigManagerFactory, component string, defaultConfig T, opts ...config.ManagerOption[T]) (*config.Manager[T], error) {
+	if f.env == "" || f.service == "" {
+		return nil, client.NewError(client.ErrCodeValidation, "config scope is not set, use coord.WithConfigScope", nil)
+	}
+	if component == "" {
+		return nil, client.NewError(client.ErrCodeValidation, "config component cannot be empty", nil)
+	}
+	return config.NewManager(f.center, f.env, f.service, component, defaultConfig, opts...), nil
+}
@@ -3,14 +3,18 @@ package coord
 import (
 	"context"
 	"fmt"
+	"os"
 	"sync"
+	"time"
 
 	"github.com/ceyewan/infra-kit/clog"
 	"github.com/ceyewan/infra-kit/coord/allocator"
 	"github.com/ceyewan/infra-kit/coord/config"
+	"github.com/ceyewan/infra-kit/coord/election"
 	"github.com/ceyewan/infra-kit/coord/internal/allocatorimpl"
 	"github.com/ceyewan/infra-kit/coord/internal/client"
 	"github.com/ceyewan/infra-kit/coord/internal/configimpl"
+	"github.com/ceyewan/infra-kit/coord/internal/electionimpl"
 	"github.com/ceyewan/infra-kit/coord/internal/lockimpl"
 	"github.com/ceyewan/infra-kit/coord/internal/registryimpl"
 	"github.com/ceyewan/infra-kit/coord/lock"
@@ -25,9 +29,26 @@ type Provider interface {
 	Registry() registry.ServiceRegistry
 	// Config 获取配置中心服务
 	Config() config.ConfigCenter
+	// ConfigReadOnly 获取配置中心的只读视图，读取和监听正常工作，写操作返回 config.ErrReadOnly
+	// 供只消费配置的服务使用，防止误写配置
+	ConfigReadOnly() config.ConfigCenter
+	// ConfigManagers 获取配置管理器工厂，配合 NewConfigManager 按 WithConfigScope 设置的 env/service 创建配置管理器
+	ConfigManagers() *ConfigManagerFactory
 	// InstanceIDAllocator 获取一个服务实例ID分配器
 	// 此方法是可重入的：为同一个 serviceName 多次调用，将返回同一个共享的分配器实例
-	InstanceIDAllocator(serviceName string, maxID int) (allocator.InstanceIDAllocator, error)
+	// 可通过 allocator.WithLeaseTTL 调整持有 ID 的租约 TTL，默认 allocator.DefaultLeaseTTL
+	InstanceIDAllocator(serviceName string, maxID int, opts ...allocator.Option) (allocator.InstanceIDAllocator, error)
+	// InstanceIDAllocatorRange 获取一个在 minID..maxID（闭区间）范围内分配 ID 的分配器
+	// 适用于低位 ID 预留给静态基础设施的场景；minID 和 maxID 必须非负且 minID <= maxID
+	// 与 InstanceIDAllocator 相同，为同一组参数多次调用返回同一个共享的分配器实例
+	InstanceIDAllocatorRange(serviceName string, minID, maxID int, opts ...allocator.Option) (allocator.InstanceIDAllocator, error)
+	// Election 创建一个 leader 选举候选者，同名选举的候选者之间竞争 leader 身份
+	// 每次调用返回独立的候选者，使用完毕后需调用 Close 释放会话
+	Election(ctx context.Context, name string, opts ...election.Option) (election.Election, error)
+	// WatchCluster 监听 etcd 集群成员的加入、移除和变更，用于记录集群重配置或据此重新解析 endpoints
+	// 这是高级功能：etcd 不支持监听成员变化，实现上按 WithClusterWatchInterval 轮询成员列表，首次获取失败时返回错误；
+	// 调用时的成员列表作为基准不产生事件，通道在 ctx 取消或协调器关闭后关闭
+	WatchCluster(ctx context.Context) (<-chan ClusterEvent, error)
 	// Health 检查协调器及其所有服务的健康状态
 	Health(ctx context.Context) error
 	// Close 关闭协调器并释放资源
@@ -40,11 +61,31 @@ type coordinator struct {
 	lock         lock.DistributedLock
 	registry     registry.ServiceRegistry
 	config       config.ConfigCenter
+	managers     *ConfigManagerFactory
 	logger       clog.Logger
 	closed       bool
+	done         chan struct{} // Close 时关闭，通知 WatchCluster 的协程退出
 	mu           sync.RWMutex
 	allocators   map[string]allocator.InstanceIDAllocator // 缓存分配器实例
 	allocatorsMu sync.RWMutex
+
+	clusterWatchInterval time.Duration // WatchCluster 轮询成员列表的间隔
+}
+
+// 各组件在 etcd 中的键前缀
+const (
+	lockPrefix     = "/locks"
+	registryPrefix = "/services"
+	configPrefix   = "/config"
+)
+
+// reservedKeyPrefixes 返回锁键不能覆盖的配置中心（含审计记录）和服务注册前缀
+func reservedKeyPrefixes(options *Options) []string {
+	return []string{
+		configPrefix,
+		config.ParseOptions(options.ConfigOptions...).AuditPrefix,
+		registryimpl.KeyRoot(registryPrefix, registry.ParseOptions(options.RegistryOptions...).KeyLayout),
+	}
 }
 
 // New 创建一个新的 coord Provider 实例
@@ -70,14 +111,33 @@ func New(ctx context.Context, config *Config, opts ...Option) (Provider, error)
 		logger.Error("invalid configuration", clog.Err(err))
 		return nil, fmt.Errorf("invalid configuration: %w", err)
 	}
+	if err := registryimpl.ValidateOptions(registry.ParseOptions(options.RegistryOptions...)); err != nil {
+		logger.Error("invalid registry options", clog.Err(err))
+		return nil, fmt.Errorf("invalid configuration: %w", err)
+	}
+	if err := validateConfigScope(options); err != nil {
+		logger.Error("invalid config scope", clog.Err(err))
+		return nil, fmt.Errorf("invalid configuration: %w", err)
+	}
 
 	// 2. 创建内部 etcd 客户端
 	clientCfg := client.Config{
-		Endpoints: config.Endpoints,
-		Username:  config.Username,
-		Password:  config.Password,
-		Timeout:   config.DialTimeout,
-		Logger:    logger.With(clog.String("component", "etcd-client")),
+		Endpoints:          config.Endpoints,
+		Username:           config.Username,
+		Password:           config.Password,
+		Timeout:            config.DialTimeout,
+		OperationTimeout:   operationTimeout(config.OperationTimeout),
+		Logger:             logger.With(clog.String("component", "etcd-client")),
+		CredentialProvider: options.CredentialProvider,
+		Dialer:             options.Dialer,
+	}
+	if config.TLS != nil {
+		clientCfg.TLS = &client.TLSConfig{
+			CertFile:   config.TLS.CertFile,
+			KeyFile:    config.TLS.KeyFile,
+			CAFile:     config.TLS.CAFile,
+			ServerName: config.TLS.ServerName,
+		}
 	}
 	etcdClient, err := client.New(clientCfg)
 	if err != nil {
@@ -86,9 +146,10 @@ func New(ctx context.Context, config *Config, opts ...Option) (Provider, error)
 	}
 
 	// 3. 创建内部服务
-	lockService := lockimpl.NewEtcdLockFactory(etcdClient, "/locks", logger.With(clog.String("component", "lock")))
-	registryService := registryimpl.NewEtcdServiceRegistry(etcdClient, "/services", logger.With(clog.String("component", "registry")))
-	configService := configimpl.NewEtcdConfigCenter(etcdClient, "/config", logger.With(clog.String("component", "config")))
+	lockService := lockimpl.NewEtcdLockFactory(etcdClient, lockPrefix, logger.With(clog.String("component", "lock")))
+	lockService.ReserveKeyPrefixes(options.StrictLockKeys, reservedKeyPrefixes(options)...)
+	registryService := registryimpl.NewEtcdServiceRegistry(etcdClient, registryPrefix, logger.With(clog.String("component", "registry")), options.RegistryOptions...)
+	configService := configimpl.NewEtcdConfigCenter(etcdClient, configPrefix, logger.With(clog.String("component", "config")), options.ConfigOptions...)
 
 	// 4. 组装 coordinator
 	coord := &coordinator{
@@ -96,10 +157,16 @@ func New(ctx context.Context, config *Config, opts ...Option) (Provider, error)
 		lock:       lockService,
 		registry:   registryService,
 		config:     configService,
+		managers:   newConfigManagerFactory(configService, options),
 		logger:     logger,
 		closed:     false,
+		done:       make(chan struct{}),
 		allocators: make(map[string]allocator.InstanceIDAllocator),
 	}
+	coord.clusterWatchInterval = options.ClusterWatchInterval
+	if coord.clusterWatchInterval <= 0 {
+		coord.clusterWatchInterval = DefaultClusterWatchInterval
+	}
 
 	logger.Info("coordinator created successfully")
 	return coord, nil
@@ -126,13 +193,32 @@ func (c *coordinator) Config() config.ConfigCenter {
 	return c.config
 }
 
+// ConfigReadOnly 实现 Provider 接口 - 获取配置中心的只读视图
+func (c *coordinator) ConfigReadOnly() config.ConfigCenter {
+	return config.ReadOnly(c.Config())
+}
+
+// ConfigManagers 实现 Provider 接口 - 获取按 WithConfigScope 作用域创建配置管理器的工厂
+func (c *coordinator) ConfigManagers() *ConfigManagerFactory {
+	return c.managers
+}
+
 // InstanceIDAllocator 实现 Provider 接口 - 获取服务实例ID分配器
 // 此方法是可重入的：为同一个 serviceName 多次调用，将返回同一个共享的分配器实例
-func (c *coordinator) InstanceIDAllocator(serviceName string, maxID int) (allocator.InstanceIDAllocator, error) {
+func (c *coordinator) InstanceIDAllocator(serviceName string, maxID int, opts ...allocator.Option) (allocator.InstanceIDAllocator, error) {
+	if maxID <= 0 {
+		return nil, fmt.Errorf("failed to create instance ID allocator: max ID must be positive")
+	}
+	return c.InstanceIDAllocatorRange(serviceName, 1, maxID, opts...)
+}
+
+// InstanceIDAllocatorRange 实现 Provider 接口 - 获取在指定范围内分配 ID 的分配器
+func (c *coordinator) InstanceIDAllocatorRange(serviceName string, minID, maxID int, opts ...allocator.Option) (allocator.InstanceIDAllocator, error) {
 	c.allocatorsMu.RLock()
 
-	// 生成缓存键
-	cacheKey := fmt.Sprintf("%s:%d", serviceName, maxID)
+	// 生成缓存键，租约 TTL、到期余量或分区不同的分配器各自独立缓存
+	options := allocator.ParseOptions(opts...)
+	cacheKey := fmt.Sprintf("%s:%d-%d:%v:%v:%s", serviceName, minID, maxID, options.LeaseTTL, options.Margin(), options.Partition)
 
 	// 检查是否已存在
 	if allocator, exists := c.allocators[cacheKey]; exists {
@@ -154,11 +240,13 @@ func (c *coordinator) InstanceIDAllocator(serviceName string, maxID int) (alloca
 	etcdClient := c.client.Client()
 
 	// 创建分配器
-	allocator, err := allocatorimpl.NewEtcdInstanceIDAllocator(
+	allocator, err := allocatorimpl.NewEtcdInstanceIDAllocatorRange(
 		etcdClient,
 		serviceName,
+		minID,
 		maxID,
 		c.logger.With(clog.String("service", serviceName)),
+		opts...,
 	)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create instance ID allocator: %w", err)
@@ -169,11 +257,28 @@ func (c *coordinator) InstanceIDAllocator(serviceName string, maxID int) (alloca
 
 	c.logger.Info("instance ID allocator created",
 		clog.String("service", serviceName),
+		clog.Int("min_id", minID),
 		clog.Int("max_id", maxID))
 
 	return allocator, nil
 }
 
+// Election 实现 Provider 接口 - 创建 leader 选举候选者
+func (c *coordinator) Election(ctx context.Context, name string, opts ...election.Option) (election.Election, error) {
+	options := election.ParseOptions(opts...)
+	e, err := electionimpl.NewEtcdElection(c.client, "/elections", name, options,
+		c.logger.With(clog.String("component", "election")))
+	if err != nil {
+		return nil, err
+	}
+
+	c.logger.Info("election candidate created",
+		clog.String("election", name),
+		clog.String("candidate", options.CandidateID))
+
+	return e, nil
+}
+
 // Close 实现 Provider 接口 - 关闭协调器并释放资源
 func (c *coordinator) Close() error {
 	c.mu.Lock()
@@ -185,6 +290,13 @@ func (c *coordinator) Close() error {
 
 	c.logger.Info("closing coordinator")
 
+	// 通知 WatchCluster 的协程退出；关闭 etcd 客户端失败时 Close 可以重试，避免重复关闭
+	select {
+	case <-c.done:
+	default:
+		close(c.done)
+	}
+
 	// 关闭所有分配器
 	c.allocatorsMu.Lock()
 	for key, allocator := range c.allocators {
@@ -197,6 +309,11 @@ func (c *coordinator) Close() error {
 	}
 	c.allocatorsMu.Unlock()
 
+	// 停止配置中心的读缓存监听
+	if closer, ok := c.config.(interface{ Close() }); ok {
+		closer.Close()
+	}
+
 	// 关闭 etcd 客户端
 	if c.client != nil {
 		if err := c.client.Close(); err != nil {
@@ -282,5 +399,46 @@ func validateConfig(config *Config) error {
 		return fmt.Errorf("dial timeout must be positive")
 	}
 
+	if config.TLS != nil {
+		if err := validateTLSConfig(config.TLS); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// operationTimeout 将配置的请求超时转换为客户端使用的值：0 使用默认值，负数表示不设默认超时
+func operationTimeout(timeout time.Duration) time.Duration {
+	switch {
+	case timeout == 0:
+		return DefaultOperationTimeout
+	case timeout < 0:
+		return 0
+	default:
+		return timeout
+	}
+}
+
+// validateTLSConfig 验证 TLS 配置，确保证书文件存在且成对出现
+func validateTLSConfig(tlsConfig *TLSConfig) error {
+	if (tlsConfig.CertFile == "") != (tlsConfig.KeyFile == "") {
+		return fmt.Errorf("tls certFile and keyFile must be specified together")
+	}
+
+	files := map[string]string{
+		"certFile": tlsConfig.CertFile,
+		"keyFile":  tlsConfig.KeyFile,
+		"caFile":   tlsConfig.CAFile,
+	}
+	for name, file := range files {
+		if file == "" {
+			continue
+		}
+		if _, err := os.Stat(file); err != nil {
+			return fmt.Errorf("tls %s %q is not accessible: %w", name, file, err)
+		}
+	}
+
 	return nil
 }
@@ -2,11 +2,15 @@ package coord
 
 import (
 	"context"
+	"errors"
 	"sync"
 	"testing"
 	"time"
 
 	"github.com/ceyewan/infra-kit/clog"
+	"github.com/ceyewan/infra-kit/coord/allocator"
+	"github.com/ceyewan/infra-kit/coord/config"
+	"github.com/ceyewan/infra-kit/coord/lock"
 	"github.com/ceyewan/infra-kit/coord/registry"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -195,6 +199,14 @@ func TestCoordinatorServices(t *testing.T) {
 	})
 }
 
+// TestOperationTimeout 测试请求超时配置的默认值和关闭方式
+func TestOperationTimeout(t *testing.T) {
+	assert.Equal(t, DefaultOperationTimeout, operationTimeout(0))
+	assert.Equal(t, 3*time.Second, operationTimeout(3*time.Second))
+	assert.Equal(t, time.Duration(0), operationTimeout(-1))
+	assert.Equal(t, DefaultOperationTimeout, GetDefaultConfig("production").OperationTimeout)
+}
+
 // TestValidateConfig 测试配置验证功能
 func TestValidateConfig(t *testing.T) {
 	tests := []struct {
@@ -253,6 +265,26 @@ func TestValidateConfig(t *testing.T) {
 			expectError: true,
 			errorMsg:    "dial timeout must be positive",
 		},
+		{
+			name: "tls cert without key",
+			config: &Config{
+				Endpoints:   []string{"localhost:2379"},
+				DialTimeout: 5 * time.Second,
+				TLS:         &TLSConfig{CertFile: "client.pem"},
+			},
+			expectError: true,
+			errorMsg:    "certFile and keyFile must be specified together",
+		},
+		{
+			name: "tls missing ca file",
+			config: &Config{
+				Endpoints:   []string{"localhost:2379"},
+				DialTimeout: 5 * time.Second,
+				TLS:         &TLSConfig{CAFile: "/nonexistent/ca.pem"},
+			},
+			expectError: true,
+			errorMsg:    "tls caFile",
+		},
 	}
 
 	for _, tt := range tests {
@@ -307,9 +339,13 @@ func TestCoordinatorInstanceIDAllocator(t *testing.T) {
 		allocator3, err := provider.InstanceIDAllocator("service-B", 10) // different serviceName
 		require.NoError(t, err)
 
+		allocator4, err := provider.InstanceIDAllocator("service-A", 10, allocator.WithPartition("tenant-1")) // different partition
+		require.NoError(t, err)
+
 		assert.NotSame(t, allocator1, allocator2)
 		assert.NotSame(t, allocator1, allocator3)
 		assert.NotSame(t, allocator2, allocator3)
+		assert.NotSame(t, allocator1, allocator4)
 	})
 
 	t.Run("invalid parameters", func(t *testing.T) {
@@ -327,6 +363,28 @@ func TestCoordinatorInstanceIDAllocator(t *testing.T) {
 		assert.Error(t, err)
 		assert.Nil(t, allocator)
 	})
+
+	t.Run("range allocator", func(t *testing.T) {
+		allocator, err := provider.InstanceIDAllocatorRange("range-service", 100, 199)
+		require.NoError(t, err)
+
+		id, err := allocator.AcquireID(ctx)
+		require.NoError(t, err)
+		defer id.Close(ctx)
+		assert.GreaterOrEqual(t, id.ID(), 100)
+		assert.LessOrEqual(t, id.ID(), 199)
+
+		// 同一组参数返回同一实例
+		cached, err := provider.InstanceIDAllocatorRange("range-service", 100, 199)
+		require.NoError(t, err)
+		assert.Same(t, allocator, cached)
+
+		// 无效范围
+		_, err = provider.InstanceIDAllocatorRange("range-service", 10, 5)
+		assert.Error(t, err)
+		_, err = provider.InstanceIDAllocatorRange("range-service", -1, 5)
+		assert.Error(t, err)
+	})
 }
 
 // TestCoordinatorClose 测试coordinator的关闭功能
@@ -339,8 +397,19 @@ func TestCoordinatorClose(t *testing.T) {
 	require.NoError(t, err)
 
 	t.Run("close once", func(t *testing.T) {
-		err := provider.Close()
+		// ctx 仍然有效，集群监听通道应随 Close 关闭，而不是等到下一次轮询
+		events, err := provider.WatchCluster(ctx)
+		require.NoError(t, err)
+
+		err = provider.Close()
 		assert.NoError(t, err)
+
+		select {
+		case _, ok := <-events:
+			assert.False(t, ok)
+		case <-time.After(time.Second):
+			t.Fatal("cluster watch channel not closed after Close")
+		}
 	})
 
 	t.Run("close multiple times", func(t *testing.T) {
@@ -452,6 +521,18 @@ func TestCoordinatorWithRealEtcd(t *testing.T) {
 		assert.NoError(t, err)
 	})
 
+	t.Run("real cluster watch", func(t *testing.T) {
+		watchCtx, cancel := context.WithCancel(ctx)
+		events, err := provider.WatchCluster(watchCtx)
+		require.NoError(t, err)
+
+		// 成员未变化时不产生事件，取消后通道关闭
+		cancel()
+		for event := range events {
+			t.Errorf("Unexpected cluster event: %+v", event)
+		}
+	})
+
 	t.Run("real service registry", func(t *testing.T) {
 		registryService := provider.Registry()
 
@@ -576,3 +657,455 @@ func BenchmarkCoordinator(b *testing.B) {
 		}
 	})
 }
+
+// TestDiffMembers 测试集群成员列表的变化检测
+func TestDiffMembers(t *testing.T) {
+	previous := map[uint64]ClusterMember{
+		1: {ID: 1, Name: "etcd-1", ClientURLs: []string{"http://10.0.0.1:2379"}},
+		2: {ID: 2, Name: "etcd-2", ClientURLs: []string{"http://10.0.0.2:2379"}},
+		3: {ID: 3, Name: "etcd-3", ClientURLs: []string{"http://10.0.0.3:2379"}},
+	}
+	current := map[uint64]ClusterMember{
+		1: {ID: 1, Name: "etcd-1", ClientURLs: []string{"http://10.0.0.1:2379"}},
+		3: {ID: 3, Name: "etcd-3", ClientURLs: []string{"http://10.0.0.30:2379"}},
+		4: {ID: 4, PeerURLs: []string{"http://10.0.0.4:2380"}, IsLearner: true},
+	}
+
+	assert.Empty(t, diffMembers(previous, previous))
+
+	events := diffMembers(previous, current)
+	require.Len(t, events, 3)
+	assert.Equal(t, ClusterMemberRemoved, events[0].Type)
+	assert.Equal(t, "etcd-2", events[0].Member.Name)
+	assert.Equal(t, ClusterMemberUpdated, events[1].Type)
+	assert.Equal(t, []string{"http://10.0.0.30:2379"}, events[1].Member.ClientURLs)
+	assert.Equal(t, ClusterMemberAdded, events[2].Type)
+	assert.True(t, events[2].Member.IsLearner)
+
+	promoted := map[uint64]ClusterMember{4: {ID: 4, PeerURLs: []string{"http://10.0.0.4:2380"}}}
+	events = diffMembers(map[uint64]ClusterMember{4: current[4]}, promoted)
+	require.Len(t, events, 1)
+	assert.Equal(t, ClusterMemberUpdated, events[0].Type)
+}
+
+// TestNewInMemory 测试不依赖 etcd 的内存 Provider
+func TestNewInMemory(t *testing.T) {
+	ctx := context.Background()
+	provider, err := NewInMemory(ctx)
+	require.NoError(t, err)
+	defer provider.Close()
+
+	require.NoError(t, provider.Health(ctx))
+
+	t.Run("config CAS", func(t *testing.T) {
+		cfg := provider.Config()
+		require.NoError(t, cfg.CompareAndSet(ctx, "app/port", 8080, 0))
+
+		var port int
+		version, err := cfg.GetWithVersion(ctx, "app/port", &port)
+		require.NoError(t, err)
+		assert.Equal(t, 8080, port)
+
+		assert.Error(t, cfg.CompareAndSet(ctx, "app/port", 9090, version+1))
+		assert.NoError(t, cfg.CompareAndSet(ctx, "app/port", 9090, version))
+	})
+
+	t.Run("config list children", func(t *testing.T) {
+		cfg := provider.Config()
+		require.NoError(t, cfg.Set(ctx, "app/db/host", "localhost"))
+		require.NoError(t, cfg.Set(ctx, "app/db/port", 5432))
+		require.NoError(t, cfg.Set(ctx, "app/name", "demo"))
+
+		children, err := cfg.ListChildren(ctx, "app")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"db", "name", "port"}, children)
+
+		children, err = cfg.ListChildren(ctx, "app/db")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"host", "port"}, children)
+
+		children, err = cfg.ListChildren(ctx, "")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"app"}, children)
+	})
+
+	t.Run("config delete prefix", func(t *testing.T) {
+		cfg := provider.Config()
+		deleted, err := cfg.DeletePrefix(ctx, "app/db")
+		require.NoError(t, err)
+		assert.Equal(t, 2, deleted)
+
+		children, err := cfg.ListChildren(ctx, "app")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"name", "port"}, children)
+
+		_, err = cfg.DeletePrefix(ctx, "/")
+		assert.ErrorIs(t, err, ErrValidation)
+	})
+
+	t.Run("config read-only", func(t *testing.T) {
+		ro := provider.ConfigReadOnly()
+		var port int
+		require.NoError(t, ro.Get(ctx, "app/port", &port))
+		assert.Equal(t, 9090, port)
+
+		assert.ErrorIs(t, ro.Set(ctx, "app/port", 1), config.ErrReadOnly)
+		assert.ErrorIs(t, ro.Delete(ctx, "app/port"), config.ErrReadOnly)
+		_, err := ro.DeletePrefix(ctx, "app")
+		assert.ErrorIs(t, err, config.ErrReadOnly)
+		require.NoError(t, provider.Config().Get(ctx, "app/port", &port))
+		assert.Equal(t, 9090, port)
+	})
+
+	t.Run("config history", func(t *testing.T) {
+		cfg := provider.Config()
+		history, err := cfg.History(ctx, "app/port", 0)
+		require.NoError(t, err)
+		require.Len(t, history, 2)
+		assert.Equal(t, float64(9090), history[0].Value)
+		assert.Equal(t, float64(8080), history[1].Value)
+
+		limited, err := cfg.History(ctx, "app/port", 1)
+		require.NoError(t, err)
+		require.Len(t, limited, 1)
+		assert.Equal(t, history[0].Version, limited[0].Version)
+
+		// 回滚到上一个版本是一次新的写入
+		require.NoError(t, cfg.Rollback(ctx, "app/port", history[1].Version))
+		var port int
+		require.NoError(t, cfg.Get(ctx, "app/port", &port))
+		assert.Equal(t, 8080, port)
+		history, err = cfg.History(ctx, "app/port", 0)
+		require.NoError(t, err)
+		require.Len(t, history, 3)
+
+		// 其他键的版本不能用于回滚
+		var name string
+		nameVersion, err := cfg.GetWithVersion(ctx, "app/name", &name)
+		require.NoError(t, err)
+		err = cfg.Rollback(ctx, "app/port", nameVersion)
+		assert.ErrorIs(t, err, ErrValidation)
+		assert.NotErrorIs(t, err, config.ErrCompacted)
+		_, err = cfg.History(ctx, "app/missing", 0)
+		assert.ErrorIs(t, err, ErrNotFound)
+
+		// 超出保留范围的版本视为已被压缩
+		first := history[len(history)-1].Version
+		for i := 0; i < 100; i++ {
+			require.NoError(t, cfg.Set(ctx, "app/port", 10000+i))
+		}
+		history, err = cfg.History(ctx, "app/port", 0)
+		require.NoError(t, err)
+		assert.Less(t, len(history), 100)
+		assert.Equal(t, float64(10099), history[0].Value)
+		assert.ErrorIs(t, cfg.Rollback(ctx, "app/port", first), config.ErrCompacted)
+	})
+
+	t.Run("config manager factory", func(t *testing.T) {
+		_, err := NewConfigManager(provider.ConfigManagers(), "db", 0)
+		assert.ErrorIs(t, err, ErrValidation)
+
+		_, err = NewInMemory(ctx, WithConfigScope("dev", ""))
+		assert.ErrorIs(t, err, ErrValidation)
+
+		scoped, err := NewInMemory(ctx, WithConfigScope("dev", "myapp"))
+		require.NoError(t, err)
+		defer scoped.Close()
+
+		factory := scoped.ConfigManagers()
+		assert.Equal(t, "dev", factory.Env())
+		assert.Equal(t, "myapp", factory.Service())
+
+		_, err = NewConfigManager(factory, "", 0)
+		assert.ErrorIs(t, err, ErrValidation)
+
+		require.NoError(t, scoped.Config().Set(ctx, "/config/dev/myapp/db", 5432))
+		manager, err := NewConfigManager(factory, "db", 0)
+		require.NoError(t, err)
+		assert.Equal(t, "/config/dev/myapp/db", manager.SourceKey())
+		require.NoError(t, manager.StartAndWait(ctx))
+		defer manager.Stop()
+		assert.Equal(t, 5432, *manager.GetCurrentConfig())
+	})
+
+	t.Run("cluster watch", func(t *testing.T) {
+		watchCtx, cancel := context.WithCancel(ctx)
+		events, err := provider.WatchCluster(watchCtx)
+		require.NoError(t, err)
+		cancel()
+		_, ok := <-events
+		assert.False(t, ok)
+	})
+
+	t.Run("cluster watch closes on Close", func(t *testing.T) {
+		closing, err := NewInMemory(ctx)
+		require.NoError(t, err)
+		events, err := closing.WatchCluster(ctx)
+		require.NoError(t, err)
+
+		require.NoError(t, closing.Close())
+		select {
+		case _, ok := <-events:
+			assert.False(t, ok)
+		case <-time.After(time.Second):
+			t.Fatal("cluster watch channel not closed after Close")
+		}
+	})
+
+	t.Run("lock conflict", func(t *testing.T) {
+		l, err := provider.Lock().TryAcquire(ctx, "job", 5*time.Second)
+		require.NoError(t, err)
+
+		_, err = provider.Lock().TryAcquire(ctx, "job", 5*time.Second)
+		assert.Error(t, err)
+
+		require.NoError(t, l.Unlock(ctx))
+		l2, err := provider.Lock().TryAcquire(ctx, "job", 5*time.Second)
+		require.NoError(t, err)
+		require.NoError(t, l2.Unlock(ctx))
+	})
+
+	t.Run("lock waiters", func(t *testing.T) {
+		held, err := provider.Lock().Acquire(ctx, "queue", 5*time.Second)
+		require.NoError(t, err)
+
+		waiters, err := provider.Lock().Waiters(ctx, "queue")
+		require.NoError(t, err)
+		assert.Empty(t, waiters)
+
+		acquired := make(chan lock.Lock, 2)
+		for _, worker := range []string{"w1", "w2"} {
+			go func() {
+				l, err := provider.Lock().Acquire(ctx, "queue", 5*time.Second, lock.WithHolder(map[string]string{"worker": worker}))
+				if err == nil {
+					acquired <- l
+				}
+			}()
+			require.Eventually(t, func() bool {
+				waiters, err := provider.Lock().Waiters(ctx, "queue")
+				return err == nil && len(waiters) > 0 && waiters[len(waiters)-1].Metadata["worker"] == worker
+			}, time.Second, 10*time.Millisecond)
+		}
+
+		waiters, err = provider.Lock().Waiters(ctx, "queue")
+		require.NoError(t, err)
+		require.Len(t, waiters, 2)
+		assert.Equal(t, "w1", waiters[0].Metadata["worker"])
+		assert.Equal(t, "w2", waiters[1].Metadata["worker"])
+		assert.True(t, waiters[1].AcquiredAt.IsZero())
+
+		require.NoError(t, held.Unlock(ctx))
+		next := <-acquired
+		waiters, err = provider.Lock().Waiters(ctx, "queue")
+		require.NoError(t, err)
+		require.Len(t, waiters, 1)
+		require.NoError(t, next.Unlock(ctx))
+		require.NoError(t, (<-acquired).Unlock(ctx))
+	})
+
+	t.Run("registry discover", func(t *testing.T) {
+		service := registry.ServiceInfo{ID: "user-1", Name: "user", Address: "127.0.0.1", Port: 9000}
+		require.NoError(t, provider.Registry().Register(ctx, service, 5*time.Second))
+
+		services, err := provider.Registry().Discover(ctx, "user")
+		require.NoError(t, err)
+		require.Len(t, services, 1)
+		assert.Equal(t, "user-1", services[0].ID)
+
+		require.NoError(t, provider.Registry().Unregister(ctx, "user-1"))
+		services, err = provider.Registry().Discover(ctx, "user")
+		require.NoError(t, err)
+		assert.Empty(t, services)
+
+		// 重复注销：默认返回错误，WithIgnoreMissing 时视为成功
+		assert.Error(t, provider.Registry().Unregister(ctx, "user-1"))
+		assert.NoError(t, provider.Registry().Unregister(ctx, "user-1", registry.WithIgnoreMissing()))
+	})
+
+	t.Run("registry picker", func(t *testing.T) {
+		for i, id := range []string{"order-1", "order-2", "order-3"} {
+			service := registry.ServiceInfo{ID: id, Name: "order", Address: "127.0.0.1", Port: 9100 + i}
+			require.NoError(t, provider.Registry().Register(ctx, service, 5*time.Second))
+		}
+
+		picker, err := provider.Registry().Picker(ctx, "order")
+		require.NoError(t, err)
+		var picked []string
+		for i := 0; i < 4; i++ {
+			service, err := picker.Next()
+			require.NoError(t, err)
+			picked = append(picked, service.ID)
+		}
+		assert.Equal(t, []string{"order-1", "order-2", "order-3", "order-1"}, picked)
+
+		for _, id := range []string{"order-1", "order-2", "order-3"} {
+			require.NoError(t, provider.Registry().Unregister(ctx, id))
+		}
+		assert.Eventually(t, func() bool {
+			_, err := picker.Next()
+			return errors.Is(err, registry.ErrNoInstances) && errors.Is(err, ErrNotFound)
+		}, time.Second, 10*time.Millisecond)
+	})
+
+	t.Run("allocator", func(t *testing.T) {
+		alloc, err := provider.InstanceIDAllocator("worker", 2)
+		require.NoError(t, err)
+
+		id1, err := alloc.AcquireID(ctx)
+		require.NoError(t, err)
+		id2, err := alloc.AcquireID(ctx)
+		require.NoError(t, err)
+		assert.NotEqual(t, id1.ID(), id2.ID())
+
+		_, err = alloc.AcquireID(ctx)
+		assert.Error(t, err)
+
+		require.NoError(t, id1.Close(ctx))
+		id3, err := alloc.AcquireID(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, id1.ID(), id3.ID())
+	})
+
+	t.Run("allocator partitions", func(t *testing.T) {
+		tenantA, err := provider.InstanceIDAllocator("tenant-worker", 1, allocator.WithPartition("tenant-a"))
+		require.NoError(t, err)
+		tenantB, err := provider.InstanceIDAllocator("tenant-worker", 1, allocator.WithPartition("tenant-b"))
+		require.NoError(t, err)
+		assert.NotSame(t, tenantA, tenantB)
+
+		cached, err := provider.InstanceIDAllocator("tenant-worker", 1, allocator.WithPartition("tenant-a"))
+		require.NoError(t, err)
+		assert.Same(t, tenantA, cached)
+
+		// 各分区的 ID 空间相互独立
+		idA, err := tenantA.AcquireID(ctx)
+		require.NoError(t, err)
+		idB, err := tenantB.AcquireID(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, idA.ID(), idB.ID())
+
+		_, err = tenantA.AcquireID(ctx)
+		assert.ErrorIs(t, err, allocator.ErrExhausted)
+
+		_, err = provider.InstanceIDAllocator("tenant-worker", 1, allocator.WithPartition("a/b"))
+		assert.Error(t, err)
+	})
+
+	require.NoError(t, provider.Close())
+	assert.Error(t, provider.Health(ctx))
+}
+
+// TestRegistryRenewal 测试注册表续约参数的校验和生效
+func TestRegistryRenewal(t *testing.T) {
+	ctx := context.Background()
+
+	_, err := NewInMemory(ctx, WithRegistryOptions(registry.WithRenewal(0.8, 0)))
+	assert.ErrorIs(t, err, ErrValidation)
+	_, err = NewInMemory(ctx, WithRegistryOptions(registry.WithRenewal(0.3, 1)))
+	assert.ErrorIs(t, err, ErrValidation)
+
+	options := registry.ParseOptions(registry.WithRenewal(0.4, 0.25))
+	for i := 0; i < 100; i++ {
+		interval := options.RenewInterval(30 * time.Second)
+		assert.GreaterOrEqual(t, interval, 9*time.Second)
+		assert.LessOrEqual(t, interval, 12*time.Second)
+	}
+
+	provider, err := NewInMemory(ctx, WithRegistryOptions(registry.WithRenewal(0.25, 0.5)))
+	require.NoError(t, err)
+	defer provider.Close()
+
+	service := registry.ServiceInfo{ID: "renew-1", Name: "renew", Address: "127.0.0.1", Port: 9000}
+	require.NoError(t, provider.Registry().Register(ctx, service, 200*time.Millisecond))
+	time.Sleep(600 * time.Millisecond)
+	services, err := provider.Registry().Discover(ctx, "renew")
+	require.NoError(t, err)
+	assert.Len(t, services, 1, "jittered renewals keep the lease alive")
+}
+
+// TestInMemoryConfigAudit 测试 SetIfAbsent、Move 和 Rollback 的审计记录
+func TestInMemoryConfigAudit(t *testing.T) {
+	provider, err := NewInMemory(context.Background(), WithConfigOptions(config.WithAuditPrefix("/config-audit")))
+	require.NoError(t, err)
+	defer provider.Close()
+
+	cfg := provider.Config()
+	ctx := config.WithActor(context.Background(), "alice")
+
+	created, err := cfg.SetIfAbsent(ctx, "app/mode", "blue")
+	require.NoError(t, err)
+	require.True(t, created)
+	// 键已存在时不写入，也不产生审计记录
+	created, err = cfg.SetIfAbsent(ctx, "app/mode", "green")
+	require.NoError(t, err)
+	require.False(t, created)
+
+	history, err := cfg.AuditHistory(ctx, "app/mode", 0)
+	require.NoError(t, err)
+	require.Len(t, history, 1)
+	assert.Equal(t, config.AuditOpSetIfAbsent, history[0].Operation)
+	assert.Empty(t, history[0].OldValue)
+	assert.Equal(t, "blue", history[0].NewValue)
+	assert.Equal(t, "alice", history[0].Actor)
+
+	require.NoError(t, cfg.Set(ctx, "app/next", "stale"))
+	require.NoError(t, cfg.Move(ctx, "app/mode", "app/next", true))
+
+	// 目标键记录写入，源键记录删除，两条记录共享同一个版本号
+	dst, err := cfg.AuditHistory(ctx, "app/next", 1)
+	require.NoError(t, err)
+	require.Len(t, dst, 1)
+	assert.Equal(t, config.AuditOpMove, dst[0].Operation)
+	assert.Equal(t, "stale", dst[0].OldValue)
+	assert.Equal(t, "blue", dst[0].NewValue)
+
+	src, err := cfg.AuditHistory(ctx, "app/mode", 1)
+	require.NoError(t, err)
+	require.Len(t, src, 1)
+	assert.Equal(t, config.AuditOpMove, src[0].Operation)
+	assert.Equal(t, "blue", src[0].OldValue)
+	assert.Empty(t, src[0].NewValue)
+	assert.Equal(t, dst[0].Version, src[0].Version)
+
+	// 回滚记录写入前的真实值
+	versions, err := cfg.History(ctx, "app/next", 0)
+	require.NoError(t, err)
+	require.Len(t, versions, 2)
+	require.NoError(t, cfg.Rollback(ctx, "app/next", versions[1].Version))
+	rollback, err := cfg.AuditHistory(ctx, "app/next", 1)
+	require.NoError(t, err)
+	require.Len(t, rollback, 1)
+	assert.Equal(t, config.AuditOpRollback, rollback[0].Operation)
+	assert.Equal(t, "blue", rollback[0].OldValue)
+	assert.Equal(t, "stale", rollback[0].NewValue)
+}
+
+// TestStrictLockKeys 测试严格模式拒绝覆盖配置和服务注册键空间的锁键
+func TestStrictLockKeys(t *testing.T) {
+	ctx := context.Background()
+	provider, err := NewInMemory(ctx, WithStrictLockKeys())
+	require.NoError(t, err)
+	defer provider.Close()
+
+	require.NoError(t, provider.Config().Set(ctx, "app", "value"))
+	_, err = provider.Lock().TryAcquire(ctx, "../config/app", 5*time.Second)
+	assert.ErrorIs(t, err, lock.ErrKeyCollision)
+	_, err = provider.Lock().TryAcquire(ctx, "../services/user", 5*time.Second)
+	assert.ErrorIs(t, err, lock.ErrKeyCollision)
+
+	var value string
+	require.NoError(t, provider.Config().Get(ctx, "app", &value))
+	assert.Equal(t, "value", value)
+
+	l, err := provider.Lock().TryAcquire(ctx, "config/app", 5*time.Second)
+	require.NoError(t, err, "keys inside the lock prefix are unaffected")
+	require.NoError(t, l.Unlock(ctx))
+
+	// 默认只记录警告
+	lenient, err := NewInMemory(ctx)
+	require.NoError(t, err)
+	defer lenient.Close()
+	l, err = lenient.Lock().TryAcquire(ctx, "../config/app", 5*time.Second)
+	require.NoError(t, err)
+	require.NoError(t, l.Unlock(ctx))
+}
@@ -0,0 +1,30 @@
+package election
+
+import (
+	"context"
+	"errors"
+)
+
+var (
+	// ErrNotLeader 当前候选者不是 leader
+	ErrNotLeader = errors.New("not the leader")
+)
+
+// Election 是基于 etcd 的 leader 选举接口
+// 同一选举名称下同一时刻只有一个候选者成为 leader，leader 的会话失效后其他候选者自动接任
+// 会话失效后实现会自动重建会话，失去 leader 身份的候选者需要重新调用 Campaign
+type Election interface {
+	// Campaign 参与竞选，阻塞直到当选或 context 被取消
+	Campaign(ctx context.Context) error
+	// Resign 主动放弃 leader 身份，让其他候选者接任；未当选时返回 ErrNotLeader
+	Resign(ctx context.Context) error
+	// IsLeader 返回当前候选者是否为 leader
+	IsLeader() bool
+	// Leader 返回当前 leader 的候选者 ID，没有 leader 时返回空字符串
+	Leader(ctx context.Context) (string, error)
+	// LeaderChanged 返回 leader 变更通知通道，每次 leader 变化时推送新 leader 的候选者 ID
+	// 通道只保留最新的变更，消费不及时时旧的通知会被丢弃；Close 后通道关闭
+	LeaderChanged() <-chan string
+	// Close 放弃竞选并释放会话资源
+	Close() error
+}
@@ -0,0 +1,54 @@
+package election
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"os"
+	"time"
+)
+
+// DefaultTTL 默认的会话 TTL，leader 失联超过该时间后由其他候选者接任
+const DefaultTTL = 15 * time.Second
+
+// MinTTL 会话 TTL 的下限，etcd 租约以秒为单位
+const MinTTL = time.Second
+
+// Options 定义选举的配置选项
+type Options struct {
+	// CandidateID 候选者标识，当选后作为 leader 值对外可见，默认为 "主机名-进程号-随机后缀"
+	// 随机后缀避免容器中主机名和进程号都相同的实例无法区分
+	CandidateID string
+	// TTL 会话租约有效期
+	TTL time.Duration
+}
+
+// Option 配置选举的函数式选项
+type Option func(*Options)
+
+// WithCandidateID 设置候选者标识，通常使用实例 ID 或服务地址
+func WithCandidateID(id string) Option {
+	return func(o *Options) {
+		o.CandidateID = id
+	}
+}
+
+// WithTTL 设置会话租约有效期，决定 leader 崩溃后的接任延迟
+// 小于 MinTTL 时创建选举返回错误；etcd 租约以秒为单位，不足整秒的部分被舍去
+func WithTTL(ttl time.Duration) Option {
+	return func(o *Options) {
+		o.TTL = ttl
+	}
+}
+
+// ParseOptions 应用选项并返回最终的选举配置
+func ParseOptions(opts ...Option) *Options {
+	hostname, _ := os.Hostname()
+	result := &Options{
+		CandidateID: fmt.Sprintf("%s-%d-%08x", hostname, os.Getpid(), rand.Uint32()),
+		TTL:         DefaultTTL,
+	}
+	for _, opt := range opts {
+		opt(result)
+	}
+	return result
+}
@@ -0,0 +1,30 @@
+package coord
+
+import "github.com/ceyewan/infra-kit/coord/internal/client"
+
+// 按错误码匹配的哨兵错误，适用于所有子系统返回的错误，用 errors.Is 判断而不是匹配错误信息：
+//
+//	if errors.Is(err, coord.ErrNotFound) { ... }
+//
+// 各子系统还导出了更具体的哨兵错误，如 config.ErrVersionMismatch、lock.ErrNotAcquired、
+// allocator.ErrExhausted，二者可同时匹配：版本冲突的错误既是 config.ErrVersionMismatch 也是 ErrConflict
+var (
+	// ErrNotFound 目标不存在，如配置键、服务实例或锁持有者
+	ErrNotFound = client.ErrNotFound
+	// ErrConflict 操作与当前状态冲突，如版本不匹配、锁已被占用
+	ErrConflict = client.ErrConflict
+	// ErrValidation 参数校验失败
+	ErrValidation = client.ErrValidation
+	// ErrTimeout 操作超时或 context 被取消
+	ErrTimeout = client.ErrTimeout
+	// ErrConnection 与 etcd 通信失败
+	ErrConnection = client.ErrConnection
+	// ErrUnavailable 服务不可用，如存储已关闭
+	ErrUnavailable = client.ErrUnavailable
+)
+
+// Error 协调器返回的错误类型，用 errors.As 取出错误码和详细信息
+type Error = client.Error
+
+// ErrorCode 错误码
+type ErrorCode = client.ErrorCode
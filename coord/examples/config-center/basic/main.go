@@ -232,14 +232,13 @@ func prefixOperationsDemo(ctx context.Context, configService config.ConfigCenter
 		fmt.Printf("    - %s\n", key)
 	}
 
-	// 批量删除配置
-	for _, cfg := range configs {
-		if err := configService.Delete(ctx, cfg.key); err != nil {
-			log.Printf("删除配置 %s 失败: %v", cfg.key, err)
-			continue
-		}
-		fmt.Printf("✓ 配置 %s 删除成功\n", cfg.key)
+	// 按前缀原子地删除全部配置
+	deleted, err := configService.DeletePrefix(ctx, basePath)
+	if err != nil {
+		log.Printf("删除前缀 %s 失败: %v", basePath, err)
+		return
 	}
+	fmt.Printf("✓ 前缀 %s 下的 %d 个配置删除成功\n", basePath, deleted)
 
 	// 验证删除
 	keys, err = configService.ListKeys(ctx, basePath)
@@ -137,7 +137,8 @@ func capacityManagementDemo(ctx context.Context, allocatorService allocator.Inst
 	if err != nil {
 		log.Printf("在有空位时分配ID失败: %v", err)
 	} else {
-		fmt.Printf("✓ 在有空位时成功分配ID: %d\n", newID.ID())
+		// 释放后再次分配的 ID 报告为复用，可据此复用与 ID 关联的状态
+		fmt.Printf("✓ 在有空位时成功分配ID: %d（复用: %v）\n", newID.ID(), newID.IsReused())
 		allocatedIDs = append(allocatedIDs, newID)
 	}
 
@@ -9,6 +9,7 @@ import (
 
 	"github.com/ceyewan/infra-kit/coord"
 	"github.com/ceyewan/infra-kit/coord/allocator"
+	"github.com/ceyewan/infra-kit/coord/election"
 	"github.com/ceyewan/infra-kit/coord/lock"
 	"github.com/ceyewan/infra-kit/coord/registry"
 )
@@ -396,27 +397,27 @@ func integrationWithTaskScheduling(ctx context.Context, provider coord.Provider)
 	// 任务调度器
 	go func() {
 		for _, task := range tasks {
-			// 获取任务分配锁
-			taskLock, err := lockService.Acquire(ctx, fmt.Sprintf("task-lock-%d", task.ID), 5*time.Second)
+			// 持有任务分配锁时分配任务给可用的worker，其他调度节点持有锁时跳过
+			ran, err := lockService.RunOnce(ctx, fmt.Sprintf("task-lock-%d", task.ID), 5*time.Second, func(ctx context.Context) error {
+				mu.Lock()
+				defer mu.Unlock()
+				for _, worker := range workers {
+					if worker.Active {
+						task.AssignedTo = worker.ID
+						task.Status = "assigned"
+						worker.Processed++
+						fmt.Printf("  任务 %d 分配给 worker %d\n", task.ID, worker.ID)
+						break
+					}
+				}
+				return nil
+			})
 			if err != nil {
 				fmt.Printf("  任务 %d 分配失败: %v\n", task.ID, err)
-				continue
-			}
-
-			// 分配任务给可用的worker
-			mu.Lock()
-			for _, worker := range workers {
-				if worker.Active {
-					task.AssignedTo = worker.ID
-					task.Status = "assigned"
-					worker.Processed++
-					fmt.Printf("  任务 %d 分配给 worker %d\n", task.ID, worker.ID)
-					break
-				}
+			} else if !ran {
+				fmt.Printf("  任务 %d 已由其他调度节点处理\n", task.ID)
 			}
-			mu.Unlock()
 
-			taskLock.Unlock(ctx)
 			time.Sleep(500 * time.Millisecond)
 		}
 	}()
@@ -469,8 +470,8 @@ func integrationWithClusterManagement(ctx context.Context, provider coord.Provid
 		}
 
 		nodeID := allocatedID.ID()
-		roles := []string{"leader", "follower", "candidate"}
-		role := roles[i%len(roles)]
+		// 角色由下方的 leader 选举决定
+		role := "candidate"
 
 		node := &ClusterNode{
 			NodeID:      nodeID,
@@ -511,37 +512,41 @@ func integrationWithClusterManagement(ctx context.Context, provider coord.Provid
 		}
 	}()
 
-	// 模拟leader选举
-	go func() {
-		time.Sleep(1 * time.Second)
+	// 基于 etcd 的 leader 选举：每个节点作为候选者竞选，同一时刻只有一个 leader
+	mu.RLock()
+	candidates := make([]*ClusterNode, 0, len(nodes))
+	for _, node := range nodes {
+		candidates = append(candidates, node)
+	}
+	mu.RUnlock()
 
-		mu.Lock()
-		defer mu.Unlock()
-
-		// 找到最小的活跃节点作为leader
-		var minNodeID int
-		found := false
-		for _, node := range nodes {
-			if node.Status == "active" {
-				if !found || node.NodeID < minNodeID {
-					minNodeID = node.NodeID
-					found = true
-				}
-			}
+	campaignCtx, cancelCampaign := context.WithTimeout(ctx, 2*time.Second)
+	defer cancelCampaign()
+
+	for _, node := range candidates {
+		elec, err := provider.Election(ctx, "cluster-management",
+			election.WithCandidateID(fmt.Sprintf("node-%d", node.NodeID)))
+		if err != nil {
+			log.Printf("节点 %d 创建选举失败: %v", node.NodeID, err)
+			continue
 		}
+		defer elec.Close()
 
-		if found {
-			// 更新节点角色
-			for _, node := range nodes {
-				if node.NodeID == minNodeID {
-					node.Role = "leader"
-					fmt.Printf("  节点 %d 被选为 leader\n", node.NodeID)
-				} else if node.Status == "active" {
-					node.Role = "follower"
-				}
+		mu.Lock()
+		node.Role = "follower"
+		mu.Unlock()
+
+		go func(node *ClusterNode, elec election.Election) {
+			// 阻塞直到当选；未当选的节点在 campaignCtx 超时后退出竞选
+			if err := elec.Campaign(campaignCtx); err != nil {
+				return
 			}
-		}
-	}()
+			mu.Lock()
+			node.Role = "leader"
+			mu.Unlock()
+			fmt.Printf("  节点 %d 被选为 leader\n", node.NodeID)
+		}(node, elec)
+	}
 
 	// 等待观察
 	time.Sleep(2 * time.Second)
@@ -130,14 +130,12 @@ func multiInstanceDemo(ctx context.Context, registryService registry.Registry) {
 		},
 	}
 
-	// 注册所有实例
-	for _, instance := range instances {
-		if err := registryService.Register(ctx, instance, 30*time.Second); err != nil {
-			log.Printf("注册实例 %s 失败: %v", instance.ID, err)
-			continue
-		}
-		fmt.Printf("✓ 实例 %s 注册成功\n", instance.ID)
+	// 在一个事务中注册所有实例，避免只注册成功一部分
+	if err := registryService.RegisterBatch(ctx, instances, 30*time.Second); err != nil {
+		log.Printf("批量注册实例失败: %v", err)
+		return
 	}
+	fmt.Printf("✓ %d 个实例注册成功\n", len(instances))
 
 	// 发现所有实例
 	services, err := registryService.Discover(ctx, serviceName)
@@ -193,6 +193,7 @@ func TestCoordIntegration(t *testing.T) {
 		_, err = idAllocator.AcquireID(ctx)
 		assert.Error(t, err)
 		assert.Contains(t, err.Error(), "no available ID")
+		assert.ErrorIs(t, err, allocator.ErrExhausted)
 
 		// 释放一个ID
 		err = allocatedIDs[0].Close(ctx)
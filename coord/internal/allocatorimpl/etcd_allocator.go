@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	clientv3 "go.etcd.io/etcd/client/v3"
@@ -17,9 +18,7 @@ import (
 const (
 	// ID 分配器的根路径
 	allocatorRoot = "/im-infra/allocators"
-	// 默认租约 TTL
-	defaultLeaseTTL = 30 * time.Second
-	// 续租间隔
+	// 会话检查间隔上限，TTL 较短时按 TTL/3 检查
 	keepAliveInterval = 10 * time.Second
 )
 
@@ -27,9 +26,15 @@ const (
 type etcdInstanceIDAllocator struct {
 	client       *clientv3.Client
 	serviceName  string
-	maxID        int
+	minID        int // 可分配范围下界（包含）
+	maxID        int // 可分配范围上界（包含）
+	leaseTTL     time.Duration
+	expiryMargin time.Duration // 触发 OnExpiring 回调的租约剩余时间
 	logger       clog.Logger
 	basePath     string
+	queuePath    string       // WaitAcquireID 的排队路径
+	historyPath  string       // 分配历史路径，记录分配过的 ID，不绑定租约
+	waitSeq      atomic.Int64 // 排队键序号，区分同一会话下的多个等待者
 	session      *concurrency.Session
 	sessionMu    sync.RWMutex
 	leaseID      clientv3.LeaseID
@@ -42,10 +47,12 @@ type etcdInstanceIDAllocator struct {
 // allocatedID 已分配 ID 的具体实现
 type allocatedID struct {
 	id        int
+	reused    bool // 该 ID 此前是否被分配过
 	allocator *etcdInstanceIDAllocator
 	leaseID   clientv3.LeaseID
 	session   *concurrency.Session
 	logger    clog.Logger
+	expiring  *expiryNotifier // OnExpiring 注册的回调
 	released  bool
 	mu        sync.RWMutex
 	closeOnce sync.Once
@@ -54,8 +61,17 @@ type allocatedID struct {
 var _ allocator.InstanceIDAllocator = (*etcdInstanceIDAllocator)(nil)
 var _ allocator.AllocatedID = (*allocatedID)(nil)
 
-// NewEtcdInstanceIDAllocator 创建新的实例 ID 分配器
-func NewEtcdInstanceIDAllocator(client *clientv3.Client, serviceName string, maxID int, logger clog.Logger) (allocator.InstanceIDAllocator, error) {
+// NewEtcdInstanceIDAllocator 创建新的实例 ID 分配器，在 1..maxID 范围内分配
+func NewEtcdInstanceIDAllocator(client *clientv3.Client, serviceName string, maxID int, logger clog.Logger, opts ...allocator.Option) (allocator.InstanceIDAllocator, error) {
+	if maxID <= 0 {
+		return nil, fmt.Errorf("[VALIDATION_ERROR] max ID must be positive")
+	}
+	return NewEtcdInstanceIDAllocatorRange(client, serviceName, 1, maxID, logger, opts...)
+}
+
+// NewEtcdInstanceIDAllocatorRange 创建在 minID..maxID（闭区间）范围内分配的实例 ID 分配器
+// 适用于低位 ID 预留给静态基础设施、多个服务共用编号方案但互不重叠的场景
+func NewEtcdInstanceIDAllocatorRange(client *clientv3.Client, serviceName string, minID, maxID int, logger clog.Logger, opts ...allocator.Option) (allocator.InstanceIDAllocator, error) {
 	// 参数验证
 	if client == nil {
 		return nil, fmt.Errorf("[VALIDATION_ERROR] client cannot be nil")
@@ -63,29 +79,51 @@ func NewEtcdInstanceIDAllocator(client *clientv3.Client, serviceName string, max
 	if serviceName == "" {
 		return nil, fmt.Errorf("[VALIDATION_ERROR] service name cannot be empty")
 	}
-	if maxID <= 0 {
-		return nil, fmt.Errorf("[VALIDATION_ERROR] max ID must be positive")
+	if minID < 0 || maxID < 0 {
+		return nil, fmt.Errorf("[VALIDATION_ERROR] ID range bounds must be non-negative")
+	}
+	if minID > maxID {
+		return nil, fmt.Errorf("[VALIDATION_ERROR] min ID %d must not exceed max ID %d", minID, maxID)
 	}
 	if logger == nil {
 		return nil, fmt.Errorf("[VALIDATION_ERROR] logger cannot be nil")
 	}
+	options := allocator.ParseOptions(opts...)
+	if options.LeaseTTL < allocator.MinLeaseTTL {
+		return nil, fmt.Errorf("[VALIDATION_ERROR] lease TTL %v must be at least %v", options.LeaseTTL, allocator.MinLeaseTTL)
+	}
+	if err := validateExpiryMargin(options); err != nil {
+		return nil, err
+	}
+	if err := validatePartition(options); err != nil {
+		return nil, err
+	}
+	if options.Partition != "" {
+		logger = logger.With(clog.String("partition", options.Partition))
+	}
+	base := allocatorPath(serviceName, options)
 
-	allocator := &etcdInstanceIDAllocator{
+	a := &etcdInstanceIDAllocator{
 		client:       client,
 		serviceName:  serviceName,
+		minID:        minID,
 		maxID:        maxID,
+		leaseTTL:     options.LeaseTTL,
+		expiryMargin: options.Margin(),
 		logger:       logger.With(clog.String("service", serviceName)),
-		basePath:     fmt.Sprintf("%s/%s/ids", allocatorRoot, serviceName),
+		basePath:     base + "/ids",
+		queuePath:    base + "/queue",
+		historyPath:  base + "/history",
 		allocatedIDs: make(map[int]struct{}),
 		done:         make(chan struct{}),
 	}
 
 	// 初始化会话
-	if err := allocator.initSession(); err != nil {
+	if err := a.initSession(); err != nil {
 		return nil, fmt.Errorf("failed to initialize allocator session: %w", err)
 	}
 
-	return allocator, nil
+	return a, nil
 }
 
 // initSession 初始化 etcd 会话
@@ -103,7 +141,7 @@ func (a *etcdInstanceIDAllocator) initSession() error {
 	}
 
 	// 创建会话
-	session, err := concurrency.NewSession(a.client, concurrency.WithTTL(int(defaultLeaseTTL/time.Second)))
+	session, err := concurrency.NewSession(a.client, concurrency.WithTTL(int(a.leaseTTL/time.Second)))
 	if err != nil {
 		return fmt.Errorf("failed to create etcd session: %w", err)
 	}
@@ -129,7 +167,11 @@ func (a *etcdInstanceIDAllocator) initSession() error {
 
 // keepSessionAlive 保持会话活跃
 func (a *etcdInstanceIDAllocator) keepSessionAlive() {
-	ticker := time.NewTicker(keepAliveInterval)
+	interval := keepAliveInterval
+	if a.leaseTTL/3 < interval {
+		interval = a.leaseTTL / 3
+	}
+	ticker := time.NewTicker(interval)
 	defer ticker.Stop()
 
 	for {
@@ -178,7 +220,7 @@ func (a *etcdInstanceIDAllocator) tryRecreateSession() error {
 	}
 
 	// 创建新会话
-	session, err := concurrency.NewSession(a.client, concurrency.WithTTL(int(defaultLeaseTTL/time.Second)))
+	session, err := concurrency.NewSession(a.client, concurrency.WithTTL(int(a.leaseTTL/time.Second)))
 	if err != nil {
 		return fmt.Errorf("failed to recreate session: %w", err)
 	}
@@ -197,12 +239,15 @@ func (a *etcdInstanceIDAllocator) tryRecreateSession() error {
 
 // AcquireID 获取一个实例 ID
 func (a *etcdInstanceIDAllocator) AcquireID(ctx context.Context) (allocator.AllocatedID, error) {
-	if a.closed {
+	a.sessionMu.RLock()
+	closed := a.closed
+	a.sessionMu.RUnlock()
+	if closed {
 		return nil, fmt.Errorf("allocator is closed")
 	}
 
-	// 从 1 开始尝试获取 ID，直到找到可用的
-	for id := 1; id <= a.maxID; id++ {
+	// 从范围下界开始尝试获取 ID，直到找到可用的
+	for id := a.minID; id <= a.maxID; id++ {
 		allocatedID, err := a.tryAcquireID(ctx, id)
 		if err == nil {
 			return allocatedID, nil
@@ -217,7 +262,106 @@ func (a *etcdInstanceIDAllocator) AcquireID(ctx context.Context) (allocator.Allo
 		return nil, err
 	}
 
-	return nil, fmt.Errorf("no available ID found (max: %d)", a.maxID)
+	return nil, fmt.Errorf("%w (range: %d-%d)", allocator.ErrExhausted, a.minID, a.maxID)
+}
+
+// WaitAcquireID 获取一个实例 ID，ID 耗尽时排队等待
+// 排队使用绑定会话租约的顺序键，按创建 revision 排序：只有队首的等待者会尝试获取 ID，
+// 其余等待者监听前一个等待者出队，避免 ID 释放时所有等待者同时争抢
+func (a *etcdInstanceIDAllocator) WaitAcquireID(ctx context.Context) (allocator.AllocatedID, error) {
+	a.sessionMu.RLock()
+	if a.closed {
+		a.sessionMu.RUnlock()
+		return nil, fmt.Errorf("allocator is closed")
+	}
+	if a.session == nil {
+		a.sessionMu.RUnlock()
+		return nil, fmt.Errorf("session not initialized")
+	}
+	leaseID := a.leaseID
+	a.sessionMu.RUnlock()
+
+	// 入队，等待者崩溃时排队键随租约自动删除
+	queueKey := fmt.Sprintf("%s/%x-%d", a.queuePath, leaseID, a.waitSeq.Add(1))
+	putResp, err := a.client.Put(ctx, queueKey, "", clientv3.WithLease(leaseID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to join allocator queue: %w", err)
+	}
+	defer func() {
+		// 无论成功、失败还是取消都要出队，否则会阻塞后面的等待者直到租约过期
+		deleteCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if _, err := a.client.Delete(deleteCtx, queueKey); err != nil {
+			a.logger.Warn("failed to leave allocator queue", clog.String("key", queueKey), clog.Err(err))
+		}
+	}()
+
+	if err := a.waitQueueTurn(ctx, putResp.Header.Revision); err != nil {
+		return nil, err
+	}
+
+	for {
+		// 记录扫描前的 revision，扫描失败后从这里开始监听释放事件，避免错过扫描期间的释放
+		resp, err := a.client.Get(ctx, a.basePath+"/", clientv3.WithPrefix(), clientv3.WithCountOnly())
+		if err != nil {
+			return nil, fmt.Errorf("failed to read allocated IDs: %w", err)
+		}
+
+		id, err := a.AcquireID(ctx)
+		if err == nil || !errors.Is(err, allocator.ErrExhausted) {
+			return id, err
+		}
+
+		a.logger.Debug("no ID available, waiting for release", clog.String("queue_key", queueKey))
+		if err := a.waitRelease(ctx, resp.Header.Revision+1); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// waitQueueTurn 等待所有排在前面（创建 revision 更小）的等待者出队
+func (a *etcdInstanceIDAllocator) waitQueueTurn(ctx context.Context, createRev int64) error {
+	for {
+		opts := append(clientv3.WithLastCreate(), clientv3.WithMaxCreateRev(createRev-1))
+		resp, err := a.client.Get(ctx, a.queuePath+"/", opts...)
+		if err != nil {
+			return fmt.Errorf("failed to read allocator queue: %w", err)
+		}
+		if len(resp.Kvs) == 0 {
+			return nil
+		}
+
+		// 只监听紧邻的前一个等待者，它出队后重新检查
+		predecessor := string(resp.Kvs[0].Key)
+		if err := a.waitEvent(ctx, predecessor, resp.Header.Revision+1, clientv3.WithFilterPut()); err != nil {
+			return err
+		}
+	}
+}
+
+// waitRelease 等待从 rev 开始出现的 ID 释放事件
+func (a *etcdInstanceIDAllocator) waitRelease(ctx context.Context, rev int64) error {
+	return a.waitEvent(ctx, a.basePath+"/", rev, clientv3.WithPrefix(), clientv3.WithFilterPut())
+}
+
+// waitEvent 从 rev 开始监听 key，收到任意事件后返回
+func (a *etcdInstanceIDAllocator) waitEvent(ctx context.Context, key string, rev int64, opts ...clientv3.OpOption) error {
+	watchCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	opts = append(opts, clientv3.WithRev(rev))
+	for resp := range a.client.Watch(watchCtx, key, opts...) {
+		if err := resp.Err(); err != nil {
+			return fmt.Errorf("failed to watch allocator key %s: %w", key, err)
+		}
+		if len(resp.Events) > 0 {
+			return nil
+		}
+	}
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	return fmt.Errorf("watch on allocator key %s closed", key)
 }
 
 // tryAcquireID 尝试获取指定的 ID
@@ -231,15 +375,19 @@ func (a *etcdInstanceIDAllocator) tryAcquireID(ctx context.Context, id int) (all
 	a.sessionMu.RUnlock()
 
 	key := fmt.Sprintf("%s/%d", a.basePath, id)
+	historyKey := fmt.Sprintf("%s/%d", a.historyPath, id)
 
 	// 使用事务来确保原子性操作
 	// 1. 检查 key 是否已存在
 	// 2. 如果不存在，创建临时节点并与租约绑定
+	// 3. 读取并写入分配历史，历史键已存在说明该 ID 被分配过
 	txn := a.client.Txn(ctx)
 	txn = txn.If(
 		clientv3.Compare(clientv3.ModRevision(key), "=", 0),
 	).Then(
 		clientv3.OpPut(key, fmt.Sprintf("%d", id), clientv3.WithLease(a.leaseID)),
+		clientv3.OpGet(historyKey, clientv3.WithCountOnly()),
+		clientv3.OpPut(historyKey, fmt.Sprintf("%d", id)),
 	)
 
 	resp, err := txn.Commit()
@@ -251,6 +399,8 @@ func (a *etcdInstanceIDAllocator) tryAcquireID(ctx context.Context, id int) (all
 		return nil, errIDOccupied
 	}
 
+	reused := resp.Responses[1].GetResponseRange().Count > 0
+
 	// 添加到已分配的 ID 映射
 	a.idsMu.Lock()
 	a.allocatedIDs[id] = struct{}{}
@@ -259,13 +409,15 @@ func (a *etcdInstanceIDAllocator) tryAcquireID(ctx context.Context, id int) (all
 	// 创建已分配 ID 对象
 	allocatedID := &allocatedID{
 		id:        id,
+		reused:    reused,
 		allocator: a,
 		leaseID:   a.leaseID,
 		session:   session,
 		logger:    a.logger.With(clog.Int("id", id)),
+		expiring:  newExpiryNotifier(),
 	}
 
-	a.logger.Info("ID acquired", clog.Int("id", id))
+	a.logger.Info("ID acquired", clog.Int("id", id), clog.Bool("reused", reused))
 	return allocatedID, nil
 }
 
@@ -278,6 +430,32 @@ func (id *allocatedID) ID() int {
 	return id.id
 }
 
+// IsReused 返回该 ID 此前是否被分配过
+func (id *allocatedID) IsReused() bool {
+	return id.reused
+}
+
+// OnExpiring 注册租约即将过期的回调，第一次注册时启动对租约剩余时间的监控
+func (id *allocatedID) OnExpiring(fn func()) {
+	id.expiring.add(fn, func(stop <-chan struct{}) {
+		watchExpiry(stop, id.allocator.leaseTTL, id.allocator.expiryMargin, id.timeToLive, func(remaining time.Duration) {
+			id.logger.Warn("ID lease is about to expire, renewal is failing", clog.Duration("remaining", remaining))
+			id.expiring.notify()
+		})
+	})
+}
+
+// timeToLive 查询持有 ID 的租约剩余时间，租约已撤销或过期时返回非正值
+func (id *allocatedID) timeToLive(timeout time.Duration) (time.Duration, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	resp, err := id.allocator.client.TimeToLive(ctx, id.leaseID)
+	if err != nil {
+		return 0, err
+	}
+	return time.Duration(resp.TTL) * time.Second, nil
+}
+
 // Close 释放 ID
 func (id *allocatedID) Close(ctx context.Context) error {
 	var err error
@@ -311,7 +489,8 @@ func (id *allocatedID) release(ctx context.Context) error {
 	id.allocator.idsMu.Unlock()
 
 	id.released = true
-	id.logger.Info("ID released", clog.Int("id", id.id))
+	id.expiring.close()
+	id.logger.Info("ID released")
 	return nil
 }
 
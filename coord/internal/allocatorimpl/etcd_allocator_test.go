@@ -2,10 +2,13 @@ package allocatorimpl
 
 import (
 	"context"
+	"fmt"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/ceyewan/infra-kit/clog"
+	"github.com/ceyewan/infra-kit/coord/allocator"
 	"github.com/stretchr/testify/require"
 	clientv3 "go.etcd.io/etcd/client/v3"
 )
@@ -97,6 +100,231 @@ func TestEtcdInstanceIDAllocator_AcquireID(t *testing.T) {
 	})
 }
 
+// TestEtcdInstanceIDAllocator_IsReused 测试区分首次分配和回收后再次分配的ID
+func TestEtcdInstanceIDAllocator_IsReused(t *testing.T) {
+	etcdClient, err := createTestEtcdClient()
+	require.NoError(t, err)
+	defer etcdClient.Close()
+
+	logger := clog.Namespace("test")
+	ctx := context.Background()
+
+	// 分配历史持久保存，每次运行使用新的服务名
+	serviceName := fmt.Sprintf("reuse-service-%d", time.Now().UnixNano())
+	allocator, err := NewEtcdInstanceIDAllocator(etcdClient, serviceName, 2, logger)
+	require.NoError(t, err)
+	defer allocator.(*etcdInstanceIDAllocator).Close()
+
+	first, err := allocator.AcquireID(ctx)
+	require.NoError(t, err)
+	require.False(t, first.IsReused())
+	second, err := allocator.AcquireID(ctx)
+	require.NoError(t, err)
+	require.False(t, second.IsReused())
+
+	require.NoError(t, first.Close(ctx))
+	again, err := allocator.AcquireID(ctx)
+	require.NoError(t, err)
+	require.Equal(t, first.ID(), again.ID())
+	require.True(t, again.IsReused())
+
+	// 分配器关闭后租约撤销，另一个分配器再次拿到这些 ID 时同样视为复用
+	require.NoError(t, allocator.(*etcdInstanceIDAllocator).Close())
+	other, err := NewEtcdInstanceIDAllocator(etcdClient, serviceName, 2, logger)
+	require.NoError(t, err)
+	defer other.(*etcdInstanceIDAllocator).Close()
+	id, err := other.AcquireID(ctx)
+	require.NoError(t, err)
+	require.True(t, id.IsReused())
+}
+
+// TestEtcdInstanceIDAllocator_Range 测试在指定范围内分配ID
+func TestEtcdInstanceIDAllocator_Range(t *testing.T) {
+	etcdClient, err := createTestEtcdClient()
+	require.NoError(t, err)
+	defer etcdClient.Close()
+
+	logger := clog.Namespace("test")
+	ctx := context.Background()
+
+	t.Run("invalid range", func(t *testing.T) {
+		_, err := NewEtcdInstanceIDAllocatorRange(etcdClient, "range-service", -1, 10, logger)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "must be non-negative")
+
+		_, err = NewEtcdInstanceIDAllocatorRange(etcdClient, "range-service", 10, 5, logger)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "must not exceed")
+	})
+
+	t.Run("allocate within range", func(t *testing.T) {
+		allocator, err := NewEtcdInstanceIDAllocatorRange(etcdClient, "range-service", 100, 101, logger)
+		require.NoError(t, err)
+		defer allocator.(*etcdInstanceIDAllocator).Close()
+
+		first, err := allocator.AcquireID(ctx)
+		require.NoError(t, err)
+		defer first.Close(ctx)
+		second, err := allocator.AcquireID(ctx)
+		require.NoError(t, err)
+		defer second.Close(ctx)
+
+		require.ElementsMatch(t, []int{100, 101}, []int{first.ID(), second.ID()})
+
+		// 范围耗尽
+		_, err = allocator.AcquireID(ctx)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "range: 100-101")
+	})
+}
+
+// TestEtcdInstanceIDAllocator_LeaseTTL 测试自定义租约 TTL
+func TestEtcdInstanceIDAllocator_LeaseTTL(t *testing.T) {
+	etcdClient, err := createTestEtcdClient()
+	require.NoError(t, err)
+	defer etcdClient.Close()
+
+	logger := clog.Namespace("test")
+	ctx := context.Background()
+
+	t.Run("too short", func(t *testing.T) {
+		_, err := NewEtcdInstanceIDAllocator(etcdClient, "ttl-service", 5, logger, allocator.WithLeaseTTL(time.Second))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "lease TTL")
+	})
+
+	t.Run("custom ttl", func(t *testing.T) {
+		a, err := NewEtcdInstanceIDAllocator(etcdClient, "ttl-service", 5, logger, allocator.WithLeaseTTL(90*time.Second))
+		require.NoError(t, err)
+		defer a.(*etcdInstanceIDAllocator).Close()
+
+		id, err := a.AcquireID(ctx)
+		require.NoError(t, err)
+		defer id.Close(ctx)
+
+		resp, err := etcdClient.TimeToLive(ctx, id.(*allocatedID).leaseID)
+		require.NoError(t, err)
+		require.Equal(t, int64(90), resp.GrantedTTL)
+	})
+}
+
+// TestWatchExpiry 测试续约失败时租约即将过期的检测
+func TestWatchExpiry(t *testing.T) {
+	t.Run("margin validation", func(t *testing.T) {
+		require.NoError(t, validateExpiryMargin(allocator.ParseOptions()))
+		require.NoError(t, validateExpiryMargin(allocator.ParseOptions(allocator.WithExpiryMargin(15*time.Second))))
+		require.Error(t, validateExpiryMargin(allocator.ParseOptions(allocator.WithExpiryMargin(20*time.Second))))
+		require.Error(t, validateExpiryMargin(allocator.ParseOptions(allocator.WithExpiryMargin(-time.Second))))
+	})
+
+	t.Run("fires once per episode", func(t *testing.T) {
+		var mu sync.Mutex
+		remaining := []time.Duration{3 * time.Second, 800 * time.Millisecond, 500 * time.Millisecond, 3 * time.Second, 900 * time.Millisecond}
+		leaseTTL := func(time.Duration) (time.Duration, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			if len(remaining) == 0 {
+				return 0, nil // 租约已不存在，结束监控
+			}
+			next := remaining[0]
+			remaining = remaining[1:]
+			return next, nil
+		}
+
+		var fired []time.Duration
+		watchExpiry(make(chan struct{}), 3*time.Second, time.Second, leaseTTL, func(r time.Duration) {
+			fired = append(fired, r)
+		})
+		require.Equal(t, []time.Duration{800 * time.Millisecond, 900 * time.Millisecond}, fired)
+	})
+
+	t.Run("estimates expiry when queries fail", func(t *testing.T) {
+		stop := make(chan struct{})
+		fired := make(chan time.Duration, 1)
+		go watchExpiry(stop, 500*time.Millisecond, 300*time.Millisecond, func(time.Duration) (time.Duration, error) {
+			return 0, fmt.Errorf("etcd unavailable")
+		}, func(r time.Duration) {
+			fired <- r
+		})
+		defer close(stop)
+
+		select {
+		case r := <-fired:
+			require.True(t, r <= 300*time.Millisecond, "remaining %v should be within the margin", r)
+		case <-time.After(2 * time.Second):
+			t.Fatal("expected the expiring callback")
+		}
+	})
+
+	t.Run("notifier", func(t *testing.T) {
+		n := newExpiryNotifier()
+		started := make(chan struct{})
+		called := make(chan struct{}, 2)
+		start := func(stop <-chan struct{}) { close(started) }
+		n.add(func() { called <- struct{}{} }, start)
+		n.add(func() { called <- struct{}{} }, start) // 只启动一次监控
+		<-started
+
+		n.notify()
+		<-called
+		<-called
+
+		n.close()
+		n.close()
+		n.add(func() { t.Error("callbacks added after close must be ignored") }, start)
+		n.notify()
+	})
+}
+
+// TestEtcdInstanceIDAllocator_WaitAcquireID 测试 ID 耗尽时按排队顺序等待
+func TestEtcdInstanceIDAllocator_WaitAcquireID(t *testing.T) {
+	etcdClient, err := createTestEtcdClient()
+	require.NoError(t, err)
+	defer etcdClient.Close()
+
+	logger := clog.Namespace("test")
+	ctx := context.Background()
+
+	allocator, err := NewEtcdInstanceIDAllocatorRange(etcdClient, "wait-service", 1, 1, logger)
+	require.NoError(t, err)
+	defer allocator.(*etcdInstanceIDAllocator).Close()
+
+	held, err := allocator.WaitAcquireID(ctx)
+	require.NoError(t, err)
+
+	// 依次排队的等待者应按顺序获得释放的 ID
+	order := make(chan int, 3)
+	for i := 0; i < 3; i++ {
+		go func(i int) {
+			id, err := allocator.WaitAcquireID(ctx)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			order <- i
+			time.Sleep(50 * time.Millisecond)
+			_ = id.Close(ctx)
+		}(i)
+		time.Sleep(100 * time.Millisecond)
+	}
+
+	// 等待超时的调用方应返回 context 错误并出队
+	timeoutCtx, cancel := context.WithTimeout(ctx, 100*time.Millisecond)
+	_, err = allocator.WaitAcquireID(timeoutCtx)
+	cancel()
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+
+	require.NoError(t, held.Close(ctx))
+	for want := 0; want < 3; want++ {
+		select {
+		case got := <-order:
+			require.Equal(t, want, got)
+		case <-time.After(5 * time.Second):
+			t.Fatalf("timed out waiting for waiter %d", want)
+		}
+	}
+}
+
 // TestEtcdInstanceIDAllocator_Health 测试健康检查
 func TestEtcdInstanceIDAllocator_Health(t *testing.T) {
 	// 创建测试etcd客户端
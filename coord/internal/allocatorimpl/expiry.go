@@ -0,0 +1,128 @@
+package allocatorimpl
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ceyewan/infra-kit/coord/allocator"
+)
+
+// minExpiryCheckInterval 租约剩余时间检查间隔的下限
+const minExpiryCheckInterval = 100 * time.Millisecond
+
+// validateExpiryMargin 检查到期余量：不能为负，也不能超过 LeaseTTL/2，否则续约正常时也会触发回调
+func validateExpiryMargin(options *allocator.Options) error {
+	if options.ExpiryMargin < 0 || options.Margin() > options.LeaseTTL/2 {
+		return fmt.Errorf("[VALIDATION_ERROR] expiry margin %v must be between 0 and half of the lease TTL %v", options.ExpiryMargin, options.LeaseTTL)
+	}
+	return nil
+}
+
+// validatePartition 检查分区名：不能包含 "/"，否则会越出服务的键空间
+func validatePartition(options *allocator.Options) error {
+	if strings.Contains(options.Partition, "/") {
+		return fmt.Errorf("[VALIDATION_ERROR] partition %q must not contain '/'", options.Partition)
+	}
+	return nil
+}
+
+// allocatorPath 返回分配器在 etcd 中的根路径，分区位于服务下独立的 partitions 目录，
+// 与未分区分配器的 ids、queue、history 路径互不重叠
+func allocatorPath(serviceName string, options *allocator.Options) string {
+	if options.Partition == "" {
+		return fmt.Sprintf("%s/%s", allocatorRoot, serviceName)
+	}
+	return fmt.Sprintf("%s/%s/partitions/%s", allocatorRoot, serviceName, options.Partition)
+}
+
+// leaseTTLFunc 在 timeout 内查询租约剩余时间，租约已不存在时返回非正值
+type leaseTTLFunc func(timeout time.Duration) (time.Duration, error)
+
+// expiryNotifier 管理 OnExpiring 注册的回调，第一次注册时启动后台监控
+type expiryNotifier struct {
+	mu        sync.Mutex
+	callbacks []func()
+	started   bool
+	stop      chan struct{}
+	stopOnce  sync.Once
+}
+
+// newExpiryNotifier 创建回调管理器
+func newExpiryNotifier() *expiryNotifier {
+	return &expiryNotifier{stop: make(chan struct{})}
+}
+
+// add 注册回调，第一次注册时调用 start 启动监控；已停止时忽略
+func (n *expiryNotifier) add(fn func(), start func(stop <-chan struct{})) {
+	if fn == nil {
+		return
+	}
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	select {
+	case <-n.stop:
+		return
+	default:
+	}
+	n.callbacks = append(n.callbacks, fn)
+	if !n.started {
+		n.started = true
+		go start(n.stop)
+	}
+}
+
+// notify 在独立的 goroutine 中依次执行所有回调
+func (n *expiryNotifier) notify() {
+	n.mu.Lock()
+	callbacks := append([]func(){}, n.callbacks...)
+	n.mu.Unlock()
+	go func() {
+		for _, fn := range callbacks {
+			fn()
+		}
+	}()
+}
+
+// close 停止监控，幂等
+func (n *expiryNotifier) close() {
+	n.stopOnce.Do(func() {
+		close(n.stop)
+	})
+}
+
+// watchExpiry 定期查询租约剩余时间，不超过 margin 时调用 fire，直到 stop 关闭或租约已不存在
+// 查询失败（如与 etcd 断开）时按上次成功查询推算的过期时间判断；剩余时间恢复到 margin 以上后重新启用回调
+func watchExpiry(stop <-chan struct{}, ttl time.Duration, margin time.Duration, leaseTTL leaseTTLFunc, fire func(remaining time.Duration)) {
+	interval := min(max(margin/4, minExpiryCheckInterval), keepAliveInterval)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	expiry := time.Now().Add(ttl)
+	fired := false
+	for {
+		remaining, err := leaseTTL(interval)
+		if err == nil {
+			if remaining <= 0 {
+				return
+			}
+			expiry = time.Now().Add(remaining)
+		} else {
+			remaining = time.Until(expiry)
+		}
+
+		if remaining > margin {
+			fired = false
+		} else if !fired {
+			fired = true
+			fire(remaining)
+		}
+
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,304 @@
+package allocatorimpl
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/ceyewan/infra-kit/clog"
+	"github.com/ceyewan/infra-kit/coord/allocator"
+	"github.com/ceyewan/infra-kit/coord/internal/memstore"
+)
+
+// memoryInstanceIDAllocator 基于进程内存储的实例 ID 分配器，仅用于测试
+// 与 etcd 实现一样，所有 ID 绑定到分配器的会话上，Close 时全部释放
+type memoryInstanceIDAllocator struct {
+	store     *memstore.Store
+	minID     int
+	maxID     int
+	leaseTTL  time.Duration
+	margin    time.Duration // 触发 OnExpiring 回调的租约剩余时间
+	logger    clog.Logger
+	basePath  string
+	queuePath string       // WaitAcquireID 的排队路径
+	history   string       // 分配历史路径，记录分配过的 ID，不绑定租约
+	waitSeq   atomic.Int64 // 排队键序号
+
+	mu      sync.Mutex
+	session *memstore.Session
+	closed  bool
+}
+
+var _ allocator.InstanceIDAllocator = (*memoryInstanceIDAllocator)(nil)
+
+// NewMemoryInstanceIDAllocatorRange 创建在 minID..maxID（闭区间）范围内分配的内存实例 ID 分配器
+func NewMemoryInstanceIDAllocatorRange(store *memstore.Store, serviceName string, minID, maxID int, logger clog.Logger, opts ...allocator.Option) (allocator.InstanceIDAllocator, error) {
+	if store == nil {
+		return nil, fmt.Errorf("[VALIDATION_ERROR] store cannot be nil")
+	}
+	if serviceName == "" {
+		return nil, fmt.Errorf("[VALIDATION_ERROR] service name cannot be empty")
+	}
+	if minID < 0 || maxID < 0 {
+		return nil, fmt.Errorf("[VALIDATION_ERROR] ID range bounds must be non-negative")
+	}
+	if minID > maxID {
+		return nil, fmt.Errorf("[VALIDATION_ERROR] min ID %d must not exceed max ID %d", minID, maxID)
+	}
+	if logger == nil {
+		return nil, fmt.Errorf("[VALIDATION_ERROR] logger cannot be nil")
+	}
+	options := allocator.ParseOptions(opts...)
+	if options.LeaseTTL < allocator.MinLeaseTTL {
+		return nil, fmt.Errorf("[VALIDATION_ERROR] lease TTL %v must be at least %v", options.LeaseTTL, allocator.MinLeaseTTL)
+	}
+	if err := validateExpiryMargin(options); err != nil {
+		return nil, err
+	}
+	if err := validatePartition(options); err != nil {
+		return nil, err
+	}
+	if options.Partition != "" {
+		logger = logger.With(clog.String("partition", options.Partition))
+	}
+	base := allocatorPath(serviceName, options)
+
+	session, err := memstore.NewSession(store, options.LeaseTTL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize allocator session: %w", err)
+	}
+
+	return &memoryInstanceIDAllocator{
+		store:     store,
+		minID:     minID,
+		maxID:     maxID,
+		leaseTTL:  options.LeaseTTL,
+		margin:    options.Margin(),
+		logger:    logger.With(clog.String("service", serviceName)),
+		basePath:  base + "/ids",
+		queuePath: base + "/queue",
+		history:   base + "/history",
+		session:   session,
+	}, nil
+}
+
+// AcquireID 从范围下界开始获取第一个未被占用的 ID
+func (a *memoryInstanceIDAllocator) AcquireID(ctx context.Context) (allocator.AllocatedID, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.closed {
+		return nil, fmt.Errorf("allocator is closed")
+	}
+
+	for id := a.minID; id <= a.maxID; id++ {
+		key := fmt.Sprintf("%s/%d", a.basePath, id)
+		historyKey := fmt.Sprintf("%s/%d", a.history, id)
+		acquired, reused := false, false
+		err := a.store.Txn(func(tx *memstore.Txn) error {
+			if _, occupied := tx.Get(key); occupied {
+				return nil
+			}
+			if err := tx.Put(key, []byte(strconv.Itoa(id)), a.session.Lease()); err != nil {
+				return err
+			}
+			acquired = true
+			_, reused = tx.Get(historyKey)
+			return tx.Put(historyKey, []byte(strconv.Itoa(id)), 0)
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to acquire ID %d: %w", id, err)
+		}
+		if acquired {
+			a.logger.Info("ID acquired", clog.Int("id", id), clog.Bool("reused", reused))
+			return &memoryAllocatedID{
+				id:        id,
+				reused:    reused,
+				key:       key,
+				store:     a.store,
+				lease:     a.session.Lease(),
+				allocator: a,
+				logger:    a.logger.With(clog.Int("id", id)),
+				expiring:  newExpiryNotifier(),
+			}, nil
+		}
+	}
+
+	return nil, fmt.Errorf("%w (range: %d-%d)", allocator.ErrExhausted, a.minID, a.maxID)
+}
+
+// WaitAcquireID 获取一个实例 ID，ID 耗尽时排队等待，排队语义与 etcd 实现一致
+func (a *memoryInstanceIDAllocator) WaitAcquireID(ctx context.Context) (allocator.AllocatedID, error) {
+	a.mu.Lock()
+	if a.closed {
+		a.mu.Unlock()
+		return nil, fmt.Errorf("allocator is closed")
+	}
+	lease := a.session.Lease()
+	a.mu.Unlock()
+
+	queueKey := fmt.Sprintf("%s/%x-%d", a.queuePath, lease, a.waitSeq.Add(1))
+	createRev, err := a.store.Put(queueKey, nil, lease)
+	if err != nil {
+		return nil, fmt.Errorf("failed to join allocator queue: %w", err)
+	}
+	defer func() {
+		_, _ = a.store.Delete(queueKey)
+	}()
+
+	if err := a.waitQueueTurn(ctx, createRev); err != nil {
+		return nil, err
+	}
+
+	for {
+		// 先建立监听再扫描，避免错过扫描期间的释放
+		watchCtx, cancel := context.WithCancel(ctx)
+		events := a.store.Watch(watchCtx, a.basePath+"/", true)
+
+		id, err := a.AcquireID(ctx)
+		if err == nil || !errors.Is(err, allocator.ErrExhausted) {
+			cancel()
+			return id, err
+		}
+
+		released := waitForDelete(events)
+		cancel()
+		if !released {
+			return nil, waitAborted(ctx)
+		}
+	}
+}
+
+// waitQueueTurn 等待所有排在前面（创建修订号更小）的等待者出队
+func (a *memoryInstanceIDAllocator) waitQueueTurn(ctx context.Context, createRev int64) error {
+	for {
+		var predecessor *memstore.KeyValue
+		for _, kv := range a.store.GetPrefix(a.queuePath + "/") {
+			if kv.CreateRevision < createRev && (predecessor == nil || kv.CreateRevision > predecessor.CreateRevision) {
+				kv := kv
+				predecessor = &kv
+			}
+		}
+		if predecessor == nil {
+			return nil
+		}
+
+		watchCtx, cancel := context.WithCancel(ctx)
+		events := a.store.Watch(watchCtx, predecessor.Key, false)
+		if _, ok := a.store.Get(predecessor.Key); !ok {
+			cancel()
+			continue
+		}
+		released := waitForDelete(events)
+		cancel()
+		if !released {
+			return waitAborted(ctx)
+		}
+	}
+}
+
+// waitForDelete 等待删除事件，监听结束（context 取消或存储关闭）时返回 false
+func waitForDelete(events <-chan memstore.Event) bool {
+	for event := range events {
+		if event.Type == memstore.EventDelete {
+			return true
+		}
+	}
+	return false
+}
+
+// waitAborted 返回等待中止的原因
+func waitAborted(ctx context.Context) error {
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	return fmt.Errorf("allocator store closed: %w", memstore.ErrClosed)
+}
+
+// Close 关闭分配器，释放所有已分配的 ID
+func (a *memoryInstanceIDAllocator) Close() error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.closed {
+		return nil
+	}
+	a.closed = true
+	a.logger.Info("allocator closed")
+	return a.session.Close()
+}
+
+// Health 健康检查
+func (a *memoryInstanceIDAllocator) Health(ctx context.Context) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.closed {
+		return fmt.Errorf("[HEALTH_CHECK_FAILED] allocator is closed")
+	}
+	select {
+	case <-a.session.Done():
+		return fmt.Errorf("[HEALTH_CHECK_FAILED] session has expired")
+	default:
+	}
+	return nil
+}
+
+// memoryAllocatedID 内存分配器分配的 ID
+type memoryAllocatedID struct {
+	id        int
+	reused    bool // 该 ID 此前是否被分配过
+	key       string
+	store     *memstore.Store
+	lease     memstore.LeaseID
+	allocator *memoryInstanceIDAllocator
+	logger    clog.Logger
+	expiring  *expiryNotifier // OnExpiring 注册的回调
+	closeOnce sync.Once
+}
+
+var _ allocator.AllocatedID = (*memoryAllocatedID)(nil)
+
+// ID 返回分配的 ID
+func (id *memoryAllocatedID) ID() int {
+	return id.id
+}
+
+// IsReused 返回该 ID 此前是否被分配过
+func (id *memoryAllocatedID) IsReused() bool {
+	return id.reused
+}
+
+// OnExpiring 注册租约即将过期的回调，第一次注册时启动对租约剩余时间的监控
+func (id *memoryAllocatedID) OnExpiring(fn func()) {
+	id.expiring.add(fn, func(stop <-chan struct{}) {
+		watchExpiry(stop, id.allocator.leaseTTL, id.allocator.margin, id.timeToLive, func(remaining time.Duration) {
+			id.logger.Warn("ID lease is about to expire, renewal is failing", clog.Duration("remaining", remaining))
+			id.expiring.notify()
+		})
+	})
+}
+
+// timeToLive 查询持有 ID 的租约剩余时间，租约已撤销或过期时返回 0
+func (id *memoryAllocatedID) timeToLive(time.Duration) (time.Duration, error) {
+	remaining, err := id.store.TimeToLive(id.lease)
+	if errors.Is(err, memstore.ErrLeaseNotFound) {
+		return 0, nil
+	}
+	return remaining, err
+}
+
+// Close 释放 ID，幂等
+func (id *memoryAllocatedID) Close(ctx context.Context) error {
+	var err error
+	id.closeOnce.Do(func() {
+		if _, deleteErr := id.store.Delete(id.key); deleteErr != nil {
+			err = fmt.Errorf("failed to release ID %d: %w", id.id, deleteErr)
+			return
+		}
+		id.expiring.close()
+		id.logger.Info("ID released")
+	})
+	return err
+}
@@ -2,9 +2,14 @@ package client
 
 import (
 	"context"
+	"crypto/tls"
+	"crypto/x509"
 	"fmt"
 	"net"
+	"os"
+	"slices"
 	"strconv"
+	"sync"
 	"time"
 
 	"errors"
@@ -12,6 +17,7 @@ import (
 	"github.com/ceyewan/infra-kit/clog"
 	"go.etcd.io/etcd/api/v3/v3rpc/rpctypes"
 	clientv3 "go.etcd.io/etcd/client/v3"
+	"google.golang.org/grpc"
 )
 
 // ============================================================================
@@ -32,9 +38,23 @@ type Config struct {
 	// Timeout 连接超时时间
 	Timeout time.Duration `json:"timeout"`
 
+	// OperationTimeout 单次一元请求的默认超时时间，仅在 context 没有截止时间时生效，0 表示不设置
+	OperationTimeout time.Duration `json:"operation_timeout,omitempty"`
+
 	// RetryConfig 重试配置
 	RetryConfig *RetryConfig `json:"retry_config,omitempty"`
 
+	// TLS TLS/mTLS 连接配置（可选）
+	TLS *TLSConfig `json:"tls,omitempty"`
+
+	// CredentialProvider 动态凭据提供者（可选）
+	// 设置后优先于 Username/Password，认证失败时会重新获取凭据并透明地重新认证
+	CredentialProvider func() (username, password string) `json:"-"`
+
+	// Dialer 自定义底层连接的拨号函数（可选），addr 为 host:port 形式的 etcd 地址
+	// 用于经由代理连接或注入网络故障；TLS 握手在返回的连接之上进行
+	Dialer func(ctx context.Context, addr string) (net.Conn, error) `json:"-"`
+
 	// Logger 可选的日志记录器
 	Logger clog.Logger `json:"-"`
 }
@@ -54,6 +74,21 @@ type RetryConfig struct {
 	Multiplier float64 `json:"multiplier"`
 }
 
+// TLSConfig TLS 连接配置
+type TLSConfig struct {
+	// CertFile 客户端证书路径（mTLS）
+	CertFile string `json:"cert_file,omitempty"`
+
+	// KeyFile 客户端私钥路径（mTLS）
+	KeyFile string `json:"key_file,omitempty"`
+
+	// CAFile 服务端 CA 证书路径
+	CAFile string `json:"ca_file,omitempty"`
+
+	// ServerName 覆盖证书校验的服务端名称
+	ServerName string `json:"server_name,omitempty"`
+}
+
 // ============================================================================
 // 错误处理相关类型定义
 // ============================================================================
@@ -70,11 +105,35 @@ const (
 	ErrCodeUnavailable ErrorCode = "SERVICE_UNAVAILABLE"
 )
 
+// 按错误码匹配的哨兵错误，由 coord 包导出
+// errors.Is(err, ErrNotFound) 对错误链中任何错误码为 ErrCodeNotFound 的 *Error 返回 true
+var (
+	ErrConnection  error = &codeError{code: ErrCodeConnection, text: "connection error"}
+	ErrTimeout     error = &codeError{code: ErrCodeTimeout, text: "timeout"}
+	ErrNotFound    error = &codeError{code: ErrCodeNotFound, text: "not found"}
+	ErrConflict    error = &codeError{code: ErrCodeConflict, text: "conflict"}
+	ErrValidation  error = &codeError{code: ErrCodeValidation, text: "validation error"}
+	ErrUnavailable error = &codeError{code: ErrCodeUnavailable, text: "service unavailable"}
+)
+
+// codeError 代表一类错误码的哨兵错误
+type codeError struct {
+	code ErrorCode
+	text string
+}
+
+// Error 实现 error 接口
+func (e *codeError) Error() string {
+	return e.text
+}
+
 // Error 协调器错误类型
 type Error struct {
 	Code    ErrorCode `json:"code"`
 	Message string    `json:"message"`
 	Cause   error     `json:"cause,omitempty"`
+	// Kind 错误类别的哨兵错误，如 config.ErrVersionMismatch，只用于 errors.Is 匹配，不出现在错误信息中
+	Kind error `json:"-"`
 }
 
 // Error 实现 error 接口
@@ -90,6 +149,21 @@ func (e *Error) Unwrap() error {
 	return e.Cause
 }
 
+// Is 支持 errors.Is 按错误类别和错误码匹配：target 为 Kind 或与 Code 对应的哨兵错误时返回 true
+func (e *Error) Is(target error) bool {
+	if e.Kind != nil && target == e.Kind {
+		return true
+	}
+	codeErr, ok := target.(*codeError)
+	return ok && codeErr.code == e.Code
+}
+
+// WithKind 设置错误类别并返回自身，错误信息保持不变
+func (e *Error) WithKind(kind error) *Error {
+	e.Kind = kind
+	return e
+}
+
 // NewError 创建协调器错误
 func NewError(code ErrorCode, message string, cause error) *Error {
 	return &Error{
@@ -169,10 +243,20 @@ func (rc *RetryConfig) validate() error {
 // ============================================================================
 
 // EtcdClient etcd 客户端封装，提供重试机制和错误处理
+// 设置 CredentialProvider 时，认证失败会用新凭据重建连接并原子地替换，Client 返回的门面始终转发到当前连接
 type EtcdClient struct {
-	client      *clientv3.Client
-	retryConfig *RetryConfig
-	logger      clog.Logger
+	mu      sync.RWMutex
+	conn    *clientv3.Client   // 当前连接，凭据轮换时替换
+	retired []*clientv3.Client // 被替换但尚未关闭的连接，宽限期结束或 Close 时关闭
+	facade  *clientv3.Client   // Client 返回的门面，未设置 CredentialProvider 时就是 conn
+
+	rotateMu    sync.Mutex                       // 串行化连接重建
+	dial        func() (*clientv3.Client, error) // 用最新凭据创建连接
+	retireGrace time.Duration                    // 被替换的连接关闭前的宽限期，0 表示使用 defaultRetireGrace
+
+	retryConfig        *RetryConfig
+	logger             clog.Logger
+	credentialProvider func() (username, password string)
 }
 
 // New 创建新的 etcd 客户端
@@ -204,11 +288,19 @@ func New(cfg Config) (*EtcdClient, error) {
 	logger.Info("etcd client created successfully",
 		clog.Strings("endpoints", cfg.Endpoints))
 
-	return &EtcdClient{
-		client:      client,
-		retryConfig: cfg.RetryConfig,
-		logger:      logger,
-	}, nil
+	c := &EtcdClient{
+		conn:               client,
+		facade:             client,
+		dial:               func() (*clientv3.Client, error) { return createEtcdClient(cfg) },
+		retireGrace:        max(defaultRetireGrace, 2*cfg.OperationTimeout),
+		retryConfig:        cfg.RetryConfig,
+		logger:             logger,
+		credentialProvider: cfg.CredentialProvider,
+	}
+	if cfg.CredentialProvider != nil {
+		c.facade = newReauthFacade(c)
+	}
+	return c, nil
 }
 
 // createEtcdClient 创建原始的 etcd 客户端
@@ -220,6 +312,28 @@ func createEtcdClient(cfg Config) (*clientv3.Client, error) {
 		Password:    cfg.Password,
 	}
 
+	if cfg.CredentialProvider != nil {
+		config.Username, config.Password = cfg.CredentialProvider()
+	}
+
+	if cfg.TLS != nil {
+		tlsConfig, err := buildTLSConfig(cfg.TLS)
+		if err != nil {
+			return nil, err
+		}
+		config.TLS = tlsConfig
+	}
+
+	if cfg.Dialer != nil {
+		// DialOptions 追加在 etcd 默认拨号选项之后，会覆盖默认的拨号函数
+		config.DialOptions = append(config.DialOptions, grpc.WithContextDialer(cfg.Dialer))
+	}
+
+	if cfg.OperationTimeout > 0 {
+		// 使用链式拦截器，不覆盖 etcd 自身的重试拦截器；监听、续约等流式请求不受影响
+		config.DialOptions = append(config.DialOptions, grpc.WithChainUnaryInterceptor(operationTimeoutInterceptor(cfg.OperationTimeout)))
+	}
+
 	client, err := clientv3.New(config)
 	if err != nil {
 		return nil, NewError(ErrCodeConnection, "failed to create etcd client", err)
@@ -228,6 +342,49 @@ func createEtcdClient(cfg Config) (*clientv3.Client, error) {
 	return client, nil
 }
 
+// operationTimeoutInterceptor 为没有截止时间的一元请求设置默认超时，避免 etcd 无响应时调用方永久阻塞
+// 调用方 context 已有截止时间时保持不变，无论它比默认超时更长还是更短
+func operationTimeoutInterceptor(timeout time.Duration) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		if _, ok := ctx.Deadline(); !ok {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, timeout)
+			defer cancel()
+		}
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
+}
+
+// buildTLSConfig 根据证书文件构建 tls.Config
+func buildTLSConfig(cfg *TLSConfig) (*tls.Config, error) {
+	tlsConfig := &tls.Config{
+		ServerName: cfg.ServerName,
+		MinVersion: tls.VersionTLS12,
+	}
+
+	if cfg.CAFile != "" {
+		caData, err := os.ReadFile(cfg.CAFile)
+		if err != nil {
+			return nil, NewError(ErrCodeValidation, "failed to read tls ca file", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(caData) {
+			return nil, NewError(ErrCodeValidation, "tls ca file contains no valid certificates", nil)
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	if cfg.CertFile != "" || cfg.KeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(cfg.CertFile, cfg.KeyFile)
+		if err != nil {
+			return nil, NewError(ErrCodeValidation, "failed to load tls client certificate", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	return tlsConfig, nil
+}
+
 // testConnection 测试 etcd 连接
 func testConnection(client *clientv3.Client, cfg Config) error {
 	ctx, cancel := context.WithTimeout(context.Background(), cfg.Timeout)
@@ -244,20 +401,42 @@ func testConnection(client *clientv3.Client, cfg Config) error {
 // 客户端基础方法
 // ============================================================================
 
-// Client 获取原始的 etcd 客户端
+// Client 获取 etcd 客户端，供会话、分配器等需要 *clientv3.Client 的代码使用
+// 设置 CredentialProvider 时返回门面：KV、Lease、Watcher 和 Cluster 调用转发到当前连接，认证失败时透明地重新认证；
+// 门面没有自己的连接，Sync、Status 等直接依赖连接的方法不可用
 func (c *EtcdClient) Client() *clientv3.Client {
-	return c.client
+	return c.facade
 }
 
-// Close 关闭客户端连接
+// current 返回当前连接
+func (c *EtcdClient) current() *clientv3.Client {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.conn
+}
+
+// Close 关闭客户端连接，包括凭据轮换时被替换的连接
 func (c *EtcdClient) Close() error {
-	if c.client == nil {
+	c.mu.Lock()
+	conns := append([]*clientv3.Client{c.conn}, c.retired...)
+	c.retired = nil
+	c.mu.Unlock()
+	if conns[0] == nil {
 		return nil
 	}
 
-	if err := c.client.Close(); err != nil {
-		c.logger.Error("failed to close etcd client", clog.Err(err))
-		return NewError(ErrCodeConnection, "failed to close etcd client", err)
+	if c.facade != conns[0] {
+		_ = c.facade.Close() // 取消门面的 context，基于门面的会话随之停止续约
+	}
+	var closeErr error
+	for _, conn := range conns {
+		if err := conn.Close(); err != nil && closeErr == nil {
+			closeErr = err
+		}
+	}
+	if closeErr != nil {
+		c.logger.Error("failed to close etcd client", clog.Err(closeErr))
+		return NewError(ErrCodeConnection, "failed to close etcd client", closeErr)
 	}
 
 	c.logger.Info("etcd client closed successfully")
@@ -266,21 +445,39 @@ func (c *EtcdClient) Close() error {
 
 // Ping 检查 etcd 连接状态
 func (c *EtcdClient) Ping(ctx context.Context) error {
-	return c.executeWithRetry(ctx, func() error {
+	return c.executeWithRetry(ctx, func(cli *clientv3.Client) error {
 		// client.Sync() 会与集群的一个健康节点同步 revision，是更可靠的健康检查
-		if err := c.client.Sync(ctx); err != nil {
+		if err := cli.Sync(ctx); err != nil {
 			return NewError(ErrCodeConnection, "etcd ping failed", err)
 		}
 		return nil
 	})
 }
 
+// MemberList 获取 etcd 集群的成员列表
+func (c *EtcdClient) MemberList(ctx context.Context) (*clientv3.MemberListResponse, error) {
+	var resp *clientv3.MemberListResponse
+	err := c.executeWithRetry(ctx, func(cli *clientv3.Client) error {
+		var err error
+		resp, err = cli.MemberList(ctx)
+		if err != nil {
+			return NewError(ErrCodeConnection, "etcd member list failed", err)
+		}
+		return nil
+	})
+	return resp, err
+}
+
 // ============================================================================
 // 重试机制实现
 // ============================================================================
 
-// executeWithRetry 执行带重试的操作
-func (c *EtcdClient) executeWithRetry(ctx context.Context, operation func() error) error {
+// executeWithRetry 在当前连接上执行带重试的操作，每次尝试都会在认证失败时重新认证
+func (c *EtcdClient) executeWithRetry(ctx context.Context, op func(cli *clientv3.Client) error) error {
+	operation := func() error {
+		return c.withReauthentication(op)
+	}
+
 	if c.retryConfig == nil || c.retryConfig.MaxAttempts <= 1 {
 		return operation()
 	}
@@ -328,6 +525,81 @@ func (c *EtcdClient) executeWithRetry(ctx context.Context, operation func() erro
 	return lastErr
 }
 
+// withReauthentication 在当前连接上执行操作，认证失败时用新凭据重建连接并重试一次
+// clientv3.Client 的 Username/Password 会被其内部无锁读取，不能原地修改，因此轮换凭据必须重建连接
+func (c *EtcdClient) withReauthentication(op func(cli *clientv3.Client) error) error {
+	cli := c.current()
+	err := op(cli)
+	if err == nil || c.credentialProvider == nil || !isAuthError(err) {
+		return err
+	}
+
+	c.logger.Warn("etcd authentication failed, reconnecting with refreshed credentials", clog.Err(err))
+	next, rotateErr := c.rotate(cli)
+	if rotateErr != nil {
+		c.logger.Error("failed to reconnect with refreshed credentials", clog.Err(rotateErr))
+		return err
+	}
+	return op(next)
+}
+
+// defaultRetireGrace 被替换的连接关闭前的默认宽限期，实际宽限期不短于两倍的单次请求超时
+const defaultRetireGrace = time.Minute
+
+// rotate 用凭据提供者的最新凭据创建新连接并替换 stale
+// 并发的认证失败只重建一次：stale 已被其他调用替换时直接返回当前连接
+// 被替换的连接在宽限期结束后关闭，其上进行中的一元请求在此之前自然结束；
+// 门面上的监听和续约在旧连接关闭后自动转到当前连接继续
+func (c *EtcdClient) rotate(stale *clientv3.Client) (*clientv3.Client, error) {
+	c.rotateMu.Lock()
+	defer c.rotateMu.Unlock()
+	if cli := c.current(); cli != stale {
+		return cli, nil
+	}
+
+	next, err := c.dial()
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	c.retired = append(c.retired, stale)
+	c.conn = next
+	c.mu.Unlock()
+
+	grace := c.retireGrace
+	if grace <= 0 {
+		grace = defaultRetireGrace
+	}
+	time.AfterFunc(grace, func() { c.closeRetired(stale) })
+	return next, nil
+}
+
+// closeRetired 关闭宽限期已结束的连接，Close 已将其关闭时不做任何事
+func (c *EtcdClient) closeRetired(conn *clientv3.Client) {
+	c.mu.Lock()
+	idx := slices.Index(c.retired, conn)
+	if idx >= 0 {
+		c.retired = slices.Delete(c.retired, idx, idx+1)
+	}
+	c.mu.Unlock()
+	if idx < 0 {
+		return
+	}
+
+	if err := conn.Close(); err != nil {
+		c.logger.Warn("failed to close retired etcd connection", clog.Err(err))
+	}
+}
+
+// isAuthError 检查是否为认证相关错误
+func isAuthError(err error) bool {
+	return errors.Is(err, rpctypes.ErrAuthFailed) ||
+		errors.Is(err, rpctypes.ErrInvalidAuthToken) ||
+		errors.Is(err, rpctypes.ErrAuthOldRevision) ||
+		errors.Is(err, rpctypes.ErrUserEmpty)
+}
+
 // waitForRetry 等待重试延迟
 func (c *EtcdClient) waitForRetry(ctx context.Context, delay time.Duration) error {
 	timer := time.NewTimer(delay)
@@ -366,9 +638,9 @@ func (c *EtcdClient) shouldNotRetry(err error) bool {
 // Put 设置键值对
 func (c *EtcdClient) Put(ctx context.Context, key, value string, cfg ...clientv3.OpOption) (*clientv3.PutResponse, error) {
 	var resp *clientv3.PutResponse
-	err := c.executeWithRetry(ctx, func() error {
+	err := c.executeWithRetry(ctx, func(cli *clientv3.Client) error {
 		var err error
-		resp, err = c.client.Put(ctx, key, value, cfg...)
+		resp, err = cli.Put(ctx, key, value, cfg...)
 		if err != nil {
 			return NewError(ErrCodeConnection, "etcd put operation failed", err)
 		}
@@ -380,9 +652,9 @@ func (c *EtcdClient) Put(ctx context.Context, key, value string, cfg ...clientv3
 // Get 获取键值对
 func (c *EtcdClient) Get(ctx context.Context, key string, cfg ...clientv3.OpOption) (*clientv3.GetResponse, error) {
 	var resp *clientv3.GetResponse
-	err := c.executeWithRetry(ctx, func() error {
+	err := c.executeWithRetry(ctx, func(cli *clientv3.Client) error {
 		var err error
-		resp, err = c.client.Get(ctx, key, cfg...)
+		resp, err = cli.Get(ctx, key, cfg...)
 		if err != nil {
 			return NewError(ErrCodeConnection, "etcd get operation failed", err)
 		}
@@ -394,9 +666,9 @@ func (c *EtcdClient) Get(ctx context.Context, key string, cfg ...clientv3.OpOpti
 // Delete 删除键值对
 func (c *EtcdClient) Delete(ctx context.Context, key string, cfg ...clientv3.OpOption) (*clientv3.DeleteResponse, error) {
 	var resp *clientv3.DeleteResponse
-	err := c.executeWithRetry(ctx, func() error {
+	err := c.executeWithRetry(ctx, func(cli *clientv3.Client) error {
 		var err error
-		resp, err = c.client.Delete(ctx, key, cfg...)
+		resp, err = cli.Delete(ctx, key, cfg...)
 		if err != nil {
 			return NewError(ErrCodeConnection, "etcd delete operation failed", err)
 		}
@@ -405,14 +677,14 @@ func (c *EtcdClient) Delete(ctx context.Context, key string, cfg ...clientv3.OpO
 	return resp, err
 }
 
-// Watch 监听键变化（不需要重试机制）
+// Watch 监听键变化（不需要重试机制），认证失败导致监听取消时在新连接上续传
 func (c *EtcdClient) Watch(ctx context.Context, key string, cfg ...clientv3.OpOption) clientv3.WatchChan {
-	return c.client.Watch(ctx, key, cfg...)
+	return c.facade.Watch(ctx, key, cfg...)
 }
 
-// Txn 创建事务（用于 CAS 操作）
+// Txn 创建事务（用于 CAS 操作），提交时认证失败会重新认证后重新提交
 func (c *EtcdClient) Txn(ctx context.Context) clientv3.Txn {
-	return c.client.Txn(ctx)
+	return c.facade.Txn(ctx)
 }
 
 // ============================================================================
@@ -422,9 +694,9 @@ func (c *EtcdClient) Txn(ctx context.Context) clientv3.Txn {
 // Grant 创建租约
 func (c *EtcdClient) Grant(ctx context.Context, ttl int64) (*clientv3.LeaseGrantResponse, error) {
 	var resp *clientv3.LeaseGrantResponse
-	err := c.executeWithRetry(ctx, func() error {
+	err := c.executeWithRetry(ctx, func(cli *clientv3.Client) error {
 		var err error
-		resp, err = c.client.Grant(ctx, ttl)
+		resp, err = cli.Grant(ctx, ttl)
 		if err != nil {
 			return NewError(ErrCodeConnection, "etcd grant operation failed", err)
 		}
@@ -435,7 +707,7 @@ func (c *EtcdClient) Grant(ctx context.Context, ttl int64) (*clientv3.LeaseGrant
 
 // KeepAlive 保持租约活跃（不需要重试机制）
 func (c *EtcdClient) KeepAlive(ctx context.Context, id clientv3.LeaseID) (<-chan *clientv3.LeaseKeepAliveResponse, error) {
-	ch, err := c.client.KeepAlive(ctx, id)
+	ch, err := c.facade.KeepAlive(ctx, id)
 	if err != nil {
 		return nil, NewError(ErrCodeConnection, "etcd keep alive failed", err)
 	}
@@ -445,9 +717,9 @@ func (c *EtcdClient) KeepAlive(ctx context.Context, id clientv3.LeaseID) (<-chan
 // Revoke 撤销租约
 func (c *EtcdClient) Revoke(ctx context.Context, id clientv3.LeaseID) (*clientv3.LeaseRevokeResponse, error) {
 	var resp *clientv3.LeaseRevokeResponse
-	err := c.executeWithRetry(ctx, func() error {
+	err := c.executeWithRetry(ctx, func(cli *clientv3.Client) error {
 		var err error
-		resp, err = c.client.Revoke(ctx, id)
+		resp, err = cli.Revoke(ctx, id)
 		if err != nil {
 			// 如果租约不存在，这是正常情况，不需要重试
 			if c.isLeaseNotFoundError(err) {
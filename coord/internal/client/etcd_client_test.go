@@ -2,15 +2,20 @@ package client
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"net"
 	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/ceyewan/infra-kit/clog"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"go.etcd.io/etcd/api/v3/v3rpc/rpctypes"
 	clientv3 "go.etcd.io/etcd/client/v3"
+	"google.golang.org/grpc"
 )
 
 // TestEtcdClient_New 测试etcd客户端创建
@@ -85,6 +90,37 @@ func TestEtcdClient_New(t *testing.T) {
 	})
 }
 
+// TestOperationTimeoutInterceptor 测试默认请求超时只作用于没有截止时间的 context
+func TestOperationTimeoutInterceptor(t *testing.T) {
+	interceptor := operationTimeoutInterceptor(time.Second)
+	deadlineOf := func(ctx context.Context) (time.Time, bool) {
+		var deadline time.Time
+		var ok bool
+		err := interceptor(ctx, "/etcdserverpb.KV/Range", nil, nil, nil,
+			func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+				deadline, ok = ctx.Deadline()
+				return nil
+			})
+		require.NoError(t, err)
+		return deadline, ok
+	}
+
+	// 没有截止时间时设置默认超时
+	deadline, ok := deadlineOf(context.Background())
+	require.True(t, ok)
+	assert.WithinDuration(t, time.Now().Add(time.Second), deadline, 100*time.Millisecond)
+
+	// 调用方的截止时间优先，无论长短
+	for _, timeout := range []time.Duration{100 * time.Millisecond, time.Minute} {
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		want, _ := ctx.Deadline()
+		deadline, ok = deadlineOf(ctx)
+		cancel()
+		require.True(t, ok)
+		assert.Equal(t, want, deadline)
+	}
+}
+
 // TestEtcdClient_Validation 测试配置验证
 func TestEtcdClient_Validation(t *testing.T) {
 	testCases := []struct {
@@ -288,6 +324,25 @@ func TestEtcdClient_Ping(t *testing.T) {
 		assert.NoError(t, err)
 	})
 
+	t.Run("ping through custom dialer", func(t *testing.T) {
+		var dialed atomic.Int32
+		dialerClient, err := New(Config{
+			Endpoints: []string{"localhost:2379"},
+			Timeout:   time.Second * 5,
+			Logger:    clog.Namespace("test"),
+			Dialer: func(ctx context.Context, addr string) (net.Conn, error) {
+				dialed.Add(1)
+				var d net.Dialer
+				return d.DialContext(ctx, "tcp", addr)
+			},
+		})
+		require.NoError(t, err)
+		defer dialerClient.Close()
+
+		assert.NoError(t, dialerClient.Ping(ctx))
+		assert.Positive(t, dialed.Load())
+	})
+
 	t.Run("ping with timeout", func(t *testing.T) {
 		timeoutCtx, cancel := context.WithTimeout(ctx, time.Millisecond*100)
 		defer cancel()
@@ -447,6 +502,120 @@ func TestEtcdClient_ErrorHandling(t *testing.T) {
 	})
 }
 
+// TestError_Is 测试错误按错误码和错误类别匹配哨兵错误
+func TestError_Is(t *testing.T) {
+	kind := errors.New("version mismatch")
+	err := fmt.Errorf("update failed: %w", NewError(ErrCodeConflict, "config version mismatch", nil).WithKind(kind))
+
+	assert.ErrorIs(t, err, ErrConflict)
+	assert.ErrorIs(t, err, kind)
+	assert.NotErrorIs(t, err, ErrNotFound)
+	assert.Equal(t, "update failed: [CONFLICT] config version mismatch", err.Error())
+
+	var coordErr *Error
+	require.ErrorAs(t, err, &coordErr)
+	assert.Equal(t, ErrCodeConflict, coordErr.Code)
+
+	// 错误链中的 Cause 也参与匹配
+	wrapped := NewError(ErrCodeConnection, "failed to put key", NewError(ErrCodeNotFound, "key not found", nil))
+	assert.ErrorIs(t, wrapped, ErrConnection)
+	assert.ErrorIs(t, wrapped, ErrNotFound)
+}
+
+// TestEtcdClient_Reauthentication 测试认证失败后用新凭据重建连接并重试
+func TestEtcdClient_Reauthentication(t *testing.T) {
+	initial := clientv3.NewCtxClient(context.Background())
+	var dialed []*clientv3.Client
+	c := &EtcdClient{
+		conn:   initial,
+		logger: clog.Namespace("test"),
+		dial: func() (*clientv3.Client, error) {
+			cli := clientv3.NewCtxClient(context.Background())
+			dialed = append(dialed, cli)
+			return cli, nil
+		},
+		credentialProvider: func() (string, string) { return "user", "password" },
+	}
+	c.facade = newReauthFacade(c)
+	defer c.Close()
+
+	t.Run("auth error swaps connection and retries", func(t *testing.T) {
+		var used []*clientv3.Client
+		err := c.executeWithRetry(context.Background(), func(cli *clientv3.Client) error {
+			used = append(used, cli)
+			if len(used) == 1 {
+				return NewError(ErrCodeConnection, "etcd get operation failed", rpctypes.ErrInvalidAuthToken)
+			}
+			return nil
+		})
+		assert.NoError(t, err)
+		require.Len(t, dialed, 1)
+		assert.Equal(t, []*clientv3.Client{initial, dialed[0]}, used)
+		assert.Same(t, dialed[0], c.current())
+		assert.Equal(t, []*clientv3.Client{initial}, c.retired)
+	})
+
+	t.Run("stale connection is not rotated twice", func(t *testing.T) {
+		next, err := c.rotate(initial)
+		assert.NoError(t, err)
+		assert.Same(t, dialed[0], next)
+		assert.Len(t, dialed, 1)
+	})
+
+	t.Run("non-auth error is not retried", func(t *testing.T) {
+		calls := 0
+		err := c.executeWithRetry(context.Background(), func(cli *clientv3.Client) error {
+			calls++
+			return NewError(ErrCodeConnection, "etcd get operation failed", fmt.Errorf("boom"))
+		})
+		assert.Error(t, err)
+		assert.Equal(t, 1, calls)
+		assert.Len(t, dialed, 1)
+	})
+
+	t.Run("facade is stable across rotations", func(t *testing.T) {
+		assert.Same(t, c.facade, c.Client())
+		assert.NotSame(t, initial, c.Client())
+	})
+}
+
+// TestEtcdClient_RetiredConnections 测试被替换的连接在宽限期后关闭，retired 不会随轮换无限增长
+func TestEtcdClient_RetiredConnections(t *testing.T) {
+	initial := clientv3.NewCtxClient(context.Background())
+	dialed := []*clientv3.Client{initial}
+	c := &EtcdClient{
+		conn:   initial,
+		logger: clog.Namespace("test"),
+		dial: func() (*clientv3.Client, error) {
+			cli := clientv3.NewCtxClient(context.Background())
+			dialed = append(dialed, cli)
+			return cli, nil
+		},
+		retireGrace:        20 * time.Millisecond,
+		credentialProvider: func() (string, string) { return "user", "password" },
+	}
+	c.facade = newReauthFacade(c)
+	defer c.Close()
+
+	for i := 0; i < 5; i++ {
+		_, err := c.rotate(c.current())
+		require.NoError(t, err)
+	}
+	require.Len(t, dialed, 6)
+
+	assert.Eventually(t, func() bool {
+		c.mu.RLock()
+		defer c.mu.RUnlock()
+		return len(c.retired) == 0
+	}, time.Second, 10*time.Millisecond)
+
+	// 被替换的连接均已关闭，当前连接保持打开
+	for _, cli := range dialed[:5] {
+		assert.Error(t, cli.Ctx().Err())
+	}
+	assert.NoError(t, c.current().Ctx().Err())
+}
+
 // TestEtcdClient_ConcurrentOperations 测试并发操作
 func TestEtcdClient_ConcurrentOperations(t *testing.T) {
 	config := Config{
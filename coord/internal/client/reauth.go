@@ -0,0 +1,294 @@
+package client
+
+import (
+	"context"
+
+	"github.com/ceyewan/infra-kit/clog"
+	clientv3 "go.etcd.io/etcd/client/v3"
+)
+
+// newReauthFacade 创建 EtcdClient.Client 返回的门面
+// concurrency 会话、Mutex、Election 和分配器都只通过 KV、Lease、Watcher 和 Cluster 接口访问 etcd，
+// 门面将这些接口转发到当前连接并在认证失败时重新认证，因此所有调用路径都能透明地完成凭据轮换；
+// Auth 和 Maintenance 固定使用创建时的连接，不参与轮换
+func newReauthFacade(c *EtcdClient) *clientv3.Client {
+	conn := c.current()
+	facade := clientv3.NewCtxClient(context.Background())
+	facade.KV = reauthKV{c}
+	facade.Lease = reauthLease{c}
+	facade.Watcher = reauthWatcher{c}
+	facade.Cluster = reauthCluster{c}
+	facade.Auth = conn.Auth
+	facade.Maintenance = conn.Maintenance
+	return facade
+}
+
+// reauthCall 在当前连接上执行 fn，认证失败时重新认证后重试一次
+func reauthCall[T any](c *EtcdClient, fn func(cli *clientv3.Client) (T, error)) (T, error) {
+	var resp T
+	err := c.withReauthentication(func(cli *clientv3.Client) error {
+		var err error
+		resp, err = fn(cli)
+		return err
+	})
+	return resp, err
+}
+
+// reauthKV 转发到当前连接的 clientv3.KV
+type reauthKV struct{ c *EtcdClient }
+
+func (kv reauthKV) Put(ctx context.Context, key, val string, opts ...clientv3.OpOption) (*clientv3.PutResponse, error) {
+	return reauthCall(kv.c, func(cli *clientv3.Client) (*clientv3.PutResponse, error) {
+		return cli.Put(ctx, key, val, opts...)
+	})
+}
+
+func (kv reauthKV) Get(ctx context.Context, key string, opts ...clientv3.OpOption) (*clientv3.GetResponse, error) {
+	return reauthCall(kv.c, func(cli *clientv3.Client) (*clientv3.GetResponse, error) {
+		return cli.Get(ctx, key, opts...)
+	})
+}
+
+func (kv reauthKV) Delete(ctx context.Context, key string, opts ...clientv3.OpOption) (*clientv3.DeleteResponse, error) {
+	return reauthCall(kv.c, func(cli *clientv3.Client) (*clientv3.DeleteResponse, error) {
+		return cli.Delete(ctx, key, opts...)
+	})
+}
+
+func (kv reauthKV) Compact(ctx context.Context, rev int64, opts ...clientv3.CompactOption) (*clientv3.CompactResponse, error) {
+	return reauthCall(kv.c, func(cli *clientv3.Client) (*clientv3.CompactResponse, error) {
+		return cli.Compact(ctx, rev, opts...)
+	})
+}
+
+func (kv reauthKV) Do(ctx context.Context, op clientv3.Op) (clientv3.OpResponse, error) {
+	return reauthCall(kv.c, func(cli *clientv3.Client) (clientv3.OpResponse, error) {
+		return cli.Do(ctx, op)
+	})
+}
+
+func (kv reauthKV) Txn(ctx context.Context) clientv3.Txn {
+	return &reauthTxn{c: kv.c, ctx: ctx}
+}
+
+// reauthTxn 记录事务的条件和操作，提交时才在当前连接上构建事务，认证失败时可以整体重新提交
+type reauthTxn struct {
+	c       *EtcdClient
+	ctx     context.Context
+	cmps    []clientv3.Cmp
+	thenOps []clientv3.Op
+	elseOps []clientv3.Op
+}
+
+func (t *reauthTxn) If(cs ...clientv3.Cmp) clientv3.Txn {
+	t.cmps = append(t.cmps, cs...)
+	return t
+}
+
+func (t *reauthTxn) Then(ops ...clientv3.Op) clientv3.Txn {
+	t.thenOps = append(t.thenOps, ops...)
+	return t
+}
+
+func (t *reauthTxn) Else(ops ...clientv3.Op) clientv3.Txn {
+	t.elseOps = append(t.elseOps, ops...)
+	return t
+}
+
+func (t *reauthTxn) Commit() (*clientv3.TxnResponse, error) {
+	return reauthCall(t.c, func(cli *clientv3.Client) (*clientv3.TxnResponse, error) {
+		return cli.Txn(t.ctx).If(t.cmps...).Then(t.thenOps...).Else(t.elseOps...).Commit()
+	})
+}
+
+// reauthLease 转发到当前连接的 clientv3.Lease，租约属于集群而不是连接，重建连接后仍然有效
+type reauthLease struct{ c *EtcdClient }
+
+func (l reauthLease) Grant(ctx context.Context, ttl int64) (*clientv3.LeaseGrantResponse, error) {
+	return reauthCall(l.c, func(cli *clientv3.Client) (*clientv3.LeaseGrantResponse, error) {
+		return cli.Grant(ctx, ttl)
+	})
+}
+
+func (l reauthLease) Revoke(ctx context.Context, id clientv3.LeaseID) (*clientv3.LeaseRevokeResponse, error) {
+	return reauthCall(l.c, func(cli *clientv3.Client) (*clientv3.LeaseRevokeResponse, error) {
+		return cli.Revoke(ctx, id)
+	})
+}
+
+func (l reauthLease) TimeToLive(ctx context.Context, id clientv3.LeaseID, opts ...clientv3.LeaseOption) (*clientv3.LeaseTimeToLiveResponse, error) {
+	return reauthCall(l.c, func(cli *clientv3.Client) (*clientv3.LeaseTimeToLiveResponse, error) {
+		return cli.TimeToLive(ctx, id, opts...)
+	})
+}
+
+func (l reauthLease) Leases(ctx context.Context) (*clientv3.LeaseLeasesResponse, error) {
+	return reauthCall(l.c, func(cli *clientv3.Client) (*clientv3.LeaseLeasesResponse, error) {
+		return cli.Leases(ctx)
+	})
+}
+
+// KeepAlive 转发当前连接上的续约响应；被替换的连接在宽限期后关闭时，在当前连接上继续续约
+// 与 clientv3 相同，调用方消费不及时时丢弃续约响应
+func (l reauthLease) KeepAlive(ctx context.Context, id clientv3.LeaseID) (<-chan *clientv3.LeaseKeepAliveResponse, error) {
+	var cli *clientv3.Client
+	in, err := reauthCall(l.c, func(conn *clientv3.Client) (<-chan *clientv3.LeaseKeepAliveResponse, error) {
+		cli = conn
+		return conn.KeepAlive(ctx, id)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	out := make(chan *clientv3.LeaseKeepAliveResponse, clientv3.LeaseResponseChSize)
+	go func() {
+		defer close(out)
+		for {
+			for resp := range in {
+				select {
+				case out <- resp:
+				default:
+				}
+			}
+			// 租约过期或 context 取消时续约通道关闭，只有连接被替换时才需要继续
+			next := l.c.current()
+			if ctx.Err() != nil || next == cli {
+				return
+			}
+			cli = next
+			if in, err = next.KeepAlive(ctx, id); err != nil {
+				return
+			}
+		}
+	}()
+	return out, nil
+}
+
+func (l reauthLease) KeepAliveOnce(ctx context.Context, id clientv3.LeaseID) (*clientv3.LeaseKeepAliveResponse, error) {
+	return reauthCall(l.c, func(cli *clientv3.Client) (*clientv3.LeaseKeepAliveResponse, error) {
+		return cli.KeepAliveOnce(ctx, id)
+	})
+}
+
+// Close 连接由 EtcdClient.Close 关闭，门面关闭时不做任何事
+func (l reauthLease) Close() error {
+	return nil
+}
+
+// reauthWatcher 转发到当前连接的 clientv3.Watcher
+type reauthWatcher struct{ c *EtcdClient }
+
+// Watch 转发当前连接上的监听事件；监听因认证失败被取消时重建连接，从最后收到的事件之后续传
+// 重新认证后仍然认证失败时将该响应原样转发并结束监听
+func (w reauthWatcher) Watch(ctx context.Context, key string, opts ...clientv3.OpOption) clientv3.WatchChan {
+	out := make(chan clientv3.WatchResponse)
+	go func() {
+		defer close(out)
+		var lastRev int64
+		reauthenticated := false
+		for {
+			cli := w.c.current()
+			watchOpts := opts
+			if lastRev > 0 {
+				// 后追加的 WithRev 覆盖调用方的起始修订号
+				watchOpts = append(opts[:len(opts):len(opts)], clientv3.WithRev(lastRev+1))
+			}
+
+			watchCtx, cancel := context.WithCancel(ctx)
+			var failed *clientv3.WatchResponse
+			retired := false
+			for resp := range cli.Watch(watchCtx, key, watchOpts...) {
+				if err := resp.Err(); err != nil && isAuthError(err) && !reauthenticated {
+					failed = &resp
+					break
+				}
+				// 被替换的连接在宽限期后关闭，监听随之被取消，转到当前连接续传
+				if resp.Canceled && ctx.Err() == nil && w.c.current() != cli {
+					retired = true
+					break
+				}
+				for _, event := range resp.Events {
+					lastRev = event.Kv.ModRevision
+					reauthenticated = false
+				}
+				select {
+				case out <- resp:
+				case <-ctx.Done():
+					cancel()
+					return
+				}
+			}
+			cancel()
+			if ctx.Err() != nil {
+				return
+			}
+			if retired || (failed == nil && w.c.current() != cli) {
+				continue
+			}
+			if failed == nil {
+				return
+			}
+
+			w.c.logger.Warn("etcd watch authentication failed, reconnecting with refreshed credentials", clog.Err(failed.Err()))
+			if _, err := w.c.rotate(cli); err != nil {
+				select {
+				case out <- *failed:
+				case <-ctx.Done():
+				}
+				return
+			}
+			reauthenticated = true
+		}
+	}()
+	return out
+}
+
+func (w reauthWatcher) RequestProgress(ctx context.Context) error {
+	return w.c.withReauthentication(func(cli *clientv3.Client) error {
+		return cli.RequestProgress(ctx)
+	})
+}
+
+// Close 连接由 EtcdClient.Close 关闭，门面关闭时不做任何事
+func (w reauthWatcher) Close() error {
+	return nil
+}
+
+// reauthCluster 转发到当前连接的 clientv3.Cluster
+type reauthCluster struct{ c *EtcdClient }
+
+func (cl reauthCluster) MemberList(ctx context.Context, opts ...clientv3.OpOption) (*clientv3.MemberListResponse, error) {
+	return reauthCall(cl.c, func(cli *clientv3.Client) (*clientv3.MemberListResponse, error) {
+		return cli.MemberList(ctx, opts...)
+	})
+}
+
+func (cl reauthCluster) MemberAdd(ctx context.Context, peerAddrs []string) (*clientv3.MemberAddResponse, error) {
+	return reauthCall(cl.c, func(cli *clientv3.Client) (*clientv3.MemberAddResponse, error) {
+		return cli.MemberAdd(ctx, peerAddrs)
+	})
+}
+
+func (cl reauthCluster) MemberAddAsLearner(ctx context.Context, peerAddrs []string) (*clientv3.MemberAddResponse, error) {
+	return reauthCall(cl.c, func(cli *clientv3.Client) (*clientv3.MemberAddResponse, error) {
+		return cli.MemberAddAsLearner(ctx, peerAddrs)
+	})
+}
+
+func (cl reauthCluster) MemberRemove(ctx context.Context, id uint64) (*clientv3.MemberRemoveResponse, error) {
+	return reauthCall(cl.c, func(cli *clientv3.Client) (*clientv3.MemberRemoveResponse, error) {
+		return cli.MemberRemove(ctx, id)
+	})
+}
+
+func (cl reauthCluster) MemberUpdate(ctx context.Context, id uint64, peerAddrs []string) (*clientv3.MemberUpdateResponse, error) {
+	return reauthCall(cl.c, func(cli *clientv3.Client) (*clientv3.MemberUpdateResponse, error) {
+		return cli.MemberUpdate(ctx, id, peerAddrs)
+	})
+}
+
+func (cl reauthCluster) MemberPromote(ctx context.Context, id uint64) (*clientv3.MemberPromoteResponse, error) {
+	return reauthCall(cl.c, func(cli *clientv3.Client) (*clientv3.MemberPromoteResponse, error) {
+		return cli.MemberPromote(ctx, id)
+	})
+}
@@ -0,0 +1,66 @@
+package configimpl
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path"
+	"time"
+
+	"github.com/ceyewan/infra-kit/coord/config"
+	"github.com/ceyewan/infra-kit/coord/internal/client"
+)
+
+// auditKey 构建审计记录的键，版本号补零使同一配置键下的记录按字典序即按时间排序
+func auditKey(auditPrefix, key string, version int64) string {
+	return path.Join(auditPrefix, key, fmt.Sprintf("%020d", version))
+}
+
+// auditSearchPrefix 构建读取指定配置键审计记录时使用的前缀
+// 前缀同时会匹配子键的记录，调用方需按 AuditEntry.Key 过滤
+func auditSearchPrefix(auditPrefix, key string) string {
+	return path.Join(auditPrefix, key) + "/"
+}
+
+// encodeAuditEntry 构建并序列化审计记录，审计记录始终以 JSON 存储，不受 Codec 影响
+func encodeAuditEntry(ctx context.Context, key string, op config.AuditOperation, oldValue, newValue []byte, version int64) ([]byte, error) {
+	return json.Marshal(config.AuditEntry{
+		Timestamp: time.Now(),
+		Key:       key,
+		Operation: op,
+		Actor:     config.ActorFromContext(ctx),
+		OldValue:  string(oldValue),
+		NewValue:  string(newValue),
+		Version:   version,
+	})
+}
+
+// decodeAuditHistory 解析按键从新到旧排列的审计记录，跳过子键的记录，最多返回 limit 条
+func decodeAuditHistory(values [][]byte, key string, limit int) ([]config.AuditEntry, error) {
+	entries := make([]config.AuditEntry, 0, len(values))
+	for _, value := range values {
+		var entry config.AuditEntry
+		if err := json.Unmarshal(value, &entry); err != nil {
+			return nil, client.NewError(client.ErrCodeValidation, "failed to parse config audit entry", err)
+		}
+		if entry.Key != key {
+			continue
+		}
+		entries = append(entries, entry)
+		if limit > 0 && len(entries) == limit {
+			break
+		}
+	}
+	return entries, nil
+}
+
+// validateAuditQuery 校验审计查询参数
+func validateAuditQuery(auditPrefix, key string) error {
+	if auditPrefix == "" {
+		return client.NewError(client.ErrCodeValidation, "config audit is not enabled", config.ErrAuditDisabled)
+	}
+	if key == "" {
+		return client.NewError(client.ErrCodeValidation, "config key cannot be empty", nil)
+	}
+	return nil
+}
@@ -0,0 +1,28 @@
+package configimpl
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/ceyewan/infra-kit/coord/config"
+)
+
+// childNames 从 searchPrefix 下的完整键中提取下一级路径段，去重后按字典序返回
+// 连续分隔符产生的空路径段会被跳过
+func childNames(keys []string, searchPrefix string) []string {
+	children := make([]string, 0)
+	seen := make(map[string]struct{})
+	for _, key := range keys {
+		name, _, _ := strings.Cut(strings.TrimPrefix(key, searchPrefix), config.KeySeparator)
+		if name == "" {
+			continue
+		}
+		if _, ok := seen[name]; ok {
+			continue
+		}
+		seen[name] = struct{}{}
+		children = append(children, name)
+	}
+	sort.Strings(children)
+	return children
+}
@@ -2,37 +2,48 @@ package configimpl
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
+	"fmt"
 	"path"
 	"reflect"
 	"strings"
+	"time"
 
 	"github.com/ceyewan/infra-kit/clog"
 	"github.com/ceyewan/infra-kit/coord/config"
 	"github.com/ceyewan/infra-kit/coord/internal/client"
+	"go.etcd.io/etcd/api/v3/v3rpc/rpctypes"
 	clientv3 "go.etcd.io/etcd/client/v3"
 )
 
 // EtcdConfigCenter 使用 etcd 实现 config.ConfigCenter 接口
 type EtcdConfigCenter struct {
-	client *client.EtcdClient // etcd 客户端
-	prefix string             // 配置前缀
-	logger clog.Logger        // 日志记录器
+	valueCodec                     // 配置值编解码，携带日志记录器
+	client      *client.EtcdClient // etcd 客户端
+	prefix      string             // 配置前缀
+	auditPrefix string             // 审计记录前缀，为空表示不记录审计
+	cache       *readCache         // Get 的本地读缓存，未启用时为 nil
 }
 
-// NewEtcdConfigCenter 创建一个基于 etcd 的配置中心
-func NewEtcdConfigCenter(c *client.EtcdClient, prefix string, logger clog.Logger) *EtcdConfigCenter {
+// NewEtcdConfigCenter 创建一个基于 etcd 的配置中心，默认使用 JSON 编码
+func NewEtcdConfigCenter(c *client.EtcdClient, prefix string, logger clog.Logger, opts ...config.Option) *EtcdConfigCenter {
 	if prefix == "" {
 		prefix = "/config"
 	}
 	if logger == nil {
 		logger = clog.Namespace("coordination.config")
 	}
-	return &EtcdConfigCenter{
-		client: c,
-		prefix: prefix,
-		logger: logger,
+	options := config.ParseOptions(opts...)
+	center := &EtcdConfigCenter{
+		valueCodec:  valueCodec{codec: options.Codec, logger: logger},
+		client:      c,
+		prefix:      prefix,
+		auditPrefix: options.AuditPrefix,
 	}
+	if options.ReadCacheTTL > 0 {
+		center.cache = newReadCache(options.ReadCacheTTL)
+	}
+	return center
 }
 
 // Get 获取配置值并反序列化到提供的类型 v
@@ -47,6 +58,12 @@ func (c *EtcdConfigCenter) Get(ctx context.Context, key string, v interface{}) e
 	}
 
 	configKey := path.Join(c.prefix, key)
+	if c.cache != nil {
+		if value, ok := c.cache.get(configKey); ok {
+			return c.unmarshalValue(value, v)
+		}
+	}
+
 	resp, err := c.client.Get(ctx, configKey)
 	if err != nil {
 		return err // 客户端已包装错误
@@ -56,7 +73,52 @@ func (c *EtcdConfigCenter) Get(ctx context.Context, key string, v interface{}) e
 		return client.NewError(client.ErrCodeNotFound, "config key not found", nil)
 	}
 
-	return unmarshalValue(resp.Kvs[0].Value, v)
+	if c.cache != nil {
+		c.ensureCacheWatch(resp.Header.Revision)
+		c.cache.put(configKey, resp.Kvs[0].Value, resp.Header.Revision)
+	}
+	return c.unmarshalValue(resp.Kvs[0].Value, v)
+}
+
+// ensureCacheWatch 从 revision 之后启动使读缓存失效的后台监听，已在运行时不做任何事
+// revision 为触发监听的读取所在的修订号，从它之后监听不会漏掉读取与监听建立之间的变更；
+// 监听随 Close、etcd 客户端关闭或出错而退出，出错退出时清空缓存，下次 Get 时重新建立
+func (c *EtcdConfigCenter) ensureCacheWatch(revision int64) {
+	ctx, cancel := context.WithCancel(context.Background())
+	if !c.cache.startWatch(revision, cancel) {
+		cancel()
+		return
+	}
+
+	watchCh := c.client.Watch(ctx, c.prefix+"/", clientv3.WithPrefix(), clientv3.WithRev(revision+1))
+	go func() {
+		defer cancel()
+		defer c.cache.reset()
+		for resp := range watchCh {
+			if err := resp.Err(); err != nil {
+				c.logger.Warn("读缓存失效监听中断，清空缓存", clog.String("prefix", c.prefix), clog.Err(err))
+				return
+			}
+			for _, event := range resp.Events {
+				c.cache.invalidate(string(event.Kv.Key), event.Kv.ModRevision)
+			}
+		}
+	}()
+}
+
+// Close 停止读缓存的后台监听，未启用读缓存时不做任何事
+// 之后的 Get 直接读取 etcd；协调器关闭时调用
+func (c *EtcdConfigCenter) Close() {
+	if c.cache != nil {
+		c.cache.close()
+	}
+}
+
+// invalidateCache 本地写入成功后立即使缓存失效，保证随后的 Get 能读到自己的写入
+func (c *EtcdConfigCenter) invalidateCache(configKey string, revision int64) {
+	if c.cache != nil {
+		c.cache.invalidate(configKey, revision)
+	}
 }
 
 // GetWithVersion 获取配置值和版本信息
@@ -81,7 +143,7 @@ func (c *EtcdConfigCenter) GetWithVersion(ctx context.Context, key string, v int
 	}
 
 	kv := resp.Kvs[0]
-	err = unmarshalValue(kv.Value, v)
+	err = c.unmarshalValue(kv.Value, v)
 	if err != nil {
 		return 0, err
 	}
@@ -96,7 +158,7 @@ func (c *EtcdConfigCenter) CompareAndSet(ctx context.Context, key string, value
 		return client.NewError(client.ErrCodeValidation, "config key cannot be empty", nil)
 	}
 
-	valueBytes, err := marshalValue(value)
+	valueBytes, err := c.marshalValue(value)
 	if err != nil {
 		return client.NewError(client.ErrCodeValidation, "failed to serialize config value", err)
 	}
@@ -109,17 +171,206 @@ func (c *EtcdConfigCenter) CompareAndSet(ctx context.Context, key string, value
 	// 失败：不执行任何操作
 	txnResp, err := c.client.Txn(ctx).
 		If(clientv3.Compare(clientv3.ModRevision(configKey), "=", expectedVersion)).
+		Then(clientv3.OpPut(configKey, string(valueBytes), c.prevKVOpts()...)).
+		Commit()
+
+	if err != nil {
+		return err // 客户端已包装错误
+	}
+
+	if !txnResp.Succeeded {
+		return client.NewError(client.ErrCodeConflict, "config version mismatch, update rejected", nil).WithKind(config.ErrVersionMismatch)
+	}
+
+	c.invalidateCache(configKey, txnResp.Header.Revision)
+	var oldValue []byte
+	if prevKv := txnResp.Responses[0].GetResponsePut().PrevKv; prevKv != nil {
+		oldValue = prevKv.Value
+	}
+	c.audit(ctx, key, config.AuditOpCompareAndSet, oldValue, valueBytes, txnResp.Header.Revision)
+	return nil
+}
+
+// CompareAndDelete 原子地比较并删除配置
+func (c *EtcdConfigCenter) CompareAndDelete(ctx context.Context, key string, expectedVersion int64) error {
+	if key == "" {
+		return client.NewError(client.ErrCodeValidation, "config key cannot be empty", nil)
+	}
+
+	configKey := path.Join(c.prefix, key)
+
+	// 条件：ModRevision 等于期望版本
+	// 成功：删除键
+	// 失败：读取当前值，用于区分键不存在和版本不匹配
+	txnResp, err := c.client.Txn(ctx).
+		If(clientv3.Compare(clientv3.ModRevision(configKey), "=", expectedVersion)).
+		Then(clientv3.OpDelete(configKey, c.prevKVOpts()...)).
+		Else(clientv3.OpGet(configKey, clientv3.WithCountOnly())).
+		Commit()
+
+	if err != nil {
+		return err // 客户端已包装错误
+	}
+
+	if !txnResp.Succeeded {
+		if txnResp.Responses[0].GetResponseRange().Count == 0 {
+			return client.NewError(client.ErrCodeNotFound, "config key not found for deletion", nil)
+		}
+		return client.NewError(client.ErrCodeConflict, "config version mismatch, delete rejected", nil).WithKind(config.ErrVersionMismatch)
+	}
+
+	deleteResp := txnResp.Responses[0].GetResponseDeleteRange()
+	if deleteResp.Deleted == 0 {
+		// expectedVersion 为 0 时条件对不存在的键成立
+		return client.NewError(client.ErrCodeNotFound, "config key not found for deletion", nil)
+	}
+
+	var oldValue []byte
+	if len(deleteResp.PrevKvs) > 0 {
+		oldValue = deleteResp.PrevKvs[0].Value
+	}
+	c.invalidateCache(configKey, txnResp.Header.Revision)
+	c.audit(ctx, key, config.AuditOpCompareAndDelete, oldValue, nil, txnResp.Header.Revision)
+	return nil
+}
+
+// CompareValueAndSet 当前值等于 expected 时以读取时的版本号为条件写入
+// 事务因读取后值被修改而失败时重新读取比较，直到写入成功、值不相等或 context 结束
+func (c *EtcdConfigCenter) CompareValueAndSet(ctx context.Context, key string, expected, value interface{}) error {
+	if key == "" {
+		return client.NewError(client.ErrCodeValidation, "config key cannot be empty", nil)
+	}
+	if expected == nil {
+		return client.NewError(client.ErrCodeValidation, "expected value cannot be nil", nil)
+	}
+
+	valueBytes, err := c.marshalValue(value)
+	if err != nil {
+		return client.NewError(client.ErrCodeValidation, "failed to serialize config value", err)
+	}
+
+	configKey := path.Join(c.prefix, key)
+	for {
+		resp, err := c.client.Get(ctx, configKey)
+		if err != nil {
+			return err // 客户端已包装错误
+		}
+		if len(resp.Kvs) == 0 {
+			return client.NewError(client.ErrCodeNotFound, "config key not found", nil)
+		}
+
+		kv := resp.Kvs[0]
+		if !c.valueEquals(kv.Value, expected) {
+			return client.NewError(client.ErrCodeConflict, "config value mismatch, update rejected", config.ErrValueMismatch)
+		}
+
+		txnResp, err := c.client.Txn(ctx).
+			If(clientv3.Compare(clientv3.ModRevision(configKey), "=", kv.ModRevision)).
+			Then(clientv3.OpPut(configKey, string(valueBytes))).
+			Commit()
+		if err != nil {
+			return client.NewError(client.ErrCodeConnection, "etcd txn operation failed", err)
+		}
+		if txnResp.Succeeded {
+			c.invalidateCache(configKey, txnResp.Header.Revision)
+			c.audit(ctx, key, config.AuditOpCompareAndSet, kv.Value, valueBytes, txnResp.Header.Revision)
+			return nil
+		}
+		// 读取之后值被修改，重新读取比较
+	}
+}
+
+// SetIfAbsent 仅当键不存在时才创建配置值
+func (c *EtcdConfigCenter) SetIfAbsent(ctx context.Context, key string, value interface{}) (bool, error) {
+	if key == "" {
+		return false, client.NewError(client.ErrCodeValidation, "config key cannot be empty", nil)
+	}
+
+	valueBytes, err := c.marshalValue(value)
+	if err != nil {
+		return false, client.NewError(client.ErrCodeValidation, "failed to serialize config value", err)
+	}
+
+	configKey := path.Join(c.prefix, key)
+
+	// 条件：ModRevision 为 0，即键从未存在或已被删除
+	// 成功：创建键
+	// 失败：不执行任何操作
+	txnResp, err := c.client.Txn(ctx).
+		If(clientv3.Compare(clientv3.ModRevision(configKey), "=", 0)).
 		Then(clientv3.OpPut(configKey, string(valueBytes))).
 		Commit()
 
+	if err != nil {
+		return false, client.NewError(client.ErrCodeConnection, "etcd txn operation failed", err)
+	}
+
+	if !txnResp.Succeeded {
+		return false, nil
+	}
+
+	c.invalidateCache(configKey, txnResp.Header.Revision)
+	c.audit(ctx, key, config.AuditOpSetIfAbsent, nil, valueBytes, txnResp.Header.Revision)
+	return true, nil
+}
+
+// Move 原子地将配置从 src 移动到 dst
+func (c *EtcdConfigCenter) Move(ctx context.Context, src, dst string, overwrite bool) error {
+	if src == "" || dst == "" {
+		return client.NewError(client.ErrCodeValidation, "config key cannot be empty", nil)
+	}
+
+	srcKey := path.Join(c.prefix, src)
+	dstKey := path.Join(c.prefix, dst)
+	if srcKey == dstKey {
+		return client.NewError(client.ErrCodeValidation, "source and destination config keys must differ", nil)
+	}
+
+	resp, err := c.client.Get(ctx, srcKey)
 	if err != nil {
 		return err // 客户端已包装错误
 	}
+	if len(resp.Kvs) == 0 {
+		return client.NewError(client.ErrCodeNotFound, "config key not found", nil)
+	}
+	kv := resp.Kvs[0]
+
+	// 条件：源键自读取后未被修改；不允许覆盖时目标键不存在
+	// 成功：写入目标键并删除源键，两个事件共享同一个 revision
+	// 失败：读取目标键以区分失败原因
+	cmps := []clientv3.Cmp{
+		clientv3.Compare(clientv3.ModRevision(srcKey), "=", kv.ModRevision),
+	}
+	if !overwrite {
+		cmps = append(cmps, clientv3.Compare(clientv3.ModRevision(dstKey), "=", 0))
+	}
+
+	txnResp, err := c.client.Txn(ctx).
+		If(cmps...).
+		Then(clientv3.OpPut(dstKey, string(kv.Value), c.prevKVOpts()...), clientv3.OpDelete(srcKey)).
+		Else(clientv3.OpGet(dstKey, clientv3.WithCountOnly())).
+		Commit()
+
+	if err != nil {
+		return client.NewError(client.ErrCodeConnection, "etcd txn operation failed", err)
+	}
 
 	if !txnResp.Succeeded {
-		return client.NewError(client.ErrCodeConflict, "config version mismatch, update rejected", nil)
+		if !overwrite && txnResp.Responses[0].GetResponseRange().Count > 0 {
+			return client.NewError(client.ErrCodeConflict, "destination config key already exists", config.ErrExists)
+		}
+		return client.NewError(client.ErrCodeConflict, "source config key changed during move", nil)
 	}
 
+	c.invalidateCache(srcKey, txnResp.Header.Revision)
+	c.invalidateCache(dstKey, txnResp.Header.Revision)
+	// 目标键的写入与源键的删除各记一条审计，两条记录共享同一个版本号
+	var oldDst []byte
+	if prevKv := txnResp.Responses[0].GetResponsePut().PrevKv; prevKv != nil {
+		oldDst = prevKv.Value
+	}
+	c.audit(ctx, dst, config.AuditOpMove, oldDst, kv.Value, txnResp.Header.Revision)
+	c.audit(ctx, src, config.AuditOpMove, kv.Value, nil, txnResp.Header.Revision)
 	return nil
 }
 
@@ -129,14 +380,24 @@ func (c *EtcdConfigCenter) Set(ctx context.Context, key string, value interface{
 		return client.NewError(client.ErrCodeValidation, "config key cannot be empty", nil)
 	}
 
-	valueBytes, err := marshalValue(value)
+	valueBytes, err := c.marshalValue(value)
 	if err != nil {
 		return client.NewError(client.ErrCodeValidation, "failed to serialize config value", err)
 	}
 
 	configKey := path.Join(c.prefix, key)
-	_, err = c.client.Put(ctx, configKey, string(valueBytes))
-	return err // 客户端已包装错误
+	resp, err := c.client.Put(ctx, configKey, string(valueBytes), c.prevKVOpts()...)
+	if err != nil {
+		return err // 客户端已包装错误
+	}
+
+	c.invalidateCache(configKey, resp.Header.Revision)
+	var oldValue []byte
+	if resp.PrevKv != nil {
+		oldValue = resp.PrevKv.Value
+	}
+	c.audit(ctx, key, config.AuditOpSet, oldValue, valueBytes, resp.Header.Revision)
+	return nil
 }
 
 // Delete 删除配置键
@@ -146,32 +407,210 @@ func (c *EtcdConfigCenter) Delete(ctx context.Context, key string) error {
 	}
 
 	configKey := path.Join(c.prefix, key)
-	resp, err := c.client.Delete(ctx, configKey)
+	resp, err := c.client.Delete(ctx, configKey, c.prevKVOpts()...)
 	if err != nil {
 		return err
 	}
 	if resp.Deleted == 0 {
 		return client.NewError(client.ErrCodeNotFound, "config key not found for deletion", nil)
 	}
+
+	c.invalidateCache(configKey, resp.Header.Revision)
+	var oldValue []byte
+	if len(resp.PrevKvs) > 0 {
+		oldValue = resp.PrevKvs[0].Value
+	}
+	c.audit(ctx, key, config.AuditOpDelete, oldValue, nil, resp.Header.Revision)
 	return nil
 }
 
+// DeletePrefix 以一次带前缀的 DeleteRange 删除前缀下的所有键，etcd 保证其原子性，所有删除事件共享同一个修订号
+// 启用审计或读缓存时要求返回删除前的键值，逐键记录审计并使缓存失效
+func (c *EtcdConfigCenter) DeletePrefix(ctx context.Context, prefix string) (int, error) {
+	searchPrefix, err := deletePrefixKey(c.prefix, prefix)
+	if err != nil {
+		return 0, err
+	}
+
+	opts := []clientv3.OpOption{clientv3.WithPrefix()}
+	if c.auditPrefix != "" || c.cache != nil {
+		opts = append(opts, clientv3.WithPrevKV())
+	}
+	resp, err := c.client.Delete(ctx, searchPrefix, opts...)
+	if err != nil {
+		return 0, err // 客户端已包装错误
+	}
+
+	for _, kv := range resp.PrevKvs {
+		c.invalidateCache(string(kv.Key), resp.Header.Revision)
+		c.audit(ctx, strings.TrimPrefix(string(kv.Key), c.prefix+"/"), config.AuditOpDeletePrefix, kv.Value, nil, resp.Header.Revision)
+	}
+	return int(resp.Deleted), nil
+}
+
+// AuditHistory 返回指定键的审计记录，按时间从新到旧排列
+func (c *EtcdConfigCenter) AuditHistory(ctx context.Context, key string, limit int) ([]config.AuditEntry, error) {
+	if err := validateAuditQuery(c.auditPrefix, key); err != nil {
+		return nil, err
+	}
+
+	resp, err := c.client.Get(ctx, auditSearchPrefix(c.auditPrefix, key),
+		clientv3.WithPrefix(), clientv3.WithSort(clientv3.SortByKey, clientv3.SortDescend))
+	if err != nil {
+		return nil, err // 客户端已包装错误
+	}
+
+	values := make([][]byte, len(resp.Kvs))
+	for i, kv := range resp.Kvs {
+		values[i] = kv.Value
+	}
+	return decodeAuditHistory(values, key, limit)
+}
+
+// History 从当前版本开始沿 ModRevision 向前逐个读取历史版本
+// 到达键的创建版本、键在更早的修订号不存在（删除后重新创建）或修订号已被压缩时停止
+func (c *EtcdConfigCenter) History(ctx context.Context, key string, limit int) ([]config.VersionedValue, error) {
+	if key == "" {
+		return nil, client.NewError(client.ErrCodeValidation, "config key cannot be empty", nil)
+	}
+
+	configKey := path.Join(c.prefix, key)
+	resp, err := c.client.Get(ctx, configKey)
+	if err != nil {
+		return nil, err // 客户端已包装错误
+	}
+	if len(resp.Kvs) == 0 {
+		return nil, client.NewError(client.ErrCodeNotFound, "config key not found", nil)
+	}
+
+	timestamps, err := c.auditTimestamps(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+
+	var history []config.VersionedValue
+	kv := resp.Kvs[0]
+	for {
+		history = append(history, c.versionedValue(kv.Value, key, kv.ModRevision, timestamps))
+		if (limit > 0 && len(history) == limit) || kv.ModRevision <= kv.CreateRevision {
+			return history, nil
+		}
+
+		// 读取上一个修订号时键的值，即上一个版本
+		prev, err := c.client.Client().Get(ctx, configKey, clientv3.WithRev(kv.ModRevision-1))
+		switch {
+		case errors.Is(err, rpctypes.ErrCompacted):
+			return history, nil
+		case err != nil:
+			return nil, client.NewError(client.ErrCodeConnection, "failed to read config history", err)
+		case len(prev.Kvs) == 0:
+			return history, nil
+		}
+		kv = prev.Kvs[0]
+	}
+}
+
+// Rollback 读取 toVersion 时的值并重新写入
+func (c *EtcdConfigCenter) Rollback(ctx context.Context, key string, toVersion int64) error {
+	if key == "" {
+		return client.NewError(client.ErrCodeValidation, "config key cannot be empty", nil)
+	}
+	if toVersion <= 0 {
+		return client.NewError(client.ErrCodeValidation, "config version must be positive", nil)
+	}
+
+	configKey := path.Join(c.prefix, key)
+	resp, err := c.client.Client().Get(ctx, configKey, clientv3.WithRev(toVersion))
+	switch {
+	case errors.Is(err, rpctypes.ErrCompacted):
+		return client.NewError(client.ErrCodeNotFound,
+			fmt.Sprintf("config version %d has been compacted", toVersion), config.ErrCompacted)
+	case errors.Is(err, rpctypes.ErrFutureRev):
+		return client.NewError(client.ErrCodeValidation, fmt.Sprintf("config version %d does not exist", toVersion), nil)
+	case err != nil:
+		return client.NewError(client.ErrCodeConnection, "failed to read config version", err)
+	}
+	if len(resp.Kvs) == 0 || resp.Kvs[0].ModRevision != toVersion {
+		return client.NewError(client.ErrCodeValidation,
+			fmt.Sprintf("config version %d is not a version of key %s", toVersion, key), nil)
+	}
+
+	value := resp.Kvs[0].Value
+	putResp, err := c.client.Put(ctx, configKey, string(value), c.prevKVOpts()...)
+	if err != nil {
+		return err // 客户端已包装错误
+	}
+
+	c.invalidateCache(configKey, putResp.Header.Revision)
+	var oldValue []byte
+	if putResp.PrevKv != nil {
+		oldValue = putResp.PrevKv.Value
+	}
+	c.audit(ctx, key, config.AuditOpRollback, oldValue, value, putResp.Header.Revision)
+	return nil
+}
+
+// auditTimestamps 读取指定键各版本的写入时间，未启用审计时返回 nil
+func (c *EtcdConfigCenter) auditTimestamps(ctx context.Context, key string) (map[int64]time.Time, error) {
+	if c.auditPrefix == "" {
+		return nil, nil
+	}
+
+	resp, err := c.client.Get(ctx, auditSearchPrefix(c.auditPrefix, key), clientv3.WithPrefix())
+	if err != nil {
+		return nil, err // 客户端已包装错误
+	}
+	values := make([][]byte, len(resp.Kvs))
+	for i, kv := range resp.Kvs {
+		values[i] = kv.Value
+	}
+	return auditTimestamps(values, key)
+}
+
+// prevKVOpts 启用审计时要求 etcd 返回写入前的值
+func (c *EtcdConfigCenter) prevKVOpts() []clientv3.OpOption {
+	if c.auditPrefix == "" {
+		return nil
+	}
+	return []clientv3.OpOption{clientv3.WithPrevKV()}
+}
+
+// audit 追加审计记录，未启用审计时不做任何事
+// 配置已写入成功，审计记录写入失败只记录日志，不影响调用结果
+func (c *EtcdConfigCenter) audit(ctx context.Context, key string, op config.AuditOperation, oldValue, newValue []byte, version int64) {
+	if c.auditPrefix == "" {
+		return
+	}
+
+	data, err := encodeAuditEntry(ctx, key, op, oldValue, newValue, version)
+	if err == nil {
+		_, err = c.client.Put(ctx, auditKey(c.auditPrefix, key, version), string(data))
+	}
+	if err != nil {
+		c.logger.Error("配置审计记录写入失败",
+			clog.String("key", key),
+			clog.String("operation", string(op)),
+			clog.Int64("version", version),
+			clog.Err(err))
+	}
+}
+
 // Watch 监听单个配置键的变更
-func (c *EtcdConfigCenter) Watch(ctx context.Context, key string, v interface{}) (config.Watcher[any], error) {
+func (c *EtcdConfigCenter) Watch(ctx context.Context, key string, v interface{}, opts ...config.WatchOption) (config.Watcher[any], error) {
 	if key == "" {
 		return nil, client.NewError(client.ErrCodeValidation, "config key cannot be empty", nil)
 	}
 	configKey := path.Join(c.prefix, key)
-	return c.watch(ctx, configKey, v, false)
+	return c.watch(ctx, configKey, v, false, config.ParseWatchOptions(opts...))
 }
 
 // WatchPrefix 监听指定前缀下所有配置键的变更
-func (c *EtcdConfigCenter) WatchPrefix(ctx context.Context, prefix string, v interface{}) (config.Watcher[any], error) {
+func (c *EtcdConfigCenter) WatchPrefix(ctx context.Context, prefix string, v interface{}, opts ...config.WatchOption) (config.Watcher[any], error) {
 	if prefix == "" {
 		return nil, client.NewError(client.ErrCodeValidation, "config prefix cannot be empty", nil)
 	}
 	configPrefix := path.Join(c.prefix, prefix)
-	return c.watch(ctx, configPrefix, v, true)
+	return c.watch(ctx, configPrefix, v, true, config.ParseWatchOptions(opts...))
 }
 
 // List 列出指定前缀下的所有配置键
@@ -193,8 +632,28 @@ func (c *EtcdConfigCenter) List(ctx context.Context, prefix string) ([]string, e
 	return keys, nil
 }
 
+// ListChildren 列出前缀下一级的子节点名称
+// etcd 没有按分隔符聚合的查询，这里只读取前缀下的全部键名（不含值）再提取下一级路径段
+func (c *EtcdConfigCenter) ListChildren(ctx context.Context, prefix string) ([]string, error) {
+	searchPrefix := path.Join(c.prefix, prefix)
+	if !strings.HasSuffix(searchPrefix, "/") {
+		searchPrefix += "/"
+	}
+
+	resp, err := c.client.Get(ctx, searchPrefix, clientv3.WithPrefix(), clientv3.WithKeysOnly())
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make([]string, len(resp.Kvs))
+	for i, kv := range resp.Kvs {
+		keys[i] = string(kv.Key)
+	}
+	return childNames(keys, searchPrefix), nil
+}
+
 // watch 内部实现，监听单个键或前缀
-func (c *EtcdConfigCenter) watch(ctx context.Context, keyOrPrefix string, v interface{}, isPrefix bool) (config.Watcher[any], error) {
+func (c *EtcdConfigCenter) watch(ctx context.Context, keyOrPrefix string, v interface{}, isPrefix bool, watchOpts *config.WatchOptions) (config.Watcher[any], error) {
 	// 检查 v 是否为非 nil 指针以获取类型
 	rv := reflect.ValueOf(v)
 	if rv.Kind() != reflect.Ptr || rv.IsNil() {
@@ -206,42 +665,103 @@ func (c *EtcdConfigCenter) watch(ctx context.Context, keyOrPrefix string, v inte
 	if isPrefix {
 		opts = append(opts, clientv3.WithPrefix())
 	}
+	if watchOpts.StartRevision > 0 {
+		if err := c.checkStartRevision(ctx, keyOrPrefix, watchOpts.StartRevision); err != nil {
+			return nil, err
+		}
+	}
 
 	watchCtx, cancel := context.WithCancel(ctx)
-	etcdWatchCh := c.client.Watch(watchCtx, keyOrPrefix, opts...)
+	// 建立通知携带当前修订号，未指定起始修订号时以此作为断线重连的续传起点
+	etcdWatchCh := c.client.Watch(watchCtx, keyOrPrefix, withWatchRev(opts, watchOpts.StartRevision)...)
 	eventCh := make(chan config.ConfigEvent[any], 10)
 
 	w := &etcdWatcher{
 		ch:     eventCh,
+		errs:   make(chan error, watchErrorBuffer),
 		cancel: cancel,
 	}
 
+	// 启用防抖时，原始事件先经过防抖协程合并后再投递
+	rawCh := eventCh
+	if watchOpts.Debounce > 0 {
+		rawCh = make(chan config.ConfigEvent[any], 10)
+		go debounceEvents(watchCtx, rawCh, eventCh, watchOpts.Debounce)
+	}
+
 	go func() {
-		defer close(eventCh)
+		defer close(rawCh)
+		defer close(w.errs)
 		defer c.logger.Info("config watch goroutine exiting", clog.String("key", keyOrPrefix))
 
+		nextRev := watchOpts.StartRevision // 下一个待接收的修订号，0 表示尚未确定
+		backoff := watchRetryMin
+		resuming := false
 		for {
+			var resp clientv3.WatchResponse
+			var ok bool
 			select {
 			case <-watchCtx.Done():
 				c.logger.Info("config watch context cancelled", clog.String("key", keyOrPrefix))
 				return
-			case resp, ok := <-etcdWatchCh:
-				if !ok {
-					c.logger.Info("etcd watch channel closed", clog.String("key", keyOrPrefix))
+			case resp, ok = <-etcdWatchCh:
+			}
+
+			if !ok || resp.Err() != nil {
+				// 监听被关闭或 etcd 客户端已关闭时无需重连
+				if watchCtx.Err() != nil || c.client.Client().Ctx().Err() != nil {
 					return
 				}
-				if err := resp.Err(); err != nil {
-					c.logger.Error("Watcher error", clog.String("key", keyOrPrefix), clog.Err(err))
+				if ok && resp.CompactRevision != 0 {
+					// 续传位置已被压缩，从压缩点继续监听，期间的变更可能丢失
+					c.logger.Error("监听的续传修订号已被压缩，部分变更可能丢失",
+						clog.String("key", keyOrPrefix),
+						clog.Int64("revision", nextRev),
+						clog.Int64("compact_revision", resp.CompactRevision))
+					w.report(client.NewError(client.ErrCodeNotFound,
+						fmt.Sprintf("watch revision %d has been compacted, resuming from %d", nextRev, resp.CompactRevision), config.ErrCompacted))
+					nextRev = resp.CompactRevision
+				} else {
+					cause := errors.New("etcd watch channel closed")
+					if ok {
+						cause = resp.Err()
+					}
+					c.logger.Warn("配置监听中断，准备重连", clog.String("key", keyOrPrefix), clog.Err(cause))
+					w.report(client.NewError(client.ErrCodeConnection, "config watch interrupted",
+						fmt.Errorf("%w: %w", config.ErrWatchInterrupted, cause)))
+				}
+
+				select {
+				case <-time.After(backoff):
+				case <-watchCtx.Done():
 					return
 				}
-				for _, event := range resp.Events {
-					configEvent := c.convertEvent(event, valueType)
-					if configEvent != nil {
-						select {
-						case eventCh <- *configEvent:
-						case <-watchCtx.Done():
-							return
-						}
+				backoff = min(backoff*2, watchRetryMax)
+				etcdWatchCh = c.client.Watch(watchCtx, keyOrPrefix, withWatchRev(opts, nextRev)...)
+				resuming = true
+				continue
+			}
+
+			if resp.Created {
+				if nextRev == 0 {
+					nextRev = resp.Header.Revision + 1
+				}
+				if resuming {
+					resuming = false
+					backoff = watchRetryMin
+					c.logger.Info("配置监听已重连", clog.String("key", keyOrPrefix), clog.Int64("revision", nextRev))
+					w.report(fmt.Errorf("%w from revision %d", config.ErrWatchResumed, nextRev))
+				}
+			}
+
+			for _, event := range resp.Events {
+				nextRev = event.Kv.ModRevision + 1
+				configEvent := c.convertEvent(event, valueType)
+				if configEvent != nil {
+					select {
+					case rawCh <- *configEvent:
+					case <-watchCtx.Done():
+						return
 					}
 				}
 			}
@@ -251,16 +771,102 @@ func (c *EtcdConfigCenter) watch(ctx context.Context, keyOrPrefix string, v inte
 	return w, nil
 }
 
+// 监听断线重连的退避时间，从 watchRetryMin 开始翻倍，不超过 watchRetryMax
+var (
+	watchRetryMin = 100 * time.Millisecond
+	watchRetryMax = 5 * time.Second
+)
+
+// watchErrorBuffer 监听错误通道的缓冲大小
+const watchErrorBuffer = 8
+
+// withWatchRev 返回带起始修订号和建立通知的监听选项，rev 为 0 表示从当前修订号开始
+func withWatchRev(opts []clientv3.OpOption, rev int64) []clientv3.OpOption {
+	opts = append(opts[:len(opts):len(opts)], clientv3.WithCreatedNotify())
+	if rev > 0 {
+		opts = append(opts, clientv3.WithRev(rev))
+	}
+	return opts
+}
+
+// checkStartRevision 在建立监听前检查起始修订号是否已被压缩
+// etcd 对已压缩的修订号只会在监听通道中返回错误，提前读取一次以便同步返回 ErrCompacted
+func (c *EtcdConfigCenter) checkStartRevision(ctx context.Context, key string, rev int64) error {
+	_, err := c.client.Client().Get(ctx, key, clientv3.WithRev(rev), clientv3.WithCountOnly())
+	switch {
+	case err == nil, errors.Is(err, rpctypes.ErrFutureRev):
+		// 未来的修订号合法，监听会等待该修订号到来
+		return nil
+	case errors.Is(err, rpctypes.ErrCompacted):
+		return client.NewError(client.ErrCodeNotFound,
+			fmt.Sprintf("watch start revision %d has been compacted", rev), config.ErrCompacted)
+	default:
+		return client.NewError(client.ErrCodeConnection, "failed to check watch start revision", err)
+	}
+}
+
+// debounceEvents 按键合并窗口内的连续事件，每个键只投递最后一次变更
+// 窗口从一批事件的首个事件开始计时，持续变更时投递延迟也不会超过窗口；
+// 输入通道关闭时先投递尚未发出的事件再关闭输出通道
+func debounceEvents(ctx context.Context, in <-chan config.ConfigEvent[any], out chan<- config.ConfigEvent[any], window time.Duration) {
+	defer close(out)
+
+	pending := make(map[string]config.ConfigEvent[any])
+	var order []string
+	var timer *time.Timer
+	var timerC <-chan time.Time
+
+	flush := func() bool {
+		if timer != nil {
+			timer.Stop()
+			timer, timerC = nil, nil
+		}
+		for _, key := range order {
+			select {
+			case out <- pending[key]:
+			case <-ctx.Done():
+				return false
+			}
+		}
+		clear(pending)
+		order = order[:0]
+		return true
+	}
+
+	for {
+		select {
+		case event, ok := <-in:
+			if !ok {
+				flush()
+				return
+			}
+			if _, exists := pending[event.Key]; !exists {
+				order = append(order, event.Key)
+			}
+			pending[event.Key] = event
+			if timer == nil {
+				timer = time.NewTimer(window)
+				timerC = timer.C
+			}
+		case <-timerC:
+			if !flush() {
+				return
+			}
+		}
+	}
+}
+
 // convertEvent 将 etcd 事件转换为配置事件
 func (c *EtcdConfigCenter) convertEvent(event *clientv3.Event, valueType reflect.Type) *config.ConfigEvent[any] {
 	relativeKey := strings.TrimPrefix(string(event.Kv.Key), c.prefix+"/")
 	var eventType config.EventType
 	var value interface{}
+	var decodeErr error
 
 	switch event.Type {
 	case clientv3.EventTypePut:
 		eventType = config.EventTypePut
-		value = c.parseEventValue(event.Kv.Value, valueType, relativeKey)
+		value, decodeErr = c.parseEventValue(event.Kv.Value, valueType, relativeKey)
 	case clientv3.EventTypeDelete:
 		eventType = config.EventTypeDelete
 		// 删除事件不包含值
@@ -269,15 +875,19 @@ func (c *EtcdConfigCenter) convertEvent(event *clientv3.Event, valueType reflect
 	}
 
 	return &config.ConfigEvent[any]{
-		Type:  eventType,
-		Key:   relativeKey,
-		Value: value,
+		Type:     eventType,
+		Key:      relativeKey,
+		Value:    value,
+		Version:  event.Kv.ModRevision,
+		Revision: event.Kv.ModRevision, // 删除事件的 ModRevision 即删除发生的修订号
+		Err:      decodeErr,
 	}
 }
 
 // etcdWatcher 实现 config.Watcher 接口
 type etcdWatcher struct {
 	ch     chan config.ConfigEvent[any] // 事件通道
+	errs   chan error                   // 错误通道，由监听协程发送并在退出时关闭
 	cancel context.CancelFunc           // 取消函数
 }
 
@@ -286,70 +896,20 @@ func (w *etcdWatcher) Chan() <-chan config.ConfigEvent[any] {
 	return w.ch
 }
 
-// Close 停止监听
-func (w *etcdWatcher) Close() {
-	w.cancel()
+// Errors 返回错误通道
+func (w *etcdWatcher) Errors() <-chan error {
+	return w.errs
 }
 
-// marshalValue 序列化值，优先处理 string 和 []byte，否则使用 JSON
-func marshalValue(value interface{}) ([]byte, error) {
-	switch v := value.(type) {
-	case string:
-		return []byte(v), nil
-	case []byte:
-		return v, nil
+// report 发送一个错误，通道已满时丢弃，不阻塞事件投递
+func (w *etcdWatcher) report(err error) {
+	select {
+	case w.errs <- err:
 	default:
-		return json.Marshal(value)
 	}
 }
 
-// unmarshalValue 反序列化值，优先尝试 JSON，失败则尝试字符串
-func unmarshalValue(data []byte, v interface{}) error {
-	if err := json.Unmarshal(data, v); err == nil {
-		return nil
-	}
-
-	// 如果目标是 *string，则直接赋值
-	if strPtr, ok := v.(*string); ok {
-		*strPtr = string(data)
-		return nil
-	}
-
-	// 非 *string 且 JSON 失败，返回错误
-	return client.NewError(client.ErrCodeValidation, "value is not valid JSON for the target type", nil)
-}
-
-// parseEventValue 智能解析事件值，支持多种类型处理策略
-func (c *EtcdConfigCenter) parseEventValue(data []byte, valueType reflect.Type, key string) interface{} {
-	// 如果目标类型是 interface{}，尝试自动推断类型
-	if valueType.Kind() == reflect.Interface && valueType.NumMethod() == 0 {
-		return c.parseAsInterface(data)
-	}
-
-	// 尝试解析为目标类型
-	newValue := reflect.New(valueType).Interface()
-	if err := unmarshalValue(data, newValue); err != nil {
-		// 类型转换失败时，记录警告但不丢弃事件
-		c.logger.Warn("Failed to unmarshal event value, returning raw string",
-			clog.String("key", key),
-			clog.String("target_type", valueType.String()),
-			clog.Err(err))
-
-		// 返回原始字符串值作为降级处理
-		return string(data)
-	}
-
-	return reflect.ValueOf(newValue).Elem().Interface()
-}
-
-// parseAsInterface 当目标类型是 interface{} 时，自动推断最合适的类型
-func (c *EtcdConfigCenter) parseAsInterface(data []byte) interface{} {
-	// 首先尝试解析为 JSON
-	var jsonValue interface{}
-	if err := json.Unmarshal(data, &jsonValue); err == nil {
-		return jsonValue
-	}
-
-	// JSON 解析失败，返回字符串
-	return string(data)
+// Close 停止监听
+func (w *etcdWatcher) Close() {
+	w.cancel()
 }
@@ -3,6 +3,7 @@ package configimpl
 import (
 	"context"
 	"fmt"
+	"os"
 	"sync"
 	"testing"
 	"time"
@@ -12,8 +13,21 @@ import (
 	"github.com/ceyewan/infra-kit/coord/internal/client"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	clientv3 "go.etcd.io/etcd/client/v3"
 )
 
+// compactEnv 设置为 1 时才运行压缩 etcd 历史的测试
+const compactEnv = "COORD_TEST_ETCD_COMPACT"
+
+// requireCompaction 跳过未显式允许的压缩测试
+// 压缩作用于整个 etcd 集群而不是某个前缀，会截断共享 etcd 上其他测试和服务依赖的历史
+func requireCompaction(t *testing.T) {
+	t.Helper()
+	if os.Getenv(compactEnv) != "1" {
+		t.Skipf("compaction affects the whole etcd cluster, set %s=1 to run", compactEnv)
+	}
+}
+
 // TestEtcdConfigCenter_New 测试配置中心创建
 func TestEtcdConfigCenter_New(t *testing.T) {
 	client, err := createTestEtcdClient()
@@ -149,6 +163,28 @@ func TestEtcdConfigCenter_GetSet(t *testing.T) {
 	})
 }
 
+// TestEtcdConfigCenter_ErrorKinds 测试错误可用 errors.Is 匹配对应的哨兵错误
+func TestEtcdConfigCenter_ErrorKinds(t *testing.T) {
+	c, err := createTestEtcdClient()
+	require.NoError(t, err)
+	defer c.Close()
+
+	configCenter := NewEtcdConfigCenter(c, "/test-config", clog.Namespace("test"))
+	ctx := context.Background()
+
+	var result string
+	err = configCenter.Get(ctx, "non-existent-key", &result)
+	assert.ErrorIs(t, err, client.ErrNotFound)
+	assert.NotErrorIs(t, err, client.ErrConflict)
+
+	key := "error-kinds"
+	require.NoError(t, configCenter.Set(ctx, key, "initial"))
+	defer configCenter.Delete(ctx, key)
+	err = configCenter.CompareAndSet(ctx, key, "updated", 99999)
+	assert.ErrorIs(t, err, config.ErrVersionMismatch)
+	assert.ErrorIs(t, err, client.ErrConflict)
+}
+
 // TestEtcdConfigCenter_CAS 测试CAS操作
 func TestEtcdConfigCenter_CAS(t *testing.T) {
 	client, err := createTestEtcdClient()
@@ -202,6 +238,7 @@ func TestEtcdConfigCenter_CAS(t *testing.T) {
 		err = configCenter.CompareAndSet(ctx, key, "updated", 99999)
 		assert.Error(t, err)
 		assert.Contains(t, err.Error(), "version mismatch")
+		assert.ErrorIs(t, err, config.ErrVersionMismatch)
 
 		// 验证值未被修改
 		var currentValue string
@@ -219,6 +256,451 @@ func TestEtcdConfigCenter_CAS(t *testing.T) {
 		assert.Error(t, err)
 		assert.Contains(t, err.Error(), "version mismatch")
 	})
+
+	t.Run("compare and delete", func(t *testing.T) {
+		key := "cad-test"
+		require.NoError(t, configCenter.Set(ctx, key, "initial"))
+
+		var currentValue string
+		version, err := configCenter.GetWithVersion(ctx, key, &currentValue)
+		require.NoError(t, err)
+
+		// 键被重新写入后，旧版本号不能删除新值
+		require.NoError(t, configCenter.Set(ctx, key, "recreated"))
+		err = configCenter.CompareAndDelete(ctx, key, version)
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "version mismatch")
+
+		version, err = configCenter.GetWithVersion(ctx, key, &currentValue)
+		require.NoError(t, err)
+		assert.Equal(t, "recreated", currentValue)
+
+		require.NoError(t, configCenter.CompareAndDelete(ctx, key, version))
+		err = configCenter.Get(ctx, key, &currentValue)
+		assert.Error(t, err)
+
+		err = configCenter.CompareAndDelete(ctx, key, version)
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "not found")
+	})
+
+	t.Run("compare value and set", func(t *testing.T) {
+		type limits struct {
+			QPS   int      `json:"qps"`
+			Users []string `json:"users"`
+		}
+		key := "cvs-test"
+		require.NoError(t, configCenter.Set(ctx, key, limits{QPS: 10, Users: []string{"a"}}))
+		defer configCenter.Delete(ctx, key)
+
+		// 当前值不等于期望值时拒绝写入
+		err := configCenter.CompareValueAndSet(ctx, key, limits{QPS: 20, Users: []string{"a"}}, limits{QPS: 30})
+		assert.ErrorIs(t, err, config.ErrValueMismatch)
+
+		require.NoError(t, configCenter.CompareValueAndSet(ctx, key, &limits{QPS: 10, Users: []string{"a"}}, limits{QPS: 20}))
+		var current limits
+		require.NoError(t, configCenter.Get(ctx, key, &current))
+		assert.Equal(t, limits{QPS: 20}, current)
+
+		// 无法解码为期望值类型视为不相等
+		assert.ErrorIs(t, configCenter.CompareValueAndSet(ctx, key, 20, limits{QPS: 40}), config.ErrValueMismatch)
+
+		require.NoError(t, configCenter.Set(ctx, key, "plain"))
+		require.NoError(t, configCenter.CompareValueAndSet(ctx, key, "plain", "next"))
+		assert.ErrorIs(t, configCenter.CompareValueAndSet(ctx, key, "plain", "again"), config.ErrValueMismatch)
+
+		err = configCenter.CompareValueAndSet(ctx, "non-existent-key", "plain", "value")
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "not found")
+	})
+}
+
+// TestEtcdConfigCenter_SetIfAbsent 测试仅在键不存在时创建
+func TestEtcdConfigCenter_SetIfAbsent(t *testing.T) {
+	client, err := createTestEtcdClient()
+	require.NoError(t, err)
+	defer client.Close()
+
+	logger := clog.Namespace("test")
+	configCenter := NewEtcdConfigCenter(client, "/test-config", logger)
+	ctx := context.Background()
+
+	key := "set-if-absent-test"
+	_ = configCenter.Delete(ctx, key)
+
+	// 首次调用应创建成功
+	created, err := configCenter.SetIfAbsent(ctx, key, "first")
+	assert.NoError(t, err)
+	assert.True(t, created)
+
+	// 再次调用不应覆盖已有值
+	created, err = configCenter.SetIfAbsent(ctx, key, "second")
+	assert.NoError(t, err)
+	assert.False(t, created)
+
+	var value string
+	err = configCenter.Get(ctx, key, &value)
+	assert.NoError(t, err)
+	assert.Equal(t, "first", value)
+
+	// 空键校验
+	_, err = configCenter.SetIfAbsent(ctx, "", "value")
+	assert.Error(t, err)
+
+	// 清理
+	err = configCenter.Delete(ctx, key)
+	assert.NoError(t, err)
+}
+
+// TestEtcdConfigCenter_Move 测试配置键的原子移动
+func TestEtcdConfigCenter_Move(t *testing.T) {
+	client, err := createTestEtcdClient()
+	require.NoError(t, err)
+	defer client.Close()
+
+	logger := clog.Namespace("test")
+	configCenter := NewEtcdConfigCenter(client, "/test-config", logger)
+	ctx := context.Background()
+
+	src, dst := "move-test/old", "move-test/new"
+	_ = configCenter.Delete(ctx, src)
+	_ = configCenter.Delete(ctx, dst)
+	defer configCenter.Delete(ctx, dst)
+
+	require.NoError(t, configCenter.Set(ctx, src, "payload"))
+
+	// 监听者应观察到源键删除和目标键写入
+	watcher, err := configCenter.WatchPrefix(ctx, "move-test", new(string))
+	require.NoError(t, err)
+	defer watcher.Close()
+
+	require.NoError(t, configCenter.Move(ctx, src, dst, false))
+
+	var value string
+	assert.NoError(t, configCenter.Get(ctx, dst, &value))
+	assert.Equal(t, "payload", value)
+	assert.Error(t, configCenter.Get(ctx, src, &value))
+
+	events := map[config.EventType]config.ConfigEvent[any]{}
+	for len(events) < 2 {
+		select {
+		case event := <-watcher.Chan():
+			events[event.Type] = event
+		case <-time.After(5 * time.Second):
+			t.Fatalf("timed out waiting for move events, got %+v", events)
+		}
+	}
+	assert.Equal(t, src, events[config.EventTypeDelete].Key)
+	assert.Equal(t, dst, events[config.EventTypePut].Key)
+	assert.Equal(t, events[config.EventTypeDelete].Version, events[config.EventTypePut].Version)
+
+	// 目标已存在且不允许覆盖
+	require.NoError(t, configCenter.Set(ctx, src, "second"))
+	err = configCenter.Move(ctx, src, dst, false)
+	assert.ErrorIs(t, err, config.ErrExists)
+
+	// 允许覆盖
+	require.NoError(t, configCenter.Move(ctx, src, dst, true))
+	assert.NoError(t, configCenter.Get(ctx, dst, &value))
+	assert.Equal(t, "second", value)
+
+	// 源键不存在
+	err = configCenter.Move(ctx, src, dst, true)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "not found")
+
+	// 参数校验
+	assert.Error(t, configCenter.Move(ctx, "", dst, false))
+	assert.Error(t, configCenter.Move(ctx, dst, dst, false))
+}
+
+// TestEtcdConfigCenter_Codec 测试使用 YAML 编码存储和读取配置
+func TestEtcdConfigCenter_Codec(t *testing.T) {
+	client, err := createTestEtcdClient()
+	require.NoError(t, err)
+	defer client.Close()
+
+	logger := clog.Namespace("test")
+	configCenter := NewEtcdConfigCenter(client, "/test-config", logger, config.WithCodec(config.YAMLCodec))
+	ctx := context.Background()
+
+	type appConfig struct {
+		Name string `yaml:"name"`
+		Port int    `yaml:"port"`
+	}
+	key := "codec-yaml-test"
+	defer configCenter.Delete(ctx, key)
+
+	require.NoError(t, configCenter.Set(ctx, key, appConfig{Name: "gateway", Port: 8080}))
+
+	// 原样读取，确认以 YAML 存储
+	var raw string
+	require.NoError(t, configCenter.Get(ctx, key, &raw))
+	assert.Contains(t, raw, "name: gateway")
+
+	var result appConfig
+	require.NoError(t, configCenter.Get(ctx, key, &result))
+	assert.Equal(t, appConfig{Name: "gateway", Port: 8080}, result)
+}
+
+// TestEtcdConfigCenter_ReadCache 测试 Get 读缓存的命中与失效
+func TestEtcdConfigCenter_ReadCache(t *testing.T) {
+	client, err := createTestEtcdClient()
+	require.NoError(t, err)
+	defer client.Close()
+
+	logger := clog.Namespace("test")
+	configCenter := NewEtcdConfigCenter(client, "/test-config", logger, config.WithReadCache(time.Minute))
+	ctx := context.Background()
+
+	key := "read-cache-test"
+	defer configCenter.Delete(ctx, key)
+
+	require.NoError(t, configCenter.Set(ctx, key, "v1"))
+	var value string
+	require.NoError(t, configCenter.Get(ctx, key, &value))
+	assert.Equal(t, "v1", value)
+
+	// 通过本配置中心写入后立即能读到新值
+	require.NoError(t, configCenter.Set(ctx, key, "v2"))
+	require.NoError(t, configCenter.Get(ctx, key, &value))
+	assert.Equal(t, "v2", value)
+
+	// 绕过配置中心直接写入 etcd，由后台监听使缓存失效
+	_, err = client.Client().Put(ctx, "/test-config/"+key, `"v3"`)
+	require.NoError(t, err)
+	assert.Eventually(t, func() bool {
+		var latest string
+		return configCenter.Get(ctx, key, &latest) == nil && latest == "v3"
+	}, 2*time.Second, 20*time.Millisecond)
+
+	// 删除后不再命中缓存
+	require.NoError(t, configCenter.Delete(ctx, key))
+	assert.Error(t, configCenter.Get(ctx, key, &value))
+}
+
+// TestEtcdConfigCenter_Audit 测试写操作的审计记录
+func TestEtcdConfigCenter_Audit(t *testing.T) {
+	client, err := createTestEtcdClient()
+	require.NoError(t, err)
+	defer client.Close()
+
+	logger := clog.Namespace("test")
+	auditPrefix := "/test-config-audit"
+	configCenter := NewEtcdConfigCenter(client, "/test-config", logger, config.WithAuditPrefix(auditPrefix))
+	ctx := config.WithActor(context.Background(), "alice")
+
+	key := "audit-test"
+	_, _ = client.Client().Delete(ctx, auditPrefix+"/", clientv3.WithPrefix())
+	defer client.Client().Delete(ctx, auditPrefix+"/", clientv3.WithPrefix())
+	_ = NewEtcdConfigCenter(client, "/test-config", logger).Delete(ctx, key)
+
+	require.NoError(t, configCenter.Set(ctx, key, "v1"))
+	var value string
+	version, err := configCenter.GetWithVersion(ctx, key, &value)
+	require.NoError(t, err)
+	require.NoError(t, configCenter.CompareAndSet(ctx, key, "v2", version))
+	require.NoError(t, configCenter.Delete(ctx, key))
+
+	// 子键的记录不应出现在父键的历史中
+	require.NoError(t, configCenter.Set(ctx, key+"/child", "other"))
+	defer configCenter.Delete(ctx, key+"/child")
+
+	history, err := configCenter.AuditHistory(ctx, key, 0)
+	require.NoError(t, err)
+	require.Len(t, history, 3)
+
+	assert.Equal(t, config.AuditOpDelete, history[0].Operation)
+	assert.Equal(t, "v2", history[0].OldValue)
+	assert.Empty(t, history[0].NewValue)
+
+	assert.Equal(t, config.AuditOpCompareAndSet, history[1].Operation)
+	assert.Equal(t, "v1", history[1].OldValue)
+	assert.Equal(t, "v2", history[1].NewValue)
+
+	assert.Equal(t, config.AuditOpSet, history[2].Operation)
+	assert.Empty(t, history[2].OldValue)
+	assert.Equal(t, version, history[2].Version)
+
+	for _, entry := range history {
+		assert.Equal(t, key, entry.Key)
+		assert.Equal(t, "alice", entry.Actor)
+		assert.False(t, entry.Timestamp.IsZero())
+	}
+
+	limited, err := configCenter.AuditHistory(ctx, key, 1)
+	require.NoError(t, err)
+	require.Len(t, limited, 1)
+	assert.Equal(t, config.AuditOpDelete, limited[0].Operation)
+
+	// 未启用审计
+	_, err = NewEtcdConfigCenter(client, "/test-config", logger).AuditHistory(ctx, key, 0)
+	assert.ErrorIs(t, err, config.ErrAuditDisabled)
+}
+
+// TestEtcdConfigCenter_AuditSetIfAbsentAndMove 测试 SetIfAbsent 和 Move 的审计记录
+func TestEtcdConfigCenter_AuditSetIfAbsentAndMove(t *testing.T) {
+	client, err := createTestEtcdClient()
+	require.NoError(t, err)
+	defer client.Close()
+
+	logger := clog.Namespace("test")
+	auditPrefix := "/test-config-audit"
+	configCenter := NewEtcdConfigCenter(client, "/test-config", logger, config.WithAuditPrefix(auditPrefix))
+	ctx := config.WithActor(context.Background(), "alice")
+
+	src, dst := "audit-move-src", "audit-move-dst"
+	_, _ = client.Client().Delete(ctx, auditPrefix+"/", clientv3.WithPrefix())
+	defer client.Client().Delete(ctx, auditPrefix+"/", clientv3.WithPrefix())
+	plain := NewEtcdConfigCenter(client, "/test-config", logger)
+	_ = plain.Delete(ctx, src)
+	_ = plain.Delete(ctx, dst)
+	defer plain.Delete(ctx, dst)
+
+	created, err := configCenter.SetIfAbsent(ctx, src, "blue")
+	require.NoError(t, err)
+	require.True(t, created)
+	// 键已存在时不写入，也不产生审计记录
+	created, err = configCenter.SetIfAbsent(ctx, src, "green")
+	require.NoError(t, err)
+	require.False(t, created)
+
+	history, err := configCenter.AuditHistory(ctx, src, 0)
+	require.NoError(t, err)
+	require.Len(t, history, 1)
+	assert.Equal(t, config.AuditOpSetIfAbsent, history[0].Operation)
+	assert.Empty(t, history[0].OldValue)
+	assert.Equal(t, "blue", history[0].NewValue)
+
+	require.NoError(t, configCenter.Set(ctx, dst, "stale"))
+	require.NoError(t, configCenter.Move(ctx, src, dst, true))
+
+	// 目标键记录写入，源键记录删除，两条记录共享同一个版本号
+	dstHistory, err := configCenter.AuditHistory(ctx, dst, 1)
+	require.NoError(t, err)
+	require.Len(t, dstHistory, 1)
+	assert.Equal(t, config.AuditOpMove, dstHistory[0].Operation)
+	assert.Equal(t, "stale", dstHistory[0].OldValue)
+	assert.Equal(t, "blue", dstHistory[0].NewValue)
+
+	srcHistory, err := configCenter.AuditHistory(ctx, src, 1)
+	require.NoError(t, err)
+	require.Len(t, srcHistory, 1)
+	assert.Equal(t, config.AuditOpMove, srcHistory[0].Operation)
+	assert.Equal(t, "blue", srcHistory[0].OldValue)
+	assert.Empty(t, srcHistory[0].NewValue)
+	assert.Equal(t, dstHistory[0].Version, srcHistory[0].Version)
+}
+
+// TestEtcdConfigCenter_HistoryAndRollback 测试历史版本读取和回滚
+func TestEtcdConfigCenter_HistoryAndRollback(t *testing.T) {
+	client, err := createTestEtcdClient()
+	require.NoError(t, err)
+	defer client.Close()
+
+	logger := clog.Namespace("test")
+	auditPrefix := "/test-config-history-audit"
+	configCenter := NewEtcdConfigCenter(client, "/test-config", logger, config.WithAuditPrefix(auditPrefix))
+	ctx := context.Background()
+
+	key := "history-test"
+	_, _ = client.Client().Delete(ctx, auditPrefix+"/", clientv3.WithPrefix())
+	defer client.Client().Delete(ctx, auditPrefix+"/", clientv3.WithPrefix())
+	_ = configCenter.Delete(ctx, key)
+	defer configCenter.Delete(ctx, key)
+
+	_, err = configCenter.History(ctx, key, 0)
+	assert.Error(t, err)
+
+	// 删除前的版本不属于当前这次创建的历史
+	require.NoError(t, configCenter.Set(ctx, key, map[string]int{"port": 1}))
+	require.NoError(t, configCenter.Delete(ctx, key))
+	require.NoError(t, configCenter.Set(ctx, key, map[string]int{"port": 8080}))
+	require.NoError(t, configCenter.Set(ctx, key, map[string]int{"port": 9090}))
+	require.NoError(t, configCenter.Set(ctx, key, "not json"))
+
+	history, err := configCenter.History(ctx, key, 0)
+	require.NoError(t, err)
+	require.Len(t, history, 3)
+	assert.Equal(t, "not json", history[0].Value)
+	assert.Equal(t, map[string]interface{}{"port": float64(9090)}, history[1].Value)
+	assert.Equal(t, map[string]interface{}{"port": float64(8080)}, history[2].Value)
+	assert.Greater(t, history[0].Version, history[1].Version)
+	assert.Greater(t, history[1].Version, history[2].Version)
+	for _, version := range history {
+		assert.NoError(t, version.Err)
+		assert.False(t, version.Timestamp.IsZero())
+	}
+
+	limited, err := configCenter.History(ctx, key, 2)
+	require.NoError(t, err)
+	assert.Len(t, limited, 2)
+
+	// 回滚产生新的版本，值与目标版本相同
+	require.NoError(t, configCenter.Rollback(ctx, key, history[2].Version))
+	var value map[string]int
+	version, err := configCenter.GetWithVersion(ctx, key, &value)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int{"port": 8080}, value)
+	assert.Greater(t, version, history[0].Version)
+
+	audit, err := configCenter.AuditHistory(ctx, key, 1)
+	require.NoError(t, err)
+	require.Len(t, audit, 1)
+	assert.Equal(t, config.AuditOpRollback, audit[0].Operation)
+
+	// 不属于该键的版本、未来的版本
+	assert.Error(t, configCenter.Rollback(ctx, key, history[2].Version-1))
+	assert.Error(t, configCenter.Rollback(ctx, key, version+1000))
+	assert.Error(t, configCenter.Rollback(ctx, key, 0))
+
+	// 压缩后历史截断，回滚到被压缩的版本返回 ErrCompacted
+	t.Run("after compaction", func(t *testing.T) {
+		requireCompaction(t)
+
+		_, err := client.Client().Compact(ctx, version)
+		require.NoError(t, err)
+		history, err := configCenter.History(ctx, key, 0)
+		require.NoError(t, err)
+		require.Len(t, history, 1)
+		assert.Equal(t, version, history[0].Version)
+		assert.ErrorIs(t, configCenter.Rollback(ctx, key, history[0].Version-1), config.ErrCompacted)
+	})
+}
+
+// TestDebounceEvents 测试防抖合并连续变更
+func TestDebounceEvents(t *testing.T) {
+	in := make(chan config.ConfigEvent[any], 10)
+	out := make(chan config.ConfigEvent[any], 10)
+	go debounceEvents(context.Background(), in, out, 100*time.Millisecond)
+
+	// 窗口内同一个键只投递最后一次变更，不同键各自保留
+	in <- config.ConfigEvent[any]{Type: config.EventTypePut, Key: "email/port", Value: 25, Version: 1}
+	in <- config.ConfigEvent[any]{Type: config.EventTypePut, Key: "email/host", Value: "smtp", Version: 2}
+	in <- config.ConfigEvent[any]{Type: config.EventTypePut, Key: "email/port", Value: 587, Version: 3}
+
+	first := <-out
+	second := <-out
+	assert.Equal(t, "email/port", first.Key)
+	assert.Equal(t, 587, first.Value)
+	assert.Equal(t, int64(3), first.Version)
+	assert.Equal(t, "email/host", second.Key)
+
+	select {
+	case event := <-out:
+		t.Fatalf("unexpected extra event: %+v", event)
+	case <-time.After(200 * time.Millisecond):
+	}
+
+	// 输入关闭时投递尚未发出的最终状态
+	in <- config.ConfigEvent[any]{Type: config.EventTypeDelete, Key: "email/port", Version: 4}
+	close(in)
+
+	last, ok := <-out
+	require.True(t, ok)
+	assert.Equal(t, config.EventTypeDelete, last.Type)
+	_, ok = <-out
+	assert.False(t, ok)
 }
 
 // TestEtcdConfigCenter_Delete 测试配置删除
@@ -263,6 +745,42 @@ func TestEtcdConfigCenter_Delete(t *testing.T) {
 	})
 }
 
+// TestEtcdConfigCenter_DeletePrefix 测试按前缀批量删除
+func TestEtcdConfigCenter_DeletePrefix(t *testing.T) {
+	client, err := createTestEtcdClient()
+	require.NoError(t, err)
+	defer client.Close()
+
+	logger := clog.Namespace("test")
+	configCenter := NewEtcdConfigCenter(client, "/test-config", logger)
+	ctx := context.Background()
+
+	for _, key := range []string{"prefix-ops/a", "prefix-ops/b/c", "prefix-ops-sibling"} {
+		require.NoError(t, configCenter.Set(ctx, key, "value"))
+	}
+	defer configCenter.Delete(ctx, "prefix-ops-sibling")
+
+	deleted, err := configCenter.DeletePrefix(ctx, "prefix-ops/")
+	require.NoError(t, err)
+	assert.Equal(t, 2, deleted)
+
+	keys, err := configCenter.List(ctx, "prefix-ops")
+	require.NoError(t, err)
+	assert.Empty(t, keys)
+	var value string
+	require.NoError(t, configCenter.Get(ctx, "prefix-ops-sibling", &value))
+
+	deleted, err = configCenter.DeletePrefix(ctx, "prefix-ops")
+	require.NoError(t, err)
+	assert.Equal(t, 0, deleted)
+
+	for _, prefix := range []string{"", "/", "..", "prefix-ops/../.."} {
+		_, err = configCenter.DeletePrefix(ctx, prefix)
+		assert.Error(t, err, prefix)
+	}
+	require.NoError(t, configCenter.Get(ctx, "prefix-ops-sibling", &value))
+}
+
 // TestEtcdConfigCenter_Watch 测试配置监听
 func TestEtcdConfigCenter_Watch(t *testing.T) {
 	client, err := createTestEtcdClient()
@@ -351,6 +869,127 @@ func TestEtcdConfigCenter_Watch(t *testing.T) {
 		}
 	})
 
+	// 续传测试使用独立前缀，不受其他子测试写入的影响
+	resumeCenter := NewEtcdConfigCenter(client, "/test-config-resume", logger)
+
+	t.Run("resume from start revision", func(t *testing.T) {
+		key := "watch-resume-test"
+		var targetValue string
+		defer resumeCenter.Delete(ctx, key)
+
+		require.NoError(t, resumeCenter.Set(ctx, key, "v1"))
+		version, err := resumeCenter.GetWithVersion(ctx, key, &targetValue)
+		require.NoError(t, err)
+		require.NoError(t, resumeCenter.Set(ctx, key, "v2"))
+		require.NoError(t, resumeCenter.Set(ctx, key, "v3"))
+
+		// 从 v1 之后续传，应收到监听建立之前写入的 v2 和 v3
+		watcher, err := resumeCenter.Watch(ctx, key, &targetValue, config.WithStartRevision(version+1))
+		require.NoError(t, err)
+		defer watcher.Close()
+
+		lastRevision := version
+		for _, expected := range []string{"v2", "v3"} {
+			select {
+			case event := <-watcher.Chan():
+				assert.Equal(t, expected, event.Value)
+				assert.Greater(t, event.Revision, lastRevision)
+				lastRevision = event.Revision
+			case <-time.After(time.Second * 2):
+				t.Fatal("Timeout waiting for resumed config event")
+			}
+		}
+
+	})
+
+	t.Run("resume from compacted revision", func(t *testing.T) {
+		requireCompaction(t)
+
+		key := "watch-compacted-test"
+		var targetValue string
+		defer resumeCenter.Delete(ctx, key)
+		require.NoError(t, resumeCenter.Set(ctx, key, "v1"))
+		version, err := resumeCenter.GetWithVersion(ctx, key, &targetValue)
+		require.NoError(t, err)
+		require.NoError(t, resumeCenter.Set(ctx, key, "v2"))
+		latest, err := resumeCenter.GetWithVersion(ctx, key, &targetValue)
+		require.NoError(t, err)
+
+		// 压缩之后从旧修订号续传应返回 ErrCompacted
+		_, err = client.Client().Compact(ctx, latest)
+		require.NoError(t, err)
+		_, err = resumeCenter.Watch(ctx, key, &targetValue, config.WithStartRevision(version))
+		assert.ErrorIs(t, err, config.ErrCompacted)
+	})
+
+	t.Run("decode errors are reported per event", func(t *testing.T) {
+		type appConfig struct {
+			Port int `json:"port"`
+		}
+		key := "watch-decode-test"
+		var target appConfig
+		watcher, err := configCenter.Watch(ctx, key, &target)
+		require.NoError(t, err)
+		defer watcher.Close()
+
+		var anyTarget interface{}
+		anyWatcher, err := configCenter.Watch(ctx, key, &anyTarget)
+		require.NoError(t, err)
+		defer anyWatcher.Close()
+
+		next := func(w config.Watcher[any]) config.ConfigEvent[any] {
+			select {
+			case event := <-w.Chan():
+				return event
+			case <-time.After(time.Second * 2):
+				t.Fatal("Timeout waiting for config event")
+				return config.ConfigEvent[any]{}
+			}
+		}
+
+		// 普通字符串无法解码为结构体，但对 interface{} 是合法值
+		require.NoError(t, configCenter.Set(ctx, key, "plain-text"))
+		event := next(watcher)
+		assert.Error(t, event.Err)
+		assert.Equal(t, "plain-text", event.Value)
+		event = next(anyWatcher)
+		assert.NoError(t, event.Err)
+		assert.Equal(t, "plain-text", event.Value)
+
+		// 形如 JSON 却无法解析的值对两种监听都是错误
+		require.NoError(t, configCenter.Set(ctx, key, `{"port": 80`+"}}"))
+		assert.Error(t, next(watcher).Err)
+		assert.Error(t, next(anyWatcher).Err)
+
+		// 之后的合法值照常投递
+		require.NoError(t, configCenter.Set(ctx, key, appConfig{Port: 8080}))
+		event = next(watcher)
+		require.NoError(t, event.Err)
+		assert.Equal(t, appConfig{Port: 8080}, event.Value)
+		assert.NoError(t, next(anyWatcher).Err)
+	})
+
+	t.Run("errors channel closes with watcher", func(t *testing.T) {
+		var targetValue string
+		watcher, err := configCenter.Watch(ctx, "watch-errors-test", &targetValue)
+		require.NoError(t, err)
+
+		// 正常运行的监听不报告错误
+		select {
+		case err := <-watcher.Errors():
+			t.Fatalf("unexpected watch error: %v", err)
+		case <-time.After(200 * time.Millisecond):
+		}
+
+		watcher.Close()
+		select {
+		case _, ok := <-watcher.Errors():
+			assert.False(t, ok, "errors channel should be closed after Close")
+		case <-time.After(time.Second * 2):
+			t.Fatal("Timeout waiting for errors channel to close")
+		}
+	})
+
 	t.Run("watch with empty key", func(t *testing.T) {
 		var targetValue string
 		watcher, err := configCenter.Watch(ctx, "", &targetValue)
@@ -419,6 +1058,25 @@ func TestEtcdConfigCenter_List(t *testing.T) {
 		assert.NoError(t, err)
 		assert.Empty(t, keys)
 	})
+
+	t.Run("list children", func(t *testing.T) {
+		children, err := configCenter.ListChildren(ctx, "list-test")
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"key1", "key2", "sub"}, children)
+
+		children, err = configCenter.ListChildren(ctx, "list-test/sub/")
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"key3"}, children)
+
+		children, err = configCenter.ListChildren(ctx, "")
+		assert.NoError(t, err)
+		assert.Contains(t, children, "list-test")
+		assert.Contains(t, children, "other-key")
+
+		children, err = configCenter.ListChildren(ctx, "non-existent")
+		assert.NoError(t, err)
+		assert.Empty(t, children)
+	})
 }
 
 // TestEtcdConfigCenter_ConcurrentOperations 测试并发操作
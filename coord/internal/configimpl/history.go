@@ -0,0 +1,31 @@
+package configimpl
+
+import (
+	"time"
+
+	"github.com/ceyewan/infra-kit/coord/config"
+)
+
+// auditTimestamps 从指定键的审计记录中提取各版本的写入时间，values 为审计前缀下读取到的原始记录
+func auditTimestamps(values [][]byte, key string) (map[int64]time.Time, error) {
+	entries, err := decodeAuditHistory(values, key, 0)
+	if err != nil {
+		return nil, err
+	}
+	timestamps := make(map[int64]time.Time, len(entries))
+	for _, entry := range entries {
+		timestamps[entry.Version] = entry.Timestamp
+	}
+	return timestamps, nil
+}
+
+// versionedValue 解码一个历史版本，解码规则与以 interface{} 监听时相同
+func (c *valueCodec) versionedValue(data []byte, key string, version int64, timestamps map[int64]time.Time) config.VersionedValue {
+	value, err := c.parseAsInterface(data, key)
+	return config.VersionedValue{
+		Version:   version,
+		Value:     value,
+		Timestamp: timestamps[version],
+		Err:       err,
+	}
+}
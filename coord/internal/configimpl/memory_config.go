@@ -0,0 +1,480 @@
+package configimpl
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/ceyewan/infra-kit/clog"
+	"github.com/ceyewan/infra-kit/coord/config"
+	"github.com/ceyewan/infra-kit/coord/internal/client"
+	"github.com/ceyewan/infra-kit/coord/internal/memstore"
+)
+
+// MemoryConfigCenter 使用进程内存储实现 config.ConfigCenter 接口，仅用于测试
+// 版本号、CAS、Move 和监听语义与 EtcdConfigCenter 保持一致
+type MemoryConfigCenter struct {
+	valueCodec                  // 配置值编解码，携带日志记录器
+	store       *memstore.Store // 进程内存储
+	prefix      string          // 配置前缀
+	auditPrefix string          // 审计记录前缀，为空表示不记录审计
+}
+
+// NewMemoryConfigCenter 创建一个基于进程内存储的配置中心，默认使用 JSON 编码
+func NewMemoryConfigCenter(store *memstore.Store, prefix string, logger clog.Logger, opts ...config.Option) *MemoryConfigCenter {
+	if prefix == "" {
+		prefix = "/config"
+	}
+	if logger == nil {
+		logger = clog.Namespace("coordination.config")
+	}
+	options := config.ParseOptions(opts...)
+	return &MemoryConfigCenter{
+		valueCodec:  valueCodec{codec: options.Codec, logger: logger},
+		store:       store,
+		prefix:      prefix,
+		auditPrefix: options.AuditPrefix,
+	}
+}
+
+// Get 获取配置值并反序列化到提供的类型 v
+func (c *MemoryConfigCenter) Get(ctx context.Context, key string, v interface{}) error {
+	_, err := c.GetWithVersion(ctx, key, v)
+	return err
+}
+
+// GetWithVersion 获取配置值和版本信息
+func (c *MemoryConfigCenter) GetWithVersion(ctx context.Context, key string, v interface{}) (int64, error) {
+	if key == "" {
+		return 0, client.NewError(client.ErrCodeValidation, "config key cannot be empty", nil)
+	}
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return 0, client.NewError(client.ErrCodeValidation, "target value must be a non-nil pointer", nil)
+	}
+
+	kv, ok := c.store.Get(path.Join(c.prefix, key))
+	if !ok {
+		return 0, client.NewError(client.ErrCodeNotFound, "config key not found", nil)
+	}
+	if err := c.unmarshalValue(kv.Value, v); err != nil {
+		return 0, err
+	}
+	return kv.ModRevision, nil
+}
+
+// CompareAndSet 原子地比较并设置配置值，expectedVersion 为 0 表示键不存在
+func (c *MemoryConfigCenter) CompareAndSet(ctx context.Context, key string, value interface{}, expectedVersion int64) error {
+	if key == "" {
+		return client.NewError(client.ErrCodeValidation, "config key cannot be empty", nil)
+	}
+	valueBytes, err := c.marshalValue(value)
+	if err != nil {
+		return client.NewError(client.ErrCodeValidation, "failed to serialize config value", err)
+	}
+
+	configKey := path.Join(c.prefix, key)
+	return c.txn(func(tx *memstore.Txn) error {
+		old, exists := tx.Get(configKey)
+		if old.ModRevision != expectedVersion {
+			return client.NewError(client.ErrCodeConflict, "config version mismatch, update rejected", nil).WithKind(config.ErrVersionMismatch)
+		}
+		if err := tx.Put(configKey, valueBytes, 0); err != nil {
+			return err
+		}
+		return c.audit(ctx, tx, key, config.AuditOpCompareAndSet, oldValue(old, exists), valueBytes)
+	})
+}
+
+// CompareAndDelete 原子地比较并删除配置
+func (c *MemoryConfigCenter) CompareAndDelete(ctx context.Context, key string, expectedVersion int64) error {
+	if key == "" {
+		return client.NewError(client.ErrCodeValidation, "config key cannot be empty", nil)
+	}
+
+	configKey := path.Join(c.prefix, key)
+	return c.txn(func(tx *memstore.Txn) error {
+		old, exists := tx.Get(configKey)
+		if !exists {
+			return client.NewError(client.ErrCodeNotFound, "config key not found for deletion", nil)
+		}
+		if old.ModRevision != expectedVersion {
+			return client.NewError(client.ErrCodeConflict, "config version mismatch, delete rejected", nil).WithKind(config.ErrVersionMismatch)
+		}
+		tx.Delete(configKey)
+		return c.audit(ctx, tx, key, config.AuditOpCompareAndDelete, old.Value, nil)
+	})
+}
+
+// CompareValueAndSet 当前值等于 expected 时写入，比较和写入在同一个存储事务中完成
+func (c *MemoryConfigCenter) CompareValueAndSet(ctx context.Context, key string, expected, value interface{}) error {
+	if key == "" {
+		return client.NewError(client.ErrCodeValidation, "config key cannot be empty", nil)
+	}
+	if expected == nil {
+		return client.NewError(client.ErrCodeValidation, "expected value cannot be nil", nil)
+	}
+	valueBytes, err := c.marshalValue(value)
+	if err != nil {
+		return client.NewError(client.ErrCodeValidation, "failed to serialize config value", err)
+	}
+
+	configKey := path.Join(c.prefix, key)
+	return c.txn(func(tx *memstore.Txn) error {
+		old, exists := tx.Get(configKey)
+		if !exists {
+			return client.NewError(client.ErrCodeNotFound, "config key not found", nil)
+		}
+		if !c.valueEquals(old.Value, expected) {
+			return client.NewError(client.ErrCodeConflict, "config value mismatch, update rejected", config.ErrValueMismatch)
+		}
+		if err := tx.Put(configKey, valueBytes, 0); err != nil {
+			return err
+		}
+		return c.audit(ctx, tx, key, config.AuditOpCompareAndSet, old.Value, valueBytes)
+	})
+}
+
+// SetIfAbsent 仅当键不存在时才创建配置值
+func (c *MemoryConfigCenter) SetIfAbsent(ctx context.Context, key string, value interface{}) (bool, error) {
+	if key == "" {
+		return false, client.NewError(client.ErrCodeValidation, "config key cannot be empty", nil)
+	}
+	valueBytes, err := c.marshalValue(value)
+	if err != nil {
+		return false, client.NewError(client.ErrCodeValidation, "failed to serialize config value", err)
+	}
+
+	configKey := path.Join(c.prefix, key)
+	created := false
+	err = c.txn(func(tx *memstore.Txn) error {
+		if modRevision(tx, configKey) != 0 {
+			return nil
+		}
+		created = true
+		if err := tx.Put(configKey, valueBytes, 0); err != nil {
+			return err
+		}
+		return c.audit(ctx, tx, key, config.AuditOpSetIfAbsent, nil, valueBytes)
+	})
+	return created, err
+}
+
+// Move 原子地将配置从 src 移动到 dst，两个事件共享同一个版本号
+func (c *MemoryConfigCenter) Move(ctx context.Context, src, dst string, overwrite bool) error {
+	if src == "" || dst == "" {
+		return client.NewError(client.ErrCodeValidation, "config key cannot be empty", nil)
+	}
+
+	srcKey := path.Join(c.prefix, src)
+	dstKey := path.Join(c.prefix, dst)
+	if srcKey == dstKey {
+		return client.NewError(client.ErrCodeValidation, "source and destination config keys must differ", nil)
+	}
+
+	return c.txn(func(tx *memstore.Txn) error {
+		kv, ok := tx.Get(srcKey)
+		if !ok {
+			return client.NewError(client.ErrCodeNotFound, "config key not found", nil)
+		}
+		if !overwrite && modRevision(tx, dstKey) != 0 {
+			return client.NewError(client.ErrCodeConflict, "destination config key already exists", config.ErrExists)
+		}
+		oldDst, dstExists := tx.Get(dstKey)
+		if err := tx.Put(dstKey, kv.Value, 0); err != nil {
+			return err
+		}
+		tx.Delete(srcKey)
+		if err := c.audit(ctx, tx, dst, config.AuditOpMove, oldValue(oldDst, dstExists), kv.Value); err != nil {
+			return err
+		}
+		return c.audit(ctx, tx, src, config.AuditOpMove, kv.Value, nil)
+	})
+}
+
+// Set 序列化并存储配置值
+func (c *MemoryConfigCenter) Set(ctx context.Context, key string, value interface{}) error {
+	if key == "" {
+		return client.NewError(client.ErrCodeValidation, "config key cannot be empty", nil)
+	}
+	valueBytes, err := c.marshalValue(value)
+	if err != nil {
+		return client.NewError(client.ErrCodeValidation, "failed to serialize config value", err)
+	}
+
+	configKey := path.Join(c.prefix, key)
+	return c.txn(func(tx *memstore.Txn) error {
+		old, exists := tx.Get(configKey)
+		if err := tx.Put(configKey, valueBytes, 0); err != nil {
+			return err
+		}
+		return c.audit(ctx, tx, key, config.AuditOpSet, oldValue(old, exists), valueBytes)
+	})
+}
+
+// Delete 删除配置键
+func (c *MemoryConfigCenter) Delete(ctx context.Context, key string) error {
+	if key == "" {
+		return client.NewError(client.ErrCodeValidation, "config key cannot be empty", nil)
+	}
+
+	configKey := path.Join(c.prefix, key)
+	return c.txn(func(tx *memstore.Txn) error {
+		old, exists := tx.Get(configKey)
+		if !exists {
+			return client.NewError(client.ErrCodeNotFound, "config key not found for deletion", nil)
+		}
+		tx.Delete(configKey)
+		return c.audit(ctx, tx, key, config.AuditOpDelete, old.Value, nil)
+	})
+}
+
+// DeletePrefix 在一个事务中删除前缀下的所有键
+func (c *MemoryConfigCenter) DeletePrefix(ctx context.Context, prefix string) (int, error) {
+	searchPrefix, err := deletePrefixKey(c.prefix, prefix)
+	if err != nil {
+		return 0, err
+	}
+
+	deleted := 0
+	err = c.txn(func(tx *memstore.Txn) error {
+		for _, kv := range tx.GetPrefix(searchPrefix) {
+			tx.Delete(kv.Key)
+			deleted++
+			if err := c.audit(ctx, tx, strings.TrimPrefix(kv.Key, c.prefix+"/"), config.AuditOpDeletePrefix, kv.Value, nil); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return deleted, nil
+}
+
+// AuditHistory 返回指定键的审计记录，按时间从新到旧排列
+func (c *MemoryConfigCenter) AuditHistory(ctx context.Context, key string, limit int) ([]config.AuditEntry, error) {
+	if err := validateAuditQuery(c.auditPrefix, key); err != nil {
+		return nil, err
+	}
+
+	kvs := c.store.GetPrefix(auditSearchPrefix(c.auditPrefix, key))
+	values := make([][]byte, len(kvs))
+	for i, kv := range kvs {
+		values[len(kvs)-1-i] = kv.Value
+	}
+	return decodeAuditHistory(values, key, limit)
+}
+
+// History 返回存储保留的历史版本，存储每个键最多保留最近的若干个版本，更早的版本视为已被压缩，历史在此截断
+func (c *MemoryConfigCenter) History(ctx context.Context, key string, limit int) ([]config.VersionedValue, error) {
+	if key == "" {
+		return nil, client.NewError(client.ErrCodeValidation, "config key cannot be empty", nil)
+	}
+
+	versions, _ := c.store.History(path.Join(c.prefix, key))
+	if len(versions) == 0 {
+		return nil, client.NewError(client.ErrCodeNotFound, "config key not found", nil)
+	}
+	if limit > 0 && len(versions) > limit {
+		versions = versions[:limit]
+	}
+
+	var timestamps map[int64]time.Time
+	if c.auditPrefix != "" {
+		kvs := c.store.GetPrefix(auditSearchPrefix(c.auditPrefix, key))
+		values := make([][]byte, len(kvs))
+		for i, kv := range kvs {
+			values[i] = kv.Value
+		}
+		var err error
+		if timestamps, err = auditTimestamps(values, key); err != nil {
+			return nil, err
+		}
+	}
+
+	history := make([]config.VersionedValue, len(versions))
+	for i, kv := range versions {
+		history[i] = c.versionedValue(kv.Value, key, kv.ModRevision, timestamps)
+	}
+	return history, nil
+}
+
+// Rollback 将键恢复为存储保留的某个历史版本的值，版本已超出保留范围时返回 ErrCompacted
+func (c *MemoryConfigCenter) Rollback(ctx context.Context, key string, toVersion int64) error {
+	if key == "" {
+		return client.NewError(client.ErrCodeValidation, "config key cannot be empty", nil)
+	}
+	if toVersion <= 0 {
+		return client.NewError(client.ErrCodeValidation, "config version must be positive", nil)
+	}
+
+	configKey := path.Join(c.prefix, key)
+	return c.txn(func(tx *memstore.Txn) error {
+		versions, compacted := tx.History(configKey)
+		idx := slices.IndexFunc(versions, func(kv memstore.KeyValue) bool { return kv.ModRevision == toVersion })
+		switch {
+		case idx >= 0:
+		case toVersion <= compacted:
+			return client.NewError(client.ErrCodeNotFound,
+				fmt.Sprintf("config version %d has been compacted", toVersion), config.ErrCompacted)
+		default:
+			return client.NewError(client.ErrCodeValidation,
+				fmt.Sprintf("config version %d is not a version of key %s", toVersion, key), nil)
+		}
+
+		// 历史非空说明键当前存在，第一个元素即当前版本
+		value := versions[idx].Value
+		if err := tx.Put(configKey, value, 0); err != nil {
+			return err
+		}
+		return c.audit(ctx, tx, key, config.AuditOpRollback, versions[0].Value, value)
+	})
+}
+
+// audit 在同一事务内追加审计记录，与配置写入共享版本号，未启用审计时不做任何事
+func (c *MemoryConfigCenter) audit(ctx context.Context, tx *memstore.Txn, key string, op config.AuditOperation, oldValue, newValue []byte) error {
+	if c.auditPrefix == "" {
+		return nil
+	}
+
+	data, err := encodeAuditEntry(ctx, key, op, oldValue, newValue, tx.Revision())
+	if err != nil {
+		return client.NewError(client.ErrCodeValidation, "failed to serialize config audit entry", err)
+	}
+	return tx.Put(auditKey(c.auditPrefix, key, tx.Revision()), data, 0)
+}
+
+// Watch 监听单个配置键的变更
+func (c *MemoryConfigCenter) Watch(ctx context.Context, key string, v interface{}, opts ...config.WatchOption) (config.Watcher[any], error) {
+	if key == "" {
+		return nil, client.NewError(client.ErrCodeValidation, "config key cannot be empty", nil)
+	}
+	return c.watch(ctx, path.Join(c.prefix, key), v, false, config.ParseWatchOptions(opts...))
+}
+
+// WatchPrefix 监听指定前缀下所有配置键的变更
+func (c *MemoryConfigCenter) WatchPrefix(ctx context.Context, prefix string, v interface{}, opts ...config.WatchOption) (config.Watcher[any], error) {
+	if prefix == "" {
+		return nil, client.NewError(client.ErrCodeValidation, "config prefix cannot be empty", nil)
+	}
+	return c.watch(ctx, path.Join(c.prefix, prefix), v, true, config.ParseWatchOptions(opts...))
+}
+
+// List 列出指定前缀下的所有配置键
+func (c *MemoryConfigCenter) List(ctx context.Context, prefix string) ([]string, error) {
+	searchPrefix := path.Join(c.prefix, prefix)
+	if !strings.HasSuffix(searchPrefix, "/") {
+		searchPrefix += "/"
+	}
+
+	kvs := c.store.GetPrefix(searchPrefix)
+	keys := make([]string, len(kvs))
+	for i, kv := range kvs {
+		keys[i] = strings.TrimPrefix(kv.Key, c.prefix+"/")
+	}
+	return keys, nil
+}
+
+// ListChildren 列出前缀下一级的子节点名称
+func (c *MemoryConfigCenter) ListChildren(ctx context.Context, prefix string) ([]string, error) {
+	searchPrefix := path.Join(c.prefix, prefix)
+	if !strings.HasSuffix(searchPrefix, "/") {
+		searchPrefix += "/"
+	}
+
+	kvs := c.store.GetPrefix(searchPrefix)
+	keys := make([]string, len(kvs))
+	for i, kv := range kvs {
+		keys[i] = kv.Key
+	}
+	return childNames(keys, searchPrefix), nil
+}
+
+// watch 内部实现，监听单个键或前缀
+func (c *MemoryConfigCenter) watch(ctx context.Context, keyOrPrefix string, v interface{}, isPrefix bool, watchOpts *config.WatchOptions) (config.Watcher[any], error) {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return nil, client.NewError(client.ErrCodeValidation, "target value type must be a non-nil pointer", nil)
+	}
+	valueType := rv.Type().Elem()
+
+	watchCtx, cancel := context.WithCancel(ctx)
+	storeCh, err := c.store.WatchFrom(watchCtx, keyOrPrefix, isPrefix, watchOpts.StartRevision)
+	if err != nil {
+		cancel()
+		return nil, client.NewError(client.ErrCodeNotFound, "watch start revision has been compacted", config.ErrCompacted)
+	}
+	eventCh := make(chan config.ConfigEvent[any], 10)
+
+	rawCh := eventCh
+	if watchOpts.Debounce > 0 {
+		rawCh = make(chan config.ConfigEvent[any], 10)
+		go debounceEvents(watchCtx, rawCh, eventCh, watchOpts.Debounce)
+	}
+
+	// 内存存储不会断线，错误通道只在监听结束时关闭
+	w := &etcdWatcher{ch: eventCh, errs: make(chan error), cancel: cancel}
+
+	go func() {
+		defer close(rawCh)
+		defer close(w.errs)
+		for event := range storeCh {
+			configEvent := c.convertEvent(event, valueType)
+			select {
+			case rawCh <- configEvent:
+			case <-watchCtx.Done():
+				return
+			}
+		}
+	}()
+
+	// 关闭监听只需取消 context，与 etcd 实现共用 watcher
+	return w, nil
+}
+
+// convertEvent 将存储事件转换为配置事件
+func (c *MemoryConfigCenter) convertEvent(event memstore.Event, valueType reflect.Type) config.ConfigEvent[any] {
+	relativeKey := strings.TrimPrefix(event.KV.Key, c.prefix+"/")
+	configEvent := config.ConfigEvent[any]{
+		Type:     config.EventTypeDelete,
+		Key:      relativeKey,
+		Version:  event.KV.ModRevision,
+		Revision: event.KV.ModRevision,
+	}
+	if event.Type == memstore.EventPut {
+		configEvent.Type = config.EventTypePut
+		configEvent.Value, configEvent.Err = c.parseEventValue(event.KV.Value, valueType, relativeKey)
+	}
+	return configEvent
+}
+
+// txn 执行事务，将存储关闭转换为协调器错误
+func (c *MemoryConfigCenter) txn(fn func(tx *memstore.Txn) error) error {
+	err := c.store.Txn(fn)
+	if err == memstore.ErrClosed {
+		return client.NewError(client.ErrCodeUnavailable, "config store is closed", err)
+	}
+	return err
+}
+
+// oldValue 返回写入前的值，键不存在时为 nil
+func oldValue(kv memstore.KeyValue, exists bool) []byte {
+	if !exists {
+		return nil
+	}
+	return kv.Value
+}
+
+// modRevision 返回键的修订号，键不存在时为 0
+func modRevision(tx *memstore.Txn, key string) int64 {
+	if kv, ok := tx.Get(key); ok {
+		return kv.ModRevision
+	}
+	return 0
+}
@@ -0,0 +1,19 @@
+package configimpl
+
+import (
+	"path"
+	"strings"
+
+	"github.com/ceyewan/infra-kit/coord/config"
+	"github.com/ceyewan/infra-kit/coord/internal/client"
+)
+
+// deletePrefixKey 返回 DeletePrefix 实际删除的完整键前缀，以分隔符结尾
+// prefix 为空、只含分隔符或经 ".." 指向配置前缀本身及其之外时返回校验错误，防止误删整个配置中心乃至其他数据
+func deletePrefixKey(configPrefix, prefix string) (string, error) {
+	searchKey := path.Join(configPrefix, prefix)
+	if strings.Trim(prefix, config.KeySeparator) == "" || !strings.HasPrefix(searchKey, configPrefix+config.KeySeparator) {
+		return "", client.NewError(client.ErrCodeValidation, "config prefix cannot be empty or point to the config root", nil)
+	}
+	return searchKey + config.KeySeparator, nil
+}
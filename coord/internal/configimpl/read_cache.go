@@ -0,0 +1,113 @@
+package configimpl
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// readCache Get 的本地读缓存，条目在 TTL 到期或收到变更事件时失效
+// 每个条目记录对应的 etcd 修订号，失效时留下短期墓碑，
+// 避免失效之前发起、之后才返回的读取把旧值重新写回缓存
+type readCache struct {
+	ttl time.Duration
+
+	mu        sync.Mutex
+	entries   map[string]cacheEntry
+	lastSweep time.Time
+	watching  bool               // 失效监听是否在运行
+	since     int64              // 失效监听覆盖的起始修订号，早于它的读取结果可能错过变更，不缓存
+	cancel    context.CancelFunc // 停止失效监听
+	closed    bool               // 已关闭，不再缓存也不再建立监听
+}
+
+// cacheEntry 缓存条目
+type cacheEntry struct {
+	value     []byte
+	revision  int64 // 读取时的集群修订号，墓碑为失效事件的修订号
+	expires   time.Time
+	tombstone bool
+}
+
+// newReadCache 创建读缓存
+func newReadCache(ttl time.Duration) *readCache {
+	return &readCache{ttl: ttl, entries: make(map[string]cacheEntry)}
+}
+
+// get 返回未过期的缓存值
+func (rc *readCache) get(key string) ([]byte, bool) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	entry, ok := rc.entries[key]
+	if rc.closed || !ok || entry.tombstone || time.Now().After(entry.expires) {
+		return nil, false
+	}
+	return entry.value, true
+}
+
+// put 缓存读取结果，revision 早于已知变更或失效监听的起点、监听未运行时丢弃
+func (rc *readCache) put(key string, value []byte, revision int64) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	if !rc.watching || revision < rc.since {
+		return
+	}
+	if entry, ok := rc.entries[key]; ok && entry.revision > revision {
+		return
+	}
+	rc.entries[key] = cacheEntry{value: value, revision: revision, expires: time.Now().Add(rc.ttl)}
+}
+
+// invalidate 使键失效，revision 为变更发生时的修订号
+func (rc *readCache) invalidate(key string, revision int64) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	now := time.Now()
+	if entry, ok := rc.entries[key]; !ok || entry.revision <= revision {
+		rc.entries[key] = cacheEntry{revision: revision, expires: now.Add(rc.ttl), tombstone: true}
+	}
+
+	// 按 TTL 周期清理过期条目和墓碑，避免监听前缀下的变更使缓存无限增长
+	if now.Sub(rc.lastSweep) >= rc.ttl {
+		for k, entry := range rc.entries {
+			if now.After(entry.expires) {
+				delete(rc.entries, k)
+			}
+		}
+		rc.lastSweep = now
+	}
+}
+
+// startWatch 标记失效监听从 revision 之后开始运行，已在运行或已关闭时返回 false
+// 监听覆盖 revision 之后的所有变更，因此修订号不早于 revision 的读取结果都可以缓存
+func (rc *readCache) startWatch(revision int64, cancel context.CancelFunc) bool {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	if rc.watching || rc.closed {
+		return false
+	}
+	rc.watching = true
+	rc.since = revision
+	rc.cancel = cancel
+	return true
+}
+
+// reset 失效监听退出时清空缓存，下次读取时重新建立监听
+func (rc *readCache) reset() {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	rc.entries = make(map[string]cacheEntry)
+	rc.watching = false
+	rc.cancel = nil
+}
+
+// close 停止失效监听并清空缓存，之后的读取直接访问 etcd
+func (rc *readCache) close() {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	if rc.cancel != nil {
+		rc.cancel()
+	}
+	rc.entries = make(map[string]cacheEntry)
+	rc.closed = true
+}
@@ -0,0 +1,39 @@
+package configimpl
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestReadCache_WatchLifecycle 测试读缓存只缓存失效监听覆盖的读取，关闭时停止监听
+func TestReadCache_WatchLifecycle(t *testing.T) {
+	rc := newReadCache(time.Minute)
+
+	// 监听未运行时不缓存
+	rc.put("/config/a", []byte("v1"), 10)
+	_, ok := rc.get("/config/a")
+	assert.False(t, ok)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	assert.True(t, rc.startWatch(10, cancel))
+	assert.False(t, rc.startWatch(12, cancel), "only one watch runs at a time")
+
+	// 早于监听起点的读取可能错过变更，不缓存
+	rc.put("/config/a", []byte("v0"), 9)
+	_, ok = rc.get("/config/a")
+	assert.False(t, ok)
+
+	rc.put("/config/a", []byte("v1"), 10)
+	value, ok := rc.get("/config/a")
+	assert.True(t, ok)
+	assert.Equal(t, []byte("v1"), value)
+
+	rc.close()
+	assert.ErrorIs(t, ctx.Err(), context.Canceled)
+	_, ok = rc.get("/config/a")
+	assert.False(t, ok)
+	assert.False(t, rc.startWatch(11, func() {}), "closed cache does not restart the watch")
+}
@@ -0,0 +1,134 @@
+package configimpl
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+
+	"github.com/ceyewan/infra-kit/clog"
+	"github.com/ceyewan/infra-kit/coord/config"
+	"github.com/ceyewan/infra-kit/coord/internal/client"
+)
+
+// valueCodec 配置值的编解码逻辑，由 etcd 和内存配置中心共用
+type valueCodec struct {
+	codec  config.Codec // 配置值编解码方式
+	logger clog.Logger  // 日志记录器
+}
+
+// marshalValue 序列化值，优先处理 string 和 []byte，否则使用配置的编码
+func (c *valueCodec) marshalValue(value interface{}) ([]byte, error) {
+	switch v := value.(type) {
+	case string:
+		return []byte(v), nil
+	case []byte:
+		return v, nil
+	default:
+		return c.codec.Marshal(value)
+	}
+}
+
+// unmarshalValue 反序列化值，优先使用配置的编码，失败则尝试字符串
+func (c *valueCodec) unmarshalValue(data []byte, v interface{}) error {
+	decodeErr := c.codec.Unmarshal(data, v)
+	if decodeErr == nil {
+		return nil
+	}
+
+	// 如果目标是 *string，则直接赋值
+	if strPtr, ok := v.(*string); ok {
+		*strPtr = string(data)
+		return nil
+	}
+
+	// 非 *string 且解码失败，返回错误
+	return client.NewError(client.ErrCodeValidation, "value is not valid "+codecName(c.codec)+" for the target type", decodeErr)
+}
+
+// valueEquals 判断编码后的配置值是否等于 expected
+// 与 marshalValue 对应，string 和 []byte 直接比较原始内容；其他类型解码为 expected 的类型后深度比较，
+// expected 为指针时比较其指向的值，解码失败视为不相等
+func (c *valueCodec) valueEquals(data []byte, expected interface{}) bool {
+	switch v := expected.(type) {
+	case string:
+		return string(data) == v
+	case []byte:
+		return bytes.Equal(data, v)
+	}
+
+	ev := reflect.ValueOf(expected)
+	for ev.Kind() == reflect.Ptr {
+		if ev.IsNil() {
+			return false
+		}
+		ev = ev.Elem()
+	}
+	current := reflect.New(ev.Type())
+	if err := c.unmarshalValue(data, current.Interface()); err != nil {
+		return false
+	}
+	return reflect.DeepEqual(current.Elem().Interface(), ev.Interface())
+}
+
+// codecName 返回内置编码的名称，用于错误信息
+func codecName(codec config.Codec) string {
+	switch codec {
+	case config.JSONCodec:
+		return "JSON"
+	case config.YAMLCodec:
+		return "YAML"
+	case config.TOMLCodec:
+		return "TOML"
+	default:
+		return "encoded data"
+	}
+}
+
+// parseEventValue 智能解析事件值，支持多种类型处理策略
+// 无法解码为目标类型时返回原始字符串和解码错误，由事件的 Err 字段交给调用方，事件本身不丢弃
+func (c *valueCodec) parseEventValue(data []byte, valueType reflect.Type, key string) (interface{}, error) {
+	// 如果目标类型是 interface{}，尝试自动推断类型
+	if valueType.Kind() == reflect.Interface && valueType.NumMethod() == 0 {
+		return c.parseAsInterface(data, key)
+	}
+
+	// 尝试解析为目标类型
+	newValue := reflect.New(valueType).Interface()
+	if err := c.unmarshalValue(data, newValue); err != nil {
+		c.logger.Warn("Failed to unmarshal event value, returning raw string",
+			clog.String("key", key),
+			clog.String("target_type", valueType.String()),
+			clog.Err(err))
+		return string(data), fmt.Errorf("failed to decode config value for key %s as %s: %w", key, valueType, err)
+	}
+
+	return reflect.ValueOf(newValue).Elem().Interface(), nil
+}
+
+// parseAsInterface 当目标类型是 interface{} 时，自动推断最合适的类型
+// 无法解码的值按普通字符串返回；但形如 JSON 对象或数组的值解码失败说明写入的数据有误，同时返回错误
+func (c *valueCodec) parseAsInterface(data []byte, key string) (interface{}, error) {
+	// 首先尝试使用配置的编码解析
+	var value interface{}
+	err := c.codec.Unmarshal(data, &value)
+	if err == nil {
+		return value, nil
+	}
+
+	// 解析失败，返回字符串
+	if looksStructured(data) {
+		return string(data), client.NewError(client.ErrCodeValidation,
+			fmt.Sprintf("config value for key %s looks structured but is not valid %s", key, codecName(c.codec)), err)
+	}
+	return string(data), nil
+}
+
+// looksStructured 判断值是否以 JSON 对象或数组的形式开头和结尾
+func looksStructured(data []byte) bool {
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) < 2 {
+		return false
+	}
+	first, last := trimmed[0], trimmed[len(trimmed)-1]
+	return (first == '{' && last == '}') || (first == '[' && last == ']')
+}
@@ -0,0 +1,331 @@
+package electionimpl
+
+import (
+	"context"
+	"errors"
+	"path"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/ceyewan/infra-kit/clog"
+	"github.com/ceyewan/infra-kit/coord/election"
+	"github.com/ceyewan/infra-kit/coord/internal/client"
+	"go.etcd.io/etcd/api/v3/v3rpc/rpctypes"
+	"go.etcd.io/etcd/client/v3/concurrency"
+)
+
+// EtcdElection 使用 etcd concurrency.Election 实现 election.Election 接口
+// 会话租约过期后自动重建会话和选举原语，失去的 leader 身份需要重新调用 Campaign 竞选
+type EtcdElection struct {
+	client      *client.EtcdClient // etcd 客户端，用于重建会话
+	ttl         int                // 会话租约 TTL（秒）
+	key         string             // 选举前缀
+	candidateID string             // 候选者标识
+	logger      clog.Logger        // 日志记录器
+
+	mu       sync.RWMutex
+	session  *concurrency.Session  // etcd 会话，管理候选者租约
+	election *concurrency.Election // etcd 选举原语，随会话一起重建
+
+	leader    atomic.Bool        // 当前是否为 leader
+	changedCh chan string        // leader 变更通知通道
+	ctx       context.Context    // 后台协程和会话的生命周期，Close 时取消
+	cancel    context.CancelFunc // 停止 leader 观察和会话重建
+	wg        sync.WaitGroup     // 等待后台协程退出
+	closeOnce sync.Once
+}
+
+// NewEtcdElection 创建一个基于 etcd 的选举，并开始观察 leader 变更
+func NewEtcdElection(c *client.EtcdClient, prefix, name string, opts *election.Options, logger clog.Logger) (*EtcdElection, error) {
+	if name == "" {
+		return nil, client.NewError(client.ErrCodeValidation, "election name cannot be empty", nil)
+	}
+	if opts.CandidateID == "" {
+		return nil, client.NewError(client.ErrCodeValidation, "election candidate ID cannot be empty", nil)
+	}
+	if opts.TTL < election.MinTTL {
+		return nil, client.NewError(client.ErrCodeValidation, "election ttl must be at least 1s", nil)
+	}
+	if prefix == "" {
+		prefix = "/elections"
+	}
+	if logger == nil {
+		logger = clog.Namespace("coordination.election")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	e := &EtcdElection{
+		client:      c,
+		ttl:         int(opts.TTL.Seconds()),
+		key:         path.Join(prefix, name),
+		candidateID: opts.CandidateID,
+		logger:      logger,
+		changedCh:   make(chan string, 1),
+		ctx:         ctx,
+		cancel:      cancel,
+	}
+
+	session, err := e.newSession()
+	if err != nil {
+		cancel()
+		return nil, client.NewError(client.ErrCodeConnection, "failed to create etcd session", err)
+	}
+	e.session = session
+	e.election = concurrency.NewElection(session, e.key)
+
+	e.wg.Add(2)
+	go e.observe(ctx)
+	go e.watchSession(ctx)
+
+	return e, nil
+}
+
+// newSession 创建会话，会话租约决定 leader 失联后的接任延迟
+// 会话的续约随 Close 停止，租约由 Close 显式撤销
+func (e *EtcdElection) newSession() (*concurrency.Session, error) {
+	return concurrency.NewSession(e.client.Client(), concurrency.WithTTL(e.ttl), concurrency.WithContext(e.ctx))
+}
+
+// current 返回当前的会话和选举原语
+func (e *EtcdElection) current() (*concurrency.Session, *concurrency.Election) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.session, e.election
+}
+
+// Campaign 参与竞选，阻塞直到当选或 context 被取消
+// 会话已失效且尚未重建时返回 ErrCodeUnavailable 错误，调用方可稍后重试
+func (e *EtcdElection) Campaign(ctx context.Context) error {
+	if e.leader.Load() {
+		return nil
+	}
+	if e.ctx.Err() != nil {
+		return client.NewError(client.ErrCodeUnavailable, "election is closed", nil)
+	}
+
+	session, el := e.current()
+	select {
+	case <-session.Done():
+		return client.NewError(client.ErrCodeUnavailable, "election session expired, retry after it is recreated", nil)
+	default:
+	}
+
+	e.logger.Debug("开始竞选",
+		clog.String("election", e.key),
+		clog.String("candidate", e.candidateID))
+
+	if err := el.Campaign(ctx, e.candidateID); err != nil {
+		if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+			return client.NewError(client.ErrCodeTimeout, "election campaign cancelled", err)
+		}
+		return client.NewError(client.ErrCodeConnection, "failed to campaign for leadership", err)
+	}
+
+	// 竞选期间会话失效时，当选的键随租约一起被删除
+	select {
+	case <-session.Done():
+		return client.NewError(client.ErrCodeUnavailable, "election session expired during campaign", nil)
+	default:
+	}
+
+	e.leader.Store(true)
+	e.logger.Info("竞选成功，成为 leader",
+		clog.String("election", e.key),
+		clog.String("candidate", e.candidateID),
+		clog.Int64("lease", int64(session.Lease())))
+	return nil
+}
+
+// Resign 主动放弃 leader 身份
+func (e *EtcdElection) Resign(ctx context.Context) error {
+	if !e.leader.Load() {
+		return election.ErrNotLeader
+	}
+
+	_, el := e.current()
+	if err := el.Resign(ctx); err != nil {
+		return client.NewError(client.ErrCodeConnection, "failed to resign leadership", err)
+	}
+
+	e.leader.Store(false)
+	e.logger.Info("已放弃 leader 身份",
+		clog.String("election", e.key),
+		clog.String("candidate", e.candidateID))
+	return nil
+}
+
+// IsLeader 返回当前候选者是否为 leader
+func (e *EtcdElection) IsLeader() bool {
+	return e.leader.Load()
+}
+
+// Leader 返回当前 leader 的候选者 ID
+func (e *EtcdElection) Leader(ctx context.Context) (string, error) {
+	_, el := e.current()
+	resp, err := el.Leader(ctx)
+	if err != nil {
+		if errors.Is(err, concurrency.ErrElectionNoLeader) {
+			return "", nil
+		}
+		return "", client.NewError(client.ErrCodeConnection, "failed to get election leader", err)
+	}
+	return string(resp.Kvs[0].Value), nil
+}
+
+// LeaderChanged 返回 leader 变更通知通道
+func (e *EtcdElection) LeaderChanged() <-chan string {
+	return e.changedCh
+}
+
+// Close 放弃竞选并释放会话资源
+func (e *EtcdElection) Close() error {
+	var closeErr error
+	e.closeOnce.Do(func() {
+		if e.leader.Load() {
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			if err := e.Resign(ctx); err != nil {
+				e.logger.Warn("关闭选举时放弃 leader 身份失败",
+					clog.String("election", e.key),
+					clog.Err(err))
+			}
+			cancel()
+		}
+
+		// 先停止后台协程，避免撤销租约后又重建会话
+		e.cancel()
+		e.wg.Wait()
+
+		session, _ := e.current()
+		ctx, cancel := context.WithTimeout(context.Background(), time.Duration(e.ttl)*time.Second)
+		// 会话已失效时租约早已过期
+		if _, err := e.client.Client().Revoke(ctx, session.Lease()); err != nil && !errors.Is(err, rpctypes.ErrLeaseNotFound) {
+			closeErr = client.NewError(client.ErrCodeConnection, "failed to close election session", err)
+		}
+		cancel()
+
+		// 后台协程退出后不会再推送通知，此时关闭通道是安全的
+		close(e.changedCh)
+	})
+	return closeErr
+}
+
+// observe 观察 leader 变更并推送到通知通道
+// etcd 的 Observe 在连接出错时会关闭其通道，此时按退避时间重新订阅，直到选举被关闭
+func (e *EtcdElection) observe(ctx context.Context) {
+	defer e.wg.Done()
+
+	var current string
+	backoff := retryMin
+	for {
+		_, el := e.current()
+		for resp := range el.Observe(ctx) {
+			backoff = retryMin
+			if len(resp.Kvs) == 0 {
+				continue
+			}
+			leader := string(resp.Kvs[0].Value)
+			if leader == current {
+				continue
+			}
+			current = leader
+
+			// 其他候选者当选说明本候选者已失去 leader 身份
+			if leader != e.candidateID && e.leader.CompareAndSwap(true, false) {
+				e.logger.Warn("leader 身份已被其他候选者接任",
+					clog.String("election", e.key),
+					clog.String("leader", leader))
+			}
+
+			e.notify(leader)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+		e.logger.Warn("leader 观察中断，重新订阅",
+			clog.String("election", e.key),
+			clog.Duration("backoff", backoff))
+		backoff = min(backoff*2, retryMax)
+	}
+}
+
+// watchSession 会话失效时清除 leader 身份并重建会话，直到选举被关闭
+func (e *EtcdElection) watchSession(ctx context.Context) {
+	defer e.wg.Done()
+
+	for {
+		session, _ := e.current()
+		select {
+		case <-ctx.Done():
+			return
+		case <-session.Done():
+		}
+		if ctx.Err() != nil {
+			return
+		}
+
+		if e.leader.CompareAndSwap(true, false) {
+			e.logger.Warn("选举会话已失效，失去 leader 身份",
+				clog.String("election", e.key),
+				clog.String("candidate", e.candidateID))
+		}
+		if !e.renewSession(ctx) {
+			return
+		}
+	}
+}
+
+// renewSession 按退避时间重试创建会话，成功后替换会话和选举原语；选举被关闭时返回 false
+func (e *EtcdElection) renewSession(ctx context.Context) bool {
+	backoff := retryMin
+	for {
+		session, err := e.newSession()
+		if err == nil {
+			e.mu.Lock()
+			e.session = session
+			e.election = concurrency.NewElection(session, e.key)
+			e.mu.Unlock()
+			e.logger.Info("选举会话已重建",
+				clog.String("election", e.key),
+				clog.Int64("lease", int64(session.Lease())))
+			return true
+		}
+
+		e.logger.Warn("重建选举会话失败",
+			clog.String("election", e.key),
+			clog.Duration("backoff", backoff),
+			clog.Err(err))
+		select {
+		case <-ctx.Done():
+			return false
+		case <-time.After(backoff):
+		}
+		backoff = min(backoff*2, retryMax)
+	}
+}
+
+// 后台协程重试的退避时间，从 retryMin 开始翻倍，不超过 retryMax
+var (
+	retryMin = 100 * time.Millisecond
+	retryMax = 5 * time.Second
+)
+
+// notify 推送 leader 变更，通道已满时丢弃旧通知只保留最新值
+func (e *EtcdElection) notify(leader string) {
+	select {
+	case e.changedCh <- leader:
+		return
+	default:
+	}
+	select {
+	case <-e.changedCh:
+	default:
+	}
+	select {
+	case e.changedCh <- leader:
+	default:
+	}
+}
@@ -0,0 +1,176 @@
+package electionimpl
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ceyewan/infra-kit/clog"
+	"github.com/ceyewan/infra-kit/coord/election"
+	"github.com/ceyewan/infra-kit/coord/internal/client"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestEtcdElection_Campaign 测试竞选、接任与放弃
+func TestEtcdElection_Campaign(t *testing.T) {
+	c, err := createTestEtcdClient()
+	require.NoError(t, err)
+	defer c.Close()
+
+	logger := clog.Namespace("test")
+	ctx := context.Background()
+	name := "campaign-test"
+
+	first, err := NewEtcdElection(c, "/test-elections", name, election.ParseOptions(election.WithCandidateID("node-1"), election.WithTTL(5*time.Second)), logger)
+	require.NoError(t, err)
+	defer first.Close()
+	second, err := NewEtcdElection(c, "/test-elections", name, election.ParseOptions(election.WithCandidateID("node-2"), election.WithTTL(5*time.Second)), logger)
+	require.NoError(t, err)
+	defer second.Close()
+
+	// 未当选时不能放弃
+	assert.ErrorIs(t, first.Resign(ctx), election.ErrNotLeader)
+
+	require.NoError(t, first.Campaign(ctx))
+	assert.True(t, first.IsLeader())
+	assert.False(t, second.IsLeader())
+
+	leader, err := second.Leader(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "node-1", leader)
+	waitForLeader(t, second, "node-1")
+
+	// 第二个候选者在 leader 存在时阻塞
+	shortCtx, cancel := context.WithTimeout(ctx, 200*time.Millisecond)
+	err = second.Campaign(shortCtx)
+	cancel()
+	assert.Error(t, err)
+	assert.False(t, second.IsLeader())
+
+	// leader 放弃后由第二个候选者接任
+	campaignDone := make(chan error, 1)
+	go func() { campaignDone <- second.Campaign(ctx) }()
+	require.NoError(t, first.Resign(ctx))
+	assert.False(t, first.IsLeader())
+
+	select {
+	case err := <-campaignDone:
+		require.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("second candidate was not elected")
+	}
+	assert.True(t, second.IsLeader())
+	waitForLeader(t, first, "node-2")
+}
+
+// TestEtcdElection_Validation 测试参数校验
+func TestEtcdElection_Validation(t *testing.T) {
+	c, err := createTestEtcdClient()
+	require.NoError(t, err)
+	defer c.Close()
+
+	_, err = NewEtcdElection(c, "/test-elections", "", election.ParseOptions(), nil)
+	assert.Error(t, err)
+
+	_, err = NewEtcdElection(c, "/test-elections", "validation", election.ParseOptions(election.WithCandidateID("")), nil)
+	assert.Error(t, err)
+
+	_, err = NewEtcdElection(c, "/test-elections", "validation", election.ParseOptions(election.WithTTL(0)), nil)
+	assert.Error(t, err)
+
+	_, err = NewEtcdElection(c, "/test-elections", "validation", election.ParseOptions(election.WithTTL(500*time.Millisecond)), nil)
+	assert.Error(t, err)
+}
+
+// TestParseOptions_DefaultCandidateID 测试同一进程内的默认候选者标识互不相同
+func TestParseOptions_DefaultCandidateID(t *testing.T) {
+	first := election.ParseOptions().CandidateID
+	second := election.ParseOptions().CandidateID
+	assert.NotEmpty(t, first)
+	assert.NotEqual(t, first, second)
+}
+
+// TestEtcdElection_Close 测试关闭后释放 leader 身份并关闭通知通道
+func TestEtcdElection_Close(t *testing.T) {
+	c, err := createTestEtcdClient()
+	require.NoError(t, err)
+	defer c.Close()
+
+	ctx := context.Background()
+	e, err := NewEtcdElection(c, "/test-elections", "close-test", election.ParseOptions(election.WithCandidateID("node-1")), nil)
+	require.NoError(t, err)
+	require.NoError(t, e.Campaign(ctx))
+
+	require.NoError(t, e.Close())
+	assert.False(t, e.IsLeader())
+	require.NoError(t, e.Close()) // 幂等
+
+	for range e.LeaderChanged() {
+	}
+}
+
+// TestEtcdElection_SessionLoss 测试会话租约丢失后重建会话并可重新竞选
+func TestEtcdElection_SessionLoss(t *testing.T) {
+	c, err := createTestEtcdClient()
+	require.NoError(t, err)
+	defer c.Close()
+
+	ctx := context.Background()
+	e, err := NewEtcdElection(c, "/test-elections", "session-loss-test", election.ParseOptions(election.WithCandidateID("node-1"), election.WithTTL(2*time.Second)), nil)
+	require.NoError(t, err)
+	defer e.Close()
+	require.NoError(t, e.Campaign(ctx))
+
+	// 模拟租约丢失
+	session, _ := e.current()
+	lost := session.Lease()
+	_, err = c.Client().Revoke(ctx, lost)
+	require.NoError(t, err)
+
+	assert.Eventually(t, func() bool {
+		current, _ := e.current()
+		return !e.IsLeader() && current.Lease() != lost
+	}, 5*time.Second, 20*time.Millisecond)
+
+	// 新会话上可以重新当选
+	require.NoError(t, e.Campaign(ctx))
+	assert.True(t, e.IsLeader())
+	leader, err := e.Leader(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "node-1", leader)
+}
+
+// waitForLeader 等待 leader 变更通知推送指定的 leader
+func waitForLeader(t *testing.T, e *EtcdElection, want string) {
+	t.Helper()
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case leader := <-e.LeaderChanged():
+			if leader == want {
+				return
+			}
+		case <-timeout:
+			t.Fatalf("timed out waiting for leader %s", want)
+		}
+	}
+}
+
+// createTestEtcdClient 创建测试用的etcd客户端
+func createTestEtcdClient() (*client.EtcdClient, error) {
+	testLogger, _ := clog.New(context.Background(), &clog.Config{
+		Level:       "warn",
+		Format:      "console",
+		Output:      "stdout",
+		AddSource:   false,
+		EnableColor: false,
+	})
+
+	config := client.Config{
+		Endpoints: []string{"localhost:2379"},
+		Timeout:   time.Second * 5,
+		Logger:    testLogger.Namespace("test-etcd-client"),
+	}
+	return client.New(config)
+}
@@ -0,0 +1,236 @@
+package electionimpl
+
+import (
+	"context"
+	"path"
+	"sync"
+	"sync/atomic"
+
+	"github.com/ceyewan/infra-kit/clog"
+	"github.com/ceyewan/infra-kit/coord/election"
+	"github.com/ceyewan/infra-kit/coord/internal/client"
+	"github.com/ceyewan/infra-kit/coord/internal/memstore"
+)
+
+// MemoryElection 使用进程内存储实现 election.Election 接口，仅用于测试
+// leader 键绑定到候选者的会话上，leader 放弃或会话关闭后由等待中的候选者接任
+type MemoryElection struct {
+	store       *memstore.Store   // 进程内存储
+	session     *memstore.Session // 候选者会话
+	key         string            // leader 键
+	candidateID string            // 候选者标识
+	logger      clog.Logger       // 日志记录器
+
+	leader    atomic.Bool        // 当前是否为 leader
+	changedCh chan string        // leader 变更通知通道
+	cancel    context.CancelFunc // 停止 leader 观察
+	wg        sync.WaitGroup     // 等待后台协程退出
+	closeOnce sync.Once
+}
+
+// NewMemoryElection 创建一个基于进程内存储的选举，并开始观察 leader 变更
+func NewMemoryElection(store *memstore.Store, prefix, name string, opts *election.Options, logger clog.Logger) (*MemoryElection, error) {
+	if name == "" {
+		return nil, client.NewError(client.ErrCodeValidation, "election name cannot be empty", nil)
+	}
+	if opts.CandidateID == "" {
+		return nil, client.NewError(client.ErrCodeValidation, "election candidate ID cannot be empty", nil)
+	}
+	if opts.TTL < election.MinTTL {
+		return nil, client.NewError(client.ErrCodeValidation, "election ttl must be at least 1s", nil)
+	}
+	if prefix == "" {
+		prefix = "/elections"
+	}
+	if logger == nil {
+		logger = clog.Namespace("coordination.election")
+	}
+
+	session, err := memstore.NewSession(store, opts.TTL)
+	if err != nil {
+		return nil, client.NewError(client.ErrCodeConnection, "failed to create session", err)
+	}
+
+	observeCtx, cancel := context.WithCancel(context.Background())
+	e := &MemoryElection{
+		store:       store,
+		session:     session,
+		key:         path.Join(prefix, name),
+		candidateID: opts.CandidateID,
+		logger:      logger,
+		changedCh:   make(chan string, 1),
+		cancel:      cancel,
+	}
+
+	// 先建立监听再读取当前 leader，避免错过两者之间的变更
+	events := store.Watch(observeCtx, e.key, false)
+	if kv, ok := store.Get(e.key); ok {
+		e.notify(string(kv.Value))
+	}
+
+	e.wg.Add(2)
+	go e.observe(events)
+	go e.watchSession()
+
+	return e, nil
+}
+
+// Campaign 参与竞选，阻塞直到当选或 context 被取消
+func (e *MemoryElection) Campaign(ctx context.Context) error {
+	if e.leader.Load() {
+		return nil
+	}
+
+	for {
+		watchCtx, cancel := context.WithCancel(ctx)
+		events := e.store.Watch(watchCtx, e.key, false)
+
+		elected := false
+		err := e.store.Txn(func(tx *memstore.Txn) error {
+			if _, taken := tx.Get(e.key); taken {
+				return nil
+			}
+			elected = true
+			return tx.Put(e.key, []byte(e.candidateID), e.session.Lease())
+		})
+		if err != nil {
+			cancel()
+			return client.NewError(client.ErrCodeConnection, "failed to campaign for leadership", err)
+		}
+		if elected {
+			cancel()
+			break
+		}
+
+		released := false
+		for event := range events {
+			if event.Type == memstore.EventDelete {
+				released = true
+				break
+			}
+		}
+		cancel()
+		if !released {
+			if ctx.Err() != nil {
+				return client.NewError(client.ErrCodeTimeout, "election campaign cancelled", ctx.Err())
+			}
+			return client.NewError(client.ErrCodeConnection, "failed to campaign for leadership", memstore.ErrClosed)
+		}
+	}
+
+	e.leader.Store(true)
+	e.logger.Info("竞选成功，成为 leader",
+		clog.String("election", e.key),
+		clog.String("candidate", e.candidateID))
+	return nil
+}
+
+// Resign 主动放弃 leader 身份
+func (e *MemoryElection) Resign(ctx context.Context) error {
+	if !e.leader.Load() {
+		return election.ErrNotLeader
+	}
+
+	err := e.store.Txn(func(tx *memstore.Txn) error {
+		if kv, ok := tx.Get(e.key); ok && kv.Lease == e.session.Lease() {
+			tx.Delete(e.key)
+		}
+		return nil
+	})
+	if err != nil {
+		return client.NewError(client.ErrCodeConnection, "failed to resign leadership", err)
+	}
+
+	e.leader.Store(false)
+	e.logger.Info("已放弃 leader 身份",
+		clog.String("election", e.key),
+		clog.String("candidate", e.candidateID))
+	return nil
+}
+
+// IsLeader 返回当前候选者是否为 leader
+func (e *MemoryElection) IsLeader() bool {
+	return e.leader.Load()
+}
+
+// Leader 返回当前 leader 的候选者 ID
+func (e *MemoryElection) Leader(ctx context.Context) (string, error) {
+	if kv, ok := e.store.Get(e.key); ok {
+		return string(kv.Value), nil
+	}
+	return "", nil
+}
+
+// LeaderChanged 返回 leader 变更通知通道
+func (e *MemoryElection) LeaderChanged() <-chan string {
+	return e.changedCh
+}
+
+// Close 放弃竞选并释放会话资源
+func (e *MemoryElection) Close() error {
+	var closeErr error
+	e.closeOnce.Do(func() {
+		if e.leader.Load() {
+			if err := e.Resign(context.Background()); err != nil {
+				e.logger.Warn("关闭选举时放弃 leader 身份失败",
+					clog.String("election", e.key),
+					clog.Err(err))
+			}
+		}
+
+		e.cancel()
+		if err := e.session.Close(); err != nil {
+			closeErr = client.NewError(client.ErrCodeConnection, "failed to close election session", err)
+		}
+		e.wg.Wait()
+	})
+	return closeErr
+}
+
+// observe 观察 leader 变更并推送到通知通道
+func (e *MemoryElection) observe(events <-chan memstore.Event) {
+	defer e.wg.Done()
+	defer close(e.changedCh)
+
+	for event := range events {
+		if event.Type != memstore.EventPut {
+			continue
+		}
+		leader := string(event.KV.Value)
+		if leader != e.candidateID && e.leader.CompareAndSwap(true, false) {
+			e.logger.Warn("leader 身份已被其他候选者接任",
+				clog.String("election", e.key),
+				clog.String("leader", leader))
+		}
+		e.notify(leader)
+	}
+}
+
+// watchSession 会话失效时清除 leader 身份
+func (e *MemoryElection) watchSession() {
+	defer e.wg.Done()
+
+	<-e.session.Done()
+	if e.leader.CompareAndSwap(true, false) {
+		e.logger.Warn("选举会话已失效，失去 leader 身份",
+			clog.String("election", e.key),
+			clog.String("candidate", e.candidateID))
+	}
+}
+
+// notify 推送 leader 变更，通道已满时丢弃旧通知只保留最新值
+func (e *MemoryElection) notify(leader string) {
+	select {
+	case e.changedCh <- leader:
+		return
+	default:
+	}
+	select {
+	case <-e.changedCh:
+	default:
+	}
+	select {
+	case e.changedCh <- leader:
+	default:
+	}
+}
@@ -0,0 +1,25 @@
+package electionimpl
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ceyewan/infra-kit/coord/election"
+	"github.com/ceyewan/infra-kit/coord/internal/client"
+	"github.com/ceyewan/infra-kit/coord/internal/memstore"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestMemoryElection_Validation 测试内存实现与 etcd 实现的参数校验一致
+func TestMemoryElection_Validation(t *testing.T) {
+	store := memstore.New()
+	defer store.Close()
+
+	_, err := NewMemoryElection(store, "/test-elections", "validation", election.ParseOptions(election.WithTTL(500*time.Millisecond)), nil)
+	assert.ErrorIs(t, err, client.ErrValidation)
+
+	e, err := NewMemoryElection(store, "/test-elections", "validation", election.ParseOptions(election.WithTTL(time.Second)), nil)
+	require.NoError(t, err)
+	assert.NoError(t, e.Close())
+}
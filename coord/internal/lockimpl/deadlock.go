@@ -0,0 +1,62 @@
+package lockimpl
+
+import (
+	"maps"
+	"sync"
+	"time"
+
+	"github.com/ceyewan/infra-kit/clog"
+	"github.com/ceyewan/infra-kit/coord/lock"
+)
+
+// lockEntry 锁前缀下的一条持有或排队记录
+type lockEntry struct {
+	lockKey string      // 完整锁键
+	holder  lock.Holder // 写入的持有者信息
+}
+
+// blockedHolder 死锁检查的结果：正在等待的锁的持有者，以及该持有者正在排队等待的另一把锁
+type blockedHolder struct {
+	holder      lock.Holder // 正在等待的锁的当前持有者
+	otherKey    string      // 持有者正在排队等待的锁
+	otherHolder lock.Holder // otherKey 的当前持有者，未知时为零值
+}
+
+// sameHolder 判断两条记录是否来自同一持有者：持有者标识非空且完全相同
+func sameHolder(a, b lock.Holder) bool {
+	return len(a.Metadata) > 0 && maps.Equal(a.Metadata, b.Metadata)
+}
+
+// watchDeadlock 阻塞获取等待超过 threshold 后每隔 threshold 调用 detect 检查一次，
+// 检测到持有者也在等待另一把锁时记录一次可能死锁的警告；返回的函数停止检查，需在等待结束后调用
+func watchDeadlock(logger clog.Logger, lockKey string, waiter lock.Holder, threshold time.Duration, detect func() (blockedHolder, bool)) func() {
+	done := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(threshold)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+			}
+
+			blocked, ok := detect()
+			if !ok {
+				continue
+			}
+			logger.Warn("获取锁等待超过阈值，且持有者正在等待另一把锁，可能存在死锁",
+				clog.String("key", lockKey),
+				clog.Duration("waited", time.Since(waiter.WaitingSince)),
+				clog.Any("waiter", waiter.Metadata),
+				clog.Any("holder", blocked.holder.Metadata),
+				clog.String("holder_waiting_for", blocked.otherKey),
+				clog.Any("holder_waiting_for_holder", blocked.otherHolder.Metadata),
+				clog.Bool("cycle", sameHolder(blocked.otherHolder, waiter)))
+			return
+		}
+	}()
+
+	var once sync.Once
+	return func() { once.Do(func() { close(done) }) }
+}
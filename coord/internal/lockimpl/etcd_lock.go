@@ -2,12 +2,16 @@ package lockimpl
 
 import (
 	"context"
-	"path"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/ceyewan/infra-kit/clog"
 	"github.com/ceyewan/infra-kit/coord/internal/client"
 	"github.com/ceyewan/infra-kit/coord/lock"
+	clientv3 "go.etcd.io/etcd/client/v3"
 	"go.etcd.io/etcd/client/v3/concurrency"
 )
 
@@ -17,6 +21,7 @@ type EtcdLockFactory struct {
 	client *client.EtcdClient // etcd 客户端
 	prefix string             // 锁的前缀
 	logger clog.Logger        // 日志记录器
+	keys   *keyGuard          // 锁键检查
 }
 
 // NewEtcdLockFactory 创建一个 etcd 分布式锁工厂
@@ -31,27 +36,132 @@ func NewEtcdLockFactory(c *client.EtcdClient, prefix string, logger clog.Logger)
 		client: c,
 		prefix: prefix,
 		logger: logger,
+		keys:   newKeyGuard(prefix, logger),
 	}
 }
 
+// ReserveKeyPrefixes 声明配置中心、服务注册等组件使用的前缀，需在获取锁之前调用
+// 锁键逃出锁前缀或与这些前缀重叠时，默认每种重叠记录一次警告，strict 为 true 时拒绝获取并返回
+// 包装 lock.ErrKeyCollision 的错误；未调用时只检查锁键是否逃出锁前缀
+func (f *EtcdLockFactory) ReserveKeyPrefixes(strict bool, prefixes ...string) {
+	f.keys.reserve(strict, prefixes...)
+}
+
 // Acquire 获取一个新锁，阻塞直到锁被获取或 context 被取消
-func (f *EtcdLockFactory) Acquire(ctx context.Context, key string, ttl time.Duration) (lock.Lock, error) {
-	return f.acquire(ctx, key, ttl, true)
+// 锁被占用时由 etcd 排队等待前一个持有者释放；创建会话或等待过程中出现连接错误时，
+// 在随机抖动后重建会话重试，避免大量 worker 同时重连冲击 etcd；最多尝试 MaxAttempts 次，用尽后返回最后一次的错误
+func (f *EtcdLockFactory) Acquire(ctx context.Context, key string, ttl time.Duration, opts ...lock.Option) (lock.Lock, error) {
+	options := lock.ParseOptions(opts...)
+	if options.JitterMin < 0 || options.JitterMax < 0 {
+		return nil, client.NewError(client.ErrCodeValidation, "lock jitter cannot be negative", nil)
+	}
+	if options.JitterMax > 0 && options.JitterMin > options.JitterMax {
+		return nil, client.NewError(client.ErrCodeValidation, "lock jitter min must not exceed max", nil)
+	}
+	if options.MaxAttempts < 1 {
+		return nil, client.NewError(client.ErrCodeValidation, "lock max attempts must be at least 1", nil)
+	}
+
+	// 各次尝试共享开始申请的时间，失败只在放弃时记录一次
+	req := acquireRequest{blocking: true, waitingSince: time.Now(), retry: true}
+	var lastErr error
+	for attempt := 1; ; attempt++ {
+		l, err := f.acquire(ctx, key, ttl, req, options)
+		if err == nil {
+			return l, nil
+		}
+		lastErr = err
+		if !isRetryable(ctx, err) {
+			break
+		}
+		if attempt >= options.MaxAttempts {
+			f.logger.Error("获取锁失败，重试次数已用尽",
+				clog.String("key", key),
+				clog.Int("attempts", attempt),
+				clog.Err(err))
+			break
+		}
+
+		delay := options.Jitter()
+		f.logger.Warn("获取锁失败，稍后重试",
+			clog.String("key", key),
+			clog.Int("attempt", attempt),
+			clog.Duration("delay", delay),
+			clog.Err(err))
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			lastErr = client.NewError(client.ErrCodeTimeout, "context cancelled while waiting to retry lock", ctx.Err())
+		case <-timer.C:
+			continue
+		}
+		break
+	}
+
+	if lockKey, err := f.keys.lockKey(key); err == nil {
+		newLockTracer(options, lockKey).acquireFailed(ctx, req.waitingSince, req.blocking, lastErr)
+	}
+	return nil, lastErr
+}
+
+// acquireRequest 一次获取请求的参数
+type acquireRequest struct {
+	blocking     bool
+	waitingSince time.Time // 开始申请的时间，阻塞获取重试时沿用首次尝试的时间，等待时长从这里算起
+	retry        bool      // 失败后可能重试，此时 lockIn 不记录失败，由调用方在放弃时记录
+}
+
+// newAcquireRequest 返回不重试的单次获取请求
+func newAcquireRequest(blocking bool) acquireRequest {
+	return acquireRequest{blocking: blocking, waitingSince: time.Now()}
+}
+
+// isRetryable 判断阻塞获取锁的错误是否可以重试
+// 只重试连接类错误；参数错误和 context 取消直接返回
+func isRetryable(ctx context.Context, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	var coordErr *client.Error
+	return errors.As(err, &coordErr) && coordErr.Code == client.ErrCodeConnection
 }
 
 // TryAcquire 尝试获取新锁，不阻塞
-func (f *EtcdLockFactory) TryAcquire(ctx context.Context, key string, ttl time.Duration) (lock.Lock, error) {
-	return f.acquire(ctx, key, ttl, false)
+func (f *EtcdLockFactory) TryAcquire(ctx context.Context, key string, ttl time.Duration, opts ...lock.Option) (lock.Lock, error) {
+	return f.acquire(ctx, key, ttl, newAcquireRequest(false), lock.ParseOptions(opts...))
+}
+
+// RunOnce 尝试获取锁，获取成功则执行 fn 并释放锁；锁已被占用时返回 ran=false
+func (f *EtcdLockFactory) RunOnce(ctx context.Context, key string, ttl time.Duration, fn func(ctx context.Context) error, opts ...lock.Option) (bool, error) {
+	if fn == nil {
+		return false, client.NewError(client.ErrCodeValidation, "run once fn cannot be nil", nil)
+	}
+	l, err := f.acquire(ctx, key, ttl, newAcquireRequest(false), lock.ParseOptions(opts...))
+	if err != nil {
+		if isConflict(err) {
+			f.logger.Debug("锁已被其他节点持有，跳过执行", clog.String("key", key))
+			return false, nil
+		}
+		return false, err
+	}
+	held := l.(*EtcdLock)
+	return true, runLocked(ctx, f.logger, held, held.session.Done(), fn)
 }
 
 // acquire 内部实现，支持阻塞和非阻塞获取锁
-func (f *EtcdLockFactory) acquire(ctx context.Context, key string, ttl time.Duration, blocking bool) (lock.Lock, error) {
+func (f *EtcdLockFactory) acquire(ctx context.Context, key string, ttl time.Duration, req acquireRequest, options *lock.Options) (lock.Lock, error) {
 	if key == "" {
 		return nil, client.NewError(client.ErrCodeValidation, "lock key cannot be empty", nil)
 	}
 	if ttl <= 0 {
 		return nil, client.NewError(client.ErrCodeValidation, "lock ttl must be positive", nil)
 	}
+	lockKey, err := f.keys.lockKey(key)
+	if err != nil {
+		return nil, err
+	}
 
 	// 创建会话，包含租约并自动续约。锁释放时关闭会话。
 	session, err := concurrency.NewSession(f.client.Client(), concurrency.WithTTL(int(ttl.Seconds())))
@@ -59,8 +169,87 @@ func (f *EtcdLockFactory) acquire(ctx context.Context, key string, ttl time.Dura
 		return nil, client.NewError(client.ErrCodeConnection, "failed to create etcd session", err)
 	}
 
-	lockKey := path.Join(f.prefix, key)
+	l, err := f.lockIn(ctx, session, lockKey, ttl, req, options)
+	if err != nil {
+		_ = session.Close() // 尝试关闭会话，释放资源
+		return nil, err
+	}
+	return l, nil
+}
+
+// NewSession 创建共享租约的锁会话，其下所有锁随会话一起失效或释放
+func (f *EtcdLockFactory) NewSession(ctx context.Context, ttl time.Duration) (lock.Session, error) {
+	if ttl <= 0 {
+		return nil, client.NewError(client.ErrCodeValidation, "lock session ttl must be positive", nil)
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, client.NewError(client.ErrCodeTimeout, "context cancelled before creating lock session", err)
+	}
+
+	session, err := concurrency.NewSession(f.client.Client(), concurrency.WithTTL(int(ttl.Seconds())))
+	if err != nil {
+		return nil, client.NewError(client.ErrCodeConnection, "failed to create etcd session", err)
+	}
+
+	s := newLockSession(f.logger, session.Done(), session.Close)
+	s.lockFn = func(ctx context.Context, key string, blocking bool, options *lock.Options) (sessionLock, error) {
+		lockKey, err := f.keys.lockKey(key)
+		if err != nil {
+			return nil, err
+		}
+		l, err := f.lockIn(ctx, session, lockKey, ttl, newAcquireRequest(blocking), options)
+		if err != nil {
+			// 共享会话不随失败关闭，需删除排队时写入的键，以免阻塞后来的等待者
+			waiter := waiterKey(lockKey, session.Lease())
+			if _, delErr := f.client.Client().Delete(context.WithoutCancel(ctx), waiter); delErr != nil {
+				f.logger.Warn("清理锁排队键失败", clog.String("key", waiter), clog.Err(delErr))
+			}
+			return nil, err
+		}
+		l.group = s
+		return l, nil
+	}
+
+	f.logger.Info("锁会话创建成功", clog.Int64("lease", int64(session.Lease())), clog.Duration("ttl", ttl))
+	return s, nil
+}
+
+// leaseDeadline 返回租约最早可能过期的时间：查询发出前的时间加上 etcd 返回的剩余整秒数
+// 获取锁前会话可能已续约过一段时间，且 etcd 租约以整秒计，因此不能直接用获取时间加 ttl；
+// 查询失败时退回到整秒 TTL 减去一个后台续约周期（TTL/3），即续约正常时租约剩余时间的下限
+func (f *EtcdLockFactory) leaseDeadline(ctx context.Context, lease clientv3.LeaseID, ttl time.Duration) time.Time {
+	start := time.Now()
+	resp, err := f.client.Client().TimeToLive(ctx, lease)
+	if err == nil && resp.TTL > 0 {
+		return start.Add(time.Duration(resp.TTL) * time.Second)
+	}
+	whole := ttl.Truncate(time.Second)
+	return start.Add(whole - whole/3)
+}
+
+// waiterKey 返回 Mutex 在 lockKey 下为租约 lease 创建的排队键
+func waiterKey(lockKey string, lease clientv3.LeaseID) string {
+	return fmt.Sprintf("%s/%x", lockKey, lease)
+}
+
+// lockIn 在已有会话上获取 lockKey（完整锁键）对应的锁，失败时不关闭会话，由调用方决定如何清理
+func (f *EtcdLockFactory) lockIn(ctx context.Context, session *concurrency.Session, lockKey string, ttl time.Duration, req acquireRequest, options *lock.Options) (*EtcdLock, error) {
 	mutex := concurrency.NewMutex(session, lockKey)
+	blocking := req.blocking
+
+	// 排队前写入持有者信息：Mutex 的排队键为 "<lockKey>/<租约十六进制>"，
+	// 键已存在时 Mutex 沿用它而不是重新创建，因此等待者和持有者的信息都可以查询
+	holder := lock.Holder{Metadata: options.HolderMetadata(), WaitingSince: req.waitingSince}
+	tracer := newLockTracer(options, lockKey)
+	fail := func(err error) error {
+		if req.retry {
+			return err
+		}
+		return tracer.acquireFailed(ctx, req.waitingSince, blocking, err)
+	}
+	if _, err := f.client.Client().Put(ctx, waiterKey(lockKey, session.Lease()), string(encodeHolder(holder)), clientv3.WithLease(session.Lease())); err != nil {
+		return nil, fail(client.NewError(client.ErrCodeConnection, "failed to record lock holder", err))
+	}
 
 	f.logger.Debug("尝试获取锁",
 		clog.String("key", lockKey),
@@ -69,31 +258,153 @@ func (f *EtcdLockFactory) acquire(ctx context.Context, key string, ttl time.Dura
 
 	var lockErr error
 	if blocking {
+		stopWatch := func() {}
+		if options.DeadlockThreshold > 0 {
+			stopWatch = watchDeadlock(f.logger, lockKey, holder, options.DeadlockThreshold, func() (blockedHolder, bool) {
+				return f.detectBlockedHolder(ctx, lockKey)
+			})
+		}
 		// 阻塞直到获取锁或 context 被取消
 		lockErr = mutex.Lock(ctx)
+		stopWatch()
 	} else {
 		// 非阻塞尝试获取锁，立即返回
 		lockErr = mutex.TryLock(ctx)
 	}
 
 	if lockErr != nil {
-		_ = session.Close() // 尝试关闭会话，释放资源
 		if lockErr == concurrency.ErrLocked {
-			return nil, client.NewError(client.ErrCodeConflict, "lock is already held", lockErr)
+			return nil, fail(client.NewError(client.ErrCodeConflict, "lock is already held", lockErr).WithKind(lock.ErrNotAcquired))
 		}
-		return nil, client.NewError(client.ErrCodeConnection, "failed to acquire lock", lockErr)
+		return nil, fail(client.NewError(client.ErrCodeConnection, "failed to acquire lock", lockErr))
 	}
 
 	f.logger.Info("锁获取成功",
 		clog.String("key", lockKey),
 		clog.Int64("lease", int64(session.Lease())))
 
-	return &EtcdLock{
-		session: session,
-		mutex:   mutex,
-		client:  f.client,
-		logger:  f.logger,
-	}, nil
+	acquiredAt := time.Now()
+	holder.AcquiredAt = acquiredAt
+	tracer.acquired(ctx, holder, int64(session.Lease()), blocking)
+	if _, err := f.client.Client().Put(ctx, mutex.Key(), string(encodeHolder(holder)), clientv3.WithLease(session.Lease())); err != nil {
+		// 持有者信息仅用于排查，更新失败不影响已获取的锁
+		f.logger.Warn("更新锁持有者信息失败", clog.String("key", lockKey), clog.Err(err))
+	}
+
+	l := &EtcdLock{
+		session:  session,
+		mutex:    mutex,
+		client:   f.client,
+		logger:   f.logger,
+		holder:   holder,
+		tracer:   tracer,
+		deadline: f.leaseDeadline(ctx, session.Lease(), ttl),
+	}
+	if options.SlowHoldThreshold > 0 {
+		l.slowHoldTimer = time.AfterFunc(options.SlowHoldThreshold, func() {
+			l.logger.Warn("锁持有时间超过阈值，临界区可能过慢",
+				clog.String("key", lockKey),
+				clog.Duration("elapsed", time.Since(acquiredAt)),
+				clog.Duration("threshold", options.SlowHoldThreshold),
+				clog.Duration("ttl", ttl))
+		})
+	}
+	return l, nil
+}
+
+// Holder 返回当前持有指定锁的进程信息
+// 持有者是锁前缀下创建版本最小的排队键，与 Mutex 判定持有者的规则一致
+func (f *EtcdLockFactory) Holder(ctx context.Context, key string) (lock.Holder, error) {
+	if key == "" {
+		return lock.Holder{}, client.NewError(client.ErrCodeValidation, "lock key cannot be empty", nil)
+	}
+
+	lockKey, err := f.keys.lockKey(key)
+	if err != nil {
+		return lock.Holder{}, err
+	}
+	resp, err := f.client.Client().Get(ctx, lockKey+"/", clientv3.WithFirstCreate()...)
+	if err != nil {
+		return lock.Holder{}, client.NewError(client.ErrCodeConnection, "failed to get lock holder", err)
+	}
+	if len(resp.Kvs) == 0 {
+		return lock.Holder{}, client.NewError(client.ErrCodeNotFound, "lock not held", lock.ErrLockNotHeld)
+	}
+	return decodeHolder(resp.Kvs[0].Value), nil
+}
+
+// Waiters 按排队顺序返回正在等待指定锁的进程信息
+// 一次按创建版本排序的前缀读取：创建版本最小的排队键为持有者，其余按创建版本依次排队，与 Mutex 的唤醒顺序一致
+func (f *EtcdLockFactory) Waiters(ctx context.Context, key string) ([]lock.Holder, error) {
+	if key == "" {
+		return nil, client.NewError(client.ErrCodeValidation, "lock key cannot be empty", nil)
+	}
+
+	lockKey, err := f.keys.lockKey(key)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := f.client.Client().Get(ctx, lockKey+"/",
+		clientv3.WithPrefix(),
+		clientv3.WithSort(clientv3.SortByCreateRevision, clientv3.SortAscend))
+	if err != nil {
+		return nil, client.NewError(client.ErrCodeConnection, "failed to get lock waiters", err)
+	}
+
+	waiters := make([]lock.Holder, 0, len(resp.Kvs))
+	held := false
+	for _, kv := range resp.Kvs {
+		// 跳过以 lockKey 为前缀的其他锁（如 "<key>/sub"）的排队键
+		if strings.Contains(strings.TrimPrefix(string(kv.Key), lockKey+"/"), "/") {
+			continue
+		}
+		if !held {
+			held = true
+			continue
+		}
+		waiters = append(waiters, decodeHolder(kv.Value))
+	}
+	return waiters, nil
+}
+
+// detectBlockedHolder 检查 lockKey 的持有者是否正在等待锁前缀下的另一把锁
+// 与 Mutex 的规则一致，同一锁键下创建版本最小的排队键为持有者，其余为等待者
+func (f *EtcdLockFactory) detectBlockedHolder(ctx context.Context, lockKey string) (blockedHolder, bool) {
+	resp, err := f.client.Client().Get(ctx, strings.TrimSuffix(f.prefix, "/")+"/", clientv3.WithPrefix())
+	if err != nil {
+		f.logger.Debug("死锁检查失败", clog.String("key", lockKey), clog.Err(err))
+		return blockedHolder{}, false
+	}
+
+	type record struct {
+		lockEntry
+		createRev int64
+	}
+	records := make([]record, 0, len(resp.Kvs))
+	holders := make(map[string]record)
+	for _, kv := range resp.Kvs {
+		key := string(kv.Key)
+		r := record{
+			lockEntry: lockEntry{lockKey: key[:strings.LastIndex(key, "/")], holder: decodeHolder(kv.Value)},
+			createRev: kv.CreateRevision,
+		}
+		records = append(records, r)
+		if current, ok := holders[r.lockKey]; !ok || r.createRev < current.createRev {
+			holders[r.lockKey] = r
+		}
+	}
+
+	current, ok := holders[lockKey]
+	if !ok {
+		return blockedHolder{}, false
+	}
+	for _, r := range records {
+		other := holders[r.lockKey]
+		if r.lockKey != lockKey && r.createRev != other.createRev && sameHolder(r.holder, current.holder) {
+			return blockedHolder{holder: current.holder, otherKey: r.lockKey, otherHolder: other.holder}, true
+		}
+	}
+	return blockedHolder{}, false
 }
 
 // EtcdLock 表示已持有的分布式锁
@@ -102,10 +413,26 @@ type EtcdLock struct {
 	mutex   *concurrency.Mutex   // etcd 互斥锁
 	client  *client.EtcdClient   // etcd 客户端
 	logger  clog.Logger          // 日志记录器
+	holder  lock.Holder          // 写入锁值的持有者信息
+	tracer  *lockTracer          // WithTracing 启用时记录获取、续约和释放，未启用时为 nil
+
+	deadline   time.Time    // 锁的绝对截止时间
+	deadlineMu sync.RWMutex // 保护 deadline
+
+	slowHoldTimer *time.Timer // 慢持有检查定时器，未启用时为 nil
+
+	group *lockSession // 所属的共享锁会话，为 nil 时锁独占 session
 }
 
-// Unlock 释放锁
+// Unlock 释放锁，通过共享会话获取的锁只删除自己的键，不关闭会话
 func (l *EtcdLock) Unlock(ctx context.Context) error {
+	err := l.unlock(ctx)
+	l.tracer.released(ctx, l.holder, err)
+	return err
+}
+
+// unlock 释放锁的具体实现
+func (l *EtcdLock) unlock(ctx context.Context) error {
 	// 在所有操作之前缓存 key 和 lease，防止 session 关闭后无法获取
 	key := l.mutex.Key()
 	leaseID := l.session.Lease()
@@ -114,6 +441,18 @@ func (l *EtcdLock) Unlock(ctx context.Context) error {
 		clog.String("key", key),
 		clog.Int64("lease", int64(leaseID)))
 
+	l.stopSlowHoldTimer()
+
+	if l.group != nil {
+		err := l.mutex.Unlock(ctx)
+		l.group.release(l)
+		if err != nil {
+			return client.NewError(client.ErrCodeConnection, "failed to unlock mutex", err)
+		}
+		l.logger.Info("锁释放成功", clog.String("key", key))
+		return nil
+	}
+
 	// 先解锁互斥锁
 	if err := l.mutex.Unlock(ctx); err != nil {
 		// 即使解锁失败，也必须关闭会话以释放租约
@@ -131,6 +470,13 @@ func (l *EtcdLock) Unlock(ctx context.Context) error {
 	return nil
 }
 
+// stopSlowHoldTimer 停止慢持有检查
+func (l *EtcdLock) stopSlowHoldTimer() {
+	if l.slowHoldTimer != nil {
+		l.slowHoldTimer.Stop()
+	}
+}
+
 // TTL 返回锁租约的剩余存活时间
 func (l *EtcdLock) TTL(ctx context.Context) (time.Duration, error) {
 	// 通过会话获取租约 ID
@@ -154,6 +500,13 @@ func (l *EtcdLock) Key() string {
 
 // Renew 手动续约锁的TTL，返回是否成功
 func (l *EtcdLock) Renew(ctx context.Context) (bool, error) {
+	renewed, err := l.renew(ctx)
+	l.tracer.renewed(ctx, time.Until(l.Deadline()), err)
+	return renewed, err
+}
+
+// renew 续约的具体实现
+func (l *EtcdLock) renew(ctx context.Context) (bool, error) {
 	// 检查会话是否仍然有效
 	select {
 	case <-l.session.Done():
@@ -165,6 +518,8 @@ func (l *EtcdLock) Renew(ctx context.Context) (bool, error) {
 	}
 
 	// 尝试续约租约 - 使用 KeepAliveOnce 进行单次续约
+	// 截止时间从请求发出前算起，etcd 在此之后才重置租约
+	start := time.Now()
 	resp, err := l.client.Client().KeepAliveOnce(ctx, l.session.Lease())
 	if err != nil {
 		l.logger.Error("租约续约失败", clog.String("key", l.mutex.Key()), clog.String("error", err.Error()))
@@ -176,10 +531,26 @@ func (l *EtcdLock) Renew(ctx context.Context) (bool, error) {
 		return false, lock.ErrLockExpired
 	}
 
+	l.deadlineMu.Lock()
+	l.deadline = start.Add(time.Duration(resp.TTL) * time.Second)
+	l.deadlineMu.Unlock()
+
 	l.logger.Debug("租约续约成功", clog.String("key", l.mutex.Key()), clog.Int64("ttl", int64(resp.TTL)))
 	return true, nil
 }
 
+// Deadline 返回锁最早可能过期的时间，获取和 Renew 成功时按租约的剩余时间计算，会话的后台自动续约不会更新它
+func (l *EtcdLock) Deadline() time.Time {
+	l.deadlineMu.RLock()
+	defer l.deadlineMu.RUnlock()
+	return l.deadline
+}
+
+// Holder 返回本次获取写入锁中的持有者信息
+func (l *EtcdLock) Holder() lock.Holder {
+	return l.holder
+}
+
 // IsExpired 检查锁是否已过期
 func (l *EtcdLock) IsExpired(ctx context.Context) (bool, error) {
 	// 首先检查会话状态
@@ -2,14 +2,21 @@ package lockimpl
 
 import (
 	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
 	"sync"
 	"testing"
 	"time"
 
 	"github.com/ceyewan/infra-kit/clog"
 	"github.com/ceyewan/infra-kit/coord/internal/client"
+	"github.com/ceyewan/infra-kit/coord/lock"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	clientv3 "go.etcd.io/etcd/client/v3"
 )
 
 // TestEtcdLockFactory_New 测试锁工厂创建
@@ -81,6 +88,269 @@ func TestEtcdLockFactory_Acquire(t *testing.T) {
 		assert.Nil(t, lock)
 		assert.Contains(t, err.Error(), "ttl must be positive")
 	})
+
+	t.Run("with jitter", func(t *testing.T) {
+		l, err := factory.Acquire(ctx, "test-key-jitter", time.Second*10, lock.WithJitter(5*time.Millisecond, 20*time.Millisecond))
+		require.NoError(t, err)
+		assert.NoError(t, l.Unlock(ctx))
+	})
+
+	t.Run("invalid jitter", func(t *testing.T) {
+		l, err := factory.Acquire(ctx, "test-key-jitter", time.Second*10, lock.WithJitter(time.Second, time.Millisecond))
+		assert.Error(t, err)
+		assert.Nil(t, l)
+		assert.Contains(t, err.Error(), "jitter min must not exceed max")
+
+		l, err = factory.Acquire(ctx, "test-key-jitter", time.Second*10, lock.WithJitter(-time.Millisecond, 0))
+		assert.Error(t, err)
+		assert.Nil(t, l)
+	})
+
+	t.Run("invalid max attempts", func(t *testing.T) {
+		l, err := factory.Acquire(ctx, "test-key-attempts", time.Second*10, lock.WithMaxAttempts(0))
+		assert.Error(t, err)
+		assert.Nil(t, l)
+		assert.Contains(t, err.Error(), "max attempts must be at least 1")
+	})
+}
+
+// TestEtcdLock_SlowHoldWarning 测试持有锁超过阈值时记录警告，提前释放时不记录
+func TestEtcdLock_SlowHoldWarning(t *testing.T) {
+	client, err := createTestEtcdClient()
+	require.NoError(t, err)
+	defer client.Close()
+
+	logFile := filepath.Join(t.TempDir(), "lock.log")
+	logger, err := clog.New(context.Background(), &clog.Config{Level: "warn", Format: "json", Output: logFile})
+	require.NoError(t, err)
+	factory := NewEtcdLockFactory(client, "/test-locks", logger)
+	ctx := context.Background()
+
+	fast, err := factory.TryAcquire(ctx, "slow-hold-fast", time.Second*10, lock.WithSlowHoldWarning(200*time.Millisecond))
+	require.NoError(t, err)
+	require.NoError(t, fast.Unlock(ctx))
+
+	slow, err := factory.Acquire(ctx, "slow-hold-slow", time.Second*10, lock.WithSlowHoldWarning(50*time.Millisecond))
+	require.NoError(t, err)
+	defer slow.Unlock(ctx)
+
+	assert.Eventually(t, func() bool {
+		content, _ := os.ReadFile(logFile)
+		return strings.Contains(string(content), "slow-hold-slow")
+	}, time.Second, 10*time.Millisecond)
+
+	time.Sleep(250 * time.Millisecond)
+	content, err := os.ReadFile(logFile)
+	require.NoError(t, err)
+	assert.NotContains(t, string(content), "slow-hold-fast")
+	assert.Contains(t, string(content), "elapsed")
+}
+
+// TestEtcdLock_DeadlockWarning 测试两个持有者互相等待对方的锁时记录可能死锁的警告
+func TestEtcdLock_DeadlockWarning(t *testing.T) {
+	client, err := createTestEtcdClient()
+	require.NoError(t, err)
+	defer client.Close()
+
+	logFile := filepath.Join(t.TempDir(), "lock.log")
+	logger, err := clog.New(context.Background(), &clog.Config{Level: "warn", Format: "json", Output: logFile})
+	require.NoError(t, err)
+	factory := NewEtcdLockFactory(client, "/test-locks-deadlock", logger)
+	ctx := context.Background()
+	workerX := lock.WithHolder(map[string]string{"worker": "x"})
+	workerY := lock.WithHolder(map[string]string{"worker": "y"})
+
+	lockA, err := factory.Acquire(ctx, "deadlock-a", time.Second*10, workerX)
+	require.NoError(t, err)
+	defer lockA.Unlock(ctx)
+	lockB, err := factory.Acquire(ctx, "deadlock-b", time.Second*10, workerY)
+	require.NoError(t, err)
+	defer lockB.Unlock(ctx)
+
+	// x 持有 a 并等待 b，y 持有 b 并等待 a
+	waitCtx, cancel := context.WithTimeout(ctx, time.Second)
+	defer cancel()
+	go func() {
+		_, _ = factory.Acquire(waitCtx, "deadlock-b", time.Second*10, workerX)
+	}()
+	_, err = factory.Acquire(waitCtx, "deadlock-a", time.Second*10, workerY, lock.WithDeadlockWarning(200*time.Millisecond))
+	require.Error(t, err)
+
+	content, err := os.ReadFile(logFile)
+	require.NoError(t, err)
+	assert.Contains(t, string(content), `"key":"/test-locks-deadlock/deadlock-a"`)
+	assert.Contains(t, string(content), `"holder_waiting_for":"/test-locks-deadlock/deadlock-b"`)
+	assert.Contains(t, string(content), `"cycle":true`)
+}
+
+// TestEtcdLock_Tracing 测试 WithTracing 以 debug 级别记录获取、续约和释放，并带上 context 中的 trace_id
+func TestEtcdLock_Tracing(t *testing.T) {
+	client, err := createTestEtcdClient()
+	require.NoError(t, err)
+	defer client.Close()
+
+	logFile := filepath.Join(t.TempDir(), "lock.log")
+	tracing, err := clog.New(context.Background(), &clog.Config{Level: "debug", Format: "json", Output: logFile})
+	require.NoError(t, err)
+	factory := NewEtcdLockFactory(client, "/test-locks-tracing", createTestLogger())
+	ctx := clog.WithTraceID(context.Background(), "trace-lock-1")
+
+	untraced, err := factory.TryAcquire(ctx, "untraced", time.Second*10)
+	require.NoError(t, err)
+	require.NoError(t, untraced.Unlock(ctx))
+
+	l, err := factory.Acquire(ctx, "traced", time.Second*10, lock.WithTracing(tracing))
+	require.NoError(t, err)
+	_, err = l.Renew(ctx)
+	require.NoError(t, err)
+	require.NoError(t, l.Unlock(ctx))
+	_ = tracing.Sync()
+
+	content, err := os.ReadFile(logFile)
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(string(content)), "\n")
+	require.Len(t, lines, 3)
+	for _, line := range lines {
+		assert.Contains(t, line, `"level":"debug"`)
+		assert.Contains(t, line, `"trace_id":"trace-lock-1"`)
+		assert.Contains(t, line, `"key":"/test-locks-tracing/traced"`)
+	}
+	assert.Contains(t, lines[0], `"wait"`)
+	assert.Contains(t, lines[1], `"ttl"`)
+	assert.Contains(t, lines[2], `"held"`)
+}
+
+// TestLockOptions_Jitter 测试随机抖动落在配置区间内
+func TestLockOptions_Jitter(t *testing.T) {
+	options := lock.ParseOptions()
+	assert.Equal(t, lock.DefaultJitterMin, options.JitterMin)
+	assert.Equal(t, lock.DefaultJitterMax, options.JitterMax)
+	assert.Equal(t, lock.DefaultMaxAttempts, options.MaxAttempts)
+
+	options = lock.ParseOptions(lock.WithJitter(10*time.Millisecond, 20*time.Millisecond))
+	for i := 0; i < 100; i++ {
+		delay := options.Jitter()
+		assert.GreaterOrEqual(t, delay, 10*time.Millisecond)
+		assert.LessOrEqual(t, delay, 20*time.Millisecond)
+	}
+
+	assert.Equal(t, time.Duration(0), lock.ParseOptions(lock.WithJitter(0, 0)).Jitter())
+}
+
+// TestKeyGuard 测试锁键逃出锁前缀或与保留前缀重叠的检测
+func TestKeyGuard(t *testing.T) {
+	guard := newKeyGuard("/locks", clog.Namespace("test"))
+	guard.reserve(true, "/config", "", "/locks/services/")
+
+	lockKey, err := guard.lockKey("jobs/billing")
+	require.NoError(t, err)
+	assert.Equal(t, "/locks/jobs/billing", lockKey)
+
+	for _, key := range []string{"../config/app", "..", ".", "services", "services/user/1", "../other"} {
+		_, err := guard.lockKey(key)
+		assert.ErrorIs(t, err, lock.ErrKeyCollision, key)
+	}
+
+	// 非严格模式下照常返回锁键
+	guard.reserve(false, "/config")
+	lockKey, err = guard.lockKey("../config/app")
+	require.NoError(t, err)
+	assert.Equal(t, "/config/app", lockKey)
+
+	// 按重叠原因去重警告，不同的锁键不会使去重记录增长
+	for i := 0; i < 10; i++ {
+		_, err := guard.lockKey("../config/app-" + strconv.Itoa(i))
+		require.NoError(t, err)
+	}
+	warned := 0
+	guard.warned.Range(func(_, _ any) bool {
+		warned++
+		return true
+	})
+	assert.Equal(t, 1, warned)
+}
+
+// TestEtcdLockFactory_Holder 测试锁持有者信息
+func TestEtcdLockFactory_Holder(t *testing.T) {
+	client, err := createTestEtcdClient()
+	require.NoError(t, err)
+	defer client.Close()
+
+	factory := NewEtcdLockFactory(client, "/test-locks", createTestLogger())
+	ctx := context.Background()
+
+	_, err = factory.Holder(ctx, "holder-key")
+	assert.ErrorIs(t, err, lock.ErrLockNotHeld)
+
+	held, err := factory.Acquire(ctx, "holder-key", time.Second*10, lock.WithHolder(map[string]string{"pod": "abc"}))
+	require.NoError(t, err)
+
+	holder, err := factory.Holder(ctx, "holder-key")
+	require.NoError(t, err)
+	assert.Equal(t, "abc", holder.Metadata["pod"])
+	assert.Equal(t, strconv.Itoa(os.Getpid()), holder.Metadata["pid"])
+	assert.NotEmpty(t, holder.Metadata["hostname"])
+	assert.False(t, holder.AcquiredAt.IsZero())
+	assert.True(t, holder.AcquiredAt.Equal(held.Holder().AcquiredAt))
+
+	require.NoError(t, held.Unlock(ctx))
+	_, err = factory.Holder(ctx, "holder-key")
+	assert.ErrorIs(t, err, lock.ErrLockNotHeld)
+}
+
+// TestEtcdLockFactory_Waiters 测试按排队顺序查询等待者
+func TestEtcdLockFactory_Waiters(t *testing.T) {
+	client, err := createTestEtcdClient()
+	require.NoError(t, err)
+	defer client.Close()
+
+	factory := NewEtcdLockFactory(client, "/test-locks", createTestLogger())
+	ctx := context.Background()
+
+	held, err := factory.Acquire(ctx, "waiters-key", time.Second*10)
+	require.NoError(t, err)
+
+	waiters, err := factory.Waiters(ctx, "waiters-key")
+	require.NoError(t, err)
+	assert.Empty(t, waiters)
+
+	// 以锁键为前缀的其他锁不计入等待者
+	nested, err := factory.Acquire(ctx, "waiters-key/nested", time.Second*10)
+	require.NoError(t, err)
+	defer nested.Unlock(ctx)
+
+	acquired := make(chan lock.Lock, 2)
+	for _, worker := range []string{"w1", "w2"} {
+		go func() {
+			l, err := factory.Acquire(ctx, "waiters-key", time.Second*10, lock.WithHolder(map[string]string{"worker": worker}))
+			if err == nil {
+				acquired <- l
+			}
+		}()
+		require.Eventually(t, func() bool {
+			waiters, err := factory.Waiters(ctx, "waiters-key")
+			return err == nil && len(waiters) > 0 && waiters[len(waiters)-1].Metadata["worker"] == worker
+		}, 5*time.Second, 50*time.Millisecond)
+	}
+
+	waiters, err = factory.Waiters(ctx, "waiters-key")
+	require.NoError(t, err)
+	require.Len(t, waiters, 2)
+	assert.Equal(t, "w1", waiters[0].Metadata["worker"])
+	assert.Equal(t, "w2", waiters[1].Metadata["worker"])
+	assert.False(t, waiters[0].WaitingSince.IsZero())
+
+	require.NoError(t, held.Unlock(ctx))
+	next := <-acquired
+	assert.Equal(t, "w1", next.Holder().Metadata["worker"])
+	waiters, err = factory.Waiters(ctx, "waiters-key")
+	require.NoError(t, err)
+	require.Len(t, waiters, 1)
+	require.NoError(t, next.Unlock(ctx))
+	require.NoError(t, (<-acquired).Unlock(ctx))
+
+	_, err = factory.Waiters(ctx, "")
+	assert.Error(t, err)
 }
 
 // TestEtcdLockFactory_TryAcquire 测试非阻塞获取锁
@@ -113,6 +383,155 @@ func TestEtcdLockFactory_TryAcquire(t *testing.T) {
 		assert.Error(t, err)
 		assert.Nil(t, lock2)
 		assert.Contains(t, err.Error(), "lock is already held")
+		assert.ErrorIs(t, err, lock.ErrNotAcquired)
+	})
+}
+
+// TestEtcdLockFactory_RunOnce 测试获取锁后执行一次任务
+func TestEtcdLockFactory_RunOnce(t *testing.T) {
+	client, err := createTestEtcdClient()
+	require.NoError(t, err)
+	defer client.Close()
+
+	factory := NewEtcdLockFactory(client, "/test-locks", createTestLogger())
+	ctx := context.Background()
+
+	t.Run("runs and releases", func(t *testing.T) {
+		ran, err := factory.RunOnce(ctx, "run-once-key", time.Second*10, func(ctx context.Context) error {
+			_, err := factory.Holder(ctx, "run-once-key")
+			return err
+		})
+		require.NoError(t, err)
+		assert.True(t, ran)
+
+		_, err = factory.Holder(ctx, "run-once-key")
+		assert.ErrorIs(t, err, lock.ErrLockNotHeld)
+	})
+
+	t.Run("skips when held elsewhere", func(t *testing.T) {
+		held, err := factory.Acquire(ctx, "run-once-key", time.Second*10)
+		require.NoError(t, err)
+		defer held.Unlock(ctx)
+
+		ran, err := factory.RunOnce(ctx, "run-once-key", time.Second*10, func(ctx context.Context) error {
+			t.Error("fn should not run while the lock is held")
+			return nil
+		})
+		require.NoError(t, err)
+		assert.False(t, ran)
+	})
+
+	t.Run("returns fn error", func(t *testing.T) {
+		jobErr := errors.New("job failed")
+		ran, err := factory.RunOnce(ctx, "run-once-key", time.Second*10, func(ctx context.Context) error {
+			return jobErr
+		})
+		assert.True(t, ran)
+		assert.ErrorIs(t, err, jobErr)
+	})
+
+	t.Run("cancels fn when session is lost", func(t *testing.T) {
+		ran, err := factory.RunOnce(ctx, "run-once-key", time.Second*10, func(runCtx context.Context) error {
+			resp, err := client.Client().Get(ctx, "/test-locks/run-once-key/", clientv3.WithPrefix())
+			require.NoError(t, err)
+			require.Len(t, resp.Kvs, 1)
+			_, err = client.Client().Revoke(ctx, clientv3.LeaseID(resp.Kvs[0].Lease))
+			require.NoError(t, err)
+
+			select {
+			case <-runCtx.Done():
+				assert.ErrorIs(t, context.Cause(runCtx), lock.ErrLockExpired)
+				return runCtx.Err()
+			case <-time.After(5 * time.Second):
+				t.Error("fn context was not cancelled after the session was lost")
+				return nil
+			}
+		})
+		assert.True(t, ran)
+		assert.ErrorIs(t, err, lock.ErrLockExpired)
+	})
+}
+
+// TestEtcdLockFactory_NewSession 测试共享租约的锁会话
+func TestEtcdLockFactory_NewSession(t *testing.T) {
+	client, err := createTestEtcdClient()
+	require.NoError(t, err)
+	defer client.Close()
+
+	factory := NewEtcdLockFactory(client, "/test-locks", createTestLogger())
+	ctx := context.Background()
+
+	t.Run("locks share one lease", func(t *testing.T) {
+		session, err := factory.NewSession(ctx, time.Second*10)
+		require.NoError(t, err)
+		defer session.Close()
+
+		first, err := session.Acquire(ctx, "session-key-1")
+		require.NoError(t, err)
+		second, err := session.TryAcquire(ctx, "session-key-2")
+		require.NoError(t, err)
+		assert.Equal(t, first.(*EtcdLock).session.Lease(), second.(*EtcdLock).session.Lease())
+
+		// 同一会话重复获取同一把锁被拒绝
+		_, err = session.TryAcquire(ctx, "session-key-1")
+		assert.ErrorIs(t, err, lock.ErrLockConflict)
+
+		// 单独释放一把锁不影响会话下的其他锁
+		require.NoError(t, first.Unlock(ctx))
+		_, err = factory.Holder(ctx, "session-key-1")
+		assert.ErrorIs(t, err, lock.ErrLockNotHeld)
+		_, err = factory.Holder(ctx, "session-key-2")
+		assert.NoError(t, err)
+	})
+
+	t.Run("close releases every lock", func(t *testing.T) {
+		session, err := factory.NewSession(ctx, time.Second*10)
+		require.NoError(t, err)
+
+		for _, key := range []string{"session-close-1", "session-close-2"} {
+			_, err := session.Acquire(ctx, key)
+			require.NoError(t, err)
+		}
+		require.NoError(t, session.Close())
+
+		for _, key := range []string{"session-close-1", "session-close-2"} {
+			l, err := factory.TryAcquire(ctx, key, time.Second*10)
+			require.NoError(t, err)
+			require.NoError(t, l.Unlock(ctx))
+		}
+
+		_, err = session.TryAcquire(ctx, "session-close-1")
+		assert.ErrorIs(t, err, lock.ErrLockExpired)
+	})
+
+	t.Run("lost session aborts waiters", func(t *testing.T) {
+		held, err := factory.Acquire(ctx, "session-wait-key", time.Second*10)
+		require.NoError(t, err)
+		defer held.Unlock(ctx)
+
+		session, err := factory.NewSession(ctx, time.Second*10)
+		require.NoError(t, err)
+		defer session.Close()
+
+		errCh := make(chan error, 1)
+		go func() {
+			_, err := session.Acquire(ctx, "session-wait-key")
+			errCh <- err
+		}()
+		time.Sleep(200 * time.Millisecond)
+		require.NoError(t, session.Close())
+
+		select {
+		case err := <-errCh:
+			assert.ErrorIs(t, err, lock.ErrLockExpired)
+		case <-time.After(5 * time.Second):
+			t.Fatal("waiter was not aborted after the session closed")
+		}
+	})
+
+	t.Run("invalid ttl", func(t *testing.T) {
+		_, err := factory.NewSession(ctx, 0)
+		assert.Error(t, err)
 	})
 }
 
@@ -187,6 +606,25 @@ func TestEtcdLock_TTL(t *testing.T) {
 		assert.LessOrEqual(t, ttl, time.Second*30)
 	})
 
+	t.Run("deadline", func(t *testing.T) {
+		before := time.Now()
+		lock, err := factory.Acquire(ctx, "deadline-test-key", time.Second*30)
+		require.NoError(t, err)
+		defer lock.Unlock(ctx)
+
+		// etcd 返回的剩余时间为整秒，截止时间不会晚于获取时间加 TTL
+		deadline := lock.Deadline()
+		assert.WithinDuration(t, before.Add(time.Second*30), deadline, 2*time.Second)
+		assert.False(t, deadline.After(time.Now().Add(time.Second*30)))
+
+		// 续约后截止时间不应提前
+		time.Sleep(100 * time.Millisecond)
+		renewed, err := lock.Renew(ctx)
+		require.NoError(t, err)
+		assert.True(t, renewed)
+		assert.False(t, lock.Deadline().Before(deadline.Add(-time.Second)))
+	})
+
 	t.Run("TTL decreases", func(t *testing.T) {
 		t.Skip("etcd sessions automatically renew leases, TTL does not decrease over time")
 
@@ -230,6 +668,7 @@ func TestEtcdLock_Reentrancy(t *testing.T) {
 		assert.Error(t, err)
 		assert.Nil(t, lock2)
 		assert.Contains(t, err.Error(), "lock is already held")
+		assert.ErrorIs(t, err, lock.ErrNotAcquired)
 	})
 }
 
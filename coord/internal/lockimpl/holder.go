@@ -0,0 +1,22 @@
+package lockimpl
+
+import (
+	"encoding/json"
+
+	"github.com/ceyewan/infra-kit/coord/lock"
+)
+
+// encodeHolder 将持有者信息编码为锁的值
+func encodeHolder(holder lock.Holder) []byte {
+	data, _ := json.Marshal(holder) // 只包含字符串和时间，不会失败
+	return data
+}
+
+// decodeHolder 解析锁的值，值为空或不是持有者信息（如旧版本写入的锁）时返回零值
+func decodeHolder(value []byte) lock.Holder {
+	var holder lock.Holder
+	if len(value) > 0 {
+		_ = json.Unmarshal(value, &holder)
+	}
+	return holder
+}
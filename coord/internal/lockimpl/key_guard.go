@@ -0,0 +1,85 @@
+package lockimpl
+
+import (
+	"fmt"
+	"path"
+	"strings"
+	"sync"
+
+	"github.com/ceyewan/infra-kit/clog"
+	"github.com/ceyewan/infra-kit/coord/internal/client"
+	"github.com/ceyewan/infra-kit/coord/lock"
+)
+
+// keyGuard 检查锁键是否与配置中心、服务注册等组件的键空间重叠
+// 锁键由锁前缀和调用方的 key 经 path.Join 拼接，key 中的 ".." 会使锁键逃出锁前缀；
+// 锁的排队键和持有者信息写在锁键之下，落入其他组件的键空间时会覆盖或删除配置、服务实例
+type keyGuard struct {
+	prefix   string      // 锁的前缀
+	logger   clog.Logger // 日志记录器
+	reserved []string    // 其他组件使用的前缀，已规范化且不以 "/" 结尾
+	strict   bool        // 发现重叠时返回错误而不是记录警告
+	warned   sync.Map    // 已记录过警告的重叠原因，数量不超过保留前缀数加一，不随锁键增长
+}
+
+// newKeyGuard 创建锁键检查器，未设置保留前缀时只检查锁键是否逃出锁前缀
+func newKeyGuard(prefix string, logger clog.Logger) *keyGuard {
+	return &keyGuard{prefix: path.Clean(prefix), logger: logger}
+}
+
+// reserve 设置其他组件使用的前缀和是否启用严格模式，需在获取锁之前调用
+func (g *keyGuard) reserve(strict bool, prefixes ...string) {
+	g.strict = strict
+	g.reserved = g.reserved[:0]
+	for _, prefix := range prefixes {
+		if prefix == "" {
+			continue
+		}
+		g.reserved = append(g.reserved, path.Clean(prefix))
+	}
+}
+
+// lockKey 返回 key 对应的完整锁键
+// 锁键逃出锁前缀或与保留前缀重叠时，严格模式下返回包装 lock.ErrKeyCollision 的错误，
+// 否则每种重叠原因（逃出锁前缀或与某个保留前缀重叠）记录一次警告后照常返回；
+// 按原因而不是按锁键去重，动态生成的锁键不会使去重记录无限增长
+func (g *keyGuard) lockKey(key string) (string, error) {
+	lockKey := path.Join(g.prefix, key)
+	reason := g.collision(lockKey)
+	if reason == "" {
+		return lockKey, nil
+	}
+
+	if g.strict {
+		return "", client.NewError(client.ErrCodeValidation,
+			fmt.Sprintf("lock key %q %s", lockKey, reason), lock.ErrKeyCollision)
+	}
+	if _, warned := g.warned.LoadOrStore(reason, struct{}{}); !warned {
+		g.logger.Warn("锁键与其他组件的键空间重叠，可能覆盖其数据",
+			clog.String("key", key),
+			clog.String("lock_key", lockKey),
+			clog.String("reason", reason))
+	}
+	return lockKey, nil
+}
+
+// collision 返回锁键与其他键空间重叠的原因，不重叠时返回空字符串
+func (g *keyGuard) collision(lockKey string) string {
+	if g.prefix != "/" && !strings.HasPrefix(lockKey, g.prefix+"/") {
+		return fmt.Sprintf("escapes lock prefix %q", g.prefix)
+	}
+	for _, reserved := range g.reserved {
+		if overlaps(lockKey, reserved) {
+			return fmt.Sprintf("overlaps reserved prefix %q", reserved)
+		}
+	}
+	return ""
+}
+
+// overlaps 判断两个路径是否相同或互为上级路径
+func overlaps(a, b string) bool {
+	if a == b || b == "/" || a == "/" {
+		return true
+	}
+	return strings.HasPrefix(a, b+"/") || strings.HasPrefix(b, a+"/")
+}
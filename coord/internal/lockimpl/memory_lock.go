@@ -0,0 +1,436 @@
+package lockimpl
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ceyewan/infra-kit/clog"
+	"github.com/ceyewan/infra-kit/coord/internal/client"
+	"github.com/ceyewan/infra-kit/coord/internal/memstore"
+	"github.com/ceyewan/infra-kit/coord/lock"
+)
+
+// MemoryLockFactory 使用进程内存储实现 lock.DistributedLock 接口，仅用于测试
+// 与 etcd 实现一样，锁绑定到自动续约的会话上，直到 Unlock 才释放
+type MemoryLockFactory struct {
+	store  *memstore.Store // 进程内存储
+	prefix string          // 锁的前缀
+	logger clog.Logger     // 日志记录器
+	keys   *keyGuard       // 锁键检查
+
+	// 正在阻塞等待的获取，模拟 etcd 的排队键，用于死锁检查
+	waitersMu sync.Mutex
+	waiters   map[*lockEntry]struct{}
+}
+
+// NewMemoryLockFactory 创建一个基于进程内存储的分布式锁工厂
+func NewMemoryLockFactory(store *memstore.Store, prefix string, logger clog.Logger) *MemoryLockFactory {
+	if prefix == "" {
+		prefix = "/locks"
+	}
+	if logger == nil {
+		logger = clog.Namespace("coordination.lock")
+	}
+	return &MemoryLockFactory{
+		store:   store,
+		prefix:  prefix,
+		logger:  logger,
+		keys:    newKeyGuard(prefix, logger),
+		waiters: make(map[*lockEntry]struct{}),
+	}
+}
+
+// ReserveKeyPrefixes 声明配置中心、服务注册等组件使用的前缀，需在获取锁之前调用
+// 锁键逃出锁前缀或与这些前缀重叠时，默认每种重叠记录一次警告，strict 为 true 时拒绝获取并返回
+// 包装 lock.ErrKeyCollision 的错误；未调用时只检查锁键是否逃出锁前缀
+func (f *MemoryLockFactory) ReserveKeyPrefixes(strict bool, prefixes ...string) {
+	f.keys.reserve(strict, prefixes...)
+}
+
+// Acquire 获取锁，锁被占用时等待持有者释放，直到获取成功或 context 被取消
+func (f *MemoryLockFactory) Acquire(ctx context.Context, key string, ttl time.Duration, opts ...lock.Option) (lock.Lock, error) {
+	options := lock.ParseOptions(opts...)
+	if options.JitterMin < 0 || options.JitterMax < 0 {
+		return nil, client.NewError(client.ErrCodeValidation, "lock jitter cannot be negative", nil)
+	}
+	if options.JitterMax > 0 && options.JitterMin > options.JitterMax {
+		return nil, client.NewError(client.ErrCodeValidation, "lock jitter min must not exceed max", nil)
+	}
+	if options.MaxAttempts < 1 {
+		return nil, client.NewError(client.ErrCodeValidation, "lock max attempts must be at least 1", nil)
+	}
+	return f.acquire(ctx, key, ttl, true, options)
+}
+
+// TryAcquire 尝试获取锁，不阻塞
+func (f *MemoryLockFactory) TryAcquire(ctx context.Context, key string, ttl time.Duration, opts ...lock.Option) (lock.Lock, error) {
+	return f.acquire(ctx, key, ttl, false, lock.ParseOptions(opts...))
+}
+
+// RunOnce 尝试获取锁，获取成功则执行 fn 并释放锁；锁已被占用时返回 ran=false
+func (f *MemoryLockFactory) RunOnce(ctx context.Context, key string, ttl time.Duration, fn func(ctx context.Context) error, opts ...lock.Option) (bool, error) {
+	if fn == nil {
+		return false, client.NewError(client.ErrCodeValidation, "run once fn cannot be nil", nil)
+	}
+	l, err := f.acquire(ctx, key, ttl, false, lock.ParseOptions(opts...))
+	if err != nil {
+		if isConflict(err) {
+			f.logger.Debug("锁已被其他节点持有，跳过执行", clog.String("key", key))
+			return false, nil
+		}
+		return false, err
+	}
+	held := l.(*MemoryLock)
+	return true, runLocked(ctx, f.logger, held, held.session.Done(), fn)
+}
+
+// acquire 内部实现，支持阻塞和非阻塞获取锁
+func (f *MemoryLockFactory) acquire(ctx context.Context, key string, ttl time.Duration, blocking bool, options *lock.Options) (lock.Lock, error) {
+	if key == "" {
+		return nil, client.NewError(client.ErrCodeValidation, "lock key cannot be empty", nil)
+	}
+	if ttl <= 0 {
+		return nil, client.NewError(client.ErrCodeValidation, "lock ttl must be positive", nil)
+	}
+	lockKey, err := f.keys.lockKey(key)
+	if err != nil {
+		return nil, err
+	}
+
+	session, err := memstore.NewSession(f.store, ttl)
+	if err != nil {
+		return nil, client.NewError(client.ErrCodeConnection, "failed to create session", err)
+	}
+
+	l, err := f.lockIn(ctx, session, lockKey, ttl, blocking, options)
+	if err != nil {
+		_ = session.Close()
+		return nil, err
+	}
+	return l, nil
+}
+
+// NewSession 创建共享租约的锁会话，其下所有锁随会话一起失效或释放
+func (f *MemoryLockFactory) NewSession(ctx context.Context, ttl time.Duration) (lock.Session, error) {
+	if ttl <= 0 {
+		return nil, client.NewError(client.ErrCodeValidation, "lock session ttl must be positive", nil)
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, client.NewError(client.ErrCodeTimeout, "context cancelled before creating lock session", err)
+	}
+
+	session, err := memstore.NewSession(f.store, ttl)
+	if err != nil {
+		return nil, client.NewError(client.ErrCodeConnection, "failed to create session", err)
+	}
+
+	s := newLockSession(f.logger, session.Done(), session.Close)
+	s.lockFn = func(ctx context.Context, key string, blocking bool, options *lock.Options) (sessionLock, error) {
+		lockKey, err := f.keys.lockKey(key)
+		if err != nil {
+			return nil, err
+		}
+		l, err := f.lockIn(ctx, session, lockKey, ttl, blocking, options)
+		if err != nil {
+			return nil, err
+		}
+		l.group = s
+		return l, nil
+	}
+	return s, nil
+}
+
+// lockIn 在已有会话上获取 lockKey（完整锁键）对应的锁，失败时不关闭会话，由调用方决定如何清理
+func (f *MemoryLockFactory) lockIn(ctx context.Context, session *memstore.Session, lockKey string, ttl time.Duration, blocking bool, options *lock.Options) (*MemoryLock, error) {
+	holder := lock.Holder{Metadata: options.HolderMetadata(), WaitingSince: time.Now()}
+	tracer := newLockTracer(options, lockKey)
+	dequeue := func() {}
+	if blocking {
+		waiter := &lockEntry{lockKey: lockKey, holder: holder}
+		f.waitersMu.Lock()
+		f.waiters[waiter] = struct{}{}
+		f.waitersMu.Unlock()
+		dequeue = func() {
+			f.waitersMu.Lock()
+			delete(f.waiters, waiter)
+			f.waitersMu.Unlock()
+		}
+		defer dequeue()
+
+		if options.DeadlockThreshold > 0 {
+			stopWatch := watchDeadlock(f.logger, lockKey, holder, options.DeadlockThreshold, func() (blockedHolder, bool) {
+				return f.detectBlockedHolder(lockKey)
+			})
+			defer stopWatch()
+		}
+	}
+	for {
+		// 先建立监听再尝试获取，避免错过两者之间的释放事件
+		watchCtx, cancel := context.WithCancel(ctx)
+		events := f.store.Watch(watchCtx, lockKey, false)
+
+		acquired := false
+		err := f.store.Txn(func(tx *memstore.Txn) error {
+			if _, held := tx.Get(lockKey); held {
+				return nil
+			}
+			acquired = true
+			holder.AcquiredAt = time.Now()
+			return tx.Put(lockKey, encodeHolder(holder), session.Lease())
+		})
+		if err != nil || acquired {
+			cancel()
+			dequeue() // 获取成功后立即离开队列，Waiters 不再返回自己
+			if err != nil {
+				return nil, tracer.acquireFailed(ctx, holder.WaitingSince, blocking,
+					client.NewError(client.ErrCodeConnection, "failed to acquire lock", err))
+			}
+			break
+		}
+
+		if !blocking {
+			cancel()
+			return nil, tracer.acquireFailed(ctx, holder.WaitingSince, blocking,
+				client.NewError(client.ErrCodeConflict, "lock is already held", nil).WithKind(lock.ErrNotAcquired))
+		}
+
+		released := waitForDelete(events)
+		cancel()
+		if !released {
+			if ctx.Err() != nil {
+				return nil, tracer.acquireFailed(ctx, holder.WaitingSince, blocking,
+					client.NewError(client.ErrCodeTimeout, "context cancelled while waiting for lock", ctx.Err()))
+			}
+			return nil, tracer.acquireFailed(ctx, holder.WaitingSince, blocking,
+				client.NewError(client.ErrCodeConnection, "failed to acquire lock", memstore.ErrClosed))
+		}
+	}
+
+	f.logger.Info("锁获取成功",
+		clog.String("key", lockKey),
+		clog.Int64("lease", int64(session.Lease())))
+
+	tracer.acquired(ctx, holder, int64(session.Lease()), blocking)
+
+	acquiredAt := holder.AcquiredAt
+	l := &MemoryLock{
+		store:    f.store,
+		session:  session,
+		key:      lockKey,
+		logger:   f.logger,
+		holder:   holder,
+		tracer:   tracer,
+		deadline: f.leaseDeadline(session.Lease(), ttl),
+	}
+	if options.SlowHoldThreshold > 0 {
+		l.slowHoldTimer = time.AfterFunc(options.SlowHoldThreshold, func() {
+			l.logger.Warn("锁持有时间超过阈值，临界区可能过慢",
+				clog.String("key", lockKey),
+				clog.Duration("elapsed", time.Since(acquiredAt)),
+				clog.Duration("threshold", options.SlowHoldThreshold),
+				clog.Duration("ttl", ttl))
+		})
+	}
+	return l, nil
+}
+
+// leaseDeadline 返回租约最早可能过期的时间，获取锁前共享会话的租约可能已走过一段时间，因此按剩余时间计算
+// 查询失败时退回到 TTL 减去一个后台续约周期（TTL/3），即续约正常时租约剩余时间的下限
+func (f *MemoryLockFactory) leaseDeadline(lease memstore.LeaseID, ttl time.Duration) time.Time {
+	start := time.Now()
+	if remaining, err := f.store.TimeToLive(lease); err == nil {
+		return start.Add(remaining)
+	}
+	return start.Add(ttl - ttl/3)
+}
+
+// Holder 返回当前持有指定锁的进程信息
+func (f *MemoryLockFactory) Holder(ctx context.Context, key string) (lock.Holder, error) {
+	if key == "" {
+		return lock.Holder{}, client.NewError(client.ErrCodeValidation, "lock key cannot be empty", nil)
+	}
+	lockKey, err := f.keys.lockKey(key)
+	if err != nil {
+		return lock.Holder{}, err
+	}
+	kv, ok := f.store.Get(lockKey)
+	if !ok {
+		return lock.Holder{}, client.NewError(client.ErrCodeNotFound, "lock not held", lock.ErrLockNotHeld)
+	}
+	return decodeHolder(kv.Value), nil
+}
+
+// Waiters 按开始等待的时间返回正在阻塞获取指定锁的进程信息
+func (f *MemoryLockFactory) Waiters(ctx context.Context, key string) ([]lock.Holder, error) {
+	if key == "" {
+		return nil, client.NewError(client.ErrCodeValidation, "lock key cannot be empty", nil)
+	}
+	lockKey, err := f.keys.lockKey(key)
+	if err != nil {
+		return nil, err
+	}
+
+	f.waitersMu.Lock()
+	waiters := make([]lock.Holder, 0, len(f.waiters))
+	for waiter := range f.waiters {
+		if waiter.lockKey == lockKey {
+			waiters = append(waiters, waiter.holder)
+		}
+	}
+	f.waitersMu.Unlock()
+
+	sort.SliceStable(waiters, func(i, j int) bool {
+		return waiters[i].WaitingSince.Before(waiters[j].WaitingSince)
+	})
+	return waiters, nil
+}
+
+// detectBlockedHolder 检查 lockKey 的持有者是否正在等待另一把锁
+func (f *MemoryLockFactory) detectBlockedHolder(lockKey string) (blockedHolder, bool) {
+	kv, ok := f.store.Get(lockKey)
+	if !ok {
+		return blockedHolder{}, false
+	}
+	holder := decodeHolder(kv.Value)
+
+	f.waitersMu.Lock()
+	defer f.waitersMu.Unlock()
+	for waiter := range f.waiters {
+		if waiter.lockKey == lockKey || !sameHolder(waiter.holder, holder) {
+			continue
+		}
+		blocked := blockedHolder{holder: holder, otherKey: waiter.lockKey}
+		if other, ok := f.store.Get(waiter.lockKey); ok {
+			blocked.otherHolder = decodeHolder(other.Value)
+		}
+		return blocked, true
+	}
+	return blockedHolder{}, false
+}
+
+// waitForDelete 等待锁键被删除，监听结束（context 取消或存储关闭）时返回 false
+func waitForDelete(events <-chan memstore.Event) bool {
+	for event := range events {
+		if event.Type == memstore.EventDelete {
+			return true
+		}
+	}
+	return false
+}
+
+// MemoryLock 表示已持有的进程内锁
+type MemoryLock struct {
+	store   *memstore.Store   // 进程内存储
+	session *memstore.Session // 会话，管理租约
+	key     string            // 锁的完整键
+	logger  clog.Logger       // 日志记录器
+	holder  lock.Holder       // 写入锁值的持有者信息
+	tracer  *lockTracer       // WithTracing 启用时记录获取、续约和释放，未启用时为 nil
+
+	deadline   time.Time    // 锁的绝对截止时间
+	deadlineMu sync.RWMutex // 保护 deadline
+
+	slowHoldTimer *time.Timer // 慢持有检查定时器，未启用时为 nil
+
+	group *lockSession // 所属的共享锁会话，为 nil 时锁独占 session
+}
+
+// Unlock 释放锁，通过共享会话获取的锁只删除自己的键，不关闭会话
+func (l *MemoryLock) Unlock(ctx context.Context) error {
+	err := l.unlock(ctx)
+	l.tracer.released(ctx, l.holder, err)
+	return err
+}
+
+// unlock 释放锁的具体实现
+func (l *MemoryLock) unlock(ctx context.Context) error {
+	l.stopSlowHoldTimer()
+
+	if l.group != nil {
+		// 只删除仍属于本会话的键，会话失效后键可能已被其他持有者重新获取
+		err := l.store.Txn(func(tx *memstore.Txn) error {
+			if kv, ok := tx.Get(l.key); ok && kv.Lease == l.session.Lease() {
+				tx.Delete(l.key)
+			}
+			return nil
+		})
+		l.group.release(l)
+		if err != nil {
+			return client.NewError(client.ErrCodeConnection, "failed to release lock", err)
+		}
+		l.logger.Info("锁释放成功", clog.String("key", l.key))
+		return nil
+	}
+
+	if err := l.session.Close(); err != nil {
+		return client.NewError(client.ErrCodeConnection, "failed to close session", err)
+	}
+	l.logger.Info("锁释放成功", clog.String("key", l.key))
+	return nil
+}
+
+// stopSlowHoldTimer 停止慢持有检查
+func (l *MemoryLock) stopSlowHoldTimer() {
+	if l.slowHoldTimer != nil {
+		l.slowHoldTimer.Stop()
+	}
+}
+
+// TTL 返回锁租约的剩余存活时间
+func (l *MemoryLock) TTL(ctx context.Context) (time.Duration, error) {
+	ttl, err := l.store.TimeToLive(l.session.Lease())
+	if err != nil || ttl <= 0 {
+		return 0, client.NewError(client.ErrCodeNotFound, "lock has expired", nil)
+	}
+	return ttl, nil
+}
+
+// Key 返回锁的完整键路径
+func (l *MemoryLock) Key() string {
+	return l.key
+}
+
+// Renew 手动续约锁的TTL，返回是否成功
+func (l *MemoryLock) Renew(ctx context.Context) (bool, error) {
+	renewed, err := l.renew(ctx)
+	l.tracer.renewed(ctx, time.Until(l.Deadline()), err)
+	return renewed, err
+}
+
+// renew 续约的具体实现
+func (l *MemoryLock) renew(ctx context.Context) (bool, error) {
+	start := time.Now()
+	ttl, err := l.store.KeepAlive(l.session.Lease())
+	if err != nil {
+		return false, lock.ErrLockExpired
+	}
+
+	l.deadlineMu.Lock()
+	l.deadline = start.Add(ttl)
+	l.deadlineMu.Unlock()
+	return true, nil
+}
+
+// Deadline 返回锁最早可能过期的时间，获取和 Renew 成功时按租约的剩余时间计算，会话的后台自动续约不会更新它
+func (l *MemoryLock) Deadline() time.Time {
+	l.deadlineMu.RLock()
+	defer l.deadlineMu.RUnlock()
+	return l.deadline
+}
+
+// Holder 返回本次获取写入锁中的持有者信息
+func (l *MemoryLock) Holder() lock.Holder {
+	return l.holder
+}
+
+// IsExpired 检查锁是否已过期
+func (l *MemoryLock) IsExpired(ctx context.Context) (bool, error) {
+	select {
+	case <-l.session.Done():
+		return true, lock.ErrLockExpired
+	default:
+	}
+	return false, nil
+}
@@ -0,0 +1,66 @@
+package lockimpl
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ceyewan/infra-kit/coord/internal/memstore"
+	"github.com/ceyewan/infra-kit/coord/lock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestMemoryLock_ConflictErrors 测试锁被他人占用和会话重复持有分别只匹配各自的哨兵错误
+func TestMemoryLock_ConflictErrors(t *testing.T) {
+	store := memstore.New()
+	defer store.Close()
+	factory := NewMemoryLockFactory(store, "/test-locks", nil)
+	ctx := context.Background()
+
+	held, err := factory.TryAcquire(ctx, "conflict", 5*time.Second)
+	require.NoError(t, err)
+	defer held.Unlock(ctx)
+
+	_, err = factory.TryAcquire(ctx, "conflict", 5*time.Second)
+	assert.ErrorIs(t, err, lock.ErrNotAcquired)
+	assert.NotErrorIs(t, err, lock.ErrLockConflict)
+
+	session, err := factory.NewSession(ctx, 5*time.Second)
+	require.NoError(t, err)
+	defer session.Close()
+
+	_, err = session.TryAcquire(ctx, "owned")
+	require.NoError(t, err)
+	_, err = session.TryAcquire(ctx, "owned")
+	assert.ErrorIs(t, err, lock.ErrLockConflict)
+	assert.NotErrorIs(t, err, lock.ErrNotAcquired)
+}
+
+// TestMemoryLock_Deadline 测试截止时间按租约的剩余时间计算，不超过租约实际过期的时间
+func TestMemoryLock_Deadline(t *testing.T) {
+	store := memstore.New()
+	defer store.Close()
+	factory := NewMemoryLockFactory(store, "/test-locks", nil)
+	ctx := context.Background()
+
+	session, err := factory.NewSession(ctx, 3*time.Second)
+	require.NoError(t, err)
+	defer session.Close()
+
+	// 共享会话的租约在获取锁前已走过一段时间
+	time.Sleep(300 * time.Millisecond)
+	l, err := session.TryAcquire(ctx, "deadline")
+	require.NoError(t, err)
+	assert.True(t, l.Deadline().Before(l.Holder().AcquiredAt.Add(3*time.Second-200*time.Millisecond)))
+
+	ttl, err := l.TTL(ctx)
+	require.NoError(t, err)
+	assert.False(t, l.Deadline().After(time.Now().Add(ttl)))
+
+	// 续约后截止时间顺延到完整 TTL
+	renewed, err := l.Renew(ctx)
+	require.NoError(t, err)
+	assert.True(t, renewed)
+	assert.WithinDuration(t, time.Now().Add(3*time.Second), l.Deadline(), 100*time.Millisecond)
+}
@@ -0,0 +1,51 @@
+package lockimpl
+
+import (
+	"context"
+	"errors"
+
+	"github.com/ceyewan/infra-kit/clog"
+	"github.com/ceyewan/infra-kit/coord/internal/client"
+	"github.com/ceyewan/infra-kit/coord/lock"
+)
+
+// isConflict 判断 TryAcquire 的错误是否表示锁已被其他持有者占用
+func isConflict(err error) bool {
+	var coordErr *client.Error
+	return errors.As(err, &coordErr) && coordErr.Code == client.ErrCodeConflict
+}
+
+// runLocked 在持有锁 l 的情况下执行 fn，执行完毕后释放锁
+// lost 在锁的会话失效时关闭，此时立即取消 fn 的 context（cause 为 lock.ErrLockExpired），
+// 并在 fn 返回后返回包装 lock.ErrLockExpired 的错误，告知调用方互斥性可能已被破坏
+func runLocked(ctx context.Context, logger clog.Logger, l lock.Lock, lost <-chan struct{}, fn func(ctx context.Context) error) error {
+	runCtx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+
+	watchDone := make(chan struct{})
+	go func() {
+		defer close(watchDone)
+		select {
+		case <-lost:
+			logger.Warn("锁会话已失效，取消正在执行的任务", clog.String("key", l.Key()))
+			cancel(lock.ErrLockExpired)
+		case <-runCtx.Done():
+		}
+	}()
+
+	fnErr := fn(runCtx)
+	cancel(nil)
+	<-watchDone // 释放锁会关闭会话，需先停止监听，避免误报会话失效
+	sessionLost := errors.Is(context.Cause(runCtx), lock.ErrLockExpired)
+
+	// 调用方的 context 可能已取消，释放锁不受其影响
+	if err := l.Unlock(context.WithoutCancel(ctx)); err != nil && !sessionLost {
+		logger.Warn("任务执行完毕后释放锁失败", clog.String("key", l.Key()), clog.Err(err))
+	}
+
+	// 会话失效时 fn 的错误通常只是 context 被取消的结果，优先报告锁失效
+	if sessionLost {
+		return client.NewError(client.ErrCodeConflict, "lock session lost while running", lock.ErrLockExpired)
+	}
+	return fnErr
+}
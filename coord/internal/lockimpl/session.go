@@ -0,0 +1,156 @@
+package lockimpl
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/ceyewan/infra-kit/clog"
+	"github.com/ceyewan/infra-kit/coord/internal/client"
+	"github.com/ceyewan/infra-kit/coord/lock"
+)
+
+// sessionLock 通过共享会话获取的锁，会话关闭时需要停止其后台定时器
+type sessionLock interface {
+	lock.Lock
+	stopSlowHoldTimer()
+}
+
+// lockSession 实现 lock.Session，多把锁共享同一个会话（租约）
+// 同一会话内同一把锁只能持有一次：etcd Mutex 以租约区分持有者，重复获取会被视为已持有，
+// 先释放的一方会删除双方共用的键，因此在这里提前拒绝
+type lockSession struct {
+	logger  clog.Logger
+	done    <-chan struct{} // 会话失效或关闭时关闭
+	closeFn func() error    // 撤销租约，其下所有锁随之释放
+	// lockFn 在会话上获取锁，失败时不关闭会话
+	lockFn func(ctx context.Context, key string, blocking bool, options *lock.Options) (sessionLock, error)
+
+	mu     sync.Mutex
+	closed bool
+	held   map[string]sessionLock // 按锁名记录已持有的锁，值为 nil 表示正在获取
+}
+
+// newLockSession 创建锁会话，调用方需在使用前设置 lockFn
+func newLockSession(logger clog.Logger, done <-chan struct{}, closeFn func() error) *lockSession {
+	return &lockSession{
+		logger:  logger,
+		done:    done,
+		closeFn: closeFn,
+		held:    make(map[string]sessionLock),
+	}
+}
+
+// Acquire 在会话下获取锁，阻塞直到获取成功、context 取消或会话失效
+func (s *lockSession) Acquire(ctx context.Context, key string, opts ...lock.Option) (lock.Lock, error) {
+	return s.acquire(ctx, key, true, lock.ParseOptions(opts...))
+}
+
+// TryAcquire 在会话下尝试获取锁，不阻塞
+func (s *lockSession) TryAcquire(ctx context.Context, key string, opts ...lock.Option) (lock.Lock, error) {
+	return s.acquire(ctx, key, false, lock.ParseOptions(opts...))
+}
+
+// acquire 内部实现，会话失效时立即中止等待
+func (s *lockSession) acquire(ctx context.Context, key string, blocking bool, options *lock.Options) (lock.Lock, error) {
+	if key == "" {
+		return nil, client.NewError(client.ErrCodeValidation, "lock key cannot be empty", nil)
+	}
+
+	s.mu.Lock()
+	if s.closed || s.expired() {
+		s.mu.Unlock()
+		return nil, errSessionExpired()
+	}
+	if _, ok := s.held[key]; ok {
+		s.mu.Unlock()
+		return nil, client.NewError(client.ErrCodeConflict, "lock is already held by this session", lock.ErrLockConflict)
+	}
+	s.held[key] = nil
+	s.mu.Unlock()
+
+	lockCtx, cancel := context.WithCancelCause(ctx)
+	go func() {
+		select {
+		case <-s.done:
+			cancel(lock.ErrLockExpired)
+		case <-lockCtx.Done():
+		}
+	}()
+	l, err := s.lockFn(lockCtx, key, blocking, options)
+	sessionLost := errors.Is(context.Cause(lockCtx), lock.ErrLockExpired)
+	cancel(nil)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err != nil {
+		delete(s.held, key)
+		if sessionLost {
+			return nil, errSessionExpired()
+		}
+		return nil, err
+	}
+	if s.closed {
+		// 获取期间会话被关闭，租约已撤销，锁随之释放
+		l.stopSlowHoldTimer()
+		return nil, errSessionExpired()
+	}
+	s.held[key] = l
+	return l, nil
+}
+
+// Done 返回会话失效或关闭时关闭的通道
+func (s *lockSession) Done() <-chan struct{} {
+	return s.done
+}
+
+// Close 撤销会话租约，释放其下所有锁，幂等
+func (s *lockSession) Close() error {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return nil
+	}
+	s.closed = true
+	held := s.held
+	s.held = nil
+	s.mu.Unlock()
+
+	for _, l := range held {
+		if l != nil {
+			l.stopSlowHoldTimer()
+		}
+	}
+	if err := s.closeFn(); err != nil {
+		return client.NewError(client.ErrCodeConnection, "failed to close lock session", err)
+	}
+	s.logger.Info("锁会话已关闭", clog.Int("locks", len(held)))
+	return nil
+}
+
+// release 在锁被单独释放后将其从会话中移除
+func (s *lockSession) release(l sessionLock) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for key, held := range s.held {
+		if held == l {
+			delete(s.held, key)
+			return
+		}
+	}
+}
+
+// expired 判断会话是否已失效，调用方需持有 mu
+func (s *lockSession) expired() bool {
+	select {
+	case <-s.done:
+		return true
+	default:
+		return false
+	}
+}
+
+// errSessionExpired 会话已失效或已关闭时返回的错误
+func errSessionExpired() error {
+	return client.NewError(client.ErrCodeConflict, "lock session has expired or been closed", lock.ErrLockExpired)
+}
@@ -0,0 +1,72 @@
+package lockimpl
+
+import (
+	"context"
+	"time"
+
+	"github.com/ceyewan/infra-kit/clog"
+	"github.com/ceyewan/infra-kit/coord/lock"
+)
+
+// lockTracer 将一把锁的获取、续约和释放以 debug 级别记录到 lock.WithTracing 设置的日志器
+// 为 nil 表示未启用，所有方法直接返回
+type lockTracer struct {
+	logger clog.Logger
+	key    string
+}
+
+// newLockTracer 未设置 WithTracing 时返回 nil
+func newLockTracer(options *lock.Options, lockKey string) *lockTracer {
+	if options.Tracing == nil {
+		return nil
+	}
+	return &lockTracer{logger: options.Tracing, key: lockKey}
+}
+
+// trace 记录一条带锁键的 debug 日志，ctx 中有 trace_id 时一并输出
+func (t *lockTracer) trace(ctx context.Context, msg string, fields ...clog.Field) {
+	if t == nil {
+		return
+	}
+	logger := clog.WithContext(clog.IntoContext(ctx, t.logger))
+	logger.Debug(msg, append([]clog.Field{clog.String("key", t.key)}, fields...)...)
+}
+
+// acquired 记录获取成功和从开始申请到获取的等待时长
+func (t *lockTracer) acquired(ctx context.Context, holder lock.Holder, lease int64, blocking bool) {
+	t.trace(ctx, "锁获取成功",
+		clog.Int64("lease", lease),
+		clog.Bool("blocking", blocking),
+		clog.Duration("wait", holder.AcquiredAt.Sub(holder.WaitingSince)))
+}
+
+// acquireFailed 记录获取失败和从开始申请起已等待的时长，原样返回 err
+func (t *lockTracer) acquireFailed(ctx context.Context, waitingSince time.Time, blocking bool, err error) error {
+	t.trace(ctx, "锁获取失败",
+		clog.Bool("blocking", blocking),
+		clog.Duration("wait", time.Since(waitingSince)),
+		clog.Err(err))
+	return err
+}
+
+// renewed 记录续约结果
+func (t *lockTracer) renewed(ctx context.Context, ttl time.Duration, err error) {
+	if err != nil {
+		t.trace(ctx, "锁续约失败", clog.Err(err))
+		return
+	}
+	t.trace(ctx, "锁续约成功", clog.Duration("ttl", ttl))
+}
+
+// released 记录释放结果和持有时长
+func (t *lockTracer) released(ctx context.Context, holder lock.Holder, err error) {
+	if t == nil {
+		return
+	}
+	fields := []clog.Field{clog.Duration("held", time.Since(holder.AcquiredAt))}
+	if err != nil {
+		t.trace(ctx, "锁释放失败", append(fields, clog.Err(err))...)
+		return
+	}
+	t.trace(ctx, "锁释放成功", fields...)
+}
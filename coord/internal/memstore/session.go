@@ -0,0 +1,78 @@
+package memstore
+
+import (
+	"sync"
+	"time"
+)
+
+// Session 自动续约的租约，对应 etcd 的 concurrency.Session
+// 在 Close 之前租约不会过期，Close 时撤销租约并删除绑定的键
+type Session struct {
+	store *Store
+	lease LeaseID
+	stop  chan struct{}
+	once  sync.Once
+}
+
+// NewSession 创建租约为 ttl 的会话，后台每 ttl/3 续约一次
+func NewSession(store *Store, ttl time.Duration) (*Session, error) {
+	return NewSessionWithInterval(store, ttl, func() time.Duration { return ttl / 3 })
+}
+
+// NewSessionWithInterval 创建租约为 ttl 的会话，每次续约前等待 interval 返回的时长
+func NewSessionWithInterval(store *Store, ttl time.Duration, interval func() time.Duration) (*Session, error) {
+	id, err := store.Grant(ttl)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &Session{store: store, lease: id, stop: make(chan struct{})}
+	go s.keepAlive(interval)
+	return s, nil
+}
+
+// Lease 返回会话的租约 ID
+func (s *Session) Lease() LeaseID {
+	return s.lease
+}
+
+// Done 返回会话结束（关闭或租约失效）时关闭的通道
+func (s *Session) Done() <-chan struct{} {
+	return s.store.LeaseDone(s.lease)
+}
+
+// Close 撤销租约，幂等
+func (s *Session) Close() error {
+	var err error
+	s.once.Do(func() {
+		close(s.stop)
+		if revokeErr := s.store.Revoke(s.lease); revokeErr != nil && revokeErr != ErrLeaseNotFound {
+			err = revokeErr
+		}
+	})
+	return err
+}
+
+// keepAlive 定期续约，租约失效或会话关闭时退出
+func (s *Session) keepAlive(interval func() time.Duration) {
+	next := func() time.Duration {
+		return max(interval(), time.Millisecond)
+	}
+	timer := time.NewTimer(next())
+	defer timer.Stop()
+
+	done := s.Done()
+	for {
+		select {
+		case <-s.stop:
+			return
+		case <-done:
+			return
+		case <-timer.C:
+			if _, err := s.store.KeepAlive(s.lease); err != nil {
+				return
+			}
+			timer.Reset(next())
+		}
+	}
+}
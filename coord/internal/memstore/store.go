@@ -0,0 +1,396 @@
+// Package memstore 提供进程内的键值存储，模拟 etcd 的修订号、租约和监听语义，
+// 是 coord 内存实现的共同基础，仅用于测试和本地开发
+package memstore
+
+import (
+	"errors"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrLeaseNotFound 租约不存在或已过期
+	ErrLeaseNotFound = errors.New("lease not found")
+	// ErrClosed 存储已关闭
+	ErrClosed = errors.New("store closed")
+	// ErrCompacted 请求的修订号已被压缩，存储不回放历史事件，任何已发生的修订号都视为已压缩
+	ErrCompacted = errors.New("revision compacted")
+)
+
+// LeaseID 租约 ID，0 表示不绑定租约
+type LeaseID int64
+
+// KeyValue 键值对快照
+type KeyValue struct {
+	Key            string
+	Value          []byte
+	CreateRevision int64   // 创建时的修订号
+	ModRevision    int64   // 最后一次修改的修订号，可用于 CAS
+	Lease          LeaseID // 绑定的租约
+}
+
+// EventType 事件类型
+type EventType int
+
+const (
+	EventPut EventType = iota
+	EventDelete
+)
+
+// Event 键值变更事件，DELETE 事件的 KV 只包含 Key 和删除时的 ModRevision
+type Event struct {
+	Type EventType
+	KV   KeyValue
+}
+
+// lease 租约，到期时删除绑定的所有键
+type lease struct {
+	id    LeaseID
+	ttl   time.Duration
+	timer *time.Timer
+	due   time.Time
+	keys  map[string]struct{}
+	done  chan struct{}
+}
+
+// historyLimit 每个键保留的历史版本数上限
+const historyLimit = 64
+
+// keyHistory 键最近一次创建以来的版本，从旧到新排列
+type keyHistory struct {
+	versions  []KeyValue
+	compacted int64 // 因超出 historyLimit 被丢弃的最新版本的修订号，0 表示没有丢弃
+}
+
+// record 追加一个版本，同一事务内对同一个键的多次写入只保留最后一次
+func (h *keyHistory) record(kv KeyValue) {
+	if n := len(h.versions); n > 0 && h.versions[n-1].ModRevision == kv.ModRevision {
+		h.versions[n-1] = kv
+		return
+	}
+	h.versions = append(h.versions, kv)
+	if len(h.versions) > historyLimit {
+		h.compacted = h.versions[0].ModRevision
+		h.versions = slices.Delete(h.versions, 0, 1)
+	}
+}
+
+// Store 进程内键值存储
+// 每次写入（包括一个事务内的多次写入）递增一次全局修订号，与 etcd 一致
+// 每个键保留最近一次创建以来的至多 historyLimit 个版本，删除键时丢弃其历史
+type Store struct {
+	mu        sync.Mutex
+	revision  int64
+	kvs       map[string]*KeyValue
+	history   map[string]*keyHistory
+	leases    map[LeaseID]*lease
+	nextLease LeaseID
+	watchers  map[*watcher]struct{}
+	closed    bool
+}
+
+// New 创建空的存储
+func New() *Store {
+	return &Store{
+		kvs:      make(map[string]*KeyValue),
+		history:  make(map[string]*keyHistory),
+		leases:   make(map[LeaseID]*lease),
+		watchers: make(map[*watcher]struct{}),
+	}
+}
+
+// Txn 事务，只能在 Store.Txn 的回调内使用
+// 同一事务内的写入共享一个修订号，事件在事务提交后统一投递
+type Txn struct {
+	s      *Store
+	rev    int64
+	events []Event
+}
+
+// Txn 在存储锁内执行 fn，fn 返回错误时已执行的写入不会回滚，调用方应先检查再写入
+func (s *Store) Txn(fn func(tx *Txn) error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return ErrClosed
+	}
+
+	tx := &Txn{s: s}
+	err := fn(tx)
+	s.dispatch(tx.events)
+	return err
+}
+
+// Get 读取键
+func (tx *Txn) Get(key string) (KeyValue, bool) {
+	kv, ok := tx.s.kvs[key]
+	if !ok {
+		return KeyValue{}, false
+	}
+	return cloneKV(kv), true
+}
+
+// GetPrefix 读取前缀下的所有键，按键排序
+func (tx *Txn) GetPrefix(prefix string) []KeyValue {
+	return tx.s.getPrefixLocked(prefix)
+}
+
+// Put 写入键，lease 不为 0 时键随租约过期而删除
+func (tx *Txn) Put(key string, value []byte, leaseID LeaseID) error {
+	s := tx.s
+	var l *lease
+	if leaseID != 0 {
+		var ok bool
+		if l, ok = s.leases[leaseID]; !ok {
+			return ErrLeaseNotFound
+		}
+	}
+
+	rev := tx.nextRevision()
+	kv, exists := s.kvs[key]
+	if !exists {
+		kv = &KeyValue{Key: key, CreateRevision: rev}
+		s.kvs[key] = kv
+		s.history[key] = &keyHistory{}
+	} else if kv.Lease != 0 && kv.Lease != leaseID {
+		if old, ok := s.leases[kv.Lease]; ok {
+			delete(old.keys, key)
+		}
+	}
+	kv.Value = append([]byte(nil), value...)
+	kv.ModRevision = rev
+	kv.Lease = leaseID
+	if l != nil {
+		l.keys[key] = struct{}{}
+	}
+	s.history[key].record(cloneKV(kv))
+
+	tx.events = append(tx.events, Event{Type: EventPut, KV: cloneKV(kv)})
+	return nil
+}
+
+// Delete 删除键，返回键是否存在
+func (tx *Txn) Delete(key string) bool {
+	s := tx.s
+	kv, ok := s.kvs[key]
+	if !ok {
+		return false
+	}
+	if kv.Lease != 0 {
+		if l, ok := s.leases[kv.Lease]; ok {
+			delete(l.keys, key)
+		}
+	}
+	delete(s.kvs, key)
+	delete(s.history, key)
+
+	tx.events = append(tx.events, Event{Type: EventDelete, KV: KeyValue{Key: key, ModRevision: tx.nextRevision()}})
+	return true
+}
+
+// History 返回键最近一次创建以来保留的版本，按修订号从新到旧排列，
+// 以及因超出上限被丢弃的最新版本的修订号，0 表示没有丢弃
+func (tx *Txn) History(key string) ([]KeyValue, int64) {
+	h, ok := tx.s.history[key]
+	if !ok {
+		return nil, 0
+	}
+	versions := make([]KeyValue, len(h.versions))
+	for i := range h.versions {
+		versions[len(h.versions)-1-i] = cloneKV(&h.versions[i])
+	}
+	return versions, h.compacted
+}
+
+// Revision 返回事务写入使用的修订号，尚未写入时为 0
+func (tx *Txn) Revision() int64 {
+	return tx.rev
+}
+
+// nextRevision 事务首次写入时递增修订号
+func (tx *Txn) nextRevision() int64 {
+	if tx.rev == 0 {
+		tx.s.revision++
+		tx.rev = tx.s.revision
+	}
+	return tx.rev
+}
+
+// Get 读取键
+func (s *Store) Get(key string) (kv KeyValue, ok bool) {
+	_ = s.Txn(func(tx *Txn) error {
+		kv, ok = tx.Get(key)
+		return nil
+	})
+	return kv, ok
+}
+
+// History 返回键最近一次创建以来保留的版本及被丢弃的最新修订号，见 Txn.History
+func (s *Store) History(key string) (versions []KeyValue, compacted int64) {
+	_ = s.Txn(func(tx *Txn) error {
+		versions, compacted = tx.History(key)
+		return nil
+	})
+	return versions, compacted
+}
+
+// GetPrefix 读取前缀下的所有键，按键排序
+func (s *Store) GetPrefix(prefix string) []KeyValue {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.getPrefixLocked(prefix)
+}
+
+// getPrefixLocked 读取前缀下的所有键，调用方需持有 s.mu
+func (s *Store) getPrefixLocked(prefix string) []KeyValue {
+	var result []KeyValue
+	for key, kv := range s.kvs {
+		if strings.HasPrefix(key, prefix) {
+			result = append(result, cloneKV(kv))
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Key < result[j].Key })
+	return result
+}
+
+// Put 写入键并返回修订号
+func (s *Store) Put(key string, value []byte, leaseID LeaseID) (int64, error) {
+	var rev int64
+	err := s.Txn(func(tx *Txn) error {
+		if err := tx.Put(key, value, leaseID); err != nil {
+			return err
+		}
+		rev = tx.rev
+		return nil
+	})
+	return rev, err
+}
+
+// Delete 删除键，返回键是否存在
+func (s *Store) Delete(key string) (deleted bool, err error) {
+	err = s.Txn(func(tx *Txn) error {
+		deleted = tx.Delete(key)
+		return nil
+	})
+	return deleted, err
+}
+
+// Grant 创建租约，到期未续约时删除绑定的所有键
+func (s *Store) Grant(ttl time.Duration) (LeaseID, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return 0, ErrClosed
+	}
+
+	s.nextLease++
+	l := &lease{
+		id:   s.nextLease,
+		ttl:  ttl,
+		due:  time.Now().Add(ttl),
+		keys: make(map[string]struct{}),
+		done: make(chan struct{}),
+	}
+	id := l.id
+	l.timer = time.AfterFunc(ttl, func() { _ = s.Revoke(id) })
+	s.leases[id] = l
+	return id, nil
+}
+
+// KeepAlive 续约，租约重新从完整 TTL 开始计时，返回续约后的 TTL
+func (s *Store) KeepAlive(id LeaseID) (time.Duration, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	l, ok := s.leases[id]
+	if !ok {
+		return 0, ErrLeaseNotFound
+	}
+	l.timer.Reset(l.ttl)
+	l.due = time.Now().Add(l.ttl)
+	return l.ttl, nil
+}
+
+// TimeToLive 返回租约的剩余时间
+func (s *Store) TimeToLive(id LeaseID) (time.Duration, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	l, ok := s.leases[id]
+	if !ok {
+		return 0, ErrLeaseNotFound
+	}
+	return time.Until(l.due), nil
+}
+
+// LeaseDone 返回租约结束（撤销或过期）时关闭的通道，租约不存在时返回已关闭的通道
+func (s *Store) LeaseDone(id LeaseID) <-chan struct{} {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if l, ok := s.leases[id]; ok {
+		return l.done
+	}
+	done := make(chan struct{})
+	close(done)
+	return done
+}
+
+// Revoke 撤销租约并删除绑定的所有键，删除事件共享一个修订号
+func (s *Store) Revoke(id LeaseID) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	l, ok := s.leases[id]
+	if !ok {
+		return ErrLeaseNotFound
+	}
+	l.timer.Stop()
+	delete(s.leases, id)
+
+	keys := make([]string, 0, len(l.keys))
+	for key := range l.keys {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	tx := &Txn{s: s}
+	for _, key := range keys {
+		tx.Delete(key)
+	}
+	s.dispatch(tx.events)
+	close(l.done)
+	return nil
+}
+
+// Close 关闭存储，撤销所有租约并关闭所有监听通道
+func (s *Store) Close() {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return
+	}
+	s.closed = true
+	for _, l := range s.leases {
+		l.timer.Stop()
+		close(l.done)
+	}
+	s.leases = make(map[LeaseID]*lease)
+	watchers := s.watchers
+	s.watchers = make(map[*watcher]struct{})
+	s.mu.Unlock()
+
+	for w := range watchers {
+		w.stop()
+	}
+}
+
+// cloneKV 复制键值对，避免调用方修改存储内部的数据
+func cloneKV(kv *KeyValue) KeyValue {
+	c := *kv
+	c.Value = append([]byte(nil), kv.Value...)
+	return c
+}
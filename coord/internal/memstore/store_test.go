@@ -0,0 +1,242 @@
+package memstore
+
+import (
+	"context"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestStore_PutGetDelete 测试基本读写和修订号
+func TestStore_PutGetDelete(t *testing.T) {
+	s := New()
+	defer s.Close()
+
+	rev1, err := s.Put("/a", []byte("1"), 0)
+	require.NoError(t, err)
+	rev2, err := s.Put("/a", []byte("2"), 0)
+	require.NoError(t, err)
+	assert.Equal(t, rev1+1, rev2)
+
+	kv, ok := s.Get("/a")
+	require.True(t, ok)
+	assert.Equal(t, "2", string(kv.Value))
+	assert.Equal(t, rev1, kv.CreateRevision)
+	assert.Equal(t, rev2, kv.ModRevision)
+
+	_, err = s.Put("/b/1", []byte("x"), 0)
+	require.NoError(t, err)
+	_, err = s.Put("/b/2", []byte("y"), 0)
+	require.NoError(t, err)
+	kvs := s.GetPrefix("/b/")
+	require.Len(t, kvs, 2)
+	assert.Equal(t, "/b/1", kvs[0].Key)
+
+	deleted, err := s.Delete("/a")
+	require.NoError(t, err)
+	assert.True(t, deleted)
+	deleted, err = s.Delete("/a")
+	require.NoError(t, err)
+	assert.False(t, deleted)
+
+	_, err = s.Put("/c", []byte("z"), 42)
+	assert.ErrorIs(t, err, ErrLeaseNotFound)
+}
+
+// TestStore_History 测试每个键保留有限的历史版本，删除时丢弃
+func TestStore_History(t *testing.T) {
+	s := New()
+	defer s.Close()
+
+	var revs []int64
+	for i := 0; i < historyLimit+2; i++ {
+		rev, err := s.Put("/a", []byte(strconv.Itoa(i)), 0)
+		require.NoError(t, err)
+		revs = append(revs, rev)
+	}
+
+	versions, compacted := s.History("/a")
+	require.Len(t, versions, historyLimit)
+	assert.Equal(t, revs[len(revs)-1], versions[0].ModRevision, "newest first")
+	assert.Equal(t, strconv.Itoa(historyLimit+1), string(versions[0].Value))
+	assert.Equal(t, revs[2], versions[historyLimit-1].ModRevision)
+	assert.Equal(t, revs[1], compacted)
+
+	// 同一事务内的多次写入只记一个版本
+	require.NoError(t, s.Txn(func(tx *Txn) error {
+		_ = tx.Put("/a", []byte("x"), 0)
+		return tx.Put("/a", []byte("y"), 0)
+	}))
+	versions, _ = s.History("/a")
+	assert.Equal(t, "y", string(versions[0].Value))
+	assert.Equal(t, revs[len(revs)-1], versions[1].ModRevision)
+
+	// 删除后重新创建只保留新建以来的版本
+	_, err := s.Delete("/a")
+	require.NoError(t, err)
+	versions, _ = s.History("/a")
+	assert.Empty(t, versions)
+	_, err = s.Put("/a", []byte("new"), 0)
+	require.NoError(t, err)
+	versions, compacted = s.History("/a")
+	require.Len(t, versions, 1)
+	assert.Zero(t, compacted)
+}
+
+// TestStore_Txn 测试事务内的写入共享修订号
+func TestStore_Txn(t *testing.T) {
+	s := New()
+	defer s.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	events := s.Watch(ctx, "/", true)
+
+	_, err := s.Put("/src", []byte("v"), 0)
+	require.NoError(t, err)
+	require.NoError(t, s.Txn(func(tx *Txn) error {
+		kv, ok := tx.Get("/src")
+		require.True(t, ok)
+		require.NoError(t, tx.Put("/dst", kv.Value, 0))
+		tx.Delete("/src")
+		return nil
+	}))
+
+	first := <-events
+	assert.Equal(t, EventPut, first.Type)
+	put, del := <-events, <-events
+	assert.Equal(t, EventPut, put.Type)
+	assert.Equal(t, "/dst", put.KV.Key)
+	assert.Equal(t, EventDelete, del.Type)
+	assert.Equal(t, "/src", del.KV.Key)
+	assert.Equal(t, put.KV.ModRevision, del.KV.ModRevision)
+}
+
+// TestStore_Lease 测试租约过期、续约和撤销
+func TestStore_Lease(t *testing.T) {
+	s := New()
+	defer s.Close()
+
+	t.Run("expires", func(t *testing.T) {
+		id, err := s.Grant(50 * time.Millisecond)
+		require.NoError(t, err)
+		_, err = s.Put("/lease/expire", []byte("v"), id)
+		require.NoError(t, err)
+
+		select {
+		case <-s.LeaseDone(id):
+		case <-time.After(time.Second):
+			t.Fatal("lease did not expire")
+		}
+		_, ok := s.Get("/lease/expire")
+		assert.False(t, ok)
+		_, err = s.KeepAlive(id)
+		assert.ErrorIs(t, err, ErrLeaseNotFound)
+	})
+
+	t.Run("keep alive and revoke", func(t *testing.T) {
+		id, err := s.Grant(time.Minute)
+		require.NoError(t, err)
+		_, err = s.Put("/lease/revoke", []byte("v"), id)
+		require.NoError(t, err)
+
+		ttl, err := s.TimeToLive(id)
+		require.NoError(t, err)
+		assert.True(t, ttl > 0 && ttl <= time.Minute)
+
+		ttl, err = s.KeepAlive(id)
+		require.NoError(t, err)
+		assert.Equal(t, time.Minute, ttl)
+
+		require.NoError(t, s.Revoke(id))
+		_, ok := s.Get("/lease/revoke")
+		assert.False(t, ok)
+	})
+
+	t.Run("session keeps lease alive", func(t *testing.T) {
+		session, err := NewSession(s, 60*time.Millisecond)
+		require.NoError(t, err)
+		_, err = s.Put("/lease/session", []byte("v"), session.Lease())
+		require.NoError(t, err)
+
+		time.Sleep(200 * time.Millisecond)
+		_, ok := s.Get("/lease/session")
+		assert.True(t, ok)
+
+		require.NoError(t, session.Close())
+		require.NoError(t, session.Close())
+		<-session.Done()
+		_, ok = s.Get("/lease/session")
+		assert.False(t, ok)
+	})
+
+	t.Run("session renews at custom interval", func(t *testing.T) {
+		var renewals atomic.Int32
+		session, err := NewSessionWithInterval(s, 60*time.Millisecond, func() time.Duration {
+			renewals.Add(1)
+			return 10 * time.Millisecond
+		})
+		require.NoError(t, err)
+		defer session.Close()
+
+		time.Sleep(200 * time.Millisecond)
+		ttl, err := s.TimeToLive(session.Lease())
+		require.NoError(t, err)
+		assert.Greater(t, ttl, 30*time.Millisecond)
+		assert.Greater(t, renewals.Load(), int32(5))
+	})
+}
+
+// TestStore_Watch 测试监听范围和关闭
+func TestStore_Watch(t *testing.T) {
+	s := New()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	keyEvents := s.Watch(ctx, "/w/a", false)
+	prefixEvents := s.Watch(context.Background(), "/w/", true)
+
+	_, err := s.Put("/w/b", []byte("b"), 0)
+	require.NoError(t, err)
+	_, err = s.Put("/w/a", []byte("a"), 0)
+	require.NoError(t, err)
+
+	event := <-keyEvents
+	assert.Equal(t, "/w/a", event.KV.Key)
+	assert.Equal(t, "/w/b", (<-prefixEvents).KV.Key)
+	assert.Equal(t, "/w/a", (<-prefixEvents).KV.Key)
+
+	cancel()
+	_, ok := <-keyEvents
+	assert.False(t, ok)
+
+	s.Close()
+	_, ok = <-prefixEvents
+	assert.False(t, ok)
+	_, err = s.Put("/w/c", []byte("c"), 0)
+	assert.ErrorIs(t, err, ErrClosed)
+}
+
+func TestStore_WatchFrom(t *testing.T) {
+	s := New()
+	defer s.Close()
+
+	rev, err := s.Put("/wf/a", []byte("a"), 0)
+	require.NoError(t, err)
+
+	// 不回放历史事件，已发生的修订号视为已压缩
+	_, err = s.WatchFrom(context.Background(), "/wf/", true, rev)
+	assert.ErrorIs(t, err, ErrCompacted)
+
+	events, err := s.WatchFrom(context.Background(), "/wf/", true, rev+1)
+	require.NoError(t, err)
+	next, err := s.Put("/wf/b", []byte("b"), 0)
+	require.NoError(t, err)
+
+	event := <-events
+	assert.Equal(t, "/wf/b", event.KV.Key)
+	assert.Equal(t, next, event.KV.ModRevision)
+}
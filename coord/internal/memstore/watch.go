@@ -0,0 +1,133 @@
+package memstore
+
+import (
+	"context"
+	"strings"
+	"sync"
+)
+
+// watcher 单个监听，事件先进入无界队列再由投递协程转发，写入方不会被慢消费者阻塞
+type watcher struct {
+	key      string
+	isPrefix bool
+
+	mu     sync.Mutex
+	queue  []Event
+	signal chan struct{}
+	done   chan struct{}
+	once   sync.Once
+}
+
+// Watch 监听键或前缀的变更，只投递调用之后发生的事件
+// ctx 取消或存储关闭时关闭返回的通道
+func (s *Store) Watch(ctx context.Context, key string, isPrefix bool) <-chan Event {
+	out, _ := s.WatchFrom(ctx, key, isPrefix, 0)
+	return out
+}
+
+// WatchFrom 从 startRev 开始监听，startRev 为 0 时等同于 Watch
+// 存储不回放历史事件，startRev 不大于当前修订号时返回 ErrCompacted；检查与注册在同一把锁内完成，不会漏掉事件
+func (s *Store) WatchFrom(ctx context.Context, key string, isPrefix bool, startRev int64) (<-chan Event, error) {
+	out := make(chan Event, 10)
+	w := &watcher{
+		key:      key,
+		isPrefix: isPrefix,
+		signal:   make(chan struct{}, 1),
+		done:     make(chan struct{}),
+	}
+
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		close(out)
+		return out, nil
+	}
+	if startRev > 0 && startRev <= s.revision {
+		s.mu.Unlock()
+		return nil, ErrCompacted
+	}
+	s.watchers[w] = struct{}{}
+	s.mu.Unlock()
+
+	go func() {
+		select {
+		case <-ctx.Done():
+		case <-w.done:
+			return
+		}
+		s.mu.Lock()
+		delete(s.watchers, w)
+		s.mu.Unlock()
+		w.stop()
+	}()
+	go w.run(out)
+	return out, nil
+}
+
+// dispatch 将事件加入匹配的监听队列，调用方需持有 s.mu
+func (s *Store) dispatch(events []Event) {
+	if len(events) == 0 {
+		return
+	}
+	for w := range s.watchers {
+		var matched []Event
+		for _, event := range events {
+			if w.matches(event.KV.Key) {
+				matched = append(matched, event)
+			}
+		}
+		if len(matched) > 0 {
+			w.enqueue(matched)
+		}
+	}
+}
+
+// matches 判断键是否在监听范围内
+func (w *watcher) matches(key string) bool {
+	if w.isPrefix {
+		return strings.HasPrefix(key, w.key)
+	}
+	return key == w.key
+}
+
+// enqueue 追加事件并唤醒投递协程
+func (w *watcher) enqueue(events []Event) {
+	w.mu.Lock()
+	w.queue = append(w.queue, events...)
+	w.mu.Unlock()
+
+	select {
+	case w.signal <- struct{}{}:
+	default:
+	}
+}
+
+// stop 停止投递
+func (w *watcher) stop() {
+	w.once.Do(func() { close(w.done) })
+}
+
+// run 按顺序投递队列中的事件，停止后关闭输出通道
+func (w *watcher) run(out chan<- Event) {
+	defer close(out)
+	for {
+		w.mu.Lock()
+		pending := w.queue
+		w.queue = nil
+		w.mu.Unlock()
+
+		for _, event := range pending {
+			select {
+			case out <- event:
+			case <-w.done:
+				return
+			}
+		}
+
+		select {
+		case <-w.signal:
+		case <-w.done:
+			return
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package registryimpl
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/ceyewan/infra-kit/coord/internal/client"
+	"github.com/ceyewan/infra-kit/coord/registry"
+)
+
+// batchEntry 批量注册中一个实例的键和编码后的服务信息
+type batchEntry struct {
+	service registry.ServiceInfo
+	key     string
+	data    []byte
+}
+
+// prepareBatch 校验批量注册的参数，计算每个实例的键并编码服务信息
+// 任一实例不合法时整批拒绝，保证不会写入部分实例
+func prepareBatch(keys *keyLayout, services []registry.ServiceInfo, ttl time.Duration) ([]batchEntry, error) {
+	if len(services) == 0 {
+		return nil, client.NewError(client.ErrCodeValidation, "service batch cannot be empty", nil)
+	}
+	if len(services) > registry.MaxBatchSize {
+		return nil, client.NewError(client.ErrCodeValidation,
+			fmt.Sprintf("service batch size %d exceeds %d", len(services), registry.MaxBatchSize), nil)
+	}
+	if ttl <= 0 {
+		return nil, client.NewError(client.ErrCodeValidation, "service TTL must be positive", nil)
+	}
+
+	entries := make([]batchEntry, 0, len(services))
+	seen := make(map[string]struct{}, len(services))
+	for _, service := range services {
+		if err := validateServiceInfo(service); err != nil {
+			return nil, err
+		}
+		if _, dup := seen[service.ID]; dup {
+			return nil, client.NewError(client.ErrCodeValidation,
+				fmt.Sprintf("duplicate service ID %q in batch", service.ID), nil)
+		}
+		seen[service.ID] = struct{}{}
+
+		key, err := keys.serviceKey(service)
+		if err != nil {
+			return nil, err
+		}
+		data, err := json.Marshal(service)
+		if err != nil {
+			return nil, client.NewError(client.ErrCodeValidation, "failed to serialize service info", err)
+		}
+		entries = append(entries, batchEntry{service: service, key: key, data: data})
+	}
+	return entries, nil
+}
+
+// detachSession 从会话映射中移除实例，调用方需持有会话锁
+// 批量注册的实例共享会话，返回的 key 不为空表示会话仍被同批其他实例使用，
+// 调用方只应删除该实例的键；key 为空时调用方应关闭会话
+func detachSession[S comparable](sessions map[string]S, batchKeys map[string]string, serviceID string) (session S, key string, ok bool) {
+	session, ok = sessions[serviceID]
+	if !ok {
+		return session, "", false
+	}
+	delete(sessions, serviceID)
+
+	key, batched := batchKeys[serviceID]
+	delete(batchKeys, serviceID)
+	if batched {
+		for _, other := range sessions {
+			if other == session {
+				return session, key, true
+			}
+		}
+	}
+	return session, "", true
+}
+
+// dropSession 会话结束后移除仍指向它的所有实例，调用方需持有会话锁
+func dropSession[S comparable](sessions map[string]S, batchKeys map[string]string, session S, serviceIDs []string) {
+	for _, id := range serviceIDs {
+		if sessions[id] == session {
+			delete(sessions, id)
+			delete(batchKeys, id)
+		}
+	}
+}
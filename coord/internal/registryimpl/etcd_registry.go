@@ -4,7 +4,6 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"path"
 	"strings"
 	"sync"
 	"time"
@@ -13,21 +12,26 @@ import (
 	"github.com/ceyewan/infra-kit/coord/internal/client"
 	"github.com/ceyewan/infra-kit/coord/registry"
 	clientv3 "go.etcd.io/etcd/client/v3"
-	"go.etcd.io/etcd/client/v3/concurrency"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/balancer/leastrequest"
 	"google.golang.org/grpc/credentials/insecure"
 	"google.golang.org/grpc/resolver"
 )
 
+// readinessPollInterval 就绪检查的轮询间隔
+var readinessPollInterval = time.Second
+
 // EtcdServiceRegistry 使用 etcd 实现 registry.ServiceRegistry 接口
 type EtcdServiceRegistry struct {
-	client *client.EtcdClient // etcd 客户端
-	prefix string             // 服务注册前缀
-	logger clog.Logger        // 日志记录器
+	client  *client.EtcdClient // etcd 客户端
+	keys    *keyLayout         // 实例键布局
+	options *registry.Options  // 注册表选项，决定续约节奏
+	logger  clog.Logger        // 日志记录器
 
 	// 跟踪当前实例注册的服务会话
-	sessions   map[string]*concurrency.Session // 服务会话映射，便于注销
-	sessionsMu sync.Mutex                      // 会话互斥锁
+	sessions   map[string]*leaseSession // 服务会话映射，便于注销
+	batchKeys  map[string]string        // 批量注册实例的键，同批实例共享一个会话
+	sessionsMu sync.Mutex               // 会话互斥锁
 
 	// gRPC resolver builder（只注册一次）
 	resolverBuilder *EtcdResolverBuilder // gRPC 解析器构建器
@@ -35,7 +39,8 @@ type EtcdServiceRegistry struct {
 }
 
 // NewEtcdServiceRegistry 创建一个基于 etcd 的服务注册表
-func NewEtcdServiceRegistry(c *client.EtcdClient, prefix string, logger clog.Logger) *EtcdServiceRegistry {
+// 通过 registry.WithKeyLayout 自定义实例键布局时忽略 prefix；布局或续约参数无效时记录错误并回退到默认值
+func NewEtcdServiceRegistry(c *client.EtcdClient, prefix string, logger clog.Logger, opts ...registry.Option) *EtcdServiceRegistry {
 	if prefix == "" {
 		prefix = "/services"
 	}
@@ -43,15 +48,19 @@ func NewEtcdServiceRegistry(c *client.EtcdClient, prefix string, logger clog.Log
 		logger = clog.Namespace("coordination.registry")
 	}
 
+	options := resolveRenewal(registry.ParseOptions(opts...), logger)
 	registry := &EtcdServiceRegistry{
-		client:   c,
-		prefix:   prefix,
-		logger:   logger,
-		sessions: make(map[string]*concurrency.Session),
+		client:    c,
+		keys:      resolveKeyLayout(prefix, options, logger),
+		options:   options,
+		logger:    logger,
+		sessions:  make(map[string]*leaseSession),
+		batchKeys: make(map[string]string),
 	}
 
-	// 创建 resolver builder
+	// 创建 resolver builder，与注册表使用同一键布局
 	registry.resolverBuilder = NewEtcdResolverBuilder(c, prefix, logger)
+	registry.resolverBuilder.keys = registry.keys
 
 	// 注册 gRPC resolver（只注册一次）
 	registry.resolverOnce.Do(func() {
@@ -72,12 +81,16 @@ func (r *EtcdServiceRegistry) Register(ctx context.Context, service registry.Ser
 	}
 
 	// 使用会话管理租约并自动续约
-	session, err := concurrency.NewSession(r.client.Client(), concurrency.WithTTL(int(ttl.Seconds())))
+	session, err := newLeaseSession(r.client.Client(), ttl, r.options, r.logger)
 	if err != nil {
 		return client.NewError(client.ErrCodeConnection, "failed to create etcd session", err)
 	}
 
-	serviceKey := r.buildServiceKey(service.Name, service.ID)
+	serviceKey, err := r.keys.serviceKey(service)
+	if err != nil {
+		_ = session.Close() // 尝试关闭会话，释放资源
+		return err
+	}
 	serviceData, err := json.Marshal(service)
 	if err != nil {
 		_ = session.Close() // 尝试关闭会话，释放资源
@@ -130,19 +143,196 @@ func (r *EtcdServiceRegistry) Register(ctx context.Context, service registry.Ser
 	return nil
 }
 
+// RegisterBatch 在一个 etcd 事务中注册多个实例，整批实例共享一个自动续约的会话
+func (r *EtcdServiceRegistry) RegisterBatch(ctx context.Context, services []registry.ServiceInfo, ttl time.Duration) error {
+	entries, err := prepareBatch(r.keys, services, ttl)
+	if err != nil {
+		return err
+	}
+
+	session, err := newLeaseSession(r.client.Client(), ttl, r.options, r.logger)
+	if err != nil {
+		return client.NewError(client.ErrCodeConnection, "failed to create etcd session", err)
+	}
+
+	ops := make([]clientv3.Op, 0, len(entries))
+	for _, entry := range entries {
+		ops = append(ops, clientv3.OpPut(entry.key, string(entry.data), clientv3.WithLease(session.Lease())))
+	}
+	if _, err := r.client.Txn(ctx).Then(ops...).Commit(); err != nil {
+		_ = session.Close() // 事务失败时没有写入任何实例，关闭会话释放租约
+		return client.NewError(client.ErrCodeConnection, "failed to register service batch", err)
+	}
+
+	ids := make([]string, 0, len(entries))
+	r.sessionsMu.Lock()
+	for _, entry := range entries {
+		r.sessions[entry.service.ID] = session
+		r.batchKeys[entry.service.ID] = entry.key
+		ids = append(ids, entry.service.ID)
+	}
+	r.sessionsMu.Unlock()
+
+	r.logger.Info("Service batch registered successfully",
+		clog.Int("count", len(entries)),
+		clog.Int64("lease_id", int64(session.Lease())))
+
+	go func() {
+		<-session.Done()
+		r.sessionsMu.Lock()
+		dropSession(r.sessions, r.batchKeys, session, ids)
+		r.sessionsMu.Unlock()
+		r.logger.Warn("批量注册的服务会话已过期或关闭", clog.Int("count", len(ids)))
+	}()
+	return nil
+}
+
+// RegisterWithCleanup 先清理同一 ID 的已有注册再重新注册
+// 本地持有该 ID 的会话时先关闭会话；etcd 中残留的同名实例条目（如进程异常退出后尚未过期的租约）直接删除
+func (r *EtcdServiceRegistry) RegisterWithCleanup(ctx context.Context, service registry.ServiceInfo, ttl time.Duration) error {
+	if err := validateServiceInfo(service); err != nil {
+		return err
+	}
+	if ttl <= 0 {
+		return client.NewError(client.ErrCodeValidation, "service TTL must be positive", nil)
+	}
+
+	r.sessionsMu.Lock()
+	session, sharedKey, ok := detachSession(r.sessions, r.batchKeys, service.ID)
+	r.sessionsMu.Unlock()
+
+	// 会话仍被同批其他实例共享时不关闭，下面删除 key 即可
+	if ok && sharedKey == "" {
+		if err := session.Close(); err != nil {
+			r.logger.Warn("清理旧注册时关闭会话失败",
+				clog.String("service_id", service.ID),
+				clog.Err(err))
+		}
+	}
+
+	serviceKey, err := r.keys.serviceKey(service)
+	if err != nil {
+		return err
+	}
+	resp, err := r.client.Delete(ctx, serviceKey)
+	if err != nil {
+		return client.NewError(client.ErrCodeConnection, "failed to clean up previous registration", err)
+	}
+	if resp.Deleted > 0 {
+		r.logger.Info("已清理残留的服务注册",
+			clog.String("service_name", service.Name),
+			clog.String("service_id", service.ID))
+	}
+
+	return r.Register(ctx, service, ttl)
+}
+
+// RegisterWhenReady 根据就绪检查结果注册或注销服务，轮询在后台进行直到 context 被取消
+func (r *EtcdServiceRegistry) RegisterWhenReady(ctx context.Context, service registry.ServiceInfo, ttl time.Duration, readyFn func() bool) error {
+	if err := validateServiceInfo(service); err != nil {
+		return err
+	}
+	if ttl <= 0 {
+		return client.NewError(client.ErrCodeValidation, "service TTL must be positive", nil)
+	}
+	if readyFn == nil {
+		return client.NewError(client.ErrCodeValidation, "readiness function cannot be nil", nil)
+	}
+
+	go runReadinessGate(ctx, r, r.logger, service, ttl, readyFn)
+	return nil
+}
+
+// readinessTarget 就绪检查驱动的注册目标，由 etcd 和内存注册表实现
+type readinessTarget interface {
+	Register(ctx context.Context, service registry.ServiceInfo, ttl time.Duration) error
+	Unregister(ctx context.Context, serviceID string, opts ...registry.UnregisterOption) error
+	hasSession(serviceID string) bool
+}
+
+// runReadinessGate 轮询就绪状态并在状态切换时注册或注销服务
+func runReadinessGate(ctx context.Context, r readinessTarget, logger clog.Logger, service registry.ServiceInfo, ttl time.Duration, readyFn func() bool) {
+	ticker := time.NewTicker(readinessPollInterval)
+	defer ticker.Stop()
+
+	registered := false
+	for {
+		// 会话过期后视为未注册，就绪时会重新注册
+		if registered && !r.hasSession(service.ID) {
+			registered = false
+		}
+
+		ready := readyFn()
+		switch {
+		case ready && !registered:
+			if err := r.Register(ctx, service, ttl); err != nil {
+				logger.Warn("实例已就绪但注册失败，稍后重试",
+					clog.String("service_name", service.Name),
+					clog.String("service_id", service.ID),
+					clog.Err(err))
+			} else {
+				registered = true
+				logger.Info("实例已就绪，服务已注册",
+					clog.String("service_name", service.Name),
+					clog.String("service_id", service.ID))
+			}
+		case !ready && registered:
+			if err := r.Unregister(ctx, service.ID); err != nil {
+				logger.Warn("实例未就绪但注销失败",
+					clog.String("service_name", service.Name),
+					clog.String("service_id", service.ID),
+					clog.Err(err))
+			}
+			registered = false
+			logger.Info("实例未就绪，服务已注销",
+				clog.String("service_name", service.Name),
+				clog.String("service_id", service.ID))
+		}
+
+		select {
+		case <-ctx.Done():
+			if registered {
+				unregisterCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+				if err := r.Unregister(unregisterCtx, service.ID); err != nil {
+					logger.Warn("停止就绪检查时注销服务失败",
+						clog.String("service_id", service.ID),
+						clog.Err(err))
+				}
+				cancel()
+			}
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// hasSession 判断当前实例是否持有指定服务的注册会话
+func (r *EtcdServiceRegistry) hasSession(serviceID string) bool {
+	r.sessionsMu.Lock()
+	defer r.sessionsMu.Unlock()
+	_, ok := r.sessions[serviceID]
+	return ok
+}
+
 // Unregister 注销服务，优先关闭会话，找不到会话则直接删除 key
-func (r *EtcdServiceRegistry) Unregister(ctx context.Context, serviceID string) error {
+func (r *EtcdServiceRegistry) Unregister(ctx context.Context, serviceID string, opts ...registry.UnregisterOption) error {
 	if serviceID == "" {
 		return client.NewError(client.ErrCodeValidation, "service ID cannot be empty", nil)
 	}
 
 	r.sessionsMu.Lock()
-	session, ok := r.sessions[serviceID]
-	if ok {
-		delete(r.sessions, serviceID) // 先从 map 中删除，避免重复操作
-	}
+	session, sharedKey, ok := detachSession(r.sessions, r.batchKeys, serviceID) // 先从 map 中删除，避免重复操作
 	r.sessionsMu.Unlock()
 
+	// 批量注册的会话仍被同批其他实例使用，只删除该实例的 key
+	if sharedKey != "" {
+		r.logger.Info("通过删除 key 注销批量注册的服务", clog.String("service_id", serviceID))
+		if _, err := r.client.Delete(ctx, sharedKey); err != nil {
+			return client.NewError(client.ErrCodeConnection, "failed to delete service key", err)
+		}
+		return nil
+	}
+
 	// 如果本地有会话，关闭会话最干净
 	if ok {
 		r.logger.Info("通过关闭会话注销服务", clog.String("service_id", serviceID))
@@ -159,6 +349,10 @@ func (r *EtcdServiceRegistry) Unregister(ctx context.Context, serviceID string)
 		return err
 	}
 	if key == "" {
+		if registry.ParseUnregisterOptions(opts...).IgnoreMissing {
+			r.logger.Debug("服务不存在，忽略注销", clog.String("service_id", serviceID))
+			return nil
+		}
 		return client.NewError(client.ErrCodeNotFound, "service not found", nil)
 	}
 
@@ -176,7 +370,7 @@ func (r *EtcdServiceRegistry) Discover(ctx context.Context, serviceName string)
 		return nil, client.NewError(client.ErrCodeValidation, "服务名不能为空", nil)
 	}
 
-	prefix := r.buildServicePrefix(serviceName)
+	prefix := r.keys.servicePrefix(serviceName)
 	resp, err := r.client.Get(ctx, prefix, clientv3.WithPrefix())
 	if err != nil {
 		return nil, client.NewError(client.ErrCodeConnection, "failed to discover services", err)
@@ -197,32 +391,68 @@ func (r *EtcdServiceRegistry) Discover(ctx context.Context, serviceName string)
 	return services, nil
 }
 
+// DiscoverSortedByScore 发现服务并按健康评分从高到低排序
+func (r *EtcdServiceRegistry) DiscoverSortedByScore(ctx context.Context, serviceName string) ([]registry.ServiceInfo, error) {
+	services, err := r.Discover(ctx, serviceName)
+	if err != nil {
+		return nil, err
+	}
+	registry.SortByScore(services)
+	return services, nil
+}
+
+// Count 统计指定服务当前注册的实例数
+// 使用 etcd 的 count-only 范围读取，不传输和解码实例信息
+func (r *EtcdServiceRegistry) Count(ctx context.Context, serviceName string) (int, error) {
+	if serviceName == "" {
+		return 0, client.NewError(client.ErrCodeValidation, "service name cannot be empty", nil)
+	}
+
+	prefix := r.keys.servicePrefix(serviceName)
+	resp, err := r.client.Get(ctx, prefix, clientv3.WithPrefix(), clientv3.WithCountOnly())
+	if err != nil {
+		return 0, client.NewError(client.ErrCodeConnection, "failed to count services", err)
+	}
+	return int(resp.Count), nil
+}
+
 // Watch 监听服务变更事件
 func (r *EtcdServiceRegistry) Watch(ctx context.Context, serviceName string) (<-chan registry.ServiceEvent, error) {
 	if serviceName == "" {
 		return nil, client.NewError(client.ErrCodeValidation, "service name cannot be empty", nil)
 	}
 
-	prefix := r.buildServicePrefix(serviceName)
+	return r.watchPrefix(ctx, r.keys.servicePrefix(serviceName), serviceName), nil
+}
+
+// WatchAll 监听所有服务的变更事件
+// 监听整个注册前缀，事件的 Service.Name 标明所属服务；租约续约不产生事件，
+// 事件量与所有实例的上下线频率成正比
+func (r *EtcdServiceRegistry) WatchAll(ctx context.Context) (<-chan registry.ServiceEvent, error) {
+	return r.watchPrefix(ctx, r.keys.root, "*"), nil
+}
+
+// watchPrefix 监听前缀下的服务变更，scope 仅用于日志
+func (r *EtcdServiceRegistry) watchPrefix(ctx context.Context, prefix, scope string) <-chan registry.ServiceEvent {
 	etcdWatchCh := r.client.Watch(ctx, prefix, clientv3.WithPrefix())
 	eventCh := make(chan registry.ServiceEvent, 10)
 
 	go func() {
 		defer close(eventCh)
-		defer r.logger.Info("service watch goroutine exiting", clog.String("service_name", serviceName))
+		defer r.logger.Info("service watch goroutine exiting", clog.String("service_name", scope))
 
 		for {
 			select {
 			case <-ctx.Done():
-				r.logger.Info("service watch context cancelled", clog.String("service_name", serviceName))
+				r.logger.Info("service watch context cancelled", clog.String("service_name", scope))
 				return
 			case resp, ok := <-etcdWatchCh:
 				if !ok {
-					r.logger.Info("etcd watch channel closed", clog.String("service_name", serviceName))
+					r.logger.Info("etcd watch channel closed", clog.String("service_name", scope))
 					return
 				}
 				if err := resp.Err(); err != nil {
-					r.logger.Error("监听服务发生错误", clog.String("service_name", serviceName), clog.Err(err))
+					r.logger.Error("监听服务发生错误", clog.String("service_name", scope), clog.Err(err))
 					return
 				}
 				for _, event := range resp.Events {
@@ -239,22 +469,12 @@ func (r *EtcdServiceRegistry) Watch(ctx context.Context, serviceName string) (<-
 		}
 	}()
 
-	return eventCh, nil
-}
-
-// buildServiceKey 构建服务实例的 etcd key
-func (r *EtcdServiceRegistry) buildServiceKey(serviceName, serviceID string) string {
-	return path.Join(r.prefix, serviceName, serviceID)
-}
-
-// buildServicePrefix 构建服务前缀
-func (r *EtcdServiceRegistry) buildServicePrefix(serviceName string) string {
-	return path.Join(r.prefix, serviceName) + "/"
+	return eventCh
 }
 
 // findServiceKey 查找指定 serviceID 的 etcd key
 func (r *EtcdServiceRegistry) findServiceKey(ctx context.Context, serviceID string) (string, error) {
-	resp, err := r.client.Get(ctx, r.prefix+"/", clientv3.WithPrefix())
+	resp, err := r.client.Get(ctx, r.keys.root, clientv3.WithPrefix())
 	if err != nil {
 		return "", client.NewError(client.ErrCodeConnection, "failed to search for service key", err)
 	}
@@ -281,11 +501,7 @@ func (r *EtcdServiceRegistry) convertEvent(event *clientv3.Event) *registry.Serv
 	case clientv3.EventTypeDelete:
 		eventType = registry.EventTypeDelete
 		// 删除事件无法获取完整服务信息，仅能从 key 解析 Name 和 ID
-		parts := strings.Split(strings.TrimPrefix(string(event.Kv.Key), r.prefix+"/"), "/")
-		if len(parts) >= 2 {
-			service.Name = parts[0]
-			service.ID = parts[1]
-		}
+		service.Name, service.ID = r.keys.parseKey(string(event.Kv.Key))
 	default:
 		return nil
 	}
@@ -310,15 +526,23 @@ func validateServiceInfo(service registry.ServiceInfo) error {
 	if service.Port <= 0 || service.Port > 65535 {
 		return client.NewError(client.ErrCodeValidation, "服务端口必须在 1~65535 之间", nil)
 	}
+	if len(service.Attributes) > registry.MaxAttributesBytes {
+		return client.NewError(client.ErrCodeValidation, "服务属性超过大小限制", registry.ErrAttributesTooLarge)
+	}
+	if len(service.Attributes) > 0 && !json.Valid(service.Attributes) {
+		return client.NewError(client.ErrCodeValidation, "服务属性必须是合法的 JSON", nil)
+	}
 	return nil
 }
 
 // GetConnection 获取到指定服务的 gRPC 连接，支持动态服务发现和负载均衡
-func (r *EtcdServiceRegistry) GetConnection(ctx context.Context, serviceName string) (*grpc.ClientConn, error) {
+func (r *EtcdServiceRegistry) GetConnection(ctx context.Context, serviceName string, opts ...registry.ConnectionOption) (*grpc.ClientConn, error) {
 	if serviceName == "" {
 		return nil, client.NewError(client.ErrCodeValidation, "服务名不能为空", nil)
 	}
 
+	options := registry.ParseConnectionOptions(opts...)
+
 	// 使用 etcd resolver 创建连接
 	// target 格式: etcd:///<service-name>
 	target := fmt.Sprintf("%s:///%s", EtcdScheme, serviceName)
@@ -326,7 +550,7 @@ func (r *EtcdServiceRegistry) GetConnection(ctx context.Context, serviceName str
 	// 创建 gRPC 连接，使用 etcd resolver 进行动态服务发现
 	conn, err := grpc.NewClient(target,
 		grpc.WithTransportCredentials(insecure.NewCredentials()),
-		grpc.WithDefaultServiceConfig(`{"loadBalancingPolicy":"round_robin"}`), // 使用轮询负载均衡
+		grpc.WithDefaultServiceConfig(buildServiceConfig(options.LoadBalancer)),
 	)
 	if err != nil {
 		return nil, client.NewError(client.ErrCodeConnection, "连接服务失败", err)
@@ -334,7 +558,24 @@ func (r *EtcdServiceRegistry) GetConnection(ctx context.Context, serviceName str
 
 	r.logger.Info("已建立 gRPC 动态服务发现连接",
 		clog.String("service_name", serviceName),
-		clog.String("target", target))
+		clog.String("target", target),
+		clog.String("load_balancer", options.LoadBalancer))
 
 	return conn, nil
 }
+
+// Picker 创建非 gRPC 客户端使用的负载均衡器，实例列表随注册变化更新
+func (r *EtcdServiceRegistry) Picker(ctx context.Context, serviceName string, opts ...registry.PickerOption) (registry.Picker, error) {
+	return newPicker(ctx, r, serviceName, r.logger, opts...)
+}
+
+// buildServiceConfig 根据负载均衡策略构建 gRPC service config
+// loadBalancingConfig 按顺序选择第一个可用的策略，因此 least_request 不可用时会回退到 round_robin
+func buildServiceConfig(loadBalancer string) string {
+	switch loadBalancer {
+	case registry.LoadBalancerLeastRequest:
+		return fmt.Sprintf(`{"loadBalancingConfig":[{"%s":{"choiceCount":2}},{"round_robin":{}}]}`, leastrequest.Name)
+	default:
+		return `{"loadBalancingPolicy":"round_robin"}`
+	}
+}
@@ -2,8 +2,12 @@ package registryimpl
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"path"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -189,6 +193,10 @@ func TestEtcdServiceRegistry_Unregister(t *testing.T) {
 		assert.Contains(t, err.Error(), "not found")
 	})
 
+	t.Run("unregister non-existent service ignoring missing", func(t *testing.T) {
+		assert.NoError(t, serviceRegistry.Unregister(ctx, "non-existent-service", registry.WithIgnoreMissing()))
+	})
+
 	t.Run("unregister with empty ID", func(t *testing.T) {
 		err := serviceRegistry.Unregister(ctx, "")
 		assert.Error(t, err)
@@ -196,6 +204,106 @@ func TestEtcdServiceRegistry_Unregister(t *testing.T) {
 	})
 }
 
+// TestEtcdServiceRegistry_RegisterWithCleanup 测试注册前清理同一 ID 的残留条目
+func TestEtcdServiceRegistry_RegisterWithCleanup(t *testing.T) {
+	client, err := createTestEtcdClient()
+	require.NoError(t, err)
+	defer client.Close()
+
+	logger := clog.Namespace("test")
+	serviceRegistry := NewEtcdServiceRegistry(client, "/test-services-cleanup", logger)
+	ctx := context.Background()
+
+	service := registry.ServiceInfo{
+		ID:      "cleanup-1",
+		Name:    "cleanup-service",
+		Address: "127.0.0.2",
+		Port:    8080,
+	}
+
+	// 模拟异常退出的旧实例留下的条目
+	stale := service
+	stale.Address = "127.0.0.1"
+	staleData, err := json.Marshal(stale)
+	require.NoError(t, err)
+	_, err = client.Put(ctx, "/test-services-cleanup/cleanup-service/cleanup-1", string(staleData))
+	require.NoError(t, err)
+
+	require.NoError(t, serviceRegistry.RegisterWithCleanup(ctx, service, 30*time.Second))
+	defer serviceRegistry.Unregister(ctx, service.ID)
+
+	services, err := serviceRegistry.Discover(ctx, "cleanup-service")
+	require.NoError(t, err)
+	require.Len(t, services, 1)
+	assert.Equal(t, "127.0.0.2", services[0].Address)
+
+	// 本地已持有会话时再次调用同样成功
+	require.NoError(t, serviceRegistry.RegisterWithCleanup(ctx, service, 30*time.Second))
+	count, err := serviceRegistry.Count(ctx, "cleanup-service")
+	require.NoError(t, err)
+	assert.Equal(t, 1, count)
+
+	err = serviceRegistry.RegisterWithCleanup(ctx, registry.ServiceInfo{Name: "cleanup-service"}, 30*time.Second)
+	assert.Error(t, err)
+}
+
+// TestEtcdServiceRegistry_RegisterWhenReady 测试基于就绪检查的注册
+func TestEtcdServiceRegistry_RegisterWhenReady(t *testing.T) {
+	client, err := createTestEtcdClient()
+	require.NoError(t, err)
+	defer client.Close()
+
+	oldInterval := readinessPollInterval
+	readinessPollInterval = 50 * time.Millisecond
+	defer func() { readinessPollInterval = oldInterval }()
+
+	logger := clog.Namespace("test")
+	serviceRegistry := NewEtcdServiceRegistry(client, "/test-services", logger)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	service := registry.ServiceInfo{
+		ID:      "test-ready-gate",
+		Name:    "ready-gate-service",
+		Address: "127.0.0.1",
+		Port:    8080,
+	}
+	var ready atomic.Bool
+
+	err = serviceRegistry.RegisterWhenReady(ctx, service, 30*time.Second, ready.Load)
+	require.NoError(t, err)
+
+	discovered := func() int {
+		services, err := serviceRegistry.Discover(context.Background(), service.Name)
+		require.NoError(t, err)
+		return len(services)
+	}
+
+	// 未就绪时不注册
+	time.Sleep(200 * time.Millisecond)
+	assert.Equal(t, 0, discovered())
+
+	// 就绪后注册
+	ready.Store(true)
+	assert.Eventually(t, func() bool { return discovered() == 1 }, 2*time.Second, 50*time.Millisecond)
+
+	// 变为未就绪后注销
+	ready.Store(false)
+	assert.Eventually(t, func() bool { return discovered() == 0 }, 2*time.Second, 50*time.Millisecond)
+
+	// 恢复就绪后重新注册，取消 context 后注销
+	ready.Store(true)
+	assert.Eventually(t, func() bool { return discovered() == 1 }, 2*time.Second, 50*time.Millisecond)
+	cancel()
+	assert.Eventually(t, func() bool { return discovered() == 0 }, 2*time.Second, 50*time.Millisecond)
+
+	// 参数校验
+	err = serviceRegistry.RegisterWhenReady(context.Background(), service, 30*time.Second, nil)
+	assert.Error(t, err)
+	err = serviceRegistry.RegisterWhenReady(context.Background(), service, 0, ready.Load)
+	assert.Error(t, err)
+}
+
 // TestEtcdServiceRegistry_Discover 测试服务发现
 func TestEtcdServiceRegistry_Discover(t *testing.T) {
 	client, err := createTestEtcdClient()
@@ -271,6 +379,207 @@ func TestEtcdServiceRegistry_Discover(t *testing.T) {
 		assert.Contains(t, err.Error(), "[VALIDATION_ERROR] 服务名不能为空")
 		assert.Nil(t, services)
 	})
+
+	t.Run("count matches discover", func(t *testing.T) {
+		count, err := serviceRegistry.Count(ctx, "discover-service")
+		assert.NoError(t, err)
+		assert.Equal(t, 2, count)
+
+		count, err = serviceRegistry.Count(ctx, "non-existent-service")
+		assert.NoError(t, err)
+		assert.Equal(t, 0, count)
+
+		_, err = serviceRegistry.Count(ctx, "")
+		assert.Error(t, err)
+	})
+}
+
+// TestEtcdServiceRegistry_DiscoverSortedByScore 测试按健康评分排序的服务发现
+func TestEtcdServiceRegistry_DiscoverSortedByScore(t *testing.T) {
+	client, err := createTestEtcdClient()
+	require.NoError(t, err)
+	defer client.Close()
+
+	logger := clog.Namespace("test")
+	serviceRegistry := NewEtcdServiceRegistry(client, "/test-services", logger)
+	ctx := context.Background()
+
+	scores := map[string]string{"score-instance-1": "0.2", "score-instance-3": "0.9"}
+	for i, id := range []string{"score-instance-1", "score-instance-2", "score-instance-3"} {
+		service := registry.ServiceInfo{ID: id, Name: "score-service", Address: "127.0.0.1", Port: 9000 + i}
+		if score, ok := scores[id]; ok {
+			service.Metadata = map[string]string{registry.ScoreMetadataKey: score}
+		}
+		require.NoError(t, serviceRegistry.Register(ctx, service, time.Second*30))
+		defer serviceRegistry.Unregister(ctx, id)
+	}
+
+	services, err := serviceRegistry.DiscoverSortedByScore(ctx, "score-service")
+	require.NoError(t, err)
+	require.Len(t, services, 3)
+	// 未公布评分的实例视为中性评分，排在两者之间
+	assert.Equal(t, "score-instance-3", services[0].ID)
+	assert.Equal(t, "score-instance-2", services[1].ID)
+	assert.Equal(t, "score-instance-1", services[2].ID)
+
+	t.Run("score parsing", func(t *testing.T) {
+		var service registry.ServiceInfo
+		assert.Equal(t, registry.NeutralScore, service.Score())
+		service.SetScore(0.75)
+		assert.Equal(t, 0.75, service.Score())
+		service.Metadata[registry.ScoreMetadataKey] = "not-a-number"
+		assert.Equal(t, registry.NeutralScore, service.Score())
+		service.Metadata[registry.ScoreMetadataKey] = "3"
+		assert.Equal(t, 1.0, service.Score())
+	})
+}
+
+// TestEtcdServiceRegistry_Picker 测试非 gRPC 客户端的负载均衡器
+func TestEtcdServiceRegistry_Picker(t *testing.T) {
+	client, err := createTestEtcdClient()
+	require.NoError(t, err)
+	defer client.Close()
+
+	logger := clog.Namespace("test")
+	serviceRegistry := NewEtcdServiceRegistry(client, "/test-services", logger)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	picker, err := serviceRegistry.Picker(ctx, "picker-service")
+	require.NoError(t, err)
+	_, err = picker.Next()
+	assert.ErrorIs(t, err, registry.ErrNoInstances)
+
+	for i, id := range []string{"picker-instance-1", "picker-instance-2"} {
+		service := registry.ServiceInfo{ID: id, Name: "picker-service", Address: "127.0.0.1", Port: 9100 + i}
+		require.NoError(t, serviceRegistry.Register(ctx, service, time.Second*30))
+		defer serviceRegistry.Unregister(context.Background(), id, registry.WithIgnoreMissing())
+	}
+
+	// 实例列表随注册变化更新，轮询依次返回每个实例
+	require.Eventually(t, func() bool {
+		first, err1 := picker.Next()
+		second, err2 := picker.Next()
+		return err1 == nil && err2 == nil && first.ID != second.ID
+	}, 5*time.Second, 50*time.Millisecond)
+
+	require.NoError(t, serviceRegistry.Unregister(ctx, "picker-instance-1"))
+	require.Eventually(t, func() bool {
+		for i := 0; i < 3; i++ {
+			if service, err := picker.Next(); err != nil || service.ID != "picker-instance-2" {
+				return false
+			}
+		}
+		return true
+	}, 5*time.Second, 50*time.Millisecond)
+
+	random, err := serviceRegistry.Picker(ctx, "picker-service", registry.WithRandomPick())
+	require.NoError(t, err)
+	service, err := random.Next()
+	require.NoError(t, err)
+	assert.Equal(t, "picker-instance-2", service.ID)
+
+	_, err = serviceRegistry.Picker(ctx, "")
+	assert.Error(t, err)
+}
+
+// TestEtcdServiceRegistry_Attributes 测试结构化实例属性的注册和发现
+func TestEtcdServiceRegistry_Attributes(t *testing.T) {
+	client, err := createTestEtcdClient()
+	require.NoError(t, err)
+	defer client.Close()
+
+	logger := clog.Namespace("test")
+	serviceRegistry := NewEtcdServiceRegistry(client, "/test-services", logger)
+	ctx := context.Background()
+
+	type zoneInfo struct {
+		Zone    string   `json:"zone"`
+		Weights []int    `json:"weights"`
+		Tags    []string `json:"tags"`
+	}
+	service := registry.ServiceInfo{
+		ID: "attr-instance-1", Name: "attr-service", Address: "127.0.0.1", Port: 9100,
+		Metadata: map[string]string{"version": "1.0.0"},
+	}
+	require.NoError(t, service.SetAttributes(zoneInfo{Zone: "cn-east-1a", Weights: []int{3, 1}, Tags: []string{"gpu"}}))
+	require.NoError(t, serviceRegistry.Register(ctx, service, time.Second*30))
+	defer serviceRegistry.Unregister(ctx, service.ID)
+
+	services, err := serviceRegistry.Discover(ctx, "attr-service")
+	require.NoError(t, err)
+	require.Len(t, services, 1)
+	assert.Equal(t, "1.0.0", services[0].Metadata["version"])
+	var got zoneInfo
+	require.NoError(t, services[0].DecodeAttributes(&got))
+	assert.Equal(t, zoneInfo{Zone: "cn-east-1a", Weights: []int{3, 1}, Tags: []string{"gpu"}}, got)
+
+	t.Run("invalid attributes", func(t *testing.T) {
+		invalid := service
+		invalid.ID = "attr-instance-2"
+		invalid.Attributes = json.RawMessage(`{"zone":`)
+		assert.Error(t, serviceRegistry.Register(ctx, invalid, time.Second*30))
+
+		invalid.Attributes = json.RawMessage(`"` + strings.Repeat("x", registry.MaxAttributesBytes) + `"`)
+		assert.ErrorIs(t, serviceRegistry.Register(ctx, invalid, time.Second*30), registry.ErrAttributesTooLarge)
+
+		var s registry.ServiceInfo
+		assert.ErrorIs(t, s.SetAttributes(strings.Repeat("x", registry.MaxAttributesBytes)), registry.ErrAttributesTooLarge)
+		assert.Nil(t, s.Attributes)
+		assert.NoError(t, s.DecodeAttributes(&got), "unset attributes decode as a no-op")
+	})
+}
+
+// TestEtcdServiceRegistry_RegisterBatch 测试同一租约下原子地批量注册实例
+func TestEtcdServiceRegistry_RegisterBatch(t *testing.T) {
+	client, err := createTestEtcdClient()
+	require.NoError(t, err)
+	defer client.Close()
+
+	logger := clog.Namespace("test")
+	serviceRegistry := NewEtcdServiceRegistry(client, "/test-services", logger)
+	ctx := context.Background()
+
+	newInstance := func(id string, port int) registry.ServiceInfo {
+		return registry.ServiceInfo{ID: id, Name: "batch-service", Address: "127.0.0.1", Port: port}
+	}
+	batch := []registry.ServiceInfo{
+		newInstance("batch-instance-1", 9201),
+		newInstance("batch-instance-2", 9202),
+		newInstance("batch-instance-3", 9203),
+	}
+	require.NoError(t, serviceRegistry.RegisterBatch(ctx, batch, time.Second*30))
+
+	services, err := serviceRegistry.Discover(ctx, "batch-service")
+	require.NoError(t, err)
+	assert.Len(t, services, 3)
+
+	// 同批实例共享租约，注销一个实例不影响其他实例
+	require.NoError(t, serviceRegistry.Unregister(ctx, "batch-instance-1"))
+	services, err = serviceRegistry.Discover(ctx, "batch-service")
+	require.NoError(t, err)
+	assert.Len(t, services, 2)
+
+	require.NoError(t, serviceRegistry.Unregister(ctx, "batch-instance-2"))
+	require.NoError(t, serviceRegistry.Unregister(ctx, "batch-instance-3"))
+	services, err = serviceRegistry.Discover(ctx, "batch-service")
+	require.NoError(t, err)
+	assert.Empty(t, services)
+
+	t.Run("invalid batch registers nothing", func(t *testing.T) {
+		assert.Error(t, serviceRegistry.RegisterBatch(ctx, nil, time.Second*30))
+		assert.Error(t, serviceRegistry.RegisterBatch(ctx, batch[:1], 0))
+
+		duplicate := []registry.ServiceInfo{newInstance("batch-instance-4", 9204), newInstance("batch-instance-4", 9205)}
+		assert.Error(t, serviceRegistry.RegisterBatch(ctx, duplicate, time.Second*30))
+
+		invalid := []registry.ServiceInfo{newInstance("batch-instance-5", 9205), newInstance("batch-instance-6", 0)}
+		assert.Error(t, serviceRegistry.RegisterBatch(ctx, invalid, time.Second*30))
+
+		services, err := serviceRegistry.Discover(ctx, "batch-service")
+		require.NoError(t, err)
+		assert.Empty(t, services)
+	})
 }
 
 // TestEtcdServiceRegistry_Watch 测试服务监听
@@ -333,6 +642,41 @@ func TestEtcdServiceRegistry_Watch(t *testing.T) {
 		assert.Contains(t, err.Error(), "cannot be empty")
 		assert.Nil(t, eventCh)
 	})
+
+	t.Run("watch all services", func(t *testing.T) {
+		eventCh, err := serviceRegistry.WatchAll(ctx)
+		require.NoError(t, err)
+
+		services := []registry.ServiceInfo{
+			{ID: "catalog-a-1", Name: "catalog-a", Address: "127.0.0.1", Port: 8081},
+			{ID: "catalog-b-1", Name: "catalog-b", Address: "127.0.0.1", Port: 8082},
+		}
+		for _, service := range services {
+			require.NoError(t, serviceRegistry.Register(ctx, service, time.Second*30))
+		}
+		for _, service := range services {
+			select {
+			case event := <-eventCh:
+				assert.Equal(t, registry.EventTypePut, event.Type)
+				assert.Equal(t, service.Name, event.Service.Name)
+				assert.Equal(t, service.ID, event.Service.ID)
+			case <-time.After(time.Second * 2):
+				t.Fatal("Timeout waiting for catalog registration event")
+			}
+		}
+
+		require.NoError(t, serviceRegistry.Unregister(ctx, services[1].ID))
+		select {
+		case event := <-eventCh:
+			assert.Equal(t, registry.EventTypeDelete, event.Type)
+			assert.Equal(t, services[1].Name, event.Service.Name)
+			assert.Equal(t, services[1].ID, event.Service.ID)
+		case <-time.After(time.Second * 2):
+			t.Fatal("Timeout waiting for catalog unregistration event")
+		}
+
+		require.NoError(t, serviceRegistry.Unregister(ctx, services[0].ID))
+	})
 }
 
 // TestEtcdServiceRegistry_ConcurrentOperations 测试并发操作
@@ -432,6 +776,58 @@ func TestEtcdServiceRegistry_SessionExpiration(t *testing.T) {
 	})
 }
 
+// TestEtcdServiceRegistry_KeyLayout 测试自定义实例键布局
+func TestEtcdServiceRegistry_KeyLayout(t *testing.T) {
+	client, err := createTestEtcdClient()
+	require.NoError(t, err)
+	defer client.Close()
+
+	layout := func(info registry.ServiceInfo) string {
+		return path.Join("/test-env/services", info.Name, info.ID)
+	}
+	serviceRegistry := NewEtcdServiceRegistry(client, "/test-services", clog.Namespace("test"), registry.WithKeyLayout(layout))
+	ctx := context.Background()
+
+	watchCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	events, err := serviceRegistry.WatchAll(watchCtx)
+	require.NoError(t, err)
+
+	service := registry.ServiceInfo{ID: "layout-1", Name: "layout-service", Address: "127.0.0.1", Port: 8080}
+	require.NoError(t, serviceRegistry.Register(ctx, service, time.Second*10))
+
+	resp, err := client.Get(ctx, "/test-env/services/layout-service/layout-1")
+	require.NoError(t, err)
+	assert.Len(t, resp.Kvs, 1)
+
+	services, err := serviceRegistry.Discover(ctx, "layout-service")
+	require.NoError(t, err)
+	require.Len(t, services, 1)
+	assert.Equal(t, "layout-1", services[0].ID)
+
+	require.NoError(t, serviceRegistry.Unregister(ctx, "layout-1"))
+	for _, want := range []registry.EventType{registry.EventTypePut, registry.EventTypeDelete} {
+		select {
+		case event := <-events:
+			assert.Equal(t, want, event.Type)
+			assert.Equal(t, "layout-service", event.Service.Name)
+			assert.Equal(t, "layout-1", event.Service.ID)
+		case <-time.After(5 * time.Second):
+			t.Fatalf("timed out waiting for %v event", want)
+		}
+	}
+
+	t.Run("invalid layouts", func(t *testing.T) {
+		assert.Error(t, ValidateKeyLayout(func(info registry.ServiceInfo) string {
+			return "/flat/" + info.ID // 缺少服务名，不同服务的实例会互相覆盖
+		}))
+		assert.Error(t, ValidateKeyLayout(func(info registry.ServiceInfo) string {
+			return "/services/" + info.Name + "-" + info.ID // 实例 ID 不是独立的一级路径
+		}))
+		assert.NoError(t, ValidateKeyLayout(nil))
+	})
+}
+
 // BenchmarkEtcdServiceRegistry 基准测试
 func BenchmarkEtcdServiceRegistry(b *testing.B) {
 	client, err := createTestEtcdClient()
@@ -494,6 +890,18 @@ func BenchmarkEtcdServiceRegistry(b *testing.B) {
 	})
 }
 
+// TestBuildServiceConfig 测试负载均衡策略对应的 service config
+func TestBuildServiceConfig(t *testing.T) {
+	assert.Equal(t, `{"loadBalancingPolicy":"round_robin"}`, buildServiceConfig(registry.LoadBalancerRoundRobin))
+
+	leastRequest := buildServiceConfig(registry.LoadBalancerLeastRequest)
+	assert.Contains(t, leastRequest, "least_request")
+	assert.Contains(t, leastRequest, "round_robin") // 回退策略
+
+	options := registry.ParseConnectionOptions(registry.WithLeastRequest())
+	assert.Equal(t, registry.LoadBalancerLeastRequest, options.LoadBalancer)
+}
+
 // createTestEtcdClient 创建测试用的etcd客户端
 func createTestEtcdClient() (*client.EtcdClient, error) {
 	config := client.Config{
@@ -4,7 +4,6 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"strings"
 	"sync"
 	"time"
 
@@ -23,7 +22,7 @@ const (
 // EtcdResolverBuilder 实现 gRPC resolver.Builder 接口
 type EtcdResolverBuilder struct {
 	client *client.EtcdClient
-	prefix string
+	keys   *keyLayout
 	logger clog.Logger
 }
 
@@ -35,9 +34,10 @@ func NewEtcdResolverBuilder(client *client.EtcdClient, prefix string, logger clo
 	if logger == nil {
 		logger = clog.Namespace("coordination.resolver")
 	}
+	keys, _ := newKeyLayout(prefix, nil)
 	return &EtcdResolverBuilder{
 		client: client,
-		prefix: prefix,
+		keys:   keys,
 		logger: logger,
 	}
 }
@@ -51,7 +51,7 @@ func (b *EtcdResolverBuilder) Build(target resolver.Target, cc resolver.ClientCo
 
 	r := &EtcdResolver{
 		client:      b.client,
-		prefix:      b.prefix,
+		keys:        b.keys,
 		serviceName: serviceName,
 		cc:          cc,
 		logger:      b.logger,
@@ -76,7 +76,7 @@ func (b *EtcdResolverBuilder) Scheme() string {
 // EtcdResolver 实现 gRPC resolver.Resolver 接口
 type EtcdResolver struct {
 	client      *client.EtcdClient
-	prefix      string
+	keys        *keyLayout
 	serviceName string
 	cc          resolver.ClientConn
 	logger      clog.Logger
@@ -108,7 +108,7 @@ func (r *EtcdResolver) start() {
 
 // resolveNow 立即解析服务地址
 func (r *EtcdResolver) resolveNow() error {
-	prefix := r.buildServicePrefix(r.serviceName)
+	prefix := r.keys.servicePrefix(r.serviceName)
 	resp, err := r.client.Get(r.ctx, prefix, clientv3.WithPrefix())
 	if err != nil {
 		return client.NewError(client.ErrCodeConnection, "failed to discover services", err)
@@ -171,7 +171,7 @@ func (r *EtcdResolver) resolveNow() error {
 
 // watch 监听服务变化
 func (r *EtcdResolver) watch() {
-	prefix := r.buildServicePrefix(r.serviceName)
+	prefix := r.keys.servicePrefix(r.serviceName)
 
 	for {
 		select {
@@ -247,8 +247,3 @@ func (r *EtcdResolver) Close() {
 	}
 	<-r.closed
 }
-
-// buildServicePrefix 构建服务前缀
-func (r *EtcdResolver) buildServicePrefix(serviceName string) string {
-	return fmt.Sprintf("%s/%s/", strings.TrimSuffix(r.prefix, "/"), serviceName)
-}
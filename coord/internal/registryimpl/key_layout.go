@@ -0,0 +1,116 @@
+package registryimpl
+
+import (
+	"path"
+	"strings"
+
+	"github.com/ceyewan/infra-kit/clog"
+	"github.com/ceyewan/infra-kit/coord/internal/client"
+	"github.com/ceyewan/infra-kit/coord/registry"
+)
+
+// 校验布局时使用的探测服务名和实例 ID
+const (
+	probeServiceName = "layout-probe-a"
+	probeOtherName   = "layout-probe-b"
+	probeServiceID   = "layout-probe-id"
+)
+
+// keyLayout 封装实例键布局，保证注册、发现、监听和解析删除事件使用同一规则
+// 合法布局的键由三部分组成：root + 服务名 + suffix + 实例 ID，其中 root 和 suffix 与服务无关
+type keyLayout struct {
+	layout registry.KeyLayout // 实例键布局
+	root   string             // 所有服务共同的前缀，以 "/" 结尾，用于 WatchAll 和按 ID 查找
+}
+
+// defaultKeyLayout 返回默认布局 "<prefix>/<name>/<id>"
+func defaultKeyLayout(prefix string) registry.KeyLayout {
+	return func(info registry.ServiceInfo) string {
+		return path.Join(prefix, info.Name, info.ID)
+	}
+}
+
+// ValidateKeyLayout 检查自定义布局是否满足 registry.KeyLayout 的约束，nil 表示使用默认布局
+func ValidateKeyLayout(layout registry.KeyLayout) error {
+	if layout == nil {
+		return nil
+	}
+	_, err := newKeyLayout("", layout)
+	return err
+}
+
+// newKeyLayout 创建键布局，layout 为 nil 时使用 prefix 下的默认布局
+// 使用探测服务名校验布局：键等于服务前缀加 ID、服务名是独立的一级路径，
+// 且替换服务名后其余部分不变，从而保证不同服务、不同实例的键互不重叠
+func newKeyLayout(prefix string, layout registry.KeyLayout) (*keyLayout, error) {
+	if layout == nil {
+		layout = defaultKeyLayout(prefix)
+	}
+	l := &keyLayout{layout: layout}
+
+	servicePrefix := l.servicePrefix(probeServiceName)
+	key := layout(registry.ServiceInfo{Name: probeServiceName, ID: probeServiceID})
+	if key != servicePrefix+probeServiceID {
+		return nil, client.NewError(client.ErrCodeValidation, "service key layout must end with the service ID and match the layout for an empty ID", nil)
+	}
+
+	segment := "/" + probeServiceName + "/"
+	idx := strings.Index(servicePrefix, segment)
+	if idx < 0 {
+		return nil, client.NewError(client.ErrCodeValidation, "service key layout must contain the service name as a path segment", nil)
+	}
+	l.root = servicePrefix[:idx+1]
+	suffix := servicePrefix[idx+len(segment):]
+	if l.servicePrefix(probeOtherName) != l.root+probeOtherName+"/"+suffix {
+		return nil, client.NewError(client.ErrCodeValidation, "service key layout must only vary by the service name and ID", nil)
+	}
+	return l, nil
+}
+
+// KeyRoot 返回布局下所有服务实例键的共同前缀（以 "/" 结尾），layout 无效时返回 prefix 下默认布局的前缀
+// 用于声明服务注册占用的键空间，避免其他组件写入
+func KeyRoot(prefix string, layout registry.KeyLayout) string {
+	keys, err := newKeyLayout(prefix, layout)
+	if err != nil {
+		keys, _ = newKeyLayout(prefix, nil)
+	}
+	return keys.root
+}
+
+// resolveKeyLayout 根据选项创建键布局，自定义布局无效时记录错误并回退到 prefix 下的默认布局
+// 通过 coord.New 创建时布局已提前校验，这里的回退只针对直接构造注册表的调用方
+func resolveKeyLayout(prefix string, options *registry.Options, logger clog.Logger) *keyLayout {
+	keys, err := newKeyLayout(prefix, options.KeyLayout)
+	if err != nil {
+		logger.Error("服务键布局无效，使用默认布局", clog.String("prefix", prefix), clog.Err(err))
+		keys, _ = newKeyLayout(prefix, nil)
+	}
+	return keys
+}
+
+// serviceKey 返回实例的完整键，布局依赖 Name、ID 以外的字段导致键与服务前缀不一致时返回错误
+func (l *keyLayout) serviceKey(service registry.ServiceInfo) (string, error) {
+	if strings.Contains(service.ID, "/") {
+		return "", client.NewError(client.ErrCodeValidation, "服务 ID 不能包含 /", nil)
+	}
+	key := l.layout(service)
+	if key != l.servicePrefix(service.Name)+service.ID {
+		return "", client.NewError(client.ErrCodeValidation, "service key layout produced a key outside the service prefix", nil)
+	}
+	return key, nil
+}
+
+// servicePrefix 返回服务下所有实例键的共同前缀，以 "/" 结尾
+func (l *keyLayout) servicePrefix(serviceName string) string {
+	return strings.TrimSuffix(l.layout(registry.ServiceInfo{Name: serviceName}), "/") + "/"
+}
+
+// parseKey 从实例键解析服务名和实例 ID，用于删除事件；键不属于该布局时返回空字符串
+func (l *keyLayout) parseKey(key string) (serviceName, serviceID string) {
+	rest, ok := strings.CutPrefix(key, l.root)
+	if !ok {
+		return "", ""
+	}
+	serviceName, _, _ = strings.Cut(rest, "/")
+	return serviceName, path.Base(key)
+}
@@ -0,0 +1,407 @@
+package registryimpl
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ceyewan/infra-kit/clog"
+	"github.com/ceyewan/infra-kit/coord/internal/client"
+	"github.com/ceyewan/infra-kit/coord/internal/memstore"
+	"github.com/ceyewan/infra-kit/coord/registry"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/resolver"
+)
+
+// MemoryScheme 内存注册表的 gRPC resolver scheme，只对 GetConnection 创建的连接生效
+const MemoryScheme = "memory"
+
+// MemoryServiceRegistry 使用进程内存储实现 registry.ServiceRegistry 接口，仅用于测试
+// 与 etcd 实现一样，每个注册绑定一个自动续约的会话，Unregister 或会话关闭时删除
+type MemoryServiceRegistry struct {
+	store   *memstore.Store   // 进程内存储
+	keys    *keyLayout        // 实例键布局
+	options *registry.Options // 注册表选项，决定续约节奏
+	logger  clog.Logger       // 日志记录器
+
+	sessions   map[string]*memstore.Session // 服务会话映射，便于注销
+	batchKeys  map[string]string            // 批量注册实例的键，同批实例共享一个会话
+	sessionsMu sync.Mutex                   // 会话互斥锁
+}
+
+// NewMemoryServiceRegistry 创建一个基于进程内存储的服务注册表，键布局规则与 etcd 实现相同
+func NewMemoryServiceRegistry(store *memstore.Store, prefix string, logger clog.Logger, opts ...registry.Option) *MemoryServiceRegistry {
+	if prefix == "" {
+		prefix = "/services"
+	}
+	if logger == nil {
+		logger = clog.Namespace("coordination.registry")
+	}
+	options := resolveRenewal(registry.ParseOptions(opts...), logger)
+	return &MemoryServiceRegistry{
+		store:     store,
+		keys:      resolveKeyLayout(prefix, options, logger),
+		options:   options,
+		logger:    logger,
+		sessions:  make(map[string]*memstore.Session),
+		batchKeys: make(map[string]string),
+	}
+}
+
+// Register 注册服务，服务会被持续保持直到 Unregister 被调用
+func (r *MemoryServiceRegistry) Register(ctx context.Context, service registry.ServiceInfo, ttl time.Duration) error {
+	if err := validateServiceInfo(service); err != nil {
+		return err
+	}
+	if ttl <= 0 {
+		return client.NewError(client.ErrCodeValidation, "service TTL must be positive", nil)
+	}
+
+	serviceKey, err := r.keys.serviceKey(service)
+	if err != nil {
+		return err
+	}
+	serviceData, err := json.Marshal(service)
+	if err != nil {
+		return client.NewError(client.ErrCodeValidation, "failed to serialize service info", err)
+	}
+
+	session, err := r.newSession(ttl)
+	if err != nil {
+		return client.NewError(client.ErrCodeConnection, "failed to create session", err)
+	}
+	if _, err := r.store.Put(serviceKey, serviceData, session.Lease()); err != nil {
+		_ = session.Close()
+		return client.NewError(client.ErrCodeConnection, "failed to register service", err)
+	}
+
+	r.sessionsMu.Lock()
+	r.sessions[service.ID] = session
+	r.sessionsMu.Unlock()
+
+	go func() {
+		<-session.Done()
+		r.sessionsMu.Lock()
+		if r.sessions[service.ID] == session {
+			delete(r.sessions, service.ID)
+		}
+		r.sessionsMu.Unlock()
+	}()
+
+	r.logger.Info("Service registered successfully",
+		clog.String("service_name", service.Name),
+		clog.String("service_id", service.ID))
+	return nil
+}
+
+// RegisterBatch 在一个存储事务中注册多个实例，整批实例共享一个自动续约的会话
+func (r *MemoryServiceRegistry) RegisterBatch(ctx context.Context, services []registry.ServiceInfo, ttl time.Duration) error {
+	entries, err := prepareBatch(r.keys, services, ttl)
+	if err != nil {
+		return err
+	}
+
+	session, err := r.newSession(ttl)
+	if err != nil {
+		return client.NewError(client.ErrCodeConnection, "failed to create session", err)
+	}
+	// 存储事务不回滚，但实例都绑定在新会话的租约上，失败时关闭会话会一并删除已写入的键
+	err = r.store.Txn(func(tx *memstore.Txn) error {
+		for _, entry := range entries {
+			if err := tx.Put(entry.key, entry.data, session.Lease()); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		_ = session.Close()
+		return client.NewError(client.ErrCodeConnection, "failed to register service batch", err)
+	}
+
+	ids := make([]string, 0, len(entries))
+	r.sessionsMu.Lock()
+	for _, entry := range entries {
+		r.sessions[entry.service.ID] = session
+		r.batchKeys[entry.service.ID] = entry.key
+		ids = append(ids, entry.service.ID)
+	}
+	r.sessionsMu.Unlock()
+
+	go func() {
+		<-session.Done()
+		r.sessionsMu.Lock()
+		dropSession(r.sessions, r.batchKeys, session, ids)
+		r.sessionsMu.Unlock()
+	}()
+
+	r.logger.Info("Service batch registered successfully", clog.Int("count", len(entries)))
+	return nil
+}
+
+// RegisterWithCleanup 先清理同一 ID 的已有注册再重新注册
+func (r *MemoryServiceRegistry) RegisterWithCleanup(ctx context.Context, service registry.ServiceInfo, ttl time.Duration) error {
+	if err := validateServiceInfo(service); err != nil {
+		return err
+	}
+	if ttl <= 0 {
+		return client.NewError(client.ErrCodeValidation, "service TTL must be positive", nil)
+	}
+
+	serviceKey, err := r.keys.serviceKey(service)
+	if err != nil {
+		return err
+	}
+
+	r.closeSession(service.ID)
+	if _, err := r.store.Delete(serviceKey); err != nil {
+		return client.NewError(client.ErrCodeConnection, "failed to clean up previous registration", err)
+	}
+	return r.Register(ctx, service, ttl)
+}
+
+// RegisterWhenReady 根据就绪检查结果注册或注销服务，轮询在后台进行直到 context 被取消
+func (r *MemoryServiceRegistry) RegisterWhenReady(ctx context.Context, service registry.ServiceInfo, ttl time.Duration, readyFn func() bool) error {
+	if err := validateServiceInfo(service); err != nil {
+		return err
+	}
+	if ttl <= 0 {
+		return client.NewError(client.ErrCodeValidation, "service TTL must be positive", nil)
+	}
+	if readyFn == nil {
+		return client.NewError(client.ErrCodeValidation, "readiness function cannot be nil", nil)
+	}
+
+	go runReadinessGate(ctx, r, r.logger, service, ttl, readyFn)
+	return nil
+}
+
+// hasSession 判断当前实例是否持有指定服务的注册会话
+func (r *MemoryServiceRegistry) hasSession(serviceID string) bool {
+	r.sessionsMu.Lock()
+	defer r.sessionsMu.Unlock()
+	_, ok := r.sessions[serviceID]
+	return ok
+}
+
+// closeSession 关闭本地持有的会话，返回是否存在会话
+// 会话仍被同批注册的其他实例共享时只删除该实例的 key
+func (r *MemoryServiceRegistry) closeSession(serviceID string) bool {
+	r.sessionsMu.Lock()
+	session, sharedKey, ok := detachSession(r.sessions, r.batchKeys, serviceID)
+	r.sessionsMu.Unlock()
+
+	switch {
+	case sharedKey != "":
+		_, _ = r.store.Delete(sharedKey)
+	case ok:
+		_ = session.Close()
+	}
+	return ok
+}
+
+// Unregister 注销服务，优先关闭会话，找不到会话则直接删除 key
+func (r *MemoryServiceRegistry) Unregister(ctx context.Context, serviceID string, opts ...registry.UnregisterOption) error {
+	if serviceID == "" {
+		return client.NewError(client.ErrCodeValidation, "service ID cannot be empty", nil)
+	}
+	if r.closeSession(serviceID) {
+		return nil
+	}
+
+	for _, kv := range r.store.GetPrefix(r.keys.root) {
+		if strings.HasSuffix(kv.Key, "/"+serviceID) {
+			if _, err := r.store.Delete(kv.Key); err != nil {
+				return client.NewError(client.ErrCodeConnection, "failed to delete service key", err)
+			}
+			return nil
+		}
+	}
+	if registry.ParseUnregisterOptions(opts...).IgnoreMissing {
+		return nil
+	}
+	return client.NewError(client.ErrCodeNotFound, "service not found", nil)
+}
+
+// Discover 查询指定服务的所有实例
+func (r *MemoryServiceRegistry) Discover(ctx context.Context, serviceName string) ([]registry.ServiceInfo, error) {
+	if serviceName == "" {
+		return nil, client.NewError(client.ErrCodeValidation, "服务名不能为空", nil)
+	}
+
+	kvs := r.store.GetPrefix(r.keys.servicePrefix(serviceName))
+	services := make([]registry.ServiceInfo, 0, len(kvs))
+	for _, kv := range kvs {
+		var service registry.ServiceInfo
+		if err := json.Unmarshal(kv.Value, &service); err != nil {
+			r.logger.Warn("Failed to unmarshal service info, skipping",
+				clog.String("key", kv.Key),
+				clog.Err(err))
+			continue
+		}
+		services = append(services, service)
+	}
+	return services, nil
+}
+
+// DiscoverSortedByScore 发现服务并按健康评分从高到低排序
+func (r *MemoryServiceRegistry) DiscoverSortedByScore(ctx context.Context, serviceName string) ([]registry.ServiceInfo, error) {
+	services, err := r.Discover(ctx, serviceName)
+	if err != nil {
+		return nil, err
+	}
+	registry.SortByScore(services)
+	return services, nil
+}
+
+// Count 统计指定服务当前注册的实例数
+func (r *MemoryServiceRegistry) Count(ctx context.Context, serviceName string) (int, error) {
+	if serviceName == "" {
+		return 0, client.NewError(client.ErrCodeValidation, "service name cannot be empty", nil)
+	}
+	return len(r.store.GetPrefix(r.keys.servicePrefix(serviceName))), nil
+}
+
+// Watch 监听服务变更事件
+func (r *MemoryServiceRegistry) Watch(ctx context.Context, serviceName string) (<-chan registry.ServiceEvent, error) {
+	if serviceName == "" {
+		return nil, client.NewError(client.ErrCodeValidation, "service name cannot be empty", nil)
+	}
+
+	return r.watchPrefix(ctx, r.keys.servicePrefix(serviceName)), nil
+}
+
+// WatchAll 监听所有服务的变更事件，事件的 Service.Name 标明所属服务
+func (r *MemoryServiceRegistry) WatchAll(ctx context.Context) (<-chan registry.ServiceEvent, error) {
+	return r.watchPrefix(ctx, r.keys.root), nil
+}
+
+// watchPrefix 监听前缀下的服务变更
+func (r *MemoryServiceRegistry) watchPrefix(ctx context.Context, prefix string) <-chan registry.ServiceEvent {
+	storeCh := r.store.Watch(ctx, prefix, true)
+	eventCh := make(chan registry.ServiceEvent, 10)
+	go func() {
+		defer close(eventCh)
+		for event := range storeCh {
+			serviceEvent, ok := r.convertEvent(event)
+			if !ok {
+				continue
+			}
+			select {
+			case eventCh <- serviceEvent:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return eventCh
+}
+
+// convertEvent 将存储事件转换为服务事件
+func (r *MemoryServiceRegistry) convertEvent(event memstore.Event) (registry.ServiceEvent, bool) {
+	if event.Type == memstore.EventPut {
+		var service registry.ServiceInfo
+		if err := json.Unmarshal(event.KV.Value, &service); err != nil {
+			r.logger.Warn("事件中服务信息解析失败", clog.String("key", event.KV.Key), clog.Err(err))
+			return registry.ServiceEvent{}, false
+		}
+		return registry.ServiceEvent{Type: registry.EventTypePut, Service: service}, true
+	}
+
+	// 删除事件无法获取完整服务信息，仅能从 key 解析 Name 和 ID
+	var service registry.ServiceInfo
+	service.Name, service.ID = r.keys.parseKey(event.KV.Key)
+	return registry.ServiceEvent{Type: registry.EventTypeDelete, Service: service}, true
+}
+
+// Picker 创建非 gRPC 客户端使用的负载均衡器，实例列表随注册变化更新
+func (r *MemoryServiceRegistry) Picker(ctx context.Context, serviceName string, opts ...registry.PickerOption) (registry.Picker, error) {
+	return newPicker(ctx, r, serviceName, r.logger, opts...)
+}
+
+// GetConnection 获取到指定服务的 gRPC 连接，地址随注册表变化动态更新
+func (r *MemoryServiceRegistry) GetConnection(ctx context.Context, serviceName string, opts ...registry.ConnectionOption) (*grpc.ClientConn, error) {
+	if serviceName == "" {
+		return nil, client.NewError(client.ErrCodeValidation, "服务名不能为空", nil)
+	}
+
+	options := registry.ParseConnectionOptions(opts...)
+	target := fmt.Sprintf("%s:///%s", MemoryScheme, serviceName)
+	conn, err := grpc.NewClient(target,
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithDefaultServiceConfig(buildServiceConfig(options.LoadBalancer)),
+		grpc.WithResolvers(&memoryResolverBuilder{registry: r}),
+	)
+	if err != nil {
+		return nil, client.NewError(client.ErrCodeConnection, "连接服务失败", err)
+	}
+	return conn, nil
+}
+
+// memoryResolverBuilder 为单个连接构建内存注册表的 resolver，不做全局注册
+type memoryResolverBuilder struct {
+	registry *MemoryServiceRegistry
+}
+
+// Build 创建 resolver，先解析一次当前实例，再监听后续变化
+func (b *memoryResolverBuilder) Build(target resolver.Target, cc resolver.ClientConn, opts resolver.BuildOptions) (resolver.Resolver, error) {
+	serviceName := target.Endpoint()
+	if serviceName == "" {
+		return nil, fmt.Errorf("service name cannot be empty")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	r := &memoryResolver{registry: b.registry, serviceName: serviceName, cc: cc, cancel: cancel}
+	events := b.registry.store.Watch(ctx, b.registry.keys.servicePrefix(serviceName), true)
+	r.resolveNow()
+	go func() {
+		for range events {
+			r.resolveNow()
+		}
+	}()
+	return r, nil
+}
+
+// Scheme 返回 resolver 的 scheme
+func (b *memoryResolverBuilder) Scheme() string {
+	return MemoryScheme
+}
+
+// memoryResolver 将注册表中的实例地址推送给 gRPC 连接
+type memoryResolver struct {
+	registry    *MemoryServiceRegistry
+	serviceName string
+	cc          resolver.ClientConn
+	cancel      context.CancelFunc
+}
+
+// resolveNow 读取当前实例并更新连接状态
+func (r *memoryResolver) resolveNow() {
+	services, _ := r.registry.Discover(context.Background(), r.serviceName)
+	addresses := make([]resolver.Address, 0, len(services))
+	for _, service := range services {
+		addresses = append(addresses, resolver.Address{Addr: fmt.Sprintf("%s:%d", service.Address, service.Port)})
+	}
+	_ = r.cc.UpdateState(resolver.State{Addresses: addresses})
+}
+
+// ResolveNow 立即触发地址解析
+func (r *memoryResolver) ResolveNow(resolver.ResolveNowOptions) {
+	r.resolveNow()
+}
+
+// Close 停止监听
+func (r *memoryResolver) Close() {
+	r.cancel()
+}
+
+// newSession 创建按注册表选项续约的会话
+func (r *MemoryServiceRegistry) newSession(ttl time.Duration) (*memstore.Session, error) {
+	return memstore.NewSessionWithInterval(r.store, ttl, func() time.Duration {
+		return r.options.RenewInterval(ttl)
+	})
+}
@@ -0,0 +1,127 @@
+package registryimpl
+
+import (
+	"context"
+	"math/rand/v2"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/ceyewan/infra-kit/clog"
+	"github.com/ceyewan/infra-kit/coord/internal/client"
+	"github.com/ceyewan/infra-kit/coord/registry"
+)
+
+// pickerRetryInterval 监听意外结束后重新建立监听的间隔
+const pickerRetryInterval = time.Second
+
+// pickerSource Picker 获取实例列表和变更通知的来源，由 etcd 和内存实现的 ServiceRegistry 提供
+type pickerSource interface {
+	Discover(ctx context.Context, serviceName string) ([]registry.ServiceInfo, error)
+	Watch(ctx context.Context, serviceName string) (<-chan registry.ServiceEvent, error)
+}
+
+// servicePicker 基于 Discover 和 Watch 维护实例列表的 Picker 实现
+// 与 gRPC resolver 一样，收到变更事件后重新读取完整的实例列表，而不是逐个应用事件
+type servicePicker struct {
+	source      pickerSource
+	serviceName string
+	policy      string
+	logger      clog.Logger
+	counter     atomic.Uint64 // 轮询计数
+
+	mu        sync.RWMutex
+	instances []registry.ServiceInfo // 按实例 ID 排序
+}
+
+var _ registry.Picker = (*servicePicker)(nil)
+
+// newPicker 加载实例列表并在后台监听变更，ctx 取消后停止更新
+func newPicker(ctx context.Context, source pickerSource, serviceName string, logger clog.Logger, opts ...registry.PickerOption) (*servicePicker, error) {
+	if serviceName == "" {
+		return nil, client.NewError(client.ErrCodeValidation, "service name cannot be empty", nil)
+	}
+	options := registry.ParsePickerOptions(opts...)
+	if options.Policy != registry.LoadBalancerRoundRobin && options.Policy != registry.LoadBalancerRandom {
+		return nil, client.NewError(client.ErrCodeValidation, "unsupported picker policy: "+options.Policy, nil)
+	}
+
+	p := &servicePicker{
+		source:      source,
+		serviceName: serviceName,
+		policy:      options.Policy,
+		logger:      logger.With(clog.String("service_name", serviceName)),
+	}
+
+	// 先建立监听再读取实例列表，避免遗漏两者之间的变更
+	events, err := source.Watch(ctx, serviceName)
+	if err != nil {
+		return nil, err
+	}
+	if err := p.refresh(ctx); err != nil {
+		return nil, err
+	}
+	go p.run(ctx, events)
+	return p, nil
+}
+
+// Next 按策略返回下一个实例
+func (p *servicePicker) Next() (registry.ServiceInfo, error) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	if len(p.instances) == 0 {
+		return registry.ServiceInfo{}, client.NewError(client.ErrCodeNotFound,
+			"no available instances for service "+p.serviceName, nil).WithKind(registry.ErrNoInstances)
+	}
+	if p.policy == registry.LoadBalancerRandom {
+		return p.instances[rand.IntN(len(p.instances))], nil
+	}
+	n := p.counter.Add(1) - 1
+	return p.instances[n%uint64(len(p.instances))], nil
+}
+
+// refresh 重新读取完整的实例列表
+func (p *servicePicker) refresh(ctx context.Context) error {
+	instances, err := p.source.Discover(ctx, p.serviceName)
+	if err != nil {
+		return err
+	}
+	sort.Slice(instances, func(i, j int) bool {
+		return instances[i].ID < instances[j].ID
+	})
+
+	p.mu.Lock()
+	p.instances = instances
+	p.mu.Unlock()
+	return nil
+}
+
+// run 收到变更事件后刷新实例列表；监听意外结束时重新建立监听并刷新，直到 ctx 取消
+func (p *servicePicker) run(ctx context.Context, events <-chan registry.ServiceEvent) {
+	for {
+		for range events {
+			if err := p.refresh(ctx); err != nil && ctx.Err() == nil {
+				// 刷新失败时保留原有的实例列表，等待下一次变更
+				p.logger.Warn("failed to refresh picker instances", clog.Err(err))
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(pickerRetryInterval):
+		}
+		restarted, err := p.source.Watch(ctx, p.serviceName)
+		if err != nil {
+			// events 已关闭，下一轮等待间隔后再次重试
+			p.logger.Warn("failed to restart picker watch", clog.Err(err))
+			continue
+		}
+		events = restarted
+		if err := p.refresh(ctx); err != nil && ctx.Err() == nil {
+			p.logger.Warn("failed to refresh picker instances", clog.Err(err))
+		}
+	}
+}
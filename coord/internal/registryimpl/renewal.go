@@ -0,0 +1,166 @@
+package registryimpl
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/ceyewan/infra-kit/clog"
+	"github.com/ceyewan/infra-kit/coord/internal/client"
+	"github.com/ceyewan/infra-kit/coord/registry"
+	"go.etcd.io/etcd/api/v3/v3rpc/rpctypes"
+	clientv3 "go.etcd.io/etcd/client/v3"
+)
+
+// renewRetryMin 续约失败后第一次重试的等待时间，之后每次翻倍，不超过续约间隔
+var renewRetryMin = 100 * time.Millisecond
+
+// ValidateOptions 检查注册表选项：键布局满足 registry.KeyLayout 的约束，续约参数在允许范围内
+func ValidateOptions(options *registry.Options) error {
+	if err := ValidateKeyLayout(options.KeyLayout); err != nil {
+		return err
+	}
+	return validateRenewal(options)
+}
+
+// validateRenewal 检查续约比例和抖动
+func validateRenewal(options *registry.Options) error {
+	if options.RenewFraction <= 0 || options.RenewFraction > registry.MaxRenewFraction {
+		return client.NewError(client.ErrCodeValidation, "registry renew fraction must be in (0, 0.5]", nil)
+	}
+	if options.RenewJitter < 0 || options.RenewJitter >= 1 {
+		return client.NewError(client.ErrCodeValidation, "registry renew jitter must be in [0, 1)", nil)
+	}
+	return nil
+}
+
+// resolveRenewal 返回续约参数有效的选项，无效时记录错误并回退到默认值
+// 通过 coord.New 创建时已提前校验，这里的回退只针对直接构造注册表的调用方
+func resolveRenewal(options *registry.Options, logger clog.Logger) *registry.Options {
+	if err := validateRenewal(options); err != nil {
+		logger.Error("续约参数无效，使用默认值",
+			clog.Float64("renew_fraction", options.RenewFraction),
+			clog.Float64("renew_jitter", options.RenewJitter),
+			clog.Err(err))
+		options.RenewFraction = registry.DefaultRenewFraction
+		options.RenewJitter = registry.DefaultRenewJitter
+	}
+	return options
+}
+
+// leaseSession 自动续约的 etcd 租约，替代 concurrency.Session 以控制续约节奏
+// concurrency.Session 固定每 TTL/3 续约一次，大量实例同时注册时续约请求会同步到达 etcd；
+// 这里每次续约前按 registry.Options.RenewInterval 随机等待，续约失败时退避重试直到租约过期
+type leaseSession struct {
+	client  *clientv3.Client
+	lease   clientv3.LeaseID
+	ttl     time.Duration
+	options *registry.Options
+	logger  clog.Logger
+
+	cancel    context.CancelFunc
+	donec     chan struct{}
+	closeOnce sync.Once
+	closeErr  error
+}
+
+// newLeaseSession 创建 ttl 秒级租约并在后台续约
+func newLeaseSession(c *clientv3.Client, ttl time.Duration, options *registry.Options, logger clog.Logger) (*leaseSession, error) {
+	ttlSeconds := int64(ttl.Seconds())
+	if ttlSeconds < 1 {
+		ttlSeconds = 1
+	}
+	resp, err := c.Grant(c.Ctx(), ttlSeconds)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithCancel(c.Ctx())
+	s := &leaseSession{
+		client:  c,
+		lease:   resp.ID,
+		ttl:     time.Duration(resp.TTL) * time.Second,
+		options: options,
+		logger:  logger,
+		cancel:  cancel,
+		donec:   make(chan struct{}),
+	}
+	go s.keepAlive(ctx)
+	return s, nil
+}
+
+// Lease 返回会话的租约 ID
+func (s *leaseSession) Lease() clientv3.LeaseID {
+	return s.lease
+}
+
+// Done 返回会话结束（关闭或租约失效）时关闭的通道
+func (s *leaseSession) Done() <-chan struct{} {
+	return s.donec
+}
+
+// Close 停止续约并撤销租约，幂等
+func (s *leaseSession) Close() error {
+	s.closeOnce.Do(func() {
+		s.cancel()
+		<-s.donec
+
+		ctx, cancel := context.WithTimeout(s.client.Ctx(), s.ttl)
+		defer cancel()
+		if _, err := s.client.Revoke(ctx, s.lease); err != nil && !errors.Is(err, rpctypes.ErrLeaseNotFound) {
+			s.closeErr = err
+		}
+	})
+	return s.closeErr
+}
+
+// keepAlive 按续约间隔单次续约；失败时从 renewRetryMin 开始指数退避，
+// 下一次重试会晚于租约过期时间，或 etcd 返回租约不存在时结束会话
+func (s *leaseSession) keepAlive(ctx context.Context) {
+	defer close(s.donec)
+
+	expiry := time.Now().Add(s.ttl)
+	interval := s.options.RenewInterval(s.ttl)
+	timer := time.NewTimer(interval)
+	defer timer.Stop()
+
+	var backoff time.Duration
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-timer.C:
+		}
+
+		callCtx, cancel := context.WithDeadline(ctx, expiry)
+		resp, err := s.client.KeepAliveOnce(callCtx, s.lease)
+		cancel()
+
+		switch {
+		case err == nil:
+			expiry = time.Now().Add(time.Duration(resp.TTL) * time.Second)
+			backoff = 0
+			interval = s.options.RenewInterval(s.ttl)
+		case ctx.Err() != nil:
+			return
+		case errors.Is(err, rpctypes.ErrLeaseNotFound):
+			s.logger.Warn("租约已过期，停止续约", clog.Int64("lease_id", int64(s.lease)))
+			return
+		default:
+			backoff = min(max(backoff*2, renewRetryMin), s.options.RenewInterval(s.ttl))
+			if time.Now().Add(backoff).After(expiry) {
+				s.logger.Warn("续约持续失败，租约即将过期",
+					clog.Int64("lease_id", int64(s.lease)),
+					clog.Err(err))
+				return
+			}
+			s.logger.Debug("续约失败，稍后重试",
+				clog.Int64("lease_id", int64(s.lease)),
+				clog.Duration("retry_in", backoff),
+				clog.Err(err))
+			interval = backoff
+		}
+		timer.Reset(interval)
+	}
+}
@@ -11,16 +11,59 @@ var (
 	ErrLockExpired = errors.New("lock has expired")
 	// ErrLockNotHeld 锁未被持有
 	ErrLockNotHeld = errors.New("lock not held")
-	// ErrLockConflict 锁冲突
+	// ErrLockConflict 同一会话重复获取自己已持有的锁，由 Session.Acquire 和 Session.TryAcquire 返回
 	ErrLockConflict = errors.New("lock conflict")
+	// ErrNotAcquired 非阻塞获取时锁已被其他持有者占用，由 TryAcquire 和 Session.TryAcquire 返回
+	// 两者互不包含：锁被他人占用只匹配 ErrNotAcquired，会话重复持有只匹配 ErrLockConflict
+	ErrNotAcquired = errors.New("lock not acquired")
+	// ErrKeyCollision 锁键逃出锁前缀或与配置中心、服务注册的键空间重叠（严格模式下返回）
+	ErrKeyCollision = errors.New("lock key collides with reserved keys")
 )
 
 // DistributedLock 是分布式锁服务的接口
 type DistributedLock interface {
 	// Acquire 获取互斥锁，如果锁已被占用，会阻塞直到获取成功或 context 取消
-	Acquire(ctx context.Context, key string, ttl time.Duration) (Lock, error)
-	// TryAcquire 尝试获取锁（非阻塞），如果锁已被占用，会立即返回错误
-	TryAcquire(ctx context.Context, key string, ttl time.Duration) (Lock, error)
+	// 与 etcd 的连接或会话出错时按 WithJitter 设置的随机间隔重试，最多尝试 WithMaxAttempts 次后返回最后一次的错误
+	Acquire(ctx context.Context, key string, ttl time.Duration, opts ...Option) (Lock, error)
+	// TryAcquire 尝试获取锁（非阻塞），如果锁已被占用，会立即返回包装 ErrNotAcquired 的错误
+	TryAcquire(ctx context.Context, key string, ttl time.Duration, opts ...Option) (Lock, error)
+	// RunOnce 尝试获取锁（非阻塞），获取成功则执行 fn 并在返回后释放锁，适用于只需在一个节点上执行的定时任务
+	// 锁已被其他节点持有时返回 ran=false 和 nil 错误；fn 的错误原样返回
+	// 执行期间锁的会话失效时立即取消 fn 的 context，并返回包装 ErrLockExpired 的错误
+	RunOnce(ctx context.Context, key string, ttl time.Duration, fn func(ctx context.Context) error, opts ...Option) (ran bool, err error)
+	// Holder 返回当前持有指定锁的进程信息，锁未被持有时返回 ErrLockNotHeld（可用 errors.Is 判断）
+	Holder(ctx context.Context, key string) (Holder, error)
+	// Waiters 按排队顺序返回正在等待指定锁的进程信息，不包含当前持有者，没有等待者时返回空切片
+	// 用于观察争用时的队列深度和队首阻塞；结果是读取时刻的快照，等待者进程异常退出后其记录会保留到租约过期
+	Waiters(ctx context.Context, key string) ([]Holder, error)
+	// NewSession 创建租约为 ttl 的锁会话，通过会话获取的多把锁共享同一个租约
+	// 相比每把锁各用一个租约更省资源，且会话失效时其下所有锁同时失效
+	NewSession(ctx context.Context, ttl time.Duration) (Session, error)
+}
+
+// Session 共享租约的锁会话
+// 其下的锁可以单独 Unlock；TTL、Renew 作用于共享租约，会影响会话下的所有锁
+type Session interface {
+	// Acquire 在会话下获取互斥锁，阻塞直到获取成功、context 取消或会话失效
+	// 同一会话不能重复持有同一把锁，重复获取返回包装 ErrLockConflict 的错误
+	Acquire(ctx context.Context, key string, opts ...Option) (Lock, error)
+	// TryAcquire 在会话下尝试获取锁（非阻塞），锁已被占用时立即返回包装 ErrNotAcquired 的错误
+	TryAcquire(ctx context.Context, key string, opts ...Option) (Lock, error)
+	// Done 返回会话失效或关闭时关闭的通道，此后会话下的所有锁都已失效
+	Done() <-chan struct{}
+	// Close 关闭会话并撤销租约，释放其下所有锁；之后获取锁返回包装 ErrLockExpired 的错误
+	Close() error
+}
+
+// Holder 锁持有者信息，序列化为 JSON 存放在锁的值中
+// 用于排查 "谁持有了锁 X"：如 "主机 Y 上的 pod abc 在 T 时刻获取"
+type Holder struct {
+	// Metadata 持有者标识，由 WithHolder 设置，默认包含 hostname 和 pid
+	Metadata map[string]string `json:"metadata,omitempty"`
+	// WaitingSince 开始申请锁的时间
+	WaitingSince time.Time `json:"waitingSince"`
+	// AcquiredAt 获取到锁的时间，仍在等待时为零值
+	AcquiredAt time.Time `json:"acquiredAt"`
 }
 
 // Lock 是一个已获取的锁对象的接口
@@ -36,4 +79,12 @@ type Lock interface {
 	Renew(ctx context.Context) (bool, error)
 	// IsExpired 检查锁是否已过期
 	IsExpired(ctx context.Context) (bool, error)
+	// Deadline 返回锁最早可能过期的时间，获取锁和手动调用 Renew 成功时按租约的实际剩余时间计算
+	// 获取前会话可能已续约过一段时间，etcd 租约也以整秒计，因此它通常早于获取时间加 TTL；
+	// 会话在后台自动续约租约，但自动续约不会更新 Deadline：
+	// 与 etcd 失联后锁至少保持到 Deadline，调用方可据此在截止前中止任务，无需反复调用 TTL；
+	// 需要租约的实际剩余时间时使用 TTL
+	Deadline() time.Time
+	// Holder 返回本次获取写入锁中的持有者信息
+	Holder() Holder
 }
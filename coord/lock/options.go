@@ -0,0 +1,131 @@
+package lock
+
+import (
+	"math/rand/v2"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/ceyewan/infra-kit/clog"
+)
+
+const (
+	// DefaultJitterMin 默认的最小重试抖动
+	DefaultJitterMin = 10 * time.Millisecond
+	// DefaultJitterMax 默认的最大重试抖动
+	DefaultJitterMax = 100 * time.Millisecond
+	// DefaultMaxAttempts 阻塞获取因连接错误失败时的默认最大尝试次数
+	DefaultMaxAttempts = 10
+)
+
+// Options 定义获取锁的配置选项
+type Options struct {
+	// JitterMin 重试前随机等待的下限，仅对阻塞获取生效
+	JitterMin time.Duration
+	// JitterMax 重试前随机等待的上限，为 0 时立即重试，仅对阻塞获取生效
+	JitterMax time.Duration
+	// MaxAttempts 阻塞获取因连接错误失败时的最大尝试次数（含首次），用尽后返回最后一次的错误，仅对阻塞获取生效
+	MaxAttempts int
+	// SlowHoldThreshold 持有锁超过该时长仍未释放时记录警告，0 表示不检查
+	SlowHoldThreshold time.Duration
+	// DeadlockThreshold 阻塞获取等待超过该时长后检查是否可能死锁，0 表示不检查，仅对阻塞获取生效
+	DeadlockThreshold time.Duration
+	// Holder 写入锁值的持有者标识，为空时只记录 hostname 和 pid
+	Holder map[string]string
+	// Tracing 记录获取、等待、续约和释放的日志器，为 nil 时不记录
+	Tracing clog.Logger
+}
+
+// Option 配置获取锁的函数式选项
+type Option func(*Options)
+
+// WithJitter 设置阻塞获取因连接错误失败后、重试前的随机等待区间 [min, max]
+// etcd 故障恢复时，随机化的重试时间可以避免大量 worker 同时重建会话冲击 etcd。
+// 抖动不作用于锁被占用时的等待：等待者按排队键的创建修订号排队，每个等待者只监听前一个排队键的删除，
+// 锁释放时只唤醒下一个等待者，不会出现惊群
+func WithJitter(min, max time.Duration) Option {
+	return func(o *Options) {
+		o.JitterMin = min
+		o.JitterMax = max
+	}
+}
+
+// WithMaxAttempts 设置阻塞获取因连接错误失败时的最大尝试次数（含首次），默认为 DefaultMaxAttempts
+// 用尽后返回最后一次的错误，避免 etcd 长时间不可用时无限重试；n 小于 1 时 Acquire 返回参数错误
+func WithMaxAttempts(n int) Option {
+	return func(o *Options) {
+		o.MaxAttempts = n
+	}
+}
+
+// WithSlowHoldWarning 持有锁超过 threshold 仍未释放时记录一条警告日志（包含锁键和已持有时长）
+// 用于尽早发现耗时过长的临界区，避免业务执行时间超过锁 TTL；释放锁时取消检查
+func WithSlowHoldWarning(threshold time.Duration) Option {
+	return func(o *Options) {
+		o.SlowHoldThreshold = threshold
+	}
+}
+
+// WithDeadlockWarning 阻塞获取等待超过 threshold 后，每隔 threshold 检查一次当前持有者是否也在等待另一把锁，
+// 是则记录一条可能死锁的警告（包含两把锁的键和持有者），每次获取最多记录一次
+// 按 WithHolder 设置的持有者标识判断是否为同一持有者，同一进程内多个并发任务需要设置不同的标识，否则可能误报
+func WithDeadlockWarning(threshold time.Duration) Option {
+	return func(o *Options) {
+		o.DeadlockThreshold = threshold
+	}
+}
+
+// WithHolder 设置写入锁值的持有者标识，如 {"pod": "abc", "job": "billing"}
+// 未设置 hostname、pid 时自动补充，可通过 DistributedLock.Holder 查询当前持有者
+func WithHolder(metadata map[string]string) Option {
+	return func(o *Options) {
+		o.Holder = metadata
+	}
+}
+
+// WithTracing 以 debug 级别将本次获取的锁的获取（含等待时长）、续约和释放记录到 logger，
+// 每条记录带有调用时 context 中的 trace_id（clog.WithTraceID 注入），用于将锁争用关联到请求链路
+// 续约和释放使用 Renew、Unlock 传入的 context；默认不记录，logger 为 nil 时同样不记录
+func WithTracing(logger clog.Logger) Option {
+	return func(o *Options) {
+		o.Tracing = logger
+	}
+}
+
+// ParseOptions 应用选项并返回最终的获取配置
+func ParseOptions(opts ...Option) *Options {
+	result := &Options{
+		JitterMin:   DefaultJitterMin,
+		JitterMax:   DefaultJitterMax,
+		MaxAttempts: DefaultMaxAttempts,
+	}
+	for _, opt := range opts {
+		opt(result)
+	}
+	return result
+}
+
+// HolderMetadata 返回写入锁值的持有者标识，在 WithHolder 的基础上补充 hostname 和 pid
+func (o *Options) HolderMetadata() map[string]string {
+	metadata := make(map[string]string, len(o.Holder)+2)
+	for k, v := range o.Holder {
+		metadata[k] = v
+	}
+	if _, ok := metadata["hostname"]; !ok {
+		if hostname, err := os.Hostname(); err == nil {
+			metadata["hostname"] = hostname
+		}
+	}
+	if _, ok := metadata["pid"]; !ok {
+		metadata["pid"] = strconv.Itoa(os.Getpid())
+	}
+	return metadata
+}
+
+// Jitter 返回 [JitterMin, JitterMax] 区间内的随机等待时间
+func (o *Options) Jitter() time.Duration {
+	if o.JitterMax <= o.JitterMin {
+		return o.JitterMin
+	}
+	return o.JitterMin + rand.N(o.JitterMax-o.JitterMin+1)
+}
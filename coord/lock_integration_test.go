@@ -71,6 +71,8 @@ func TestLockInterfaceIntegration(t *testing.T) {
 		_, err = lockService.TryAcquire(ctx, lockKey, time.Second*3)
 		assert.Error(t, err)
 		assert.Contains(t, err.Error(), "already held")
+		assert.ErrorIs(t, err, lock.ErrNotAcquired)
+		assert.ErrorIs(t, err, ErrConflict)
 
 		// 释放第一个锁
 		err = lock1.Unlock(ctx)
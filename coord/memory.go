@@ -0,0 +1,194 @@
+package coord
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/ceyewan/infra-kit/clog"
+	"github.com/ceyewan/infra-kit/coord/allocator"
+	"github.com/ceyewan/infra-kit/coord/config"
+	"github.com/ceyewan/infra-kit/coord/election"
+	"github.com/ceyewan/infra-kit/coord/internal/allocatorimpl"
+	"github.com/ceyewan/infra-kit/coord/internal/client"
+	"github.com/ceyewan/infra-kit/coord/internal/configimpl"
+	"github.com/ceyewan/infra-kit/coord/internal/electionimpl"
+	"github.com/ceyewan/infra-kit/coord/internal/lockimpl"
+	"github.com/ceyewan/infra-kit/coord/internal/memstore"
+	"github.com/ceyewan/infra-kit/coord/internal/registryimpl"
+	"github.com/ceyewan/infra-kit/coord/lock"
+	"github.com/ceyewan/infra-kit/coord/registry"
+)
+
+// memoryCoordinator 基于进程内存储的协调器实现
+type memoryCoordinator struct {
+	store        *memstore.Store
+	lock         lock.DistributedLock
+	registry     registry.ServiceRegistry
+	config       config.ConfigCenter
+	managers     *ConfigManagerFactory
+	logger       clog.Logger
+	closed       bool
+	done         chan struct{} // Close 时关闭，通知 WatchCluster 的协程退出
+	mu           sync.RWMutex
+	allocators   map[string]allocator.InstanceIDAllocator // 缓存分配器实例
+	allocatorsMu sync.Mutex
+}
+
+// NewInMemory 创建一个不依赖 etcd 的 coord Provider，所有数据保存在进程内存中
+// 锁、注册、配置、分配器和选举的语义（TTL、CAS 版本号、监听事件）与 etcd 实现保持一致，
+// 适用于单元测试和本地开发，不适用于生产环境；不同 Provider 实例之间不共享数据
+func NewInMemory(ctx context.Context, opts ...Option) (Provider, error) {
+	options := &Options{}
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	var logger clog.Logger
+	if options.Logger != nil {
+		logger = options.Logger.With(clog.String("component", "coord"))
+	} else {
+		logger = clog.Namespace("coord")
+	}
+
+	if err := registryimpl.ValidateOptions(registry.ParseOptions(options.RegistryOptions...)); err != nil {
+		return nil, fmt.Errorf("invalid configuration: %w", err)
+	}
+	if err := validateConfigScope(options); err != nil {
+		return nil, fmt.Errorf("invalid configuration: %w", err)
+	}
+
+	store := memstore.New()
+	lockService := lockimpl.NewMemoryLockFactory(store, lockPrefix, logger.With(clog.String("component", "lock")))
+	lockService.ReserveKeyPrefixes(options.StrictLockKeys, reservedKeyPrefixes(options)...)
+	configService := configimpl.NewMemoryConfigCenter(store, configPrefix, logger.With(clog.String("component", "config")), options.ConfigOptions...)
+	c := &memoryCoordinator{
+		store:      store,
+		lock:       lockService,
+		registry:   registryimpl.NewMemoryServiceRegistry(store, registryPrefix, logger.With(clog.String("component", "registry")), options.RegistryOptions...),
+		config:     configService,
+		managers:   newConfigManagerFactory(configService, options),
+		logger:     logger,
+		done:       make(chan struct{}),
+		allocators: make(map[string]allocator.InstanceIDAllocator),
+	}
+
+	logger.Info("in-memory coordinator created")
+	return c, nil
+}
+
+// Lock 实现 Provider 接口 - 获取分布式锁服务
+func (c *memoryCoordinator) Lock() lock.DistributedLock {
+	return c.lock
+}
+
+// Registry 实现 Provider 接口 - 获取服务注册发现服务
+func (c *memoryCoordinator) Registry() registry.ServiceRegistry {
+	return c.registry
+}
+
+// Config 实现 Provider 接口 - 获取配置中心服务
+func (c *memoryCoordinator) Config() config.ConfigCenter {
+	return c.config
+}
+
+// ConfigReadOnly 实现 Provider 接口 - 获取配置中心的只读视图
+func (c *memoryCoordinator) ConfigReadOnly() config.ConfigCenter {
+	return config.ReadOnly(c.config)
+}
+
+// ConfigManagers 实现 Provider 接口 - 获取按 WithConfigScope 作用域创建配置管理器的工厂
+func (c *memoryCoordinator) ConfigManagers() *ConfigManagerFactory {
+	return c.managers
+}
+
+// InstanceIDAllocator 实现 Provider 接口 - 获取服务实例ID分配器
+func (c *memoryCoordinator) InstanceIDAllocator(serviceName string, maxID int, opts ...allocator.Option) (allocator.InstanceIDAllocator, error) {
+	if maxID <= 0 {
+		return nil, fmt.Errorf("failed to create instance ID allocator: max ID must be positive")
+	}
+	return c.InstanceIDAllocatorRange(serviceName, 1, maxID, opts...)
+}
+
+// InstanceIDAllocatorRange 实现 Provider 接口 - 获取在指定范围内分配 ID 的分配器
+func (c *memoryCoordinator) InstanceIDAllocatorRange(serviceName string, minID, maxID int, opts ...allocator.Option) (allocator.InstanceIDAllocator, error) {
+	c.allocatorsMu.Lock()
+	defer c.allocatorsMu.Unlock()
+
+	// 租约 TTL、到期余量或分区不同的分配器各自独立缓存
+	options := allocator.ParseOptions(opts...)
+	cacheKey := fmt.Sprintf("%s:%d-%d:%v:%v:%s", serviceName, minID, maxID, options.LeaseTTL, options.Margin(), options.Partition)
+	if allocator, exists := c.allocators[cacheKey]; exists {
+		return allocator, nil
+	}
+
+	allocator, err := allocatorimpl.NewMemoryInstanceIDAllocatorRange(c.store, serviceName, minID, maxID, c.logger, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create instance ID allocator: %w", err)
+	}
+	c.allocators[cacheKey] = allocator
+	return allocator, nil
+}
+
+// Election 实现 Provider 接口 - 创建 leader 选举候选者
+func (c *memoryCoordinator) Election(ctx context.Context, name string, opts ...election.Option) (election.Election, error) {
+	return electionimpl.NewMemoryElection(c.store, "/elections", name, election.ParseOptions(opts...),
+		c.logger.With(clog.String("component", "election")))
+}
+
+// Health 实现 Provider 接口 - 内存协调器未关闭即视为健康
+func (c *memoryCoordinator) Health(ctx context.Context) error {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if c.closed {
+		return fmt.Errorf("coordinator is closed")
+	}
+	return nil
+}
+
+// WatchCluster 实现 Provider 接口 - 进程内存储没有集群成员，返回的通道不产生事件，在 ctx 取消或协调器关闭后关闭
+func (c *memoryCoordinator) WatchCluster(ctx context.Context) (<-chan ClusterEvent, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if c.closed {
+		return nil, client.NewError(client.ErrCodeUnavailable, "coordinator is closed", nil)
+	}
+	eventCh := make(chan ClusterEvent)
+	go func() {
+		select {
+		case <-ctx.Done():
+		case <-c.done:
+		}
+		close(eventCh)
+	}()
+	return eventCh, nil
+}
+
+// Close 实现 Provider 接口 - 关闭所有分配器并清空存储
+func (c *memoryCoordinator) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.closed {
+		return nil
+	}
+
+	c.allocatorsMu.Lock()
+	for key, allocator := range c.allocators {
+		if closer, ok := allocator.(interface{ Close() error }); ok {
+			if err := closer.Close(); err != nil {
+				c.logger.Error("failed to close allocator", clog.String("key", key), clog.Err(err))
+			}
+		}
+		delete(c.allocators, key)
+	}
+	c.allocatorsMu.Unlock()
+
+	close(c.done)
+	c.store.Close()
+	c.closed = true
+	c.logger.Info("in-memory coordinator closed")
+	return nil
+}
@@ -1,11 +1,27 @@
 package coord
 
-import "github.com/ceyewan/infra-kit/clog"
+import (
+	"context"
+	"net"
+	"time"
+
+	"github.com/ceyewan/infra-kit/clog"
+	"github.com/ceyewan/infra-kit/coord/config"
+	"github.com/ceyewan/infra-kit/coord/registry"
+)
 
 // Options holds configuration for the coordinator.
 type Options struct {
-	Logger    clog.Logger
-	Namespace string
+	Logger               clog.Logger
+	Namespace            string
+	CredentialProvider   func() (username, password string)
+	ConfigOptions        []config.Option
+	RegistryOptions      []registry.Option
+	Dialer               func(ctx context.Context, addr string) (net.Conn, error)
+	StrictLockKeys       bool
+	ConfigEnv            string
+	ConfigService        string
+	ClusterWatchInterval time.Duration
 }
 
 // Option configures a coordinator.
@@ -25,6 +41,79 @@ func WithNamespace(namespace string) Option {
 	}
 }
 
+// WithCredentialProvider sets a dynamic credential source for etcd authentication.
+// It takes precedence over Config.Username/Password and is consulted again whenever
+// etcd rejects the current credentials: a new connection is dialed with the fresh
+// credentials and swapped in, and every call path (including locks, elections and watches)
+// is transparently retried on it.
+func WithCredentialProvider(provider func() (username, password string)) Option {
+	return func(o *Options) {
+		o.CredentialProvider = provider
+	}
+}
+
+// WithConfigOptions configures the config center, e.g. config.WithCodec(config.YAMLCodec)
+// to store values as YAML instead of the default JSON.
+func WithConfigOptions(opts ...config.Option) Option {
+	return func(o *Options) {
+		o.ConfigOptions = append(o.ConfigOptions, opts...)
+	}
+}
+
+// WithRegistryOptions configures the service registry, e.g. registry.WithKeyLayout to store
+// instances under "/{env}/services/{name}/{id}" instead of the default "/services/{name}/{id}".
+// registry.WithRenewal tunes how often registered instances renew their leases.
+// An invalid key layout or renewal setting makes New and NewInMemory fail.
+func WithRegistryOptions(opts ...registry.Option) Option {
+	return func(o *Options) {
+		o.RegistryOptions = append(o.RegistryOptions, opts...)
+	}
+}
+
+// WithDialer sets a custom dialer for etcd connections, e.g. to go through a SOCKS5 or
+// HTTP CONNECT proxy in restricted networks, or to inject network faults in tests.
+// addr is the host:port of the etcd endpoint being dialed. When Config.TLS is set, the
+// TLS handshake runs on top of the returned connection, so the dialer must return a raw
+// (not TLS-wrapped) stream to the endpoint, and certificates are verified against the
+// etcd endpoint (or TLS.ServerName), not the proxy.
+func WithDialer(dialer func(ctx context.Context, addr string) (net.Conn, error)) Option {
+	return func(o *Options) {
+		o.Dialer = dialer
+	}
+}
+
+// WithStrictLockKeys rejects lock keys that escape the lock prefix (e.g. "../config/app")
+// or overlap the config center or service registry key space, returning an error that wraps
+// lock.ErrKeyCollision. Without it such keys are still accepted and logged as a warning once
+// per key, since a lock's waiter and holder keys would overwrite or delete the other
+// component's data.
+func WithStrictLockKeys() Option {
+	return func(o *Options) {
+		o.StrictLockKeys = true
+	}
+}
+
+// WithConfigScope sets the env and service used by Provider.ConfigManagers, so every
+// config manager created through coord.NewConfigManager reads "/config/{env}/{service}/{component}"
+// with the same scoping instead of repeating env and service at each call site.
+// Setting only one of env and service makes New and NewInMemory fail.
+func WithConfigScope(env, service string) Option {
+	return func(o *Options) {
+		o.ConfigEnv = env
+		o.ConfigService = service
+	}
+}
+
+// WithClusterWatchInterval sets how often Provider.WatchCluster polls the etcd member list.
+// etcd has no watch API for membership, so changes are detected by comparing successive
+// member lists; a shorter interval reports changes sooner at the cost of one extra request
+// per interval. Non-positive values use DefaultClusterWatchInterval.
+func WithClusterWatchInterval(interval time.Duration) Option {
+	return func(o *Options) {
+		o.ClusterWatchInterval = interval
+	}
+}
+
 // DefaultOptions returns default options for coordinator.
 func DefaultOptions() *Options {
 	return &Options{
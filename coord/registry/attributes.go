@@ -0,0 +1,40 @@
+package registry
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// MaxAttributesBytes Attributes 编码后的最大字节数
+// 服务信息整体作为一个 etcd 值存储，etcd 默认单个请求上限为 1.5 MiB；
+// 实例数多时 Discover 和 Watch 会一次性传输全部实例，属性应保持在 KB 级别
+const MaxAttributesBytes = 64 * 1024
+
+// ErrAttributesTooLarge Attributes 超过 MaxAttributesBytes
+var ErrAttributesTooLarge = errors.New("service attributes too large")
+
+// SetAttributes 将 v 编码为 JSON 写入 Attributes，注册前由实例调用
+// 编码失败或超过 MaxAttributesBytes 时返回错误，Attributes 保持不变
+func (s *ServiceInfo) SetAttributes(v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("failed to encode service attributes: %w", err)
+	}
+	if len(data) > MaxAttributesBytes {
+		return fmt.Errorf("%w: %d bytes exceeds %d", ErrAttributesTooLarge, len(data), MaxAttributesBytes)
+	}
+	s.Attributes = data
+	return nil
+}
+
+// DecodeAttributes 将 Attributes 解码到 v，未设置 Attributes 时不修改 v 并返回 nil
+func (s ServiceInfo) DecodeAttributes(v interface{}) error {
+	if len(s.Attributes) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(s.Attributes, v); err != nil {
+		return fmt.Errorf("failed to decode service attributes: %w", err)
+	}
+	return nil
+}
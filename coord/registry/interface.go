@@ -2,11 +2,16 @@ package registry
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"time"
 
 	"google.golang.org/grpc"
 )
 
+// ErrNoInstances Picker.Next 时服务没有可用实例，返回的错误同时匹配 coord.ErrNotFound
+var ErrNoInstances = errors.New("no available service instances")
+
 // EventType 事件类型
 type EventType string
 
@@ -22,6 +27,9 @@ type ServiceInfo struct {
 	Address  string            `json:"address"`
 	Port     int               `json:"port"`
 	Metadata map[string]string `json:"metadata,omitempty"`
+	// Attributes 结构化的实例属性，必须是合法的 JSON，编码后不超过 MaxAttributesBytes
+	// 可通过 SetAttributes/DecodeAttributes 读写，Metadata 仍适合简单的字符串键值
+	Attributes json.RawMessage `json:"attributes,omitempty"`
 }
 
 // ServiceEvent 服务变化事件
@@ -30,16 +38,53 @@ type ServiceEvent struct {
 	Service ServiceInfo
 }
 
+// MaxBatchSize RegisterBatch 单次注册的最大实例数，与 etcd 默认的单个事务最大操作数（--max-txn-ops）一致
+const MaxBatchSize = 128
+
 // ServiceRegistry 服务注册发现接口
 type ServiceRegistry interface {
 	// Register 注册服务，ttl 是租约的有效期
 	Register(ctx context.Context, service ServiceInfo, ttl time.Duration) error
-	// Unregister 注销服务
-	Unregister(ctx context.Context, serviceID string) error
+	// RegisterWithCleanup 先删除同一 ID 的已有注册再重新注册
+	// 用于进程异常退出后重启的场景，避免旧条目在租约过期前与新条目同时被路由
+	RegisterWithCleanup(ctx context.Context, service ServiceInfo, ttl time.Duration) error
+	// RegisterWhenReady 在实例通过就绪检查后才注册服务，并在后台持续轮询 readyFn：
+	// 变为未就绪时自动注销，恢复就绪后重新注册，context 取消时注销并停止轮询
+	// 避免流量被路由到仍在预热中的实例
+	RegisterWhenReady(ctx context.Context, service ServiceInfo, ttl time.Duration, readyFn func() bool) error
+	// RegisterBatch 在一个事务中注册多个实例，要么全部注册成功，要么都不注册
+	// 整批实例共享一个租约：Unregister 其中一个实例只删除它的条目，最后一个实例注销时撤销租约；
+	// 实例 ID 不能重复，单批最多 MaxBatchSize 个，适合启动时一次性注册一组静态端点
+	RegisterBatch(ctx context.Context, services []ServiceInfo, ttl time.Duration) error
+	// Unregister 注销服务，实例不存在时返回 not found 错误，使用 WithIgnoreMissing 时视为成功
+	Unregister(ctx context.Context, serviceID string, opts ...UnregisterOption) error
 	// Discover 发现服务
 	Discover(ctx context.Context, serviceName string) ([]ServiceInfo, error)
+	// DiscoverSortedByScore 发现服务并按实例公布的健康评分（ServiceInfo.Score）从高到低排序
+	// 未公布评分的实例视为中性评分；适合非 gRPC 客户端优先选择最健康的实例，
+	// 评分只在实例更新注册时刷新，不能替代 WithLeastRequest 这类基于实时负载的均衡
+	DiscoverSortedByScore(ctx context.Context, serviceName string) ([]ServiceInfo, error)
+	// Count 返回指定服务当前注册的实例数，只读取计数，不获取和解码实例信息
+	// 结果是读取时刻的快照：实例崩溃后要等租约过期才会被移除，
+	// 且计数包含 Discover 会跳过的无法解码的条目，适合作为扩缩容等粗粒度信号
+	Count(ctx context.Context, serviceName string) (int, error)
 	// Watch 监听服务变化
 	Watch(ctx context.Context, serviceName string) (<-chan ServiceEvent, error)
+	// WatchAll 监听所有服务的变化，每个事件的 Service.Name 标明所属服务，用于构建实时的服务目录
+	// 事件量随实例总数增长，适用于数百个服务、数千个实例的规模；
+	// 只关心少数服务时应使用 Watch；只投递建立监听之后的变更，不包含已注册的实例
+	WatchAll(ctx context.Context) (<-chan ServiceEvent, error)
 	// GetConnection 获取到指定服务的 gRPC 连接，支持负载均衡
-	GetConnection(ctx context.Context, serviceName string) (*grpc.ClientConn, error)
+	// 默认使用 round_robin，可通过 WithLeastRequest 等选项切换策略
+	GetConnection(ctx context.Context, serviceName string, opts ...ConnectionOption) (*grpc.ClientConn, error)
+	// Picker 创建客户端负载均衡器，供 HTTP、TCP 等非 gRPC 客户端按策略选择实例
+	// 默认轮询，可通过 WithRandomPick 切换为随机；实例列表在创建时加载，之后随注册变化实时更新，
+	// ctx 取消后停止更新，Next 继续基于最后的实例列表选择；服务暂时没有实例时也能创建成功
+	Picker(ctx context.Context, serviceName string, opts ...PickerOption) (Picker, error)
+}
+
+// Picker 按负载均衡策略从服务的实例中选择一个，并发安全
+type Picker interface {
+	// Next 返回下一个实例，没有可用实例时返回包装 ErrNoInstances 的错误
+	Next() (ServiceInfo, error)
 }
@@ -0,0 +1,168 @@
+package registry
+
+import (
+	"math/rand/v2"
+	"time"
+)
+
+// 负载均衡策略名称
+const (
+	// LoadBalancerRoundRobin 轮询策略，默认策略
+	LoadBalancerRoundRobin = "round_robin"
+	// LoadBalancerLeastRequest 最少请求策略，基于每个实例的在途请求数选择负载最低的实例
+	LoadBalancerLeastRequest = "least_request"
+	// LoadBalancerRandom 随机策略，仅用于 Picker
+	LoadBalancerRandom = "random"
+)
+
+// ConnectionOptions 定义 GetConnection 的连接选项
+type ConnectionOptions struct {
+	// LoadBalancer 负载均衡策略，默认为 round_robin
+	LoadBalancer string
+}
+
+// ConnectionOption 配置 GetConnection 的函数式选项
+type ConnectionOption func(*ConnectionOptions)
+
+// WithLeastRequest 使用自适应的最少请求负载均衡
+// 根据 gRPC 连接上每个实例的在途请求数，将请求路由到负载最低的实例，
+// 适用于实例容量不均衡、关注尾延迟的场景；负载数据不可用时回退到 round_robin
+func WithLeastRequest() ConnectionOption {
+	return func(o *ConnectionOptions) {
+		o.LoadBalancer = LoadBalancerLeastRequest
+	}
+}
+
+// ParseConnectionOptions 应用选项并返回最终的连接配置
+func ParseConnectionOptions(opts ...ConnectionOption) *ConnectionOptions {
+	result := &ConnectionOptions{
+		LoadBalancer: LoadBalancerRoundRobin,
+	}
+	for _, opt := range opts {
+		opt(result)
+	}
+	return result
+}
+
+// PickerOptions 定义 Picker 的选项
+type PickerOptions struct {
+	// Policy 选择实例的策略，支持 round_robin（默认）和 random
+	Policy string
+}
+
+// PickerOption 配置 Picker 的函数式选项
+type PickerOption func(*PickerOptions)
+
+// WithRandomPick 每次随机选择一个实例，而不是按实例 ID 的顺序轮询
+// 多个客户端同时启动时轮询可能从同一个实例开始，随机选择在客户端较多时分布更均匀
+func WithRandomPick() PickerOption {
+	return func(o *PickerOptions) {
+		o.Policy = LoadBalancerRandom
+	}
+}
+
+// ParsePickerOptions 应用选项并返回最终的 Picker 配置
+func ParsePickerOptions(opts ...PickerOption) *PickerOptions {
+	result := &PickerOptions{
+		Policy: LoadBalancerRoundRobin,
+	}
+	for _, opt := range opts {
+		opt(result)
+	}
+	return result
+}
+
+// UnregisterOptions 定义 Unregister 的选项
+type UnregisterOptions struct {
+	// IgnoreMissing 实例不存在时视为注销成功，默认返回 not found 错误
+	IgnoreMissing bool
+}
+
+// UnregisterOption 配置 Unregister 的函数式选项
+type UnregisterOption func(*UnregisterOptions)
+
+// WithIgnoreMissing 注销不存在的实例时返回 nil 而不是 not found 错误
+// 适用于可能重复执行的优雅退出、清理逻辑：实例已被注销或租约已过期时不再产生多余的错误
+func WithIgnoreMissing() UnregisterOption {
+	return func(o *UnregisterOptions) {
+		o.IgnoreMissing = true
+	}
+}
+
+// ParseUnregisterOptions 应用选项并返回最终的注销配置
+func ParseUnregisterOptions(opts ...UnregisterOption) *UnregisterOptions {
+	result := &UnregisterOptions{}
+	for _, opt := range opts {
+		opt(result)
+	}
+	return result
+}
+
+// KeyLayout 根据服务信息生成实例在 etcd 中的完整键，如 "/prod/services/{name}/{id}"
+// 发现和监听时以空 ID 调用同一函数得到服务前缀，因此布局只能依赖 Name 和 ID，
+// 且必须满足：服务名是键中的一级路径，实例 ID 是最后一级路径（即键 = 服务前缀 + ID）
+type KeyLayout func(info ServiceInfo) string
+
+// 租约续约的默认参数
+const (
+	// DefaultRenewFraction 默认在租约经过 1/3 TTL 时续约，与 etcd 客户端自带的 keep-alive 一致
+	DefaultRenewFraction = 1.0 / 3
+	// DefaultRenewJitter 默认将续约间隔随机提前至多 20%
+	DefaultRenewJitter = 0.2
+	// MaxRenewFraction 续约比例的上限，保证续约前至少还剩一半 TTL 用于失败重试
+	MaxRenewFraction = 0.5
+)
+
+// Options 定义服务注册表的配置选项
+type Options struct {
+	// KeyLayout 实例键的布局，为空时使用默认布局 "/services/{name}/{id}"
+	KeyLayout KeyLayout
+	// RenewFraction 续约间隔占 TTL 的比例，取值 (0, MaxRenewFraction]
+	RenewFraction float64
+	// RenewJitter 续约间隔随机提前的最大比例，取值 [0, 1)，0 表示不抖动
+	RenewJitter float64
+}
+
+// Option 配置服务注册表的函数式选项
+type Option func(*Options)
+
+// WithKeyLayout 自定义实例键的布局，便于与期望特定 etcd 目录结构的已有工具互通
+// 注册、发现、监听和 gRPC resolver 使用同一布局；布局不满足 KeyLayout 的约束时创建 Provider 失败
+func WithKeyLayout(layout KeyLayout) Option {
+	return func(o *Options) {
+		o.KeyLayout = layout
+	}
+}
+
+// WithRenewal 设置租约续约的节奏：每次续约在上次续约后 TTL*fraction 时进行，
+// 并随机提前至多 jitter 比例，如 WithRenewal(0.4, 0.25) 对 30s 的 TTL 在 9s 到 12s 之间续约
+// 大量实例使用相同 TTL 时，固定间隔的续约会同时到达 etcd，抖动可以把续约请求打散
+// 抖动只会提前续约，因此续约前至少还剩 TTL*(1-fraction) 的余量，续约失败时在余量内退避重试，
+// 直到租约过期才放弃；fraction 超过 MaxRenewFraction 或 jitter 不在 [0, 1) 时创建 Provider 失败
+func WithRenewal(fraction, jitter float64) Option {
+	return func(o *Options) {
+		o.RenewFraction = fraction
+		o.RenewJitter = jitter
+	}
+}
+
+// ParseOptions 应用选项并返回最终的注册表配置
+func ParseOptions(opts ...Option) *Options {
+	result := &Options{
+		RenewFraction: DefaultRenewFraction,
+		RenewJitter:   DefaultRenewJitter,
+	}
+	for _, opt := range opts {
+		opt(result)
+	}
+	return result
+}
+
+// RenewInterval 返回 ttl 租约下一次续约前的等待时间，在 [TTL*fraction*(1-jitter), TTL*fraction] 区间内随机
+func (o *Options) RenewInterval(ttl time.Duration) time.Duration {
+	interval := float64(ttl) * o.RenewFraction
+	if o.RenewJitter > 0 {
+		interval -= interval * o.RenewJitter * rand.Float64()
+	}
+	return time.Duration(interval)
+}
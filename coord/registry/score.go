@@ -0,0 +1,49 @@
+package registry
+
+import (
+	"math"
+	"sort"
+	"strconv"
+)
+
+const (
+	// ScoreMetadataKey 实例在元数据中公布健康评分使用的键
+	ScoreMetadataKey = "score"
+	// NeutralScore 未公布或无法解析评分的实例视为中性评分
+	NeutralScore = 0.5
+)
+
+// Score 返回实例在元数据中公布的健康评分，取值范围 [0, 1]，越大表示越健康、负载越低
+// 未公布或无法解析时返回 NeutralScore，超出范围的值截断到 [0, 1]
+func (s ServiceInfo) Score() float64 {
+	raw, ok := s.Metadata[ScoreMetadataKey]
+	if !ok {
+		return NeutralScore
+	}
+	score, err := strconv.ParseFloat(raw, 64)
+	if err != nil || math.IsNaN(score) {
+		return NeutralScore
+	}
+	if score < 0 {
+		return 0
+	}
+	if score > 1 {
+		return 1
+	}
+	return score
+}
+
+// SetScore 将健康评分写入元数据，注册或更新注册时由实例调用
+func (s *ServiceInfo) SetScore(score float64) {
+	if s.Metadata == nil {
+		s.Metadata = make(map[string]string)
+	}
+	s.Metadata[ScoreMetadataKey] = strconv.FormatFloat(score, 'f', -1, 64)
+}
+
+// SortByScore 按评分从高到低排序，评分相同的实例保持原有顺序
+func SortByScore(services []ServiceInfo) {
+	sort.SliceStable(services, func(i, j int) bool {
+		return services[i].Score() > services[j].Score()
+	})
+}
@@ -0,0 +1,47 @@
+package uid
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ceyewan/infra-kit/uid/internal"
+)
+
+// BucketKeyLayout 分桶键的时间格式，表示桶的起始时间（UTC）
+const BucketKeyLayout = "20060102T150405Z"
+
+// defaultBucket 未指定有效桶大小时使用的默认值
+const defaultBucket = time.Hour
+
+// newBucketedID 生成带时间桶前缀的 ID，格式为 "<bucketKey>_<UUID v7>"
+// 桶按 UUID v7 内嵌的时间计算，保证前缀与 ID 本身的时间一致
+func newBucketedID(bucket time.Duration, uuidStr string) (id string, bucketKey string) {
+	if bucket <= 0 {
+		bucket = defaultBucket
+	}
+
+	generatedAt, err := internal.ExtractTimeFromUUIDV7(uuidStr)
+	if err != nil {
+		// 备选 UUID 不含时间信息时使用当前时间
+		generatedAt = time.Now()
+	}
+
+	bucketKey = generatedAt.UTC().Truncate(bucket).Format(BucketKeyLayout)
+	return bucketKey + "_" + uuidStr, bucketKey
+}
+
+// ParseBucketedID 解析 GenerateBucketedID 生成的 ID，返回分桶键和桶的起始时间
+// 只解析前缀，不需要 Provider，适合在路由层直接根据 ID 定位分区
+func ParseBucketedID(id string) (bucketKey string, bucketStart time.Time, err error) {
+	bucketKey, rest, ok := strings.Cut(id, "_")
+	if !ok || !internal.IsValidUUID(rest) {
+		return "", time.Time{}, fmt.Errorf("无效的分桶 ID: %s", id)
+	}
+
+	bucketStart, err = time.Parse(BucketKeyLayout, bucketKey)
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("无效的分桶键: %w", err)
+	}
+	return bucketKey, bucketStart, nil
+}
@@ -0,0 +1,78 @@
+package uid
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"time"
+)
+
+// CompositeIDLength 复合 ID 的固定长度
+const CompositeIDLength = 26
+
+// compositeAlphabet Crockford Base32 字母表，按 ASCII 升序排列，保证编码后的字符串可按字典序比较
+const compositeAlphabet = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+// compositeMaxMillis 48 位毫秒时间戳的上限，约到公元 10889 年
+const compositeMaxMillis = 1<<48 - 1
+
+// newCompositeID 生成复合 ID：48 位毫秒时间戳 + 16 位分片 + 64 位随机数，
+// 共 128 位，按大端序以 Crockford Base32 编码为 26 个字符
+// 时间戳位于最高位，字符串的字典序即生成时间的先后（毫秒精度，同一毫秒内的顺序由随机位决定）
+func newCompositeID(shard uint16, now time.Time) string {
+	return newCompositeIDFromReader(shard, now, rand.Reader)
+}
+
+// newCompositeIDFromReader 与 newCompositeID 相同，但从 r 读取随机位
+func newCompositeIDFromReader(shard uint16, now time.Time, r io.Reader) string {
+	millis := uint64(now.UnixMilli()) & compositeMaxMillis
+
+	var random [8]byte
+	if _, err := io.ReadFull(r, random[:]); err != nil {
+		// 随机数源不可用时退化为纳秒时间，仍保持时间戳和分片正确
+		binary.BigEndian.PutUint64(random[:], uint64(now.UnixNano()))
+	}
+
+	hi := millis<<16 | uint64(shard)
+	lo := binary.BigEndian.Uint64(random[:])
+
+	var buf [CompositeIDLength]byte
+	for i := CompositeIDLength - 1; i >= 0; i-- {
+		buf[i] = compositeAlphabet[lo&31]
+		lo = lo>>5 | hi<<59
+		hi >>= 5
+	}
+	return string(buf[:])
+}
+
+// ParseCompositeID 解析 GenerateComposite 生成的 ID，返回生成时间（毫秒精度）和分片
+// 只解码 ID 本身，不需要 Provider，适合在路由层直接根据 ID 定位分片
+func ParseCompositeID(id string) (generatedAt time.Time, shard uint16, err error) {
+	if len(id) != CompositeIDLength {
+		return time.Time{}, 0, fmt.Errorf("无效的复合 ID 长度: %d", len(id))
+	}
+
+	var hi, lo uint64
+	for i := 0; i < CompositeIDLength; i++ {
+		v := compositeCharValue(id[i])
+		// 26 个字符共 130 位，首字符只能承载最高的 2 位
+		if v < 0 || (i == 0 && v > 3) {
+			return time.Time{}, 0, fmt.Errorf("无效的复合 ID: %s", id)
+		}
+		hi = hi<<5 | lo>>59
+		lo = lo<<5 | uint64(v)
+	}
+
+	return time.UnixMilli(int64(hi >> 16)), uint16(hi), nil
+}
+
+// compositeCharValue 返回字符在字母表中的位置，不在字母表中时返回 -1
+func compositeCharValue(c byte) int {
+	for i := 0; i < len(compositeAlphabet); i++ {
+		if compositeAlphabet[i] == c {
+			return i
+		}
+	}
+	return -1
+}
@@ -11,8 +11,17 @@ type Config struct {
 	ServiceName   string `json:"serviceName"`   // 服务名称，用于日志和监控
 	MaxInstanceID int    `json:"maxInstanceID"` // 最大实例 ID，默认 1023
 	InstanceID    int    `json:"instanceId"`    // 实例 ID，可选（0 表示自动分配）
+	UUIDVersion   int    `json:"uuidVersion"`   // GetUUID 生成的 UUID 版本，4 或 7，默认 7（0 表示默认）
 }
 
+// 支持的 UUID 版本
+// v7 内嵌毫秒时间戳，按字典序即按生成时间排序，适合作为数据库主键，但会暴露生成时间；
+// v4 完全随机，不泄露任何时间信息，适合对外暴露且对隐私敏感的 ID，但不具备排序性
+const (
+	UUIDVersion4 = 4
+	UUIDVersion7 = 7
+)
+
 // GetDefaultConfig 返回环境相关的默认配置
 // 根据不同的运行环境提供优化的配置
 func GetDefaultConfig(env string) *Config {
@@ -20,6 +29,7 @@ func GetDefaultConfig(env string) *Config {
 		ServiceName:   getEnvWithDefault("SERVICE_NAME", "unknown-service"),
 		MaxInstanceID: getEnvIntWithDefault("MAX_INSTANCE_ID", 1023),
 		InstanceID:    getEnvIntWithDefault("INSTANCE_ID", 0), // 0 表示自动分配
+		UUIDVersion:   getEnvIntWithDefault("UUID_VERSION", UUIDVersion7),
 	}
 
 	// 根据环境调整默认值
@@ -50,9 +60,22 @@ func (c *Config) Validate() error {
 		return fmt.Errorf("最大实例 ID 必须在 1-1023 范围内")
 	}
 
+	// 验证 UUID 版本
+	if c.UUIDVersion != 0 && c.UUIDVersion != UUIDVersion4 && c.UUIDVersion != UUIDVersion7 {
+		return fmt.Errorf("UUID 版本必须是 %d 或 %d", UUIDVersion4, UUIDVersion7)
+	}
+
 	return nil
 }
 
+// uuidVersion 返回生效的 UUID 版本，未配置时为 v7
+func (c *Config) uuidVersion() int {
+	if c.UUIDVersion == 0 {
+		return UUIDVersion7
+	}
+	return c.UUIDVersion
+}
+
 // SetServiceName 设置服务名称
 // 提供便捷的配置方法
 func (c *Config) SetServiceName(name string) *Config {
@@ -74,6 +97,13 @@ func (c *Config) SetInstanceID(instanceID int) *Config {
 	return c
 }
 
+// SetUUIDVersion 设置 GetUUID 生成的 UUID 版本
+// 提供便捷的配置方法
+func (c *Config) SetUUIDVersion(version int) *Config {
+	c.UUIDVersion = version
+	return c
+}
+
 // 环境变量辅助函数
 func getEnvWithDefault(key, defaultValue string) string {
 	if value := os.Getenv(key); value != "" {
@@ -0,0 +1,62 @@
+package uid
+
+import (
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/ceyewan/infra-kit/uid/internal"
+)
+
+// deterministicStart 确定性 Provider 的时钟起点，即生成的第一个 ID 所带的时间
+var deterministicStart = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// NewDeterministic 创建确定性的 Provider，仅用于测试，例如生成可复现的测试数据（fixture）
+//
+// 随机位来自以 seed 为种子的 math/rand，时钟从 2024-01-01 00:00:00 UTC 开始、每次读取前进 1 毫秒，
+// 实例 ID 固定为 0。相同的 seed 按相同的调用顺序得到完全相同的 UUID、Snowflake、
+// 复合 ID 等序列，且时间有序的 ID 仍保持有序。
+//
+// 警告：生成的 ID 可预测，不具备密码学安全性，不要在生产环境使用。
+func NewDeterministic(seed int64) Provider {
+	config := &Config{
+		ServiceName:   "deterministic",
+		MaxInstanceID: internal.MaxInstanceID,
+		UUIDVersion:   UUIDVersion7,
+	}
+	source := &deterministicSource{
+		rand: rand.New(rand.NewSource(seed)),
+		now:  deterministicStart,
+	}
+
+	return &uidProvider{
+		config:    config,
+		snowflake: internal.NewSnowflakeGeneratorWithClock(0, source.Now),
+		random:    source,
+		now:       source.Now,
+	}
+}
+
+// deterministicSource 并发安全的伪随机数和时钟来源
+// math/rand.Rand 本身不是并发安全的，读取随机数和推进时钟都需要加锁
+type deterministicSource struct {
+	mu   sync.Mutex
+	rand *rand.Rand
+	now  time.Time
+}
+
+// Read 实现 io.Reader，从带种子的伪随机数生成器读取
+func (s *deterministicSource) Read(p []byte) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.rand.Read(p)
+}
+
+// Now 返回当前时间，并将时钟推进 1 毫秒
+func (s *deterministicSource) Now() time.Time {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := s.now
+	s.now = now.Add(time.Millisecond)
+	return now
+}
@@ -7,6 +7,7 @@ import (
 
 	"github.com/ceyewan/infra-kit/clog"
 	"github.com/ceyewan/infra-kit/uid"
+	"github.com/ceyewan/infra-kit/uid/uidtest"
 )
 
 func main() {
@@ -288,15 +289,11 @@ func snowflakeExample() {
 	}
 
 	// 验证排序性
-	for i := 1; i < len(ids); i++ {
-		if ids[i] <= ids[i-1] {
-			clog.Error("Snowflake ID 应该按时间排序",
-				clog.Int64("prev_id", ids[i-1]),
-				clog.Int64("current_id", ids[i]),
-			)
-		}
+	sortErr := uidtest.VerifySorted(ids)
+	if sortErr != nil {
+		clog.Error("Snowflake ID 应该按时间排序", clog.Err(sortErr))
 	}
-	clog.Info("排序性验证", clog.Bool("is_sorted", true))
+	clog.Info("排序性验证", clog.Bool("is_sorted", sortErr == nil))
 
 	// 演示高并发生成
 	fmt.Println("\n高并发生成演示:")
@@ -0,0 +1,82 @@
+package uid
+
+import (
+	"fmt"
+	"math/rand"
+	"os"
+	"strconv"
+)
+
+// 实例 ID 的来源，按优先级从高到低排列
+const (
+	InstanceIDSourceConfig    = "config"    // Config.InstanceID 显式指定
+	InstanceIDSourceAllocator = "allocator" // 分布式分配器分配，如 coord 的 InstanceIDAllocator
+	InstanceIDSourceEnv       = "env"       // 环境变量 INSTANCE_ID
+	InstanceIDSourceAuto      = "auto"      // 随机分配
+)
+
+// InstanceIDSource 提供已分配的实例 ID
+// coord 的 allocator.AllocatedID 满足该接口，可直接传入
+type InstanceIDSource interface {
+	ID() int
+}
+
+// ResolveInstanceID 按固定优先级确定实例 ID，并返回其来源，便于调用方记录日志
+//
+// 优先级：显式配置 > 分配器 > 环境变量 INSTANCE_ID > 随机分配
+//
+// 以下情况视为冲突并返回错误，而不是静默选择其中一个：
+//   - 同时显式配置了实例 ID 并提供了分配器
+//   - 环境变量与显式配置或分配器给出的实例 ID 不一致
+//
+// 环境变量无法解析或任一来源的实例 ID 超出 0-MaxInstanceID 范围时同样返回错误。
+// 通过 GetDefaultConfig 读取的 INSTANCE_ID 会与环境变量一致，不构成冲突。
+func ResolveInstanceID(cfg *Config, source InstanceIDSource) (int, string, error) {
+	if cfg == nil {
+		return 0, "", fmt.Errorf("配置不能为空")
+	}
+
+	envID, hasEnv, err := instanceIDFromEnv()
+	if err != nil {
+		return 0, "", err
+	}
+
+	var id int
+	var from string
+	switch {
+	case cfg.InstanceID > 0 && source != nil:
+		return 0, "", fmt.Errorf("实例 ID 冲突: 同时配置了实例 ID %d 和分配器", cfg.InstanceID)
+	case cfg.InstanceID > 0:
+		id, from = cfg.InstanceID, InstanceIDSourceConfig
+	case source != nil:
+		id, from = source.ID(), InstanceIDSourceAllocator
+	case hasEnv:
+		id, from = envID, InstanceIDSourceEnv
+	default:
+		return rand.Intn(cfg.MaxInstanceID + 1), InstanceIDSourceAuto, nil
+	}
+
+	if hasEnv && envID != id {
+		return 0, "", fmt.Errorf("实例 ID 冲突: 环境变量 INSTANCE_ID=%d 与 %s 提供的实例 ID %d 不一致", envID, from, id)
+	}
+	if id < 0 || id > cfg.MaxInstanceID {
+		return 0, "", fmt.Errorf("%s 提供的实例 ID %d 超出 0-%d 范围", from, id, cfg.MaxInstanceID)
+	}
+	return id, from, nil
+}
+
+// instanceIDFromEnv 读取环境变量 INSTANCE_ID，未设置或为 0 时视为未提供
+func instanceIDFromEnv() (int, bool, error) {
+	value := os.Getenv("INSTANCE_ID")
+	if value == "" {
+		return 0, false, nil
+	}
+	id, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, false, fmt.Errorf("环境变量 INSTANCE_ID 不是有效的整数: %q", value)
+	}
+	if id == 0 {
+		return 0, false, nil
+	}
+	return id, true, nil
+}
@@ -14,6 +14,7 @@ const (
 
 	MaxInstanceID = (1 << InstanceIDBits) - 1 // 最大实例 ID: 1023
 	MaxSequence   = (1 << SequenceBits) - 1   // 最大序列号: 4095
+	MaxTimestamp  = (1 << 41) - 1             // 最大相对时间戳（41 位），约 69 年
 
 	InstanceIDShift = SequenceBits                  // 实例 ID 左移位数
 	TimestampShift  = InstanceIDBits + SequenceBits // 时间戳左移位数
@@ -27,10 +28,17 @@ type SnowflakeGenerator struct {
 	sequence   int64
 	lastTime   int64
 	epoch      int64
+	now        func() time.Time
 }
 
 // NewSnowflakeGenerator 创建新的 Snowflake 生成器
 func NewSnowflakeGenerator(instanceID int64) *SnowflakeGenerator {
+	return NewSnowflakeGeneratorWithClock(instanceID, time.Now)
+}
+
+// NewSnowflakeGeneratorWithClock 创建使用指定时钟的 Snowflake 生成器
+// 时钟需要单调不减，否则 Generate 会按时钟回拨返回错误
+func NewSnowflakeGeneratorWithClock(instanceID int64, now func() time.Time) *SnowflakeGenerator {
 	if instanceID < 0 || instanceID > MaxInstanceID {
 		panic(fmt.Sprintf("实例 ID 必须在 0-%d 范围内", MaxInstanceID))
 	}
@@ -40,6 +48,7 @@ func NewSnowflakeGenerator(instanceID int64) *SnowflakeGenerator {
 		epoch:      SnowflakeEpoch,
 		lastTime:   0,
 		sequence:   0,
+		now:        now,
 	}
 }
 
@@ -50,7 +59,7 @@ func (g *SnowflakeGenerator) Generate() (int64, error) {
 	defer g.mu.Unlock()
 
 	// 获取当前时间戳（相对于 epoch）
-	currentTime := time.Now().UnixMilli() - g.epoch
+	currentTime := g.now().UnixMilli() - g.epoch
 
 	// 检测时钟回拨
 	if currentTime < g.lastTime {
@@ -63,7 +72,7 @@ func (g *SnowflakeGenerator) Generate() (int64, error) {
 		if g.sequence == 0 {
 			// 序列号溢出，等待下一毫秒
 			for currentTime <= g.lastTime {
-				currentTime = time.Now().UnixMilli() - g.epoch
+				currentTime = g.now().UnixMilli() - g.epoch
 			}
 		}
 	} else {
@@ -82,6 +91,24 @@ func (g *SnowflakeGenerator) Generate() (int64, error) {
 	return id, nil
 }
 
+// CheckClock 检查当前时钟能否用于生成 ID，不消耗序列号
+// 时钟早于纪元、时间戳超出 41 位或相对上次生成发生回拨时返回错误
+func (g *SnowflakeGenerator) CheckClock() error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	currentTime := g.now().UnixMilli() - g.epoch
+	switch {
+	case currentTime < 0:
+		return fmt.Errorf("系统时钟早于 Snowflake 纪元 %d", g.epoch)
+	case currentTime > MaxTimestamp:
+		return fmt.Errorf("时间戳 %d 超出 Snowflake 可表示的范围", currentTime)
+	case currentTime < g.lastTime:
+		return fmt.Errorf("时钟回拨检测：上次时间 %d，当前时间 %d", g.lastTime, currentTime)
+	}
+	return nil
+}
+
 // TODO: 未来考虑添加批量生成功能，但需要解决并发安全问题
 // GenerateBatch 批量生成 Snowflake ID
 // 适用于需要大量 ID 的场景，提高生成效率
@@ -2,6 +2,7 @@ package internal
 
 import (
 	"fmt"
+	"io"
 	"time"
 
 	"github.com/google/uuid"
@@ -20,6 +21,42 @@ func GenerateUUIDV7() string {
 	return u.String()
 }
 
+// GenerateUUIDV4 生成随机的 UUID v4，不包含任何时间信息
+func GenerateUUIDV4() string {
+	return uuid.New().String()
+}
+
+// GenerateUUIDV7FromReader 以 now 为时间戳、从 r 读取随机位生成 UUID v7
+// 不经过 Google UUID 库的全局单调状态，相同的 now 和随机字节得到相同的结果
+func GenerateUUIDV7FromReader(r io.Reader, now time.Time) (string, error) {
+	var u uuid.UUID
+	if _, err := io.ReadFull(r, u[:]); err != nil {
+		return "", fmt.Errorf("读取随机数失败: %w", err)
+	}
+
+	// 高 48 位为毫秒时间戳
+	millis := now.UnixMilli()
+	u[0] = byte(millis >> 40)
+	u[1] = byte(millis >> 32)
+	u[2] = byte(millis >> 24)
+	u[3] = byte(millis >> 16)
+	u[4] = byte(millis >> 8)
+	u[5] = byte(millis)
+
+	u[6] = u[6]&0x0f | 0x70 // 版本 7
+	u[8] = u[8]&0x3f | 0x80 // RFC 4122 变体
+	return u.String(), nil
+}
+
+// GenerateUUIDV4FromReader 从 r 读取随机位生成 UUID v4
+func GenerateUUIDV4FromReader(r io.Reader) (string, error) {
+	u, err := uuid.NewRandomFromReader(r)
+	if err != nil {
+		return "", fmt.Errorf("读取随机数失败: %w", err)
+	}
+	return u.String(), nil
+}
+
 // GenerateUUIDV7Batch 批量生成 UUID v7
 // 适用于需要大量 UUID 的场景
 func GenerateUUIDV7Batch(count int) []string {
@@ -36,14 +73,24 @@ func GenerateUUIDV7Batch(count int) []string {
 
 // IsValidUUID 验证字符串是否为有效的 UUID v7 格式
 func IsValidUUID(s string) bool {
+	return IsValidUUIDVersion(s, 7)
+}
+
+// IsValidUUIDVersion 验证字符串是否为指定版本的 UUID，version 为 0 时接受 v4 和 v7
+func IsValidUUIDVersion(s string, version int) bool {
 	// 使用 Google UUID 库验证基本格式
 	parsed, err := uuid.Parse(s)
 	if err != nil {
 		return false
 	}
 
-	// 验证版本号（UUID v7 的版本号必须是 7）
-	if parsed.Version() != 7 {
+	// 验证版本号
+	switch {
+	case version == 0:
+		if parsed.Version() != 4 && parsed.Version() != 7 {
+			return false
+		}
+	case int(parsed.Version()) != version:
 		return false
 	}
 
@@ -6,7 +6,8 @@ import (
 
 // Options 定义 uid 组件的配置选项
 type Options struct {
-	logger clog.Logger // 日志依赖
+	logger           clog.Logger      // 日志依赖
+	instanceIDSource InstanceIDSource // 实例 ID 分配器
 }
 
 // Option 定义配置选项的函数类型
@@ -21,6 +22,14 @@ func WithLogger(logger clog.Logger) Option {
 	}
 }
 
+// WithInstanceIDSource 使用分配器提供的实例 ID
+// 优先级和冲突规则见 ResolveInstanceID
+func WithInstanceIDSource(source InstanceIDSource) Option {
+	return func(opts *Options) {
+		opts.instanceIDSource = source
+	}
+}
+
 // parseOptions 解析选项参数并返回配置结构
 func parseOptions(opts []Option) *Options {
 	result := &Options{
@@ -0,0 +1,72 @@
+package uid
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+const (
+	// PrefixSeparator 前缀与 ID 主体之间的分隔符
+	PrefixSeparator = "_"
+
+	// MaxPrefixLength 前缀的最大长度
+	MaxPrefixLength = 16
+
+	// PrefixedBodyLength 带前缀 ID 主体的固定长度，11 位 Base62 足以表示任意非负 int64
+	PrefixedBodyLength = 11
+)
+
+// base62Alphabet Base62 字母表，按 ASCII 升序排列，定长编码后字典序与数值大小一致
+const base62Alphabet = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+
+// validatePrefix 校验前缀：1 到 MaxPrefixLength 个字符，只能包含小写字母和数字，且以字母开头
+// 不允许分隔符出现在前缀中，保证 ParsePrefixed 能无歧义地拆分
+func validatePrefix(prefix string) error {
+	if prefix == "" || len(prefix) > MaxPrefixLength {
+		return fmt.Errorf("前缀长度必须在 1 到 %d 之间: %q", MaxPrefixLength, prefix)
+	}
+	for i := 0; i < len(prefix); i++ {
+		c := prefix[i]
+		if (c < 'a' || c > 'z') && (i == 0 || c < '0' || c > '9') {
+			return fmt.Errorf("前缀只能包含小写字母和数字，且以字母开头: %q", prefix)
+		}
+	}
+	return nil
+}
+
+// newPrefixedID 将 Snowflake ID 编码为 "<prefix>_<11 位 Base62>"
+func newPrefixedID(prefix string, id int64) string {
+	var buf [PrefixedBodyLength]byte
+	v := uint64(id)
+	for i := PrefixedBodyLength - 1; i >= 0; i-- {
+		buf[i] = base62Alphabet[v%62]
+		v /= 62
+	}
+	return prefix + PrefixSeparator + string(buf[:])
+}
+
+// ParsePrefixed 拆分 GeneratePrefixed 生成的 ID，返回前缀和主体对应的 Snowflake ID
+// 只解码 ID 本身，不需要 Provider；Snowflake ID 可继续交给 ParseSnowflake 还原时间戳和实例 ID
+func ParsePrefixed(id string) (prefix string, snowflakeID int64, err error) {
+	prefix, body, ok := strings.Cut(id, PrefixSeparator)
+	if !ok {
+		return "", 0, fmt.Errorf("无效的带前缀 ID，缺少分隔符: %s", id)
+	}
+	if err := validatePrefix(prefix); err != nil {
+		return "", 0, fmt.Errorf("无效的带前缀 ID: %w", err)
+	}
+	if len(body) != PrefixedBodyLength {
+		return "", 0, fmt.Errorf("无效的带前缀 ID 主体长度: %d", len(body))
+	}
+
+	var v int64
+	for i := 0; i < len(body); i++ {
+		d := int64(strings.IndexByte(base62Alphabet, body[i]))
+		if d < 0 || v > (math.MaxInt64-d)/62 {
+			return "", 0, fmt.Errorf("无效的带前缀 ID: %s", id)
+		}
+		v = v*62 + d
+	}
+	return prefix, v, nil
+}
@@ -0,0 +1,94 @@
+package uid
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"sync"
+)
+
+// 内置生成器名称
+const (
+	GeneratorUUIDV7    = "uuidv7"
+	GeneratorSnowflake = "snowflake"
+)
+
+// IDGenerator 定义可插拔的 ID 生成器
+// 生成器接收当前 Provider，可复用其 Snowflake、UUID v7 等能力组合出自定义格式，
+// 例如带业务前缀的 ID（usr_xxx）
+type IDGenerator interface {
+	Generate(p Provider) (string, error)
+}
+
+// GeneratorFunc 将普通函数适配为 IDGenerator
+type GeneratorFunc func(p Provider) (string, error)
+
+// Generate 实现 IDGenerator 接口
+func (f GeneratorFunc) Generate(p Provider) (string, error) {
+	return f(p)
+}
+
+var (
+	registryMu sync.RWMutex
+	generators = map[string]IDGenerator{
+		GeneratorUUIDV7: GeneratorFunc(func(p Provider) (string, error) {
+			return p.GetUUIDV7(), nil
+		}),
+		GeneratorSnowflake: GeneratorFunc(func(p Provider) (string, error) {
+			id, err := p.GenerateSnowflake()
+			if err != nil {
+				return "", err
+			}
+			return strconv.FormatInt(id, 10), nil
+		}),
+	}
+)
+
+// Register 注册自定义 ID 生成器，注册后可通过 Provider.Generate(name) 使用
+// 名称为空、生成器为 nil 或名称已被注册时返回错误
+func Register(name string, gen IDGenerator) error {
+	if name == "" {
+		return fmt.Errorf("生成器名称不能为空")
+	}
+	if gen == nil {
+		return fmt.Errorf("生成器 %q 不能为 nil", name)
+	}
+
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	if _, exists := generators[name]; exists {
+		return fmt.Errorf("生成器 %q 已注册", name)
+	}
+	generators[name] = gen
+	return nil
+}
+
+// unregister 移除已注册的生成器，仅供测试清理使用
+func unregister(name string) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	delete(generators, name)
+}
+
+// Generators 返回所有已注册的生成器名称（按字典序排列）
+func Generators() []string {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	names := make([]string, 0, len(generators))
+	for name := range generators {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// lookupGenerator 按名称查找已注册的生成器
+func lookupGenerator(name string) (IDGenerator, bool) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	gen, ok := generators[name]
+	return gen, ok
+}
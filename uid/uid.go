@@ -3,8 +3,10 @@ package uid
 import (
 	"context"
 	"fmt"
-	"math/rand"
+	"io"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/ceyewan/infra-kit/clog"
 	"github.com/ceyewan/infra-kit/uid/internal"
@@ -13,7 +15,11 @@ import (
 // Provider 定义唯一 ID 生成组件的主接口
 // 提供 Snowflake 和 UUID v7 两种 ID 生成方案
 type Provider interface {
-	// GetUUIDV7 生成 UUID v7 格式的唯一标识符
+	// GetUUID 按 Config.UUIDVersion 生成 UUID，默认为 v7
+	// v7 可按时间排序但暴露生成时间，v4 完全随机，适合对隐私敏感的场景
+	GetUUID() string
+
+	// GetUUIDV7 生成 UUID v7 格式的唯一标识符，不受 Config.UUIDVersion 影响
 	// 适用于需要全局唯一性和可读性的场景，如请求 ID、会话 ID
 	GetUUIDV7() string
 
@@ -21,12 +27,45 @@ type Provider interface {
 	// 适用于需要排序和高性能的场景，如数据库主键、消息 ID
 	GenerateSnowflake() (int64, error)
 
-	// IsValidUUID 验证字符串是否为有效的 UUID 格式
+	// IsValidUUID 验证字符串是否为 Config.UUIDVersion 指定版本（默认 v7）的有效 UUID
 	IsValidUUID(s string) bool
 
 	// ParseSnowflake 解析 Snowflake ID，返回时间戳、实例ID和序列号
 	ParseSnowflake(id int64) (timestamp, instanceID, sequence int64)
 
+	// NextSequence 返回进程内单调递增的序列号
+	// 基于原子计数器实现，不依赖 etcd 和时钟，适用于内存去重键、请求内排序等场景
+	// 注意：序列号仅在当前进程内唯一，不具备全局唯一性，进程重启后从头计数
+	NextSequence() uint64
+
+	// Generate 使用通过 Register 注册的生成器生成 ID
+	// 内置 "uuidv7" 和 "snowflake" 两种生成器，未注册的名称返回错误
+	Generate(name string) (string, error)
+
+	// GenerateBucketedID 生成带时间桶前缀的 ID，同时返回分桶键，用于按时间分片路由
+	// ID 格式为 "<bucketKey>_<UUID v7>"，bucketKey 为桶起始时间（UTC，格式见 BucketKeyLayout），
+	// bucket 小于等于 0 时按小时分桶；可通过 ParseBucketedID 从 ID 中还原分桶键
+	// 唯一性由 UUID v7 保证，同一桶内同一毫秒生成的 ID 依靠 UUID v7 的随机位区分，碰撞概率可忽略
+	GenerateBucketedID(bucket time.Duration) (id string, bucketKey string)
+
+	// GenerateComposite 生成可按字典序排序的复合 ID，由毫秒时间戳、分片和 64 位随机数组成
+	// 固定 26 个字符（Crockford Base32），字典序与生成时间一致（毫秒精度）；
+	// 唯一性依赖随机位，不需要实例 ID 或任何协调，介于 Snowflake（需要实例 ID）和 UUID（不含分片）之间
+	// 可通过 ParseCompositeID 从 ID 中还原生成时间和分片
+	GenerateComposite(shard uint16) string
+
+	// GeneratePrefixed 生成带类型前缀的 ID，如 "ord_0ABcd3fGh1J"，使 ID 在日志和 URL 中自描述
+	// 格式为 "<prefix>_<11 位 Base62 编码的 Snowflake ID>"，总长度为 len(prefix)+12；
+	// 前缀只能包含小写字母和数字、以字母开头且不超过 MaxPrefixLength 个字符，否则返回错误
+	// 唯一性与 Snowflake 相同（依赖实例 ID 唯一），同一前缀下字典序与生成顺序一致；
+	// 时钟回拨时与 GenerateSnowflake 一样返回错误。可通过 ParsePrefixed 拆分前缀和主体
+	GeneratePrefixed(prefix string) (string, error)
+
+	// WarmUp 提前完成生成 ID 的准备工作，返回发现的配置或时钟问题，适合在就绪检查中调用
+	// 实例 ID 在 New 中已经确定，这里校验其范围和系统时钟，并初始化 UUID 的随机数源；
+	// 返回 nil 后首次 GenerateSnowflake 不会因配置问题失败，但运行中的时钟回拨仍会返回错误
+	WarmUp(ctx context.Context) error
+
 	// Close 释放资源
 	Close() error
 }
@@ -37,7 +76,13 @@ type uidProvider struct {
 	logger     clog.Logger
 	snowflake  *internal.SnowflakeGenerator
 	instanceID int64
+	sequence   atomic.Uint64 // 进程内单调序列号计数器
 	closeOnce  sync.Once
+
+	// random 和 now 为 ID 的随机位和时间戳来源，默认为 crypto/rand 和系统时钟
+	// NewDeterministic 替换为带种子的伪随机数和固定步进的时钟
+	random io.Reader
+	now    func() time.Time
 }
 
 // TODO: 待 coord 组件实现后，添加分布式实例 ID 管理
@@ -60,35 +105,68 @@ func New(ctx context.Context, config *Config, opts ...Option) (Provider, error)
 	provider := &uidProvider{
 		config: config,
 		logger: options.logger,
+		now:    time.Now,
 	}
 
-	// 确定实例 ID
-	if config.InstanceID > 0 {
-		// 使用配置的实例 ID
-		provider.instanceID = int64(config.InstanceID)
-	} else {
-		// 自动分配随机实例 ID
-		provider.instanceID = rand.Int63n(int64(config.MaxInstanceID + 1))
+	// 确定实例 ID：显式配置 > 分配器 > 环境变量 > 随机分配
+	instanceID, source, err := ResolveInstanceID(config, options.instanceIDSource)
+	if err != nil {
+		return nil, err
 	}
+	provider.instanceID = int64(instanceID)
 
 	// 初始化 Snowflake 生成器
-	provider.snowflake = internal.NewSnowflakeGenerator(provider.instanceID)
+	provider.snowflake = internal.NewSnowflakeGeneratorWithClock(provider.instanceID, provider.now)
 
 	// 记录初始化信息
 	if provider.logger != nil {
 		provider.logger.Info("uid 组件初始化成功",
 			clog.String("service_name", config.ServiceName),
 			clog.Int64("instance_id", provider.instanceID),
+			clog.String("instance_id_source", source),
 			clog.Int("max_instance_id", config.MaxInstanceID),
+			clog.Int("uuid_version", config.uuidVersion()),
 		)
 	}
 
 	return provider, nil
 }
 
+// GetUUID 按配置的版本生成 UUID
+func (p *uidProvider) GetUUID() string {
+	if p.config.uuidVersion() == UUIDVersion4 {
+		return p.newUUIDV4()
+	}
+	return p.newUUIDV7()
+}
+
 // GetUUIDV7 生成 UUID v7 格式的唯一标识符
 func (p *uidProvider) GetUUIDV7() string {
-	return internal.GenerateUUIDV7()
+	return p.newUUIDV7()
+}
+
+// newUUIDV7 未替换随机数源时使用 Google UUID 库，保留其同一毫秒内的单调性
+func (p *uidProvider) newUUIDV7() string {
+	if p.random == nil {
+		return internal.GenerateUUIDV7()
+	}
+	u, err := internal.GenerateUUIDV7FromReader(p.random, p.now())
+	if err != nil {
+		return internal.GenerateUUIDV7()
+	}
+	return u
+}
+
+// newUUIDV4 未替换随机数源时使用 Google UUID 库
+func (p *uidProvider) newUUIDV4() string {
+	if p.random == nil {
+		return internal.GenerateUUIDV4()
+	}
+	u, err := internal.GenerateUUIDV4FromReader(p.random)
+	if err != nil {
+		return internal.GenerateUUIDV4()
+	}
+	return u
 }
 
 // GenerateSnowflake 生成 Snowflake ID
@@ -98,7 +176,7 @@ func (p *uidProvider) GenerateSnowflake() (int64, error) {
 
 // IsValidUUID 验证 UUID 格式
 func (p *uidProvider) IsValidUUID(s string) bool {
-	return internal.IsValidUUID(s)
+	return internal.IsValidUUIDVersion(s, p.config.uuidVersion())
 }
 
 // ParseSnowflake 解析 Snowflake ID
@@ -106,6 +184,69 @@ func (p *uidProvider) ParseSnowflake(id int64) (timestamp, instanceID, sequence
 	return p.snowflake.Parse(id)
 }
 
+// NextSequence 返回进程内单调递增的序列号
+func (p *uidProvider) NextSequence() uint64 {
+	return p.sequence.Add(1)
+}
+
+// Generate 使用指定名称的生成器生成 ID
+func (p *uidProvider) Generate(name string) (string, error) {
+	gen, ok := lookupGenerator(name)
+	if !ok {
+		return "", fmt.Errorf("未注册的 ID 生成器: %s", name)
+	}
+	return gen.Generate(p)
+}
+
+// GenerateBucketedID 生成带时间桶前缀的 ID
+func (p *uidProvider) GenerateBucketedID(bucket time.Duration) (id string, bucketKey string) {
+	return newBucketedID(bucket, p.newUUIDV7())
+}
+
+// GenerateComposite 生成由时间戳、分片和随机数组成的复合 ID
+func (p *uidProvider) GenerateComposite(shard uint16) string {
+	if p.random == nil {
+		return newCompositeID(shard, p.now())
+	}
+	return newCompositeIDFromReader(shard, p.now(), p.random)
+}
+
+// GeneratePrefixed 生成带类型前缀的 ID
+func (p *uidProvider) GeneratePrefixed(prefix string) (string, error) {
+	if err := validatePrefix(prefix); err != nil {
+		return "", err
+	}
+	id, err := p.snowflake.Generate()
+	if err != nil {
+		return "", err
+	}
+	return newPrefixedID(prefix, id), nil
+}
+
+// WarmUp 校验实例 ID 和系统时钟，并预热 UUID 生成
+func (p *uidProvider) WarmUp(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if p.instanceID < 0 || p.instanceID > int64(p.config.MaxInstanceID) {
+		return fmt.Errorf("实例 ID %d 超出 0-%d 范围", p.instanceID, p.config.MaxInstanceID)
+	}
+	if err := p.snowflake.CheckClock(); err != nil {
+		return fmt.Errorf("时钟检查失败: %w", err)
+	}
+
+	// 首次生成 UUID 时才会初始化随机数源，提前生成一次
+	_ = p.GetUUID()
+
+	if p.logger != nil {
+		p.logger.Debug("uid 组件预热完成",
+			clog.String("service_name", p.config.ServiceName),
+			clog.Int64("instance_id", p.instanceID),
+		)
+	}
+	return nil
+}
+
 // Close 释放资源
 func (p *uidProvider) Close() error {
 	p.closeOnce.Do(func() {
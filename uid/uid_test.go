@@ -2,14 +2,17 @@ package uid
 
 import (
 	"context"
+	"math"
 	"math/rand"
-	"os"
+	"strconv"
+	"strings"
 	"sync"
 	"testing"
 	"time"
 
 	"github.com/ceyewan/infra-kit/clog"
 	"github.com/ceyewan/infra-kit/uid/internal"
+	"github.com/ceyewan/infra-kit/uid/uidtest"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -199,6 +202,21 @@ func TestConcurrentSnowflakeGeneration(t *testing.T) {
 	}
 }
 
+// TestProviderConcurrentSnowflake 测试通过 Provider 并发生成的 ID 唯一，且每个协程内严格递增
+func TestProviderConcurrentSnowflake(t *testing.T) {
+	provider, err := New(context.Background(), &Config{ServiceName: "test-service", MaxInstanceID: 10, InstanceID: 1})
+	assert.NoError(t, err)
+	defer provider.Close()
+
+	const workers, perWorker = 8, 1000
+	ids, err := uidtest.GenerateConcurrently(workers, perWorker, provider.GenerateSnowflake)
+	assert.NoError(t, err)
+	assert.NoError(t, uidtest.VerifyUnique(ids))
+	for w := 0; w < workers; w++ {
+		assert.NoError(t, uidtest.VerifySorted(ids[w*perWorker:(w+1)*perWorker]))
+	}
+}
+
 // TestConcurrentUUIDV7Generation 测试并发 UUID v7 生成
 func TestConcurrentUUIDV7Generation(t *testing.T) {
 	var wg sync.WaitGroup
@@ -324,23 +342,52 @@ func TestUUIDV7Validation(t *testing.T) {
 	}
 }
 
+// TestUUIDVersion 测试按配置生成 UUID v4 或 v7
+func TestUUIDVersion(t *testing.T) {
+	ctx := context.Background()
+
+	// 默认生成 v7
+	provider, err := New(ctx, &Config{ServiceName: "test-service", MaxInstanceID: 10, InstanceID: 1})
+	assert.NoError(t, err)
+	defer provider.Close()
+	id := provider.GetUUID()
+	assert.Equal(t, byte('7'), id[14])
+	assert.True(t, provider.IsValidUUID(id))
+
+	// 配置为 v4 时 GetUUID 生成随机 UUID，GetUUIDV7 仍生成 v7
+	v4Provider, err := New(ctx, (&Config{ServiceName: "test-service", MaxInstanceID: 10, InstanceID: 1}).SetUUIDVersion(UUIDVersion4))
+	assert.NoError(t, err)
+	defer v4Provider.Close()
+	id = v4Provider.GetUUID()
+	assert.Equal(t, byte('4'), id[14])
+	assert.True(t, v4Provider.IsValidUUID(id))
+	assert.False(t, v4Provider.IsValidUUID(v4Provider.GetUUIDV7()))
+	assert.False(t, provider.IsValidUUID(id))
+	assert.Equal(t, byte('7'), v4Provider.GetUUIDV7()[14])
+
+	// 版本为 0 时接受任一受支持的版本
+	assert.True(t, internal.IsValidUUIDVersion(id, 0))
+	assert.True(t, internal.IsValidUUIDVersion(internal.GenerateUUIDV7(), 0))
+	assert.False(t, internal.IsValidUUIDVersion("0189d1b0-6a7e-6b3e-8c4d-123456789012", 0))
+
+	// 不支持的版本
+	_, err = New(ctx, &Config{ServiceName: "test-service", MaxInstanceID: 10, UUIDVersion: 5})
+	assert.ErrorContains(t, err, "UUID 版本必须是 4 或 7")
+}
+
 // TestConfigEnvVars 测试环境变量配置
 func TestConfigEnvVars(t *testing.T) {
-	// 设置环境变量
-	oldServiceName := setEnv("SERVICE_NAME", "test-service-from-env")
-	oldMaxInstanceID := setEnv("MAX_INSTANCE_ID", "100")
-	oldInstanceID := setEnv("INSTANCE_ID", "5")
-	defer func() {
-		// 恢复环境变量
-		setEnv("SERVICE_NAME", oldServiceName)
-		setEnv("MAX_INSTANCE_ID", oldMaxInstanceID)
-		setEnv("INSTANCE_ID", oldInstanceID)
-	}()
+	// 设置环境变量，测试结束后自动恢复
+	t.Setenv("SERVICE_NAME", "test-service-from-env")
+	t.Setenv("MAX_INSTANCE_ID", "100")
+	t.Setenv("INSTANCE_ID", "5")
+	t.Setenv("UUID_VERSION", "4")
 
 	config := GetDefaultConfig("production")
 	assert.Equal(t, "test-service-from-env", config.ServiceName)
 	assert.Equal(t, 100, config.MaxInstanceID)
 	assert.Equal(t, 5, config.InstanceID)
+	assert.Equal(t, UUIDVersion4, config.UUIDVersion)
 }
 
 // TestBenchmark 性能基准测试
@@ -415,20 +462,388 @@ func TestProviderWithLogger(t *testing.T) {
 	assert.Greater(t, snowflakeID, int64(0))
 }
 
-// 辅助函数：设置环境变量
-func setEnv(key, value string) string {
-	oldValue := ""
-	if value != "" {
-		oldValue = setEnvRestore(key, value)
+// TestNextSequence 测试进程内单调序列号
+func TestNextSequence(t *testing.T) {
+	ctx := context.Background()
+	config := &Config{
+		ServiceName:   "test-sequence-service",
+		MaxInstanceID: 10,
+		InstanceID:    1,
+	}
+
+	provider, err := New(ctx, config)
+	assert.NoError(t, err)
+	defer provider.Close()
+
+	// 单调递增
+	first := provider.NextSequence()
+	second := provider.NextSequence()
+	assert.Equal(t, first+1, second)
+
+	// 并发生成不重复
+	var wg sync.WaitGroup
+	seqs := make(chan uint64, 10000)
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 1000; j++ {
+				seqs <- provider.NextSequence()
+			}
+		}()
+	}
+	wg.Wait()
+	close(seqs)
+
+	seqSet := make(map[uint64]bool)
+	for seq := range seqs {
+		assert.False(t, seqSet[seq], "序列号重复: %d", seq)
+		seqSet[seq] = true
+	}
+}
+
+// TestWarmUp 测试预热时的实例 ID 和时钟校验
+func TestWarmUp(t *testing.T) {
+	ctx := context.Background()
+	config := &Config{
+		ServiceName:   "test-warmup-service",
+		MaxInstanceID: 10,
+		InstanceID:    5,
+	}
+
+	provider, err := New(ctx, config)
+	assert.NoError(t, err)
+	defer provider.Close()
+
+	assert.NoError(t, provider.WarmUp(ctx))
+	_, err = provider.GenerateSnowflake()
+	assert.NoError(t, err)
+	assert.NoError(t, provider.WarmUp(ctx), "生成 ID 后再次预热")
+
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	assert.ErrorIs(t, provider.WarmUp(canceled), context.Canceled)
+
+	// 配置在创建后被修改，实例 ID 不再在范围内
+	config.MaxInstanceID = 3
+	assert.Error(t, provider.WarmUp(ctx))
+
+	assert.NoError(t, internal.NewSnowflakeGenerator(1).CheckClock())
+}
+
+// TestGenerateBucketedID 测试带时间桶前缀的 ID
+func TestGenerateBucketedID(t *testing.T) {
+	ctx := context.Background()
+	config := &Config{
+		ServiceName:   "test-bucket-service",
+		MaxInstanceID: 10,
+		InstanceID:    1,
+	}
+
+	provider, err := New(ctx, config)
+	assert.NoError(t, err)
+	defer provider.Close()
+
+	id, bucketKey := provider.GenerateBucketedID(time.Hour)
+	assert.True(t, strings.HasPrefix(id, bucketKey+"_"))
+
+	parsedKey, bucketStart, err := ParseBucketedID(id)
+	assert.NoError(t, err)
+	assert.Equal(t, bucketKey, parsedKey)
+	assert.Equal(t, bucketStart, bucketStart.Truncate(time.Hour))
+	assert.WithinDuration(t, time.Now(), bucketStart, time.Hour)
+
+	// 前缀与 UUID v7 内嵌的时间一致
+	generatedAt, err := internal.ExtractTimeFromUUIDV7(strings.TrimPrefix(id, bucketKey+"_"))
+	assert.NoError(t, err)
+	assert.Equal(t, bucketStart, generatedAt.UTC().Truncate(time.Hour))
+
+	// 同一桶内不重复
+	seen := make(map[string]bool)
+	for i := 0; i < 1000; i++ {
+		id, _ := provider.GenerateBucketedID(24 * time.Hour)
+		assert.False(t, seen[id], "分桶 ID 重复: %s", id)
+		seen[id] = true
+	}
+
+	// 无效桶大小按小时处理
+	_, defaultKey := provider.GenerateBucketedID(0)
+	_, hourKey := provider.GenerateBucketedID(time.Hour)
+	assert.Equal(t, hourKey, defaultKey)
+
+	// 无效 ID
+	_, _, err = ParseBucketedID("not-a-bucketed-id")
+	assert.Error(t, err)
+	_, _, err = ParseBucketedID("bad_" + provider.GetUUIDV7())
+	assert.Error(t, err)
+}
+
+// TestGenerateComposite 测试可排序的复合 ID
+func TestGenerateComposite(t *testing.T) {
+	ctx := context.Background()
+	config := &Config{
+		ServiceName:   "test-composite-service",
+		MaxInstanceID: 10,
+		InstanceID:    1,
+	}
+
+	provider, err := New(ctx, config)
+	assert.NoError(t, err)
+	defer provider.Close()
+
+	before := time.Now().Truncate(time.Millisecond)
+	id := provider.GenerateComposite(513)
+	assert.Len(t, id, CompositeIDLength)
+
+	generatedAt, shard, err := ParseCompositeID(id)
+	assert.NoError(t, err)
+	assert.Equal(t, uint16(513), shard)
+	assert.False(t, generatedAt.Before(before))
+	assert.WithinDuration(t, time.Now(), generatedAt, time.Second)
+
+	// 字典序与生成时间一致，分片取值边界
+	base := time.UnixMilli(1700000000000)
+	earlier := newCompositeID(65535, base)
+	later := newCompositeID(0, base.Add(time.Millisecond))
+	assert.True(t, earlier < later, "%s 应排在 %s 之前", earlier, later)
+	parsedAt, parsedShard, err := ParseCompositeID(earlier)
+	assert.NoError(t, err)
+	assert.Equal(t, base, parsedAt)
+	assert.Equal(t, uint16(65535), parsedShard)
+
+	// 不重复
+	seen := make(map[string]bool)
+	for i := 0; i < 1000; i++ {
+		id := provider.GenerateComposite(1)
+		assert.False(t, seen[id], "复合 ID 重复: %s", id)
+		seen[id] = true
+	}
+
+	// 无效 ID
+	for _, invalid := range []string{"", "too-short", "ZZZZZZZZZZZZZZZZZZZZZZZZZZ", "0000000000000000000000000U"} {
+		_, _, err := ParseCompositeID(invalid)
+		assert.Error(t, err, invalid)
+	}
+}
+
+// TestNewDeterministic 测试相同种子生成相同的 ID 序列
+func TestNewDeterministic(t *testing.T) {
+	generate := func(seed int64) []string {
+		provider := NewDeterministic(seed)
+		defer provider.Close()
+
+		snowflake, err := provider.GenerateSnowflake()
+		assert.NoError(t, err)
+		prefixed, err := provider.GeneratePrefixed("usr")
+		assert.NoError(t, err)
+		bucketed, _ := provider.GenerateBucketedID(time.Hour)
+		return []string{
+			provider.GetUUID(),
+			provider.GetUUIDV7(),
+			strconv.FormatInt(snowflake, 10),
+			provider.GenerateComposite(7),
+			prefixed,
+			bucketed,
+		}
+	}
+
+	first := generate(42)
+	assert.Equal(t, first, generate(42))
+	assert.NotEqual(t, first, generate(43))
+
+	// 生成的 ID 仍是合法且按时间有序的
+	provider := NewDeterministic(42)
+	defer provider.Close()
+	assert.True(t, provider.IsValidUUID(first[0]))
+	previous := ""
+	for i := 0; i < 100; i++ {
+		id := provider.GetUUIDV7()
+		assert.True(t, previous < id, "%s 应排在 %s 之后", id, previous)
+		previous = id
+	}
+	generatedAt, shard, err := ParseCompositeID(first[3])
+	assert.NoError(t, err)
+	assert.Equal(t, uint16(7), shard)
+	assert.Equal(t, 2024, generatedAt.UTC().Year())
+}
+
+// TestGeneratePrefixed 测试带类型前缀的 ID 生成和解析
+func TestGeneratePrefixed(t *testing.T) {
+	ctx := context.Background()
+	config := &Config{
+		ServiceName:   "test-prefixed-service",
+		MaxInstanceID: 10,
+		InstanceID:    3,
+	}
+
+	provider, err := New(ctx, config)
+	assert.NoError(t, err)
+	defer provider.Close()
+
+	id, err := provider.GeneratePrefixed("ord")
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(id, "ord_"), id)
+	assert.Len(t, id, len("ord")+1+PrefixedBodyLength)
+
+	prefix, sfID, err := ParsePrefixed(id)
+	assert.NoError(t, err)
+	assert.Equal(t, "ord", prefix)
+	_, instanceID, _ := provider.ParseSnowflake(sfID)
+	assert.Equal(t, int64(3), instanceID)
+
+	// 字典序与生成顺序一致
+	next, err := provider.GeneratePrefixed("ord")
+	assert.NoError(t, err)
+	assert.True(t, id < next, "%s 应排在 %s 之前", id, next)
+
+	// 编码边界
+	for _, v := range []int64{0, 61, 62, math.MaxInt64} {
+		_, parsed, err := ParsePrefixed(newPrefixedID("x", v))
+		assert.NoError(t, err)
+		assert.Equal(t, v, parsed)
+	}
+
+	// 无效前缀
+	for _, invalid := range []string{"", "Ord", "1ord", "or_d", "ord-x", strings.Repeat("a", MaxPrefixLength+1)} {
+		_, err := provider.GeneratePrefixed(invalid)
+		assert.Error(t, err, invalid)
+	}
+
+	// 无效 ID
+	for _, invalid := range []string{"", "ord", "ord_", "ord_0000000000", "Ord_00000000000", "ord_0000000000!", "ord_zzzzzzzzzzz"} {
+		_, _, err := ParsePrefixed(invalid)
+		assert.Error(t, err, invalid)
+	}
+}
+
+// TestGeneratorRegistry 测试可插拔 ID 生成器注册
+func TestGeneratorRegistry(t *testing.T) {
+	ctx := context.Background()
+	config := &Config{
+		ServiceName:   "test-registry-service",
+		MaxInstanceID: 10,
+		InstanceID:    1,
 	}
-	return oldValue
+
+	provider, err := New(ctx, config)
+	assert.NoError(t, err)
+	defer provider.Close()
+
+	// 内置生成器
+	uuid, err := provider.Generate(GeneratorUUIDV7)
+	assert.NoError(t, err)
+	assert.True(t, provider.IsValidUUID(uuid))
+
+	sfID, err := provider.Generate(GeneratorSnowflake)
+	assert.NoError(t, err)
+	id, err := strconv.ParseInt(sfID, 10, 64)
+	assert.NoError(t, err)
+	assert.Greater(t, id, int64(0))
+
+	// 自定义生成器
+	err = Register("test_user", GeneratorFunc(func(p Provider) (string, error) {
+		id, err := p.GenerateSnowflake()
+		if err != nil {
+			return "", err
+		}
+		return "usr_" + strconv.FormatInt(id, 10), nil
+	}))
+	assert.NoError(t, err)
+	t.Cleanup(func() { unregister("test_user") })
+	assert.Contains(t, Generators(), "test_user")
+
+	userID, err := provider.Generate("test_user")
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(userID, "usr_"))
+
+	// 非法注册
+	assert.Error(t, Register("", GeneratorFunc(func(p Provider) (string, error) { return "", nil })))
+	assert.Error(t, Register("test_nil", nil))
+	assert.Error(t, Register(GeneratorSnowflake, GeneratorFunc(func(p Provider) (string, error) { return "", nil })))
+
+	// 未注册的生成器
+	_, err = provider.Generate("not-registered")
+	assert.Error(t, err)
+}
+
+// fixedInstanceIDSource 测试用的分配器
+type fixedInstanceIDSource int
+
+func (s fixedInstanceIDSource) ID() int { return int(s) }
+
+// TestResolveInstanceID 测试实例 ID 来源优先级与冲突检测
+func TestResolveInstanceID(t *testing.T) {
+	t.Setenv("INSTANCE_ID", "")
+	config := &Config{ServiceName: "test-service", MaxInstanceID: 10}
+
+	id, source, err := ResolveInstanceID(config, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, InstanceIDSourceAuto, source)
+	assert.LessOrEqual(t, id, 10)
+
+	id, source, err = ResolveInstanceID(config, fixedInstanceIDSource(7))
+	assert.NoError(t, err)
+	assert.Equal(t, 7, id)
+	assert.Equal(t, InstanceIDSourceAllocator, source)
+
+	_, _, err = ResolveInstanceID(config, fixedInstanceIDSource(11))
+	assert.Error(t, err)
+
+	config.InstanceID = 3
+	id, source, err = ResolveInstanceID(config, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, id)
+	assert.Equal(t, InstanceIDSourceConfig, source)
+
+	_, _, err = ResolveInstanceID(config, fixedInstanceIDSource(7))
+	assert.Error(t, err)
+
+	// 环境变量与显式配置一致时不冲突，不一致时报错
+	t.Setenv("INSTANCE_ID", "3")
+	_, source, err = ResolveInstanceID(config, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, InstanceIDSourceConfig, source)
+
+	t.Setenv("INSTANCE_ID", "4")
+	_, _, err = ResolveInstanceID(config, nil)
+	assert.Error(t, err)
+
+	config.InstanceID = 0
+	id, source, err = ResolveInstanceID(config, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, 4, id)
+	assert.Equal(t, InstanceIDSourceEnv, source)
+
+	t.Setenv("INSTANCE_ID", "abc")
+	_, _, err = ResolveInstanceID(config, nil)
+	assert.Error(t, err)
+
+	// New 使用同样的规则
+	t.Setenv("INSTANCE_ID", "")
+	provider, err := New(context.Background(), config, WithInstanceIDSource(fixedInstanceIDSource(9)))
+	assert.NoError(t, err)
+	defer provider.Close()
+	snowflakeID, err := provider.GenerateSnowflake()
+	assert.NoError(t, err)
+	_, instanceID, _ := provider.ParseSnowflake(snowflakeID)
+	assert.Equal(t, int64(9), instanceID)
 }
 
-func setEnvRestore(key, value string) string {
-	oldValue := ""
-	if v, exists := os.LookupEnv(key); exists {
-		oldValue = v
+// BenchmarkGenerateSnowflakeParallel 并发生成 Snowflake ID 的基准测试
+func BenchmarkGenerateSnowflakeParallel(b *testing.B) {
+	provider, err := New(context.Background(), &Config{ServiceName: "bench-service", MaxInstanceID: 10, InstanceID: 1})
+	if err != nil {
+		b.Fatal(err)
 	}
-	os.Setenv(key, value)
-	return oldValue
+	defer provider.Close()
+
+	b.ReportAllocs()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			if _, err := provider.GenerateSnowflake(); err != nil {
+				b.Error(err)
+				return
+			}
+		}
+	})
 }
@@ -0,0 +1,68 @@
+// Package uidtest 提供校验 ID 生成器性质的测试辅助函数
+// 下游可在自己的测试中断言生成的 ID 唯一、有序，不依赖任何测试框架
+package uidtest
+
+import (
+	"fmt"
+	"sync"
+)
+
+// VerifyUnique 检查 ids 中没有重复值，发现重复时返回包含该值及两处下标的错误
+func VerifyUnique(ids []int64) error {
+	seen := make(map[int64]int, len(ids))
+	for i, id := range ids {
+		if prev, ok := seen[id]; ok {
+			return fmt.Errorf("uidtest: ID %d 重复，下标 %d 和 %d", id, prev, i)
+		}
+		seen[id] = i
+	}
+	return nil
+}
+
+// VerifySorted 检查 ids 严格递增，即按生成顺序排列的 Snowflake ID 既有序又不重复
+// 发现逆序或相等的相邻值时返回包含其下标的错误
+func VerifySorted(ids []int64) error {
+	for i := 1; i < len(ids); i++ {
+		if ids[i] <= ids[i-1] {
+			return fmt.Errorf("uidtest: 下标 %d 的 ID %d 不大于下标 %d 的 ID %d",
+				i, ids[i], i-1, ids[i-1])
+		}
+	}
+	return nil
+}
+
+// GenerateConcurrently 启动 workers 个协程，每个协程调用 generate 生成 perWorker 个 ID
+// 返回的切片中每个协程生成的 ID 连续存放并保持生成顺序，可分段用 VerifySorted 校验，
+// 整体用 VerifyUnique 校验；任一次生成失败时返回第一个错误
+func GenerateConcurrently(workers, perWorker int, generate func() (int64, error)) ([]int64, error) {
+	if workers <= 0 || perWorker <= 0 {
+		return nil, fmt.Errorf("uidtest: workers 和 perWorker 必须为正数，实际为 %d 和 %d", workers, perWorker)
+	}
+
+	ids := make([]int64, workers*perWorker)
+	errs := make([]error, workers)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			segment := ids[w*perWorker : (w+1)*perWorker]
+			for i := range segment {
+				id, err := generate()
+				if err != nil {
+					errs[w] = fmt.Errorf("uidtest: 协程 %d 生成 %d 个 ID 后失败: %w", w, i, err)
+					return
+				}
+				segment[i] = id
+			}
+		}(w)
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+	return ids, nil
+}
@@ -0,0 +1,47 @@
+package uidtest
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestVerifyUnique 测试重复检测
+func TestVerifyUnique(t *testing.T) {
+	assert.NoError(t, VerifyUnique(nil), "empty ids should be unique")
+	assert.NoError(t, VerifyUnique([]int64{3, 1, 2}))
+	assert.Error(t, VerifyUnique([]int64{1, 2, 3, 2}))
+}
+
+// TestVerifySorted 测试严格递增检测
+func TestVerifySorted(t *testing.T) {
+	assert.NoError(t, VerifySorted([]int64{1, 2, 5}))
+	assert.Error(t, VerifySorted([]int64{1, 3, 2}))
+	// 相等的相邻值同样视为违反
+	assert.Error(t, VerifySorted([]int64{1, 2, 2}))
+}
+
+// TestGenerateConcurrently 测试并发生成及错误传播
+func TestGenerateConcurrently(t *testing.T) {
+	var next atomic.Int64
+	ids, err := GenerateConcurrently(8, 100, func() (int64, error) {
+		return next.Add(1), nil
+	})
+	require.NoError(t, err)
+	require.Len(t, ids, 800)
+	assert.NoError(t, VerifyUnique(ids))
+	// 每个协程的分段保持生成顺序
+	for w := 0; w < 8; w++ {
+		assert.NoError(t, VerifySorted(ids[w*100:(w+1)*100]))
+	}
+
+	errBoom := errors.New("boom")
+	_, err = GenerateConcurrently(2, 10, func() (int64, error) { return 0, errBoom })
+	assert.ErrorIs(t, err, errBoom)
+
+	_, err = GenerateConcurrently(0, 10, func() (int64, error) { return next.Add(1), nil })
+	assert.Error(t, err, "zero workers should be rejected")
+}